@@ -58,6 +58,28 @@ func init() {
 	} else {
 		config.CRDReadyWaitTimeout = 30 * time.Second
 	}
+
+	if enableLeaderElection := os.Getenv("ENABLE_LEADER_ELECTION"); enableLeaderElection != "" {
+		config.EnableLeaderElection = enableLeaderElection == "true"
+	}
+
+	if leaseDuration := os.Getenv("LEADER_ELECTION_LEASE_DURATION"); leaseDuration != "" {
+		config.LeaderElectionLeaseDuration = mustParseDuration(leaseDuration)
+	} else {
+		config.LeaderElectionLeaseDuration = 15 * time.Second
+	}
+
+	if renewDeadline := os.Getenv("LEADER_ELECTION_RENEW_DEADLINE"); renewDeadline != "" {
+		config.LeaderElectionRenewDeadline = mustParseDuration(renewDeadline)
+	} else {
+		config.LeaderElectionRenewDeadline = 10 * time.Second
+	}
+
+	if retryPeriod := os.Getenv("LEADER_ELECTION_RETRY_PERIOD"); retryPeriod != "" {
+		config.LeaderElectionRetryPeriod = mustParseDuration(retryPeriod)
+	} else {
+		config.LeaderElectionRetryPeriod = 2 * time.Second
+	}
 }
 
 func main() {
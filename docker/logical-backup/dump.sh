@@ -15,6 +15,33 @@ TOKEN=$(cat /var/run/secrets/kubernetes.io/serviceaccount/token)
 K8S_API_URL=https://$KUBERNETES_SERVICE_HOST:$KUBERNETES_SERVICE_PORT/api/v1
 CERT=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt
 
+LOGICAL_BACKUP_PROVIDER=${LOGICAL_BACKUP_PROVIDER:-s3}
+
+# mimic bucket setup from Spilo
+# to keep logical backups at the same path as WAL
+# NB: $LOGICAL_BACKUP_S3_BUCKET_SCOPE_SUFFIX already contains the leading "/" when set by the Postgres Operator
+# NB: $LOGICAL_BACKUP_FILE_NAME is set by the Postgres Operator for on-demand backups
+BACKUP_KEY="spilo/"$SCOPE$LOGICAL_BACKUP_S3_BUCKET_SCOPE_SUFFIX"/logical_backups/"${LOGICAL_BACKUP_FILE_NAME:-$(date +%s).sql.gz}
+
+case $LOGICAL_BACKUP_PROVIDER in
+    s3)
+        PATH_TO_BACKUP=s3://$LOGICAL_BACKUP_S3_BUCKET/$BACKUP_KEY
+        ;;
+    gcs)
+        PATH_TO_BACKUP=gs://$LOGICAL_BACKUP_GCS_BUCKET/$BACKUP_KEY
+        ;;
+    azure)
+        PATH_TO_BACKUP=https://$AZURE_STORAGE_ACCOUNT.blob.core.windows.net/$LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER/$BACKUP_KEY
+        ;;
+    pvc)
+        PATH_TO_BACKUP=$LOGICAL_BACKUP_PVC_PATH/$BACKUP_KEY
+        ;;
+    *)
+        echo "unknown logical backup provider $LOGICAL_BACKUP_PROVIDER"
+        exit 1
+        ;;
+esac
+
 function estimate_size {
     "$PG_BIN"/psql -tqAc "${ALL_DB_SIZE_QUERY}"
 }
@@ -31,11 +58,6 @@ function compress {
 function aws_upload {
     declare -r EXPECTED_SIZE="$1"
 
-    # mimic bucket setup from Spilo
-    # to keep logical backups at the same path as WAL
-    # NB: $LOGICAL_BACKUP_S3_BUCKET_SCOPE_SUFFIX already contains the leading "/" when set by the Postgres Operator
-    PATH_TO_BACKUP=s3://$LOGICAL_BACKUP_S3_BUCKET"/spilo/"$SCOPE$LOGICAL_BACKUP_S3_BUCKET_SCOPE_SUFFIX"/logical_backups/"$(date +%s).sql.gz
-
     args=()
 
     [[ ! -z "$EXPECTED_SIZE" ]] && args+=("--expected-size=$EXPECTED_SIZE")
@@ -46,6 +68,139 @@ function aws_upload {
     aws s3 cp - "$PATH_TO_BACKUP" "${args[@]//\'/}"
 }
 
+function gcs_upload {
+    # without a service account key the default credentials of the pod are used
+    if [[ ! -z "${GOOGLE_APPLICATION_CREDENTIALS:-}" ]]; then
+        gcloud auth activate-service-account --key-file="$GOOGLE_APPLICATION_CREDENTIALS" --quiet
+    fi
+
+    gsutil -q cp - "$PATH_TO_BACKUP"
+}
+
+function azure_upload {
+    # the Azure CLI reads the storage account and its key from AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY
+    # and needs the size of the blob in advance, so the dump is buffered in a file
+    TMP_FILE=$(mktemp)
+    cat > "$TMP_FILE"
+
+    az storage blob upload --only-show-errors \
+        --container-name "$LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER" \
+        --name "$BACKUP_KEY" \
+        --file "$TMP_FILE" || { rm -f "$TMP_FILE"; return 1; }
+
+    rm -f "$TMP_FILE"
+}
+
+function pvc_upload {
+    mkdir -p "$(dirname "$PATH_TO_BACKUP")"
+    cat > "$PATH_TO_BACKUP"
+}
+
+function upload {
+    case $LOGICAL_BACKUP_PROVIDER in
+        s3)
+            aws_upload "$1"
+            ;;
+        gcs)
+            gcs_upload
+            ;;
+        azure)
+            azure_upload
+            ;;
+        pvc)
+            pvc_upload
+            ;;
+    esac
+}
+
+function backup_size {
+    case $LOGICAL_BACKUP_PROVIDER in
+        s3)
+            args=()
+
+            [[ ! -z "$LOGICAL_BACKUP_S3_ENDPOINT" ]] && args+=("--endpoint-url=$LOGICAL_BACKUP_S3_ENDPOINT")
+            [[ ! -z "$LOGICAL_BACKUP_S3_REGION" ]] && args+=("--region=$LOGICAL_BACKUP_S3_REGION")
+
+            aws s3 ls "$PATH_TO_BACKUP" "${args[@]//\'/}" | awk '{print $3}'
+            ;;
+        gcs)
+            gsutil du "$PATH_TO_BACKUP" | awk '{print $1}'
+            ;;
+        azure)
+            az storage blob show --only-show-errors \
+                --container-name "$LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER" \
+                --name "$BACKUP_KEY" \
+                --query properties.contentLength --output tsv
+            ;;
+        pvc)
+            stat --format=%s "$PATH_TO_BACKUP"
+            ;;
+    esac
+}
+
+function report {
+    # the Postgres Operator reads the location and the size of the backup from the termination message
+    SIZE=$(backup_size)
+    echo "{\"location\": \"$PATH_TO_BACKUP\", \"size\": ${SIZE:-0}}" > /dev/termination-log
+}
+
+function list_dumps {
+    # prints the file names of the dumps of the cluster
+    case $LOGICAL_BACKUP_PROVIDER in
+        gcs)
+            gsutil ls "$BACKUP_DIR/" | sed 's|.*/||'
+            ;;
+        azure)
+            az storage blob list --only-show-errors \
+                --container-name "$LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER" \
+                --prefix "$(dirname "$BACKUP_KEY")/" \
+                --query "[].name" --output tsv | sed 's|.*/||'
+            ;;
+        pvc)
+            ls "$BACKUP_DIR"
+            ;;
+    esac
+}
+
+function delete_dump {
+    declare -r DUMP="$1"
+
+    echo "removing logical backup $BACKUP_DIR/$DUMP"
+    case $LOGICAL_BACKUP_PROVIDER in
+        gcs)
+            gsutil -q rm "$BACKUP_DIR/$DUMP"
+            ;;
+        azure)
+            az storage blob delete --only-show-errors \
+                --container-name "$LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER" \
+                --name "$(dirname "$BACKUP_KEY")/$DUMP"
+            ;;
+        pvc)
+            rm -f "$BACKUP_DIR/$DUMP"
+            ;;
+    esac
+}
+
+function prune {
+    # the Postgres Operator only reaches S3, so the retention of scheduled dumps on the other providers is applied here;
+    # scheduled dumps are named after the time they were taken and the latest one is always kept
+    BACKUP_DIR=$(dirname "$PATH_TO_BACKUP")
+    RETENTION_COUNT=${LOGICAL_BACKUP_RETENTION_COUNT:-0}
+    RETENTION_DAYS=${LOGICAL_BACKUP_RETENTION_DAYS:-0}
+    OLDEST_KEPT=$(( $(date +%s) - RETENTION_DAYS * 86400 ))
+
+    (( RETENTION_COUNT == 0 && RETENTION_DAYS == 0 )) && return 0
+
+    mapfile -t DUMPS < <(list_dumps | grep -E '^[0-9]+\.sql\.gz$' | sort -rn)
+    for i in "${!DUMPS[@]}"; do
+        (( i == 0 )) && continue
+        TAKEN_AT=${DUMPS[$i]%%.*}
+        if (( RETENTION_COUNT > 0 && i >= RETENTION_COUNT )) || (( RETENTION_DAYS > 0 && TAKEN_AT < OLDEST_KEPT )); then
+            delete_dump "${DUMPS[$i]}"
+        fi
+    done
+}
+
 function get_pods {
     declare -r SELECTOR="$1"
 
@@ -93,8 +248,11 @@ for search in "${search_strategy[@]}"; do
 done
 
 set -x
-dump | compress | aws_upload $(($(estimate_size) / DUMP_SIZE_COEFF))
+dump | compress | upload $(($(estimate_size) / DUMP_SIZE_COEFF))
 [[ ${PIPESTATUS[0]} != 0 || ${PIPESTATUS[1]} != 0 || ${PIPESTATUS[2]} != 0 ]] && (( ERRORCOUNT += 1 ))
 set +x
 
+(( ERRORCOUNT == 0 )) && (report || echo "could not report the location and the size of the backup")
+[[ $LOGICAL_BACKUP_PROVIDER != "s3" ]] && (( ERRORCOUNT == 0 )) && (prune || echo "could not remove old logical backups")
+
 exit $ERRORCOUNT
@@ -6,6 +6,7 @@ require (
 	github.com/aws/aws-sdk-go v1.29.33
 	github.com/lib/pq v1.3.0
 	github.com/motomux/pretty v0.0.0-20161209205251-b2aad2c9a95d
+	github.com/prometheus/client_golang v1.2.1
 	github.com/r3labs/diff v0.0.0-20191120142937-b4ed99a31f5a
 	github.com/sirupsen/logrus v1.5.0
 	github.com/stretchr/testify v1.4.0
@@ -0,0 +1,171 @@
+/*
+Copyright © 2019 Vineeth Pothulapati <vineethpothulapati@outlook.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	PostgresqlLister "github.com/zalando/postgres-operator/pkg/generated/clientset/versioned/typed/acid.zalan.do/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// OperatorAPIPort is the port the REST API of the postgres operator listens on
+const OperatorAPIPort = "8080"
+
+type logicalBackupDump struct {
+	Name         string    `json:"name"`
+	Location     string    `json:"location"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Scheduled    bool      `json:"scheduled"`
+}
+
+type logicalBackupCatalog struct {
+	Cluster   string `json:"cluster"`
+	Location  string `json:"location"`
+	Retention struct {
+		Count int32 `json:"count"`
+		Days  int32 `json:"days"`
+	} `json:"retention"`
+	Dumps []logicalBackupDump `json:"dumps"`
+}
+
+// backupsCmd represents the backups command
+var backupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "Lists the logical backups of the specified postgres cluster",
+	Long:  `Lists the logical backups of the postgres cluster from the catalog of the postgres operator together with the retention applied to them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterName, _ := cmd.Flags().GetString("cluster")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		if clusterName == "" {
+			log.Fatal("provide the cluster name with -c")
+		}
+		listBackups(clusterName, namespace)
+	},
+	Example: `
+#List the logical backups of the provided cluster
+kubectl pg backups -c cluster01
+
+#List the logical backups of the provided cluster in the namespace
+kubectl pg backups -c cluster01 -n namespace01
+`,
+}
+
+func listBackups(clusterName string, namespace string) {
+	config := getConfig()
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	postgresConfig, err := PostgresqlLister.NewForConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	postgresCluster, err := postgresConfig.Postgresqls(namespace).Get(clusterName, metav1.GetOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the REST API of the operator identifies clusters by team and the cluster name without the team prefix
+	team := strings.ToLower(postgresCluster.Spec.TeamID)
+	name := strings.TrimPrefix(clusterName, team+"-")
+
+	operator := getPostgresOperator(client)
+	if operator == nil {
+		log.Fatal("could not find the postgres operator")
+	}
+
+	response, err := client.CoreV1().RESTClient().Get().Namespace(operator.Namespace).
+		Resource("services").
+		Name(OperatorName+":"+OperatorAPIPort).
+		SubResource("proxy").
+		Suffix("clusters", team, namespace, name, "logical-backups").
+		DoRaw()
+	if err != nil {
+		log.Fatalf("could not get logical backups of the cluster %s: %v", clusterName, err)
+	}
+
+	var catalog logicalBackupCatalog
+	if err := json.Unmarshal(response, &catalog); err != nil {
+		log.Fatalf("could not decode logical backups of the cluster %s: %v", clusterName, err)
+	}
+
+	fmt.Printf("Location: %s\n", catalog.Location)
+	fmt.Printf("Retention: %s\n\n", retentionDescription(catalog.Retention.Count, catalog.Retention.Days))
+
+	if len(catalog.Dumps) == 0 {
+		fmt.Printf("No logical backups found for the cluster: %s\n", clusterName)
+		return
+	}
+
+	template := "%-32s%-12s%-28s%-12s\n"
+	fmt.Printf(template, "NAME", "SIZE", "LAST MODIFIED", "SCHEDULED")
+	for _, dump := range catalog.Dumps {
+		fmt.Printf(template, dump.Name,
+			humanReadableSize(dump.Size),
+			dump.LastModified.Format(time.RFC3339),
+			fmt.Sprintf("%t", dump.Scheduled))
+	}
+}
+
+func retentionDescription(count int32, days int32) string {
+	limits := make([]string, 0)
+	if count > 0 {
+		limits = append(limits, fmt.Sprintf("latest %d scheduled dumps", count))
+	}
+	if days > 0 {
+		limits = append(limits, fmt.Sprintf("scheduled dumps of the last %d days", days))
+	}
+	if len(limits) == 0 {
+		return "disabled"
+	}
+	return strings.Join(limits, ", ")
+}
+
+func humanReadableSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	backupsCmd.Flags().StringP("cluster", "c", "", "list the logical backups of the provided cluster")
+	backupsCmd.Flags().StringP("namespace", "n", getCurrentNamespace(), "provide the namespace")
+	rootCmd.AddCommand(backupsCmd)
+}
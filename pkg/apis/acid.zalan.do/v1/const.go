@@ -12,6 +12,53 @@ const (
 	ClusterStatusInvalid      = "Invalid"
 )
 
+// UpgradePhaseChecking etc : phases of a major version upgrade of a Postgres cluster
+const (
+	UpgradePhaseChecking       = "Checking"
+	UpgradePhaseCheckFailed    = "CheckFailed"
+	UpgradePhaseUpgrading      = "Upgrading"
+	UpgradePhaseRecreatingPods = "RecreatingPods"
+	UpgradePhaseFailed         = "Failed"
+	UpgradePhaseFinished       = "Finished"
+)
+
+// BackupMethodBasebackup etc : methods of an on-demand backup of a Postgres cluster
+const (
+	BackupMethodBasebackup = "basebackup"
+	BackupMethodLogical    = "logical"
+)
+
+// BackupPhaseRunning etc : phases of an on-demand backup of a Postgres cluster
+const (
+	BackupPhaseRunning   = "Running"
+	BackupPhaseSucceeded = "Succeeded"
+	BackupPhaseFailed    = "Failed"
+)
+
+// RestorePhaseRunning etc : phases of a point-in-time recovery of a Postgres cluster into a new cluster
+const (
+	RestorePhaseRunning   = "Running"
+	RestorePhaseSucceeded = "Succeeded"
+	RestorePhaseFailed    = "Failed"
+)
+
+// VerificationPhaseRunning etc : phases of a verification that a Postgres cluster can be restored
+const (
+	VerificationPhaseRunning   = "Running"
+	VerificationPhaseSucceeded = "Succeeded"
+	VerificationPhaseFailed    = "Failed"
+)
+
+// ConditionReady etc : types of conditions reported in the status of a Postgres cluster
+const (
+	ConditionReady          ConditionType = "Ready"
+	ConditionSyncing        ConditionType = "Syncing"
+	ConditionBackupHealthy  ConditionType = "BackupHealthy"
+	ConditionPoolerReady    ConditionType = "PoolerReady"
+	ConditionUpgradePending ConditionType = "UpgradePending"
+	ConditionPaused         ConditionType = "Paused"
+)
+
 const (
 	serviceNameMaxLength   = 63
 	clusterNameMaxLength   = serviceNameMaxLength - len("-repl")
@@ -17,6 +17,16 @@ const (
 	OperatorConfigCRDResourcePlural = "operatorconfigurations"
 	OperatorConfigCRDResourceName   = OperatorConfigCRDResourcePlural + "." + acidzalando.GroupName
 	OperatorConfigCRDResourceShort  = "opconfig"
+
+	PostgresBackupCRDResourceKind   = "PostgresBackup"
+	PostgresBackupCRDResourcePlural = "postgresbackups"
+	PostgresBackupCRDResourceName   = PostgresBackupCRDResourcePlural + "." + acidzalando.GroupName
+	PostgresBackupCRDResourceShort  = "pgbackup"
+
+	PostgresRestoreCRDResourceKind   = "PostgresRestore"
+	PostgresRestoreCRDResourcePlural = "postgresrestores"
+	PostgresRestoreCRDResourceName   = PostgresRestoreCRDResourcePlural + "." + acidzalando.GroupName
+	PostgresRestoreCRDResourceShort  = "pgrestore"
 )
 
 // PostgresCRDResourceColumns definition of AdditionalPrinterColumns for postgresql CRD
@@ -103,6 +113,72 @@ var OperatorConfigCRDResourceColumns = []apiextv1beta1.CustomResourceColumnDefin
 	},
 }
 
+// PostgresBackupCRDResourceColumns definition of AdditionalPrinterColumns for PostgresBackup CRD
+var PostgresBackupCRDResourceColumns = []apiextv1beta1.CustomResourceColumnDefinition{
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Cluster",
+		Type:        "string",
+		Description: "Postgres cluster to back up",
+		JSONPath:    ".spec.cluster",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Method",
+		Type:        "string",
+		Description: "Backup method",
+		JSONPath:    ".spec.method",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "Phase of the backup",
+		JSONPath:    ".status.phase",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Size",
+		Type:        "integer",
+		Description: "Size of the backup in bytes",
+		JSONPath:    ".status.size",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:     "Age",
+		Type:     "date",
+		JSONPath: ".metadata.creationTimestamp",
+	},
+}
+
+// PostgresRestoreCRDResourceColumns definition of AdditionalPrinterColumns for PostgresRestore CRD
+var PostgresRestoreCRDResourceColumns = []apiextv1beta1.CustomResourceColumnDefinition{
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Cluster",
+		Type:        "string",
+		Description: "Postgres cluster the backup is restored into",
+		JSONPath:    ".spec.cluster",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Target-Time",
+		Type:        "string",
+		Description: "Point in time the cluster is recovered to",
+		JSONPath:    ".status.targetTime",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "Phase of the restore",
+		JSONPath:    ".status.phase",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:        "Reached-LSN",
+		Type:        "string",
+		Description: "LSN the recovery stopped at",
+		JSONPath:    ".status.reachedLSN",
+	},
+	apiextv1beta1.CustomResourceColumnDefinition{
+		Name:     "Age",
+		Type:     "date",
+		JSONPath: ".metadata.creationTimestamp",
+	},
+}
+
 var min0 = 0.0
 var min1 = 1.0
 var min2 = 2.0
@@ -144,6 +220,28 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 							},
 						},
 					},
+					"bootstrapSQL": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "array",
+								Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1beta1.JSONSchemaProps{
+										Type:     "object",
+										Required: []string{"configMap"},
+										Properties: map[string]apiextv1beta1.JSONSchemaProps{
+											"configMap": {
+												Type: "string",
+											},
+											"key": {
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"clone": {
 						Type:     "object",
 						Required: []string{"cluster"},
@@ -274,6 +372,27 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 					"enableShmVolume": {
 						Type: "boolean",
 					},
+					"extensions": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "object",
+								AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+									Schema: &apiextv1beta1.JSONSchemaProps{
+										Type: "object",
+										Properties: map[string]apiextv1beta1.JSONSchemaProps{
+											"schema": {
+												Type: "string",
+											},
+											"version": {
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"init_containers": {
 						Type:        "array",
 						Description: "Deprecated",
@@ -297,10 +416,60 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 							},
 						},
 					},
+					"logicalBackupRetention": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"count": {
+								Type:    "integer",
+								Minimum: &min0,
+							},
+							"days": {
+								Type:    "integer",
+								Minimum: &min0,
+							},
+						},
+					},
 					"logicalBackupSchedule": {
 						Type:    "string",
 						Pattern: "^(\\d+|\\*)(/\\d+)?(\\s+(\\d+|\\*)(/\\d+)?){4}$",
 					},
+					"logicalBackupTarget": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"bucket": {
+								Type: "string",
+							},
+							"claimName": {
+								Type: "string",
+							},
+							"container": {
+								Type: "string",
+							},
+							"credentialsSecret": {
+								Type: "string",
+							},
+							"provider": {
+								Type: "string",
+								Enum: []apiextv1beta1.JSON{
+									{
+										Raw: []byte(`"s3"`),
+									},
+									{
+										Raw: []byte(`"gcs"`),
+									},
+									{
+										Raw: []byte(`"azure"`),
+									},
+									{
+										Raw: []byte(`"pvc"`),
+									},
+								},
+							},
+							"storageAccount": {
+								Type: "string",
+							},
+						},
+					},
 					"maintenanceWindows": {
 						Type: "array",
 						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
@@ -314,6 +483,22 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 						Type:    "integer",
 						Minimum: &min0,
 					},
+					"passwordRotation": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"interval": {
+										Type: "string",
+									},
+									"inPlace": {
+										Type: "boolean",
+									},
+								},
+							},
+						},
+					},
 					"patroni": {
 						Type: "object",
 						Properties: map[string]apiextv1beta1.JSONSchemaProps{
@@ -415,6 +600,60 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 							},
 						},
 					},
+					"preparedDatabases": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"defaultUsers": {
+										Type: "boolean",
+									},
+									"schemas": {
+										Type: "object",
+										AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+											Schema: &apiextv1beta1.JSONSchemaProps{
+												Type: "object",
+												Properties: map[string]apiextv1beta1.JSONSchemaProps{
+													"defaultRoles": {
+														Type: "boolean",
+													},
+													"defaultUsers": {
+														Type: "boolean",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"publications": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"database"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"allTables": {
+										Type: "boolean",
+									},
+									"database": {
+										Type: "string",
+									},
+									"tables": {
+										Type: "array",
+										Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+											Schema: &apiextv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"replicaLoadBalancer": {
 						Type:        "boolean",
 						Description: "Deprecated",
@@ -457,6 +696,33 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 							},
 						},
 					},
+					"restoreVerification": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"checks": {
+								Type: "array",
+								Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1beta1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"database": {
+								Type: "string",
+							},
+							"interval": {
+								Type: "string",
+							},
+						},
+					},
+					"secretNamespaces": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+						},
+					},
 					"serviceAnnotations": {
 						Type: "object",
 						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
@@ -488,6 +754,43 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 							},
 						},
 					},
+					"subscriptions": {
+						Type: "object",
+						AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"database", "cluster", "publications"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"cluster": {
+										Type: "string",
+									},
+									"copyData": {
+										Type: "boolean",
+									},
+									"database": {
+										Type: "string",
+									},
+									"enabled": {
+										Type: "boolean",
+									},
+									"publications": {
+										Type: "array",
+										Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+											Schema: &apiextv1beta1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
+									"sourceDatabase": {
+										Type: "string",
+									},
+									"user": {
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
 					"teamId": {
 						Type: "string",
 					},
@@ -679,60 +982,242 @@ var PostgresCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
 				},
 			},
 			"status": {
-				Type: "object",
-				AdditionalProperties: &apiextv1beta1.JSONSchemaPropsOrBool{
-					Schema: &apiextv1beta1.JSONSchemaProps{
-						Type: "string",
-					},
-				},
-			},
-		},
-	},
-}
-
-// OperatorConfigCRDResourceValidation to check applied manifest parameters
-var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
-	OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
-		Type:     "object",
-		Required: []string{"kind", "apiVersion", "configuration"},
-		Properties: map[string]apiextv1beta1.JSONSchemaProps{
-			"kind": {
-				Type: "string",
-				Enum: []apiextv1beta1.JSON{
-					{
-						Raw: []byte(`"OperatorConfiguration"`),
-					},
-				},
-			},
-			"apiVersion": {
-				Type: "string",
-				Enum: []apiextv1beta1.JSON{
-					{
-						Raw: []byte(`"acid.zalan.do/v1"`),
-					},
-				},
-			},
-			"configuration": {
 				Type: "object",
 				Properties: map[string]apiextv1beta1.JSONSchemaProps{
-					"docker_image": {
-						Type: "string",
-					},
-					"enable_crd_validation": {
-						Type: "boolean",
-					},
-					"enable_shm_volume": {
-						Type: "boolean",
-					},
-					"etcd_host": {
+					"PostgresClusterStatus": {
 						Type: "string",
 					},
-					"max_instances": {
-						Type:        "integer",
-						Description: "-1 = disabled",
-						Minimum:     &minDisable,
+					"pendingMaintenance": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+						},
 					},
-					"min_instances": {
+					"majorVersionUpgrade": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"fromVersion": {
+								Type: "string",
+							},
+							"toVersion": {
+								Type: "string",
+							},
+							"phase": {
+								Type: "string",
+							},
+							"message": {
+								Type: "string",
+							},
+						},
+					},
+					"restoreVerification": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"phase": {
+								Type: "string",
+							},
+							"clone": {
+								Type: "string",
+							},
+							"targetTime": {
+								Type: "string",
+							},
+							"startTime": {
+								Type:   "string",
+								Format: "date-time",
+							},
+							"completionTime": {
+								Type:   "string",
+								Format: "date-time",
+							},
+							"recoveryTime": {
+								Type: "string",
+							},
+							"lastSuccessTime": {
+								Type:   "string",
+								Format: "date-time",
+							},
+							"message": {
+								Type: "string",
+							},
+						},
+					},
+					"deprecatedRoles": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"name", "deprecationTime"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"name": {
+										Type: "string",
+									},
+									"deprecationTime": {
+										Type:   "string",
+										Format: "date-time",
+									},
+								},
+							},
+						},
+					},
+					"extensionErrors": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"database", "extension", "message"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"database": {
+										Type: "string",
+									},
+									"extension": {
+										Type: "string",
+									},
+									"message": {
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+					"bootstrapSQLErrors": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"database", "message"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"database": {
+										Type: "string",
+									},
+									"message": {
+										Type: "string",
+									},
+									"script": {
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+					"observedGeneration": {
+						Type: "integer",
+					},
+					"masterPod": {
+						Type: "string",
+					},
+					"instances": {
+						Type: "integer",
+					},
+					"readyInstances": {
+						Type: "integer",
+					},
+					"lastSyncError": {
+						Type: "string",
+					},
+					"conditions": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type:     "object",
+								Required: []string{"type", "status"},
+								Properties: map[string]apiextv1beta1.JSONSchemaProps{
+									"type": {
+										Type: "string",
+									},
+									"status": {
+										Type: "string",
+										Enum: []apiextv1beta1.JSON{
+											{
+												Raw: []byte(`"True"`),
+											},
+											{
+												Raw: []byte(`"False"`),
+											},
+											{
+												Raw: []byte(`"Unknown"`),
+											},
+										},
+									},
+									"lastTransitionTime": {
+										Type:   "string",
+										Format: "date-time",
+									},
+									"reason": {
+										Type: "string",
+									},
+									"message": {
+										Type: "string",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// OperatorConfigCRDResourceValidation to check applied manifest parameters
+var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
+	OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"kind", "apiVersion", "configuration"},
+		Properties: map[string]apiextv1beta1.JSONSchemaProps{
+			"kind": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"OperatorConfiguration"`),
+					},
+				},
+			},
+			"apiVersion": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"acid.zalan.do/v1"`),
+					},
+				},
+			},
+			"configuration": {
+				Type: "object",
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"allowed_extensions": {
+						Type: "array",
+						Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1beta1.JSONSchemaProps{
+								Type: "string",
+							},
+						},
+					},
+					"docker_image": {
+						Type: "string",
+					},
+					"enable_crd_validation": {
+						Type: "boolean",
+					},
+					"enable_dry_run": {
+						Type: "boolean",
+					},
+					"enable_paused_cluster_deletion": {
+						Type: "boolean",
+					},
+					"enable_shm_volume": {
+						Type: "boolean",
+					},
+					"etcd_host": {
+						Type: "string",
+					},
+					"max_instances": {
+						Type:        "integer",
+						Description: "-1 = disabled",
+						Minimum:     &minDisable,
+					},
+					"min_instances": {
 						Type:        "integer",
 						Description: "-1 = disabled",
 						Minimum:     &minDisable,
@@ -767,6 +1252,47 @@ var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation
 							"super_username": {
 								Type: "string",
 							},
+							"enable_role_deprecation": {
+								Type: "boolean",
+							},
+							"role_deletion_suffix": {
+								Type: "string",
+							},
+							"enable_role_drop": {
+								Type: "boolean",
+							},
+							"role_drop_grace_period": {
+								Type: "string",
+							},
+							"enable_password_rotation": {
+								Type: "boolean",
+							},
+							"password_rotation_interval": {
+								Type: "string",
+							},
+							"password_rotation_overlap_period": {
+								Type: "string",
+							},
+							"enable_system_password_rotation": {
+								Type: "boolean",
+							},
+							"password_encryption": {
+								Type: "string",
+								Enum: []apiextv1beta1.JSON{
+									{
+										Raw: []byte(`"md5"`),
+									},
+									{
+										Raw: []byte(`"scram-sha-256"`),
+									},
+								},
+							},
+							"enable_password_encryption_migration": {
+								Type: "boolean",
+							},
+							"enable_cross_namespace_secrets": {
+								Type: "boolean",
+							},
 						},
 					},
 					"kubernetes": {
@@ -997,9 +1523,52 @@ var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation
 					"logical_backup": {
 						Type: "object",
 						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"logical_backup_azure_credentials_secret": {
+								Type: "string",
+							},
+							"logical_backup_azure_storage_account": {
+								Type: "string",
+							},
+							"logical_backup_azure_storage_container": {
+								Type: "string",
+							},
 							"logical_backup_docker_image": {
 								Type: "string",
 							},
+							"logical_backup_gcs_bucket": {
+								Type: "string",
+							},
+							"logical_backup_gcs_credentials_secret": {
+								Type: "string",
+							},
+							"logical_backup_provider": {
+								Type: "string",
+								Enum: []apiextv1beta1.JSON{
+									{
+										Raw: []byte(`"s3"`),
+									},
+									{
+										Raw: []byte(`"gcs"`),
+									},
+									{
+										Raw: []byte(`"azure"`),
+									},
+									{
+										Raw: []byte(`"pvc"`),
+									},
+								},
+							},
+							"logical_backup_pvc_claim_name": {
+								Type: "string",
+							},
+							"logical_backup_retention_count": {
+								Type:    "integer",
+								Minimum: &min0,
+							},
+							"logical_backup_retention_days": {
+								Type:    "integer",
+								Minimum: &min0,
+							},
 							"logical_backup_s3_access_key_id": {
 								Type: "string",
 							},
@@ -1099,6 +1668,45 @@ var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation
 							},
 						},
 					},
+					"admission_webhook": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"enable_admission_webhook": {
+								Type: "boolean",
+							},
+							"webhook_port": {
+								Type: "integer",
+							},
+							"webhook_tls_cert_file": {
+								Type: "string",
+							},
+							"webhook_tls_key_file": {
+								Type: "string",
+							},
+							"enable_defaulting_webhook": {
+								Type: "boolean",
+							},
+							"defaulted_fields": {
+								Type: "array",
+								Items: &apiextv1beta1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1beta1.JSONSchemaProps{
+										Type: "string",
+										Enum: []apiextv1beta1.JSON{
+											{
+												Raw: []byte(`"resources"`),
+											},
+											{
+												Raw: []byte(`"docker_image"`),
+											},
+											{
+												Raw: []byte(`"connection_pool"`),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"scalyr": {
 						Type: "object",
 						Properties: map[string]apiextv1beta1.JSONSchemaProps{
@@ -1177,6 +1785,48 @@ var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation
 							},
 						},
 					},
+					"secret_backend": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"secret_backend_type": {
+								Type: "string",
+								Enum: []apiextv1beta1.JSON{
+									{
+										Raw: []byte(`"kubernetes"`),
+									},
+									{
+										Raw: []byte(`"vault"`),
+									},
+								},
+							},
+							"vault_address": {
+								Type: "string",
+							},
+							"vault_kv_mount": {
+								Type: "string",
+							},
+							"vault_path_prefix": {
+								Type: "string",
+							},
+							"vault_token_secret_name": {
+								Type: "string",
+							},
+						},
+					},
+					"restore_verification": {
+						Type: "object",
+						Properties: map[string]apiextv1beta1.JSONSchemaProps{
+							"enable_restore_verification": {
+								Type: "boolean",
+							},
+							"restore_verification_interval": {
+								Type: "string",
+							},
+							"restore_verification_timeout": {
+								Type: "string",
+							},
+						},
+					},
 				},
 			},
 			"status": {
@@ -1191,6 +1841,157 @@ var OperatorConfigCRDResourceValidation = apiextv1beta1.CustomResourceValidation
 	},
 }
 
+// PostgresBackupCRDResourceValidation to check applied manifest parameters
+var PostgresBackupCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
+	OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"kind", "apiVersion", "spec"},
+		Properties: map[string]apiextv1beta1.JSONSchemaProps{
+			"kind": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"PostgresBackup"`),
+					},
+				},
+			},
+			"apiVersion": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"acid.zalan.do/v1"`),
+					},
+				},
+			},
+			"spec": {
+				Type:     "object",
+				Required: []string{"cluster", "method"},
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"cluster": {
+						Type: "string",
+					},
+					"method": {
+						Type: "string",
+						Enum: []apiextv1beta1.JSON{
+							{
+								Raw: []byte(`"basebackup"`),
+							},
+							{
+								Raw: []byte(`"logical"`),
+							},
+						},
+					},
+				},
+			},
+			"status": {
+				Type: "object",
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"completionTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"jobName": {
+						Type: "string",
+					},
+					"location": {
+						Type: "string",
+					},
+					"message": {
+						Type: "string",
+					},
+					"phase": {
+						Type: "string",
+					},
+					"size": {
+						Type: "integer",
+					},
+					"startTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"walEnd": {
+						Type: "string",
+					},
+					"walStart": {
+						Type: "string",
+					},
+				},
+			},
+		},
+	},
+}
+
+// PostgresRestoreCRDResourceValidation to check applied manifest parameters
+var PostgresRestoreCRDResourceValidation = apiextv1beta1.CustomResourceValidation{
+	OpenAPIV3Schema: &apiextv1beta1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"kind", "apiVersion", "spec"},
+		Properties: map[string]apiextv1beta1.JSONSchemaProps{
+			"kind": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"PostgresRestore"`),
+					},
+				},
+			},
+			"apiVersion": {
+				Type: "string",
+				Enum: []apiextv1beta1.JSON{
+					{
+						Raw: []byte(`"acid.zalan.do/v1"`),
+					},
+				},
+			},
+			"spec": {
+				Type:     "object",
+				Required: []string{"cluster"},
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"backup": {
+						Type: "string",
+					},
+					"cluster": {
+						Type: "string",
+					},
+					"sourceCluster": {
+						Type: "string",
+					},
+					"targetTime": {
+						Type:        "string",
+						Description: "Date-time format that specifies a timezone as an offset relative to UTC e.g. 1996-12-19T16:39:57-08:00",
+						Pattern:     "^([0-9]+)-(0[1-9]|1[012])-(0[1-9]|[12][0-9]|3[01])[Tt]([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9]|60)(\\.[0-9]+)?(([Zz])|([+-]([01][0-9]|2[0-3]):[0-5][0-9]))$",
+					},
+				},
+			},
+			"status": {
+				Type: "object",
+				Properties: map[string]apiextv1beta1.JSONSchemaProps{
+					"completionTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"message": {
+						Type: "string",
+					},
+					"phase": {
+						Type: "string",
+					},
+					"reachedLSN": {
+						Type: "string",
+					},
+					"startTime": {
+						Type:   "string",
+						Format: "date-time",
+					},
+					"targetTime": {
+						Type: "string",
+					},
+				},
+			},
+		},
+	},
+}
+
 func buildCRD(name, kind, plural, short string, columns []apiextv1beta1.CustomResourceColumnDefinition, validation apiextv1beta1.CustomResourceValidation) *apiextv1beta1.CustomResourceDefinition {
 	return &apiextv1beta1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1245,3 +2046,35 @@ func ConfigurationCRD(enableValidation *bool) *apiextv1beta1.CustomResourceDefin
 		OperatorConfigCRDResourceColumns,
 		opconfigCRDvalidation)
 }
+
+// PostgresBackupCRD returns CustomResourceDefinition built from PostgresBackupCRDResource
+func PostgresBackupCRD(enableValidation *bool) *apiextv1beta1.CustomResourceDefinition {
+	postgresBackupCRDvalidation := apiextv1beta1.CustomResourceValidation{}
+
+	if enableValidation != nil && *enableValidation {
+		postgresBackupCRDvalidation = PostgresBackupCRDResourceValidation
+	}
+
+	return buildCRD(PostgresBackupCRDResourceName,
+		PostgresBackupCRDResourceKind,
+		PostgresBackupCRDResourcePlural,
+		PostgresBackupCRDResourceShort,
+		PostgresBackupCRDResourceColumns,
+		postgresBackupCRDvalidation)
+}
+
+// PostgresRestoreCRD returns CustomResourceDefinition built from PostgresRestoreCRDResource
+func PostgresRestoreCRD(enableValidation *bool) *apiextv1beta1.CustomResourceDefinition {
+	postgresRestoreCRDvalidation := apiextv1beta1.CustomResourceValidation{}
+
+	if enableValidation != nil && *enableValidation {
+		postgresRestoreCRDvalidation = PostgresRestoreCRDResourceValidation
+	}
+
+	return buildCRD(PostgresRestoreCRDResourceName,
+		PostgresRestoreCRDResourceKind,
+		PostgresRestoreCRDResourcePlural,
+		PostgresRestoreCRDResourceShort,
+		PostgresRestoreCRDResourceColumns,
+		postgresRestoreCRDvalidation)
+}
@@ -36,8 +36,21 @@ type OperatorConfigurationList struct {
 
 // PostgresUsersConfiguration defines the system users of Postgres.
 type PostgresUsersConfiguration struct {
-	SuperUsername       string `json:"super_username,omitempty"`
-	ReplicationUsername string `json:"replication_username,omitempty"`
+	SuperUsername         string   `json:"super_username,omitempty"`
+	ReplicationUsername   string   `json:"replication_username,omitempty"`
+	EnableRoleDeprecation bool     `json:"enable_role_deprecation,omitempty"`
+	RoleDeletionSuffix    string   `json:"role_deletion_suffix,omitempty"`
+	EnableRoleDrop        bool     `json:"enable_role_drop,omitempty"`
+	RoleDropGracePeriod   Duration `json:"role_drop_grace_period,omitempty"`
+	// rotation of the passwords of manifest and system users
+	EnablePasswordRotation        bool     `json:"enable_password_rotation,omitempty"`
+	PasswordRotationInterval      Duration `json:"password_rotation_interval,omitempty"`
+	PasswordRotationOverlapPeriod Duration `json:"password_rotation_overlap_period,omitempty"`
+	EnableSystemPasswordRotation  bool     `json:"enable_system_password_rotation,omitempty"`
+	// encryption of passwords, md5 or scram-sha-256
+	PasswordEncryption                string `json:"password_encryption,omitempty"`
+	EnablePasswordEncryptionMigration bool   `json:"enable_password_encryption_migration,omitempty"`
+	EnableCrossNamespaceSecrets       bool   `json:"enable_cross_namespace_secrets,omitempty"`
 }
 
 // KubernetesMetaConfiguration defines k8s conf required for all Postgres clusters and the operator itself
@@ -142,6 +155,16 @@ type LoggingRESTAPIConfiguration struct {
 	ClusterHistoryEntries int `json:"cluster_history_entries,omitempty"`
 }
 
+// AdmissionWebhookConfiguration defines the configuration of the admission webhook server
+type AdmissionWebhookConfiguration struct {
+	EnableAdmissionWebhook  bool     `json:"enable_admission_webhook,omitempty"`
+	WebhookPort             int      `json:"webhook_port,omitempty"`
+	WebhookTLSCertFile      string   `json:"webhook_tls_cert_file,omitempty"`
+	WebhookTLSKeyFile       string   `json:"webhook_tls_key_file,omitempty"`
+	EnableDefaultingWebhook bool     `json:"enable_defaulting_webhook,omitempty"`
+	DefaultedFields         []string `json:"defaulted_fields,omitempty"`
+}
+
 // ScalyrConfiguration defines the configuration for ScalyrAPI
 type ScalyrConfiguration struct {
 	ScalyrAPIKey        string `json:"scalyr_api_key,omitempty"`
@@ -169,41 +192,72 @@ type ConnectionPoolConfiguration struct {
 
 // OperatorLogicalBackupConfiguration defines configuration for logical backup
 type OperatorLogicalBackupConfiguration struct {
-	Schedule          string `json:"logical_backup_schedule,omitempty"`
-	DockerImage       string `json:"logical_backup_docker_image,omitempty"`
-	S3Bucket          string `json:"logical_backup_s3_bucket,omitempty"`
-	S3Region          string `json:"logical_backup_s3_region,omitempty"`
-	S3Endpoint        string `json:"logical_backup_s3_endpoint,omitempty"`
-	S3AccessKeyID     string `json:"logical_backup_s3_access_key_id,omitempty"`
-	S3SecretAccessKey string `json:"logical_backup_s3_secret_access_key,omitempty"`
-	S3SSE             string `json:"logical_backup_s3_sse,omitempty"`
+	Schedule               string `json:"logical_backup_schedule,omitempty"`
+	DockerImage            string `json:"logical_backup_docker_image,omitempty"`
+	Provider               string `json:"logical_backup_provider,omitempty"`
+	S3Bucket               string `json:"logical_backup_s3_bucket,omitempty"`
+	S3Region               string `json:"logical_backup_s3_region,omitempty"`
+	S3Endpoint             string `json:"logical_backup_s3_endpoint,omitempty"`
+	S3AccessKeyID          string `json:"logical_backup_s3_access_key_id,omitempty"`
+	S3SecretAccessKey      string `json:"logical_backup_s3_secret_access_key,omitempty"`
+	S3SSE                  string `json:"logical_backup_s3_sse,omitempty"`
+	GCSBucket              string `json:"logical_backup_gcs_bucket,omitempty"`
+	GCSCredentialsSecret   string `json:"logical_backup_gcs_credentials_secret,omitempty"`
+	AzureStorageAccount    string `json:"logical_backup_azure_storage_account,omitempty"`
+	AzureStorageContainer  string `json:"logical_backup_azure_storage_container,omitempty"`
+	AzureCredentialsSecret string `json:"logical_backup_azure_credentials_secret,omitempty"`
+	PVCClaimName           string `json:"logical_backup_pvc_claim_name,omitempty"`
+	RetentionCount         int32  `json:"logical_backup_retention_count,omitempty"`
+	RetentionDays          int32  `json:"logical_backup_retention_days,omitempty"`
+}
+
+// SecretBackendConfiguration defines where the credentials of roles are stored
+type SecretBackendConfiguration struct {
+	Type                 string              `json:"secret_backend_type,omitempty"`
+	VaultAddress         string              `json:"vault_address,omitempty"`
+	VaultKVMount         string              `json:"vault_kv_mount,omitempty"`
+	VaultPathPrefix      string              `json:"vault_path_prefix,omitempty"`
+	VaultTokenSecretName spec.NamespacedName `json:"vault_token_secret_name,omitempty"`
+}
+
+// RestoreVerificationConfiguration defines the periodic verification that clusters can be restored
+type RestoreVerificationConfiguration struct {
+	EnableRestoreVerification bool     `json:"enable_restore_verification,omitempty"`
+	Interval                  Duration `json:"restore_verification_interval,omitempty"`
+	Timeout                   Duration `json:"restore_verification_timeout,omitempty"`
 }
 
 // OperatorConfigurationData defines the operation config
 type OperatorConfigurationData struct {
-	EnableCRDValidation        *bool                              `json:"enable_crd_validation,omitempty"`
-	EtcdHost                   string                             `json:"etcd_host,omitempty"`
-	DockerImage                string                             `json:"docker_image,omitempty"`
-	Workers                    uint32                             `json:"workers,omitempty"`
-	MinInstances               int32                              `json:"min_instances,omitempty"`
-	MaxInstances               int32                              `json:"max_instances,omitempty"`
-	ResyncPeriod               Duration                           `json:"resync_period,omitempty"`
-	RepairPeriod               Duration                           `json:"repair_period,omitempty"`
-	SetMemoryRequestToLimit    bool                               `json:"set_memory_request_to_limit,omitempty"`
-	ShmVolume                  *bool                              `json:"enable_shm_volume,omitempty"`
-	Sidecars                   map[string]string                  `json:"sidecar_docker_images,omitempty"`
-	PostgresUsersConfiguration PostgresUsersConfiguration         `json:"users"`
-	Kubernetes                 KubernetesMetaConfiguration        `json:"kubernetes"`
-	PostgresPodResources       PostgresPodResourcesDefaults       `json:"postgres_pod_resources"`
-	Timeouts                   OperatorTimeouts                   `json:"timeouts"`
-	LoadBalancer               LoadBalancerConfiguration          `json:"load_balancer"`
-	AWSGCP                     AWSGCPConfiguration                `json:"aws_or_gcp"`
-	OperatorDebug              OperatorDebugConfiguration         `json:"debug"`
-	TeamsAPI                   TeamsAPIConfiguration              `json:"teams_api"`
-	LoggingRESTAPI             LoggingRESTAPIConfiguration        `json:"logging_rest_api"`
-	Scalyr                     ScalyrConfiguration                `json:"scalyr"`
-	LogicalBackup              OperatorLogicalBackupConfiguration `json:"logical_backup"`
-	ConnectionPool             ConnectionPoolConfiguration        `json:"connection_pool"`
+	EnableCRDValidation         *bool                              `json:"enable_crd_validation,omitempty"`
+	EtcdHost                    string                             `json:"etcd_host,omitempty"`
+	DockerImage                 string                             `json:"docker_image,omitempty"`
+	Workers                     uint32                             `json:"workers,omitempty"`
+	MinInstances                int32                              `json:"min_instances,omitempty"`
+	MaxInstances                int32                              `json:"max_instances,omitempty"`
+	ResyncPeriod                Duration                           `json:"resync_period,omitempty"`
+	RepairPeriod                Duration                           `json:"repair_period,omitempty"`
+	EnablePausedClusterDeletion bool                               `json:"enable_paused_cluster_deletion,omitempty"`
+	EnableDryRun                bool                               `json:"enable_dry_run,omitempty"`
+	AllowedExtensions           []string                           `json:"allowed_extensions,omitempty"`
+	SetMemoryRequestToLimit     bool                               `json:"set_memory_request_to_limit,omitempty"`
+	ShmVolume                   *bool                              `json:"enable_shm_volume,omitempty"`
+	Sidecars                    map[string]string                  `json:"sidecar_docker_images,omitempty"`
+	PostgresUsersConfiguration  PostgresUsersConfiguration         `json:"users"`
+	Kubernetes                  KubernetesMetaConfiguration        `json:"kubernetes"`
+	PostgresPodResources        PostgresPodResourcesDefaults       `json:"postgres_pod_resources"`
+	Timeouts                    OperatorTimeouts                   `json:"timeouts"`
+	LoadBalancer                LoadBalancerConfiguration          `json:"load_balancer"`
+	AWSGCP                      AWSGCPConfiguration                `json:"aws_or_gcp"`
+	OperatorDebug               OperatorDebugConfiguration         `json:"debug"`
+	TeamsAPI                    TeamsAPIConfiguration              `json:"teams_api"`
+	LoggingRESTAPI              LoggingRESTAPIConfiguration        `json:"logging_rest_api"`
+	AdmissionWebhook            AdmissionWebhookConfiguration      `json:"admission_webhook"`
+	Scalyr                      ScalyrConfiguration                `json:"scalyr"`
+	LogicalBackup               OperatorLogicalBackupConfiguration `json:"logical_backup"`
+	ConnectionPool              ConnectionPoolConfiguration        `json:"connection_pool"`
+	SecretBackend               SecretBackendConfiguration         `json:"secret_backend"`
+	RestoreVerification         RestoreVerificationConfiguration   `json:"restore_verification"`
 }
 
 //Duration shortens this frequently used name
@@ -0,0 +1,49 @@
+package v1
+
+// PostgresBackup CRD definition, please use CamelCase for field names.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresBackup defines an on-demand backup of a Postgres cluster
+type PostgresBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresBackupSpec   `json:"spec"`
+	Status PostgresBackupStatus `json:"status,omitempty"`
+}
+
+// PostgresBackupSpec defines the cluster to back up and the backup method
+type PostgresBackupSpec struct {
+	// name of the postgresql object in the namespace of the backup
+	Cluster string `json:"cluster"`
+	Method  string `json:"method"`
+}
+
+// PostgresBackupStatus describes the progress and the result of a backup
+type PostgresBackupStatus struct {
+	Phase          string       `json:"phase,omitempty"`
+	Size           int64        `json:"size,omitempty"`
+	Location       string       `json:"location,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	WALStart       string       `json:"walStart,omitempty"`
+	WALEnd         string       `json:"walEnd,omitempty"`
+	JobName        string       `json:"jobName,omitempty"`
+	Message        string       `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresBackupList defines a list of Postgres backups.
+type PostgresBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PostgresBackup `json:"items"`
+}
@@ -0,0 +1,49 @@
+package v1
+
+// PostgresRestore CRD definition, please use CamelCase for field names.
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresRestore defines a point-in-time recovery of a Postgres cluster into a new cluster
+type PostgresRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresRestoreSpec   `json:"spec"`
+	Status PostgresRestoreStatus `json:"status,omitempty"`
+}
+
+// PostgresRestoreSpec defines the source of a restore and the cluster it is restored into
+type PostgresRestoreSpec struct {
+	// name of the new postgresql object in the namespace of the restore
+	Cluster string `json:"cluster"`
+	// the source is either a postgresql object or a base backup taken with a PostgresBackup
+	SourceCluster string `json:"sourceCluster,omitempty"`
+	Backup        string `json:"backup,omitempty"`
+	TargetTime    string `json:"targetTime,omitempty"`
+}
+
+// PostgresRestoreStatus describes the progress and the result of a restore
+type PostgresRestoreStatus struct {
+	Phase          string       `json:"phase,omitempty"`
+	TargetTime     string       `json:"targetTime,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	ReachedLSN     string       `json:"reachedLSN,omitempty"`
+	Message        string       `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresRestoreList defines a list of Postgres restores.
+type PostgresRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PostgresRestore `json:"items"`
+}
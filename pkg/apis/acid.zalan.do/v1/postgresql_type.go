@@ -50,23 +50,33 @@ type PostgresSpec struct {
 	// load balancers' source ranges are the same for master and replica services
 	AllowedSourceRanges []string `json:"allowedSourceRanges"`
 
-	NumberOfInstances     int32                `json:"numberOfInstances"`
-	Users                 map[string]UserFlags `json:"users"`
-	MaintenanceWindows    []MaintenanceWindow  `json:"maintenanceWindows,omitempty"`
-	Clone                 CloneDescription     `json:"clone"`
-	ClusterName           string               `json:"-"`
-	Databases             map[string]string    `json:"databases,omitempty"`
-	Tolerations           []v1.Toleration      `json:"tolerations,omitempty"`
-	Sidecars              []Sidecar            `json:"sidecars,omitempty"`
-	InitContainers        []v1.Container       `json:"initContainers,omitempty"`
-	PodPriorityClassName  string               `json:"podPriorityClassName,omitempty"`
-	ShmVolume             *bool                `json:"enableShmVolume,omitempty"`
-	EnableLogicalBackup   bool                 `json:"enableLogicalBackup,omitempty"`
-	LogicalBackupSchedule string               `json:"logicalBackupSchedule,omitempty"`
-	StandbyCluster        *StandbyDescription  `json:"standby"`
-	PodAnnotations        map[string]string    `json:"podAnnotations"`
-	ServiceAnnotations    map[string]string    `json:"serviceAnnotations"`
-	TLS                   *TLSDescription      `json:"tls"`
+	NumberOfInstances      int32                           `json:"numberOfInstances"`
+	Users                  map[string]UserFlags            `json:"users"`
+	PasswordRotation       map[string]PasswordRotation     `json:"passwordRotation,omitempty"`
+	SecretNamespaces       map[string]string               `json:"secretNamespaces,omitempty"`
+	MaintenanceWindows     []MaintenanceWindow             `json:"maintenanceWindows,omitempty"`
+	Clone                  CloneDescription                `json:"clone"`
+	ClusterName            string                          `json:"-"`
+	Databases              map[string]string               `json:"databases,omitempty"`
+	PreparedDatabases      map[string]PreparedDatabase     `json:"preparedDatabases,omitempty"`
+	Extensions             map[string]map[string]Extension `json:"extensions,omitempty"`
+	BootstrapSQL           map[string][]BootstrapScript    `json:"bootstrapSQL,omitempty"`
+	Publications           map[string]Publication          `json:"publications,omitempty"`
+	Subscriptions          map[string]Subscription         `json:"subscriptions,omitempty"`
+	Tolerations            []v1.Toleration                 `json:"tolerations,omitempty"`
+	Sidecars               []Sidecar                       `json:"sidecars,omitempty"`
+	InitContainers         []v1.Container                  `json:"initContainers,omitempty"`
+	PodPriorityClassName   string                          `json:"podPriorityClassName,omitempty"`
+	ShmVolume              *bool                           `json:"enableShmVolume,omitempty"`
+	EnableLogicalBackup    bool                            `json:"enableLogicalBackup,omitempty"`
+	LogicalBackupSchedule  string                          `json:"logicalBackupSchedule,omitempty"`
+	LogicalBackupRetention *LogicalBackupRetention         `json:"logicalBackupRetention,omitempty"`
+	LogicalBackupTarget    *LogicalBackupTarget            `json:"logicalBackupTarget,omitempty"`
+	RestoreVerification    *RestoreVerification            `json:"restoreVerification,omitempty"`
+	StandbyCluster         *StandbyDescription             `json:"standby"`
+	PodAnnotations         map[string]string               `json:"podAnnotations"`
+	ServiceAnnotations     map[string]string               `json:"serviceAnnotations"`
+	TLS                    *TLSDescription                 `json:"tls"`
 
 	// deprecated json tags
 	InitContainersOld       []v1.Container `json:"init_containers,omitempty"`
@@ -132,6 +142,31 @@ type StandbyDescription struct {
 	S3WalPath string `json:"s3_wal_path,omitempty"`
 }
 
+// LogicalBackupRetention overrides the retention of scheduled logical backups configured in the operator
+type LogicalBackupRetention struct {
+	Count *int32 `json:"count,omitempty"`
+	Days  *int32 `json:"days,omitempty"`
+}
+
+// LogicalBackupTarget overrides the storage logical backups are uploaded to, unset values fall back to the
+// operator configuration of the provider
+type LogicalBackupTarget struct {
+	Provider          string `json:"provider,omitempty"`
+	Bucket            string `json:"bucket,omitempty"`
+	StorageAccount    string `json:"storageAccount,omitempty"`
+	Container         string `json:"container,omitempty"`
+	ClaimName         string `json:"claimName,omitempty"`
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// RestoreVerification enables the periodic verification that the cluster can be restored from its backups.
+// The checks are SQL queries run in the restored database that have to return true.
+type RestoreVerification struct {
+	Interval string   `json:"interval,omitempty"`
+	Database string   `json:"database,omitempty"`
+	Checks   []string `json:"checks,omitempty"`
+}
+
 type TLSDescription struct {
 	SecretName      string `json:"secretName,omitempty"`
 	CertificateFile string `json:"certificateFile,omitempty"`
@@ -165,7 +200,131 @@ type UserFlags []string
 
 // PostgresStatus contains status of the PostgreSQL cluster (running, creation failed etc.)
 type PostgresStatus struct {
-	PostgresClusterStatus string `json:"PostgresClusterStatus"`
+	PostgresClusterStatus string   `json:"PostgresClusterStatus"`
+	PendingMaintenance    []string `json:"pendingMaintenance,omitempty"`
+
+	MajorVersionUpgrade *MajorVersionUpgradeStatus `json:"majorVersionUpgrade,omitempty"`
+	RestoreVerification *RestoreVerificationStatus `json:"restoreVerification,omitempty"`
+	DeprecatedRoles     []DeprecatedRole           `json:"deprecatedRoles,omitempty"`
+	ExtensionErrors     []ExtensionError           `json:"extensionErrors,omitempty"`
+	BootstrapSQLErrors  []BootstrapScriptError     `json:"bootstrapSQLErrors,omitempty"`
+	SubscriptionSlots   []string                   `json:"subscriptionSlots,omitempty"`
+
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	MasterPod          string      `json:"masterPod,omitempty"`
+	Instances          int32       `json:"instances,omitempty"`
+	ReadyInstances     int32       `json:"readyInstances,omitempty"`
+	LastSyncError      string      `json:"lastSyncError,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+// ConditionType is the type of a condition reported in the cluster status
+type ConditionType string
+
+// Condition describes one aspect of the cluster state in the style of Kubernetes conditions
+type Condition struct {
+	Type               ConditionType      `json:"type"`
+	Status             v1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time        `json:"lastTransitionTime,omitempty"`
+	Reason             string             `json:"reason,omitempty"`
+	Message            string             `json:"message,omitempty"`
+}
+
+// MajorVersionUpgradeStatus describes the progress of the last major version upgrade of the cluster
+type MajorVersionUpgradeStatus struct {
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	Phase       string `json:"phase"`
+	Message     string `json:"message,omitempty"`
+}
+
+// RestoreVerificationStatus describes the last verification that the cluster can be restored from its backups
+type RestoreVerificationStatus struct {
+	Phase           string       `json:"phase"`
+	Clone           string       `json:"clone,omitempty"`
+	TargetTime      string       `json:"targetTime,omitempty"`
+	StartTime       *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime  *metav1.Time `json:"completionTime,omitempty"`
+	RecoveryTime    string       `json:"recoveryTime,omitempty"`
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+	Message         string       `json:"message,omitempty"`
+}
+
+// PasswordRotation configures the rotation of the password of a user defined in the manifest
+type PasswordRotation struct {
+	Interval string `json:"interval,omitempty"`
+	InPlace  bool   `json:"inPlace,omitempty"`
+}
+
+// PreparedDatabase describes a database the operator sets up with schemas and default roles. The
+// NOLOGIN roles <db>_owner, <db>_reader and <db>_writer get login users with the _user suffix if
+// DefaultUsers is set.
+type PreparedDatabase struct {
+	PreparedSchemas map[string]PreparedSchema `json:"schemas,omitempty"`
+	DefaultUsers    bool                      `json:"defaultUsers,omitempty"`
+}
+
+// PreparedSchema describes a schema of a prepared database. Unless DefaultRoles is disabled, the schema
+// gets its own <db>_<schema>_owner, _reader and _writer roles, with login users if DefaultUsers is set.
+type PreparedSchema struct {
+	DefaultRoles *bool `json:"defaultRoles,omitempty"`
+	DefaultUsers bool  `json:"defaultUsers,omitempty"`
+}
+
+// Extension describes an extension to create in a database, in the given schema and version or the default
+// ones of the extension
+type Extension struct {
+	Version string `json:"version,omitempty"`
+	Schema  string `json:"schema,omitempty"`
+}
+
+// ExtensionError describes an extension the operator could not create or update in a database
+type ExtensionError struct {
+	Database  string `json:"database"`
+	Extension string `json:"extension"`
+	Message   string `json:"message"`
+}
+
+// BootstrapScript refers to SQL scripts in a ConfigMap that are executed once in a database, all keys of
+// the ConfigMap in alphabetical order unless a key is given
+type BootstrapScript struct {
+	ConfigMap string `json:"configMap"`
+	Key       string `json:"key,omitempty"`
+}
+
+// BootstrapScriptError describes a bootstrap SQL script the operator could not execute in a database, or
+// an error of all the scripts of the database without Script
+type BootstrapScriptError struct {
+	Database string `json:"database"`
+	Script   string `json:"script,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Publication describes a publication of tables of a database for logical replication, or of all the
+// tables of the database if AllTables is set. Tables are given as table or schema.table.
+type Publication struct {
+	Database  string   `json:"database"`
+	Tables    []string `json:"tables,omitempty"`
+	AllTables bool     `json:"allTables,omitempty"`
+}
+
+// Subscription describes a subscription of a database to publications of another cluster in the same
+// namespace. The credentials of User, the superuser by default, are taken from the secrets of that cluster.
+type Subscription struct {
+	Database       string   `json:"database"`
+	Cluster        string   `json:"cluster"`
+	SourceDatabase string   `json:"sourceDatabase,omitempty"`
+	Publications   []string `json:"publications"`
+	User           string   `json:"user,omitempty"`
+	CopyData       *bool    `json:"copyData,omitempty"`
+	Enabled        *bool    `json:"enabled,omitempty"`
+}
+
+// DeprecatedRole describes a role that has been removed from the manifest or the Teams API. The role
+// is renamed with the deprecation suffix and can be dropped once the grace period has passed.
+type DeprecatedRole struct {
+	Name            string      `json:"name"`
+	DeprecationTime metav1.Time `json:"deprecationTime"`
 }
 
 // Options for connection pooler
@@ -48,6 +48,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&OperatorConfiguration{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("OperatorConfigurationList"),
 		&OperatorConfigurationList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresBackup{}, &PostgresBackupList{})
+	scheme.AddKnownTypes(SchemeGroupVersion, &PostgresRestore{}, &PostgresRestoreList{})
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
 }
@@ -22,6 +22,16 @@ func (p *Postgresql) Clone() *Postgresql {
 	return p.DeepCopy()
 }
 
+// Finished checks whether the backup has either succeeded or failed
+func (status PostgresBackupStatus) Finished() bool {
+	return status.Phase == BackupPhaseSucceeded || status.Phase == BackupPhaseFailed
+}
+
+// Finished checks whether the restore has either succeeded or failed
+func (status PostgresRestoreStatus) Finished() bool {
+	return status.Phase == RestorePhaseSucceeded || status.Phase == RestorePhaseFailed
+}
+
 func parseTime(s string) (metav1.Time, error) {
 	parts := strings.Split(s, ":")
 	if len(parts) != 2 {
@@ -46,6 +56,28 @@ func parseWeekday(s string) (time.Weekday, error) {
 	return time.Weekday(weekday), nil
 }
 
+// IsInMaintenanceWindow checks if the given time falls into one of the maintenance windows.
+// A cluster without maintenance windows can be maintained at any time.
+func IsInMaintenanceWindow(windows []MaintenanceWindow, now time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	now = now.UTC()
+	// maintenance window boundaries are parsed as a time of day without the date
+	timeOfDay := metav1.NewTime(time.Date(0, time.January, 1, now.Hour(), now.Minute(), 0, 0, time.UTC))
+
+	for _, window := range windows {
+		if !window.Everyday && window.Weekday != now.Weekday() {
+			continue
+		}
+		if !timeOfDay.Before(&window.StartTime) && !window.EndTime.Before(&timeOfDay) {
+			return true
+		}
+	}
+	return false
+}
+
 func extractClusterName(clusterName string, teamName string) (string, error) {
 	teamNameLen := len(teamName)
 	if len(clusterName) < teamNameLen+2 {
@@ -101,6 +133,45 @@ func (postgresStatus PostgresStatus) Creating() bool {
 	return postgresStatus.PostgresClusterStatus == ClusterStatusCreating
 }
 
+// MaintenancePending reports whether disruptive actions were postponed until the next maintenance window
+func (postgresStatus PostgresStatus) MaintenancePending() bool {
+	return len(postgresStatus.PendingMaintenance) > 0
+}
+
+// GetCondition returns the condition of the given type or nil if it is not reported
+func (postgresStatus PostgresStatus) GetCondition(conditionType ConditionType) *Condition {
+	for i := range postgresStatus.Conditions {
+		if postgresStatus.Conditions[i].Type == conditionType {
+			return &postgresStatus.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or replaces the condition of the same type. The transition time
+// is only changed when the status of the condition changes.
+func (postgresStatus *PostgresStatus) SetCondition(condition Condition) {
+	if existing := postgresStatus.GetCondition(condition.Type); existing != nil {
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	postgresStatus.Conditions = append(postgresStatus.Conditions, condition)
+}
+
+// RemoveCondition removes the condition of the given type from the status
+func (postgresStatus *PostgresStatus) RemoveCondition(conditionType ConditionType) {
+	var conditions []Condition
+	for _, condition := range postgresStatus.Conditions {
+		if condition.Type != conditionType {
+			conditions = append(conditions, condition)
+		}
+	}
+	postgresStatus.Conditions = conditions
+}
+
 func (postgresStatus PostgresStatus) String() string {
 	return postgresStatus.PostgresClusterStatus
 }
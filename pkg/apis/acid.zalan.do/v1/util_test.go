@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/zalando/postgres-operator/pkg/util"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -121,6 +122,34 @@ var maintenanceWindows = []struct {
 	{"expect error as 'To' time set seconds", []byte(`"Mon:00:00-00:00:00"`), MaintenanceWindow{}, errors.New("could not parse end time: incorrect time format")},
 	{"expect error as 'To' time is missing", []byte(`"Mon:00:00"`), MaintenanceWindow{}, errors.New("incorrect maintenance window format")}}
 
+// 2020-06-01 is a Monday
+var maintenanceWindowTimes = []struct {
+	about   string
+	windows []MaintenanceWindow
+	in      time.Time
+	out     bool
+}{
+	{"no maintenance windows", nil, time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC), true},
+	{"inside window on the same weekday", []MaintenanceWindow{{Weekday: time.Monday, StartTime: mustParseTime("10:00"), EndTime: mustParseTime("14:00")}},
+		time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC), true},
+	{"window start is inclusive", []MaintenanceWindow{{Weekday: time.Monday, StartTime: mustParseTime("10:00"), EndTime: mustParseTime("14:00")}},
+		time.Date(2020, time.June, 1, 10, 0, 0, 0, time.UTC), true},
+	{"window end is inclusive", []MaintenanceWindow{{Weekday: time.Monday, StartTime: mustParseTime("10:00"), EndTime: mustParseTime("14:00")}},
+		time.Date(2020, time.June, 1, 14, 0, 30, 0, time.UTC), true},
+	{"outside window on the same weekday", []MaintenanceWindow{{Weekday: time.Monday, StartTime: mustParseTime("10:00"), EndTime: mustParseTime("14:00")}},
+		time.Date(2020, time.June, 1, 15, 0, 0, 0, time.UTC), false},
+	{"inside window time on another weekday", []MaintenanceWindow{{Weekday: time.Tuesday, StartTime: mustParseTime("10:00"), EndTime: mustParseTime("14:00")}},
+		time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC), false},
+	{"inside everyday window", []MaintenanceWindow{{Everyday: true, StartTime: mustParseTime("01:00"), EndTime: mustParseTime("02:00")}},
+		time.Date(2020, time.June, 4, 1, 30, 0, 0, time.UTC), true},
+	{"time is converted to UTC", []MaintenanceWindow{{Everyday: true, StartTime: mustParseTime("01:00"), EndTime: mustParseTime("02:00")}},
+		time.Date(2020, time.June, 4, 3, 30, 0, 0, time.FixedZone("CEST", 2*60*60)), true},
+	{"second of several windows matches", []MaintenanceWindow{
+		{Weekday: time.Sunday, StartTime: mustParseTime("01:00"), EndTime: mustParseTime("02:00")},
+		{Weekday: time.Monday, StartTime: mustParseTime("22:00"), EndTime: mustParseTime("23:59")}},
+		time.Date(2020, time.June, 1, 23, 0, 0, 0, time.UTC), true},
+}
+
 var postgresStatus = []struct {
 	about string
 	in    []byte
@@ -698,6 +727,80 @@ func TestMarshalMaintenanceWindow(t *testing.T) {
 	}
 }
 
+func TestIsInMaintenanceWindow(t *testing.T) {
+	for _, tt := range maintenanceWindowTimes {
+		t.Run(tt.about, func(t *testing.T) {
+			if result := IsInMaintenanceWindow(tt.windows, tt.in); result != tt.out {
+				t.Errorf("Expected IsInMaintenanceWindow to return %t, got %t", tt.out, result)
+			}
+		})
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC))
+	laterTime := metav1.NewTime(transitionTime.Add(time.Hour))
+
+	tests := []struct {
+		about     string
+		status    PostgresStatus
+		condition Condition
+		expected  []Condition
+	}{
+		{
+			"add condition to an empty status",
+			PostgresStatus{},
+			Condition{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime},
+			[]Condition{{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime}},
+		},
+		{
+			"keep transition time when the status does not change",
+			PostgresStatus{Conditions: []Condition{{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime}}},
+			Condition{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: laterTime, Reason: "Running"},
+			[]Condition{{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime, Reason: "Running"}},
+		},
+		{
+			"update transition time when the status changes",
+			PostgresStatus{Conditions: []Condition{{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime}}},
+			Condition{Type: ConditionReady, Status: v1.ConditionFalse, LastTransitionTime: laterTime},
+			[]Condition{{Type: ConditionReady, Status: v1.ConditionFalse, LastTransitionTime: laterTime}},
+		},
+		{
+			"add condition of another type",
+			PostgresStatus{Conditions: []Condition{{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime}}},
+			Condition{Type: ConditionSyncing, Status: v1.ConditionFalse, LastTransitionTime: laterTime},
+			[]Condition{
+				{Type: ConditionReady, Status: v1.ConditionTrue, LastTransitionTime: transitionTime},
+				{Type: ConditionSyncing, Status: v1.ConditionFalse, LastTransitionTime: laterTime},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.about, func(t *testing.T) {
+			tt.status.SetCondition(tt.condition)
+			if !reflect.DeepEqual(tt.status.Conditions, tt.expected) {
+				t.Errorf("Expected conditions %#v, got %#v", tt.expected, tt.status.Conditions)
+			}
+		})
+	}
+}
+
+func TestRemoveCondition(t *testing.T) {
+	status := PostgresStatus{Conditions: []Condition{
+		{Type: ConditionReady, Status: v1.ConditionTrue},
+		{Type: ConditionPoolerReady, Status: v1.ConditionFalse},
+	}}
+
+	status.RemoveCondition(ConditionPoolerReady)
+	if status.GetCondition(ConditionPoolerReady) != nil {
+		t.Errorf("Expected condition %s to be removed", ConditionPoolerReady)
+	}
+	if status.GetCondition(ConditionReady) == nil {
+		t.Errorf("Expected condition %s to be kept", ConditionReady)
+	}
+}
+
 func TestUnmarshalPostgresStatus(t *testing.T) {
 	for _, tt := range postgresStatus {
 		t.Run(tt.about, func(t *testing.T) {
@@ -47,6 +47,59 @@ func (in *AWSGCPConfiguration) DeepCopy() *AWSGCPConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionWebhookConfiguration) DeepCopyInto(out *AdmissionWebhookConfiguration) {
+	*out = *in
+	if in.DefaultedFields != nil {
+		in, out := &in.DefaultedFields, &out.DefaultedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionWebhookConfiguration.
+func (in *AdmissionWebhookConfiguration) DeepCopy() *AdmissionWebhookConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionWebhookConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapScript) DeepCopyInto(out *BootstrapScript) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapScript.
+func (in *BootstrapScript) DeepCopy() *BootstrapScript {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapScriptError) DeepCopyInto(out *BootstrapScriptError) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapScriptError.
+func (in *BootstrapScriptError) DeepCopy() *BootstrapScriptError {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapScriptError)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneDescription) DeepCopyInto(out *CloneDescription) {
 	*out = *in
@@ -68,6 +121,23 @@ func (in *CloneDescription) DeepCopy() *CloneDescription {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectionPool) DeepCopyInto(out *ConnectionPool) {
 	*out = *in
@@ -121,6 +191,55 @@ func (in *ConnectionPoolConfiguration) DeepCopy() *ConnectionPoolConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecatedRole) DeepCopyInto(out *DeprecatedRole) {
+	*out = *in
+	in.DeprecationTime.DeepCopyInto(&out.DeprecationTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecatedRole.
+func (in *DeprecatedRole) DeepCopy() *DeprecatedRole {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecatedRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Extension) DeepCopyInto(out *Extension) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extension.
+func (in *Extension) DeepCopy() *Extension {
+	if in == nil {
+		return nil
+	}
+	out := new(Extension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionError) DeepCopyInto(out *ExtensionError) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionError.
+func (in *ExtensionError) DeepCopy() *ExtensionError {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionError)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesMetaConfiguration) DeepCopyInto(out *KubernetesMetaConfiguration) {
 	*out = *in
@@ -232,6 +351,64 @@ func (in *LoggingRESTAPIConfiguration) DeepCopy() *LoggingRESTAPIConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalBackupRetention) DeepCopyInto(out *LogicalBackupRetention) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackupRetention.
+func (in *LogicalBackupRetention) DeepCopy() *LogicalBackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalBackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalBackupTarget) DeepCopyInto(out *LogicalBackupTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackupTarget.
+func (in *LogicalBackupTarget) DeepCopy() *LogicalBackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalBackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MajorVersionUpgradeStatus) DeepCopyInto(out *MajorVersionUpgradeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MajorVersionUpgradeStatus.
+func (in *MajorVersionUpgradeStatus) DeepCopy() *MajorVersionUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MajorVersionUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
@@ -285,6 +462,11 @@ func (in *OperatorConfigurationData) DeepCopyInto(out *OperatorConfigurationData
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AllowedExtensions != nil {
+		in, out := &in.AllowedExtensions, &out.AllowedExtensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ShmVolume != nil {
 		in, out := &in.ShmVolume, &out.ShmVolume
 		*out = new(bool)
@@ -306,9 +488,12 @@ func (in *OperatorConfigurationData) DeepCopyInto(out *OperatorConfigurationData
 	out.OperatorDebug = in.OperatorDebug
 	in.TeamsAPI.DeepCopyInto(&out.TeamsAPI)
 	out.LoggingRESTAPI = in.LoggingRESTAPI
+	in.AdmissionWebhook.DeepCopyInto(&out.AdmissionWebhook)
 	out.Scalyr = in.Scalyr
 	out.LogicalBackup = in.LogicalBackup
 	in.ConnectionPool.DeepCopyInto(&out.ConnectionPool)
+	out.SecretBackend = in.SecretBackend
+	out.RestoreVerification = in.RestoreVerification
 	return
 }
 
@@ -403,6 +588,22 @@ func (in *OperatorTimeouts) DeepCopy() *OperatorTimeouts {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotation) DeepCopyInto(out *PasswordRotation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotation.
+func (in *PasswordRotation) DeepCopy() *PasswordRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Patroni) DeepCopyInto(out *Patroni) {
 	*out = *in
@@ -448,6 +649,107 @@ func (in *Patroni) DeepCopy() *Patroni {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackup) DeepCopyInto(out *PostgresBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackup.
+func (in *PostgresBackup) DeepCopy() *PostgresBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupList) DeepCopyInto(out *PostgresBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupList.
+func (in *PostgresBackupList) DeepCopy() *PostgresBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupSpec) DeepCopyInto(out *PostgresBackupSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupSpec.
+func (in *PostgresBackupSpec) DeepCopy() *PostgresBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresBackupStatus) DeepCopyInto(out *PostgresBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresBackupStatus.
+func (in *PostgresBackupStatus) DeepCopy() *PostgresBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresPodResourcesDefaults) DeepCopyInto(out *PostgresPodResourcesDefaults) {
 	*out = *in
@@ -464,6 +766,107 @@ func (in *PostgresPodResourcesDefaults) DeepCopy() *PostgresPodResourcesDefaults
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRestore) DeepCopyInto(out *PostgresRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRestore.
+func (in *PostgresRestore) DeepCopy() *PostgresRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRestoreList) DeepCopyInto(out *PostgresRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRestoreList.
+func (in *PostgresRestoreList) DeepCopy() *PostgresRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRestoreSpec) DeepCopyInto(out *PostgresRestoreSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRestoreSpec.
+func (in *PostgresRestoreSpec) DeepCopy() *PostgresRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresRestoreStatus) DeepCopyInto(out *PostgresRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresRestoreStatus.
+func (in *PostgresRestoreStatus) DeepCopy() *PostgresRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 	*out = *in
@@ -526,6 +929,20 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = make(map[string]PasswordRotation, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretNamespaces != nil {
+		in, out := &in.SecretNamespaces, &out.SecretNamespaces
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.MaintenanceWindows != nil {
 		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
 		*out = make([]MaintenanceWindow, len(*in))
@@ -541,6 +958,59 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.PreparedDatabases != nil {
+		in, out := &in.PreparedDatabases, &out.PreparedDatabases
+		*out = make(map[string]PreparedDatabase, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make(map[string]map[string]Extension, len(*in))
+		for key, val := range *in {
+			var outVal map[string]Extension
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(map[string]Extension, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.BootstrapSQL != nil {
+		in, out := &in.BootstrapSQL, &out.BootstrapSQL
+		*out = make(map[string][]BootstrapScript, len(*in))
+		for key, val := range *in {
+			var outVal []BootstrapScript
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]BootstrapScript, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make(map[string]Publication, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make(map[string]Subscription, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
@@ -567,6 +1037,21 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.LogicalBackupRetention != nil {
+		in, out := &in.LogicalBackupRetention, &out.LogicalBackupRetention
+		*out = new(LogicalBackupRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogicalBackupTarget != nil {
+		in, out := &in.LogicalBackupTarget, &out.LogicalBackupTarget
+		*out = new(LogicalBackupTarget)
+		**out = **in
+	}
+	if in.RestoreVerification != nil {
+		in, out := &in.RestoreVerification, &out.RestoreVerification
+		*out = new(RestoreVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StandbyCluster != nil {
 		in, out := &in.StandbyCluster, &out.StandbyCluster
 		*out = new(StandbyDescription)
@@ -614,6 +1099,50 @@ func (in *PostgresSpec) DeepCopy() *PostgresSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresStatus) DeepCopyInto(out *PostgresStatus) {
 	*out = *in
+	if in.PendingMaintenance != nil {
+		in, out := &in.PendingMaintenance, &out.PendingMaintenance
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MajorVersionUpgrade != nil {
+		in, out := &in.MajorVersionUpgrade, &out.MajorVersionUpgrade
+		*out = new(MajorVersionUpgradeStatus)
+		**out = **in
+	}
+	if in.RestoreVerification != nil {
+		in, out := &in.RestoreVerification, &out.RestoreVerification
+		*out = new(RestoreVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeprecatedRoles != nil {
+		in, out := &in.DeprecatedRoles, &out.DeprecatedRoles
+		*out = make([]DeprecatedRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtensionErrors != nil {
+		in, out := &in.ExtensionErrors, &out.ExtensionErrors
+		*out = make([]ExtensionError, len(*in))
+		copy(*out, *in)
+	}
+	if in.BootstrapSQLErrors != nil {
+		in, out := &in.BootstrapSQLErrors, &out.BootstrapSQLErrors
+		*out = make([]BootstrapScriptError, len(*in))
+		copy(*out, *in)
+	}
+	if in.SubscriptionSlots != nil {
+		in, out := &in.SubscriptionSlots, &out.SubscriptionSlots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -649,7 +1178,7 @@ func (in *Postgresql) DeepCopyInto(out *Postgresql) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -727,6 +1256,71 @@ func (in *PostgresqlParam) DeepCopy() *PostgresqlParam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreparedDatabase) DeepCopyInto(out *PreparedDatabase) {
+	*out = *in
+	if in.PreparedSchemas != nil {
+		in, out := &in.PreparedSchemas, &out.PreparedSchemas
+		*out = make(map[string]PreparedSchema, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreparedDatabase.
+func (in *PreparedDatabase) DeepCopy() *PreparedDatabase {
+	if in == nil {
+		return nil
+	}
+	out := new(PreparedDatabase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreparedSchema) DeepCopyInto(out *PreparedSchema) {
+	*out = *in
+	if in.DefaultRoles != nil {
+		in, out := &in.DefaultRoles, &out.DefaultRoles
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreparedSchema.
+func (in *PreparedSchema) DeepCopy() *PreparedSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(PreparedSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Publication) DeepCopyInto(out *Publication) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Publication.
+func (in *Publication) DeepCopy() *Publication {
+	if in == nil {
+		return nil
+	}
+	out := new(Publication)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceDescription) DeepCopyInto(out *ResourceDescription) {
 	*out = *in
@@ -761,6 +1355,71 @@ func (in *Resources) DeepCopy() *Resources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerification) DeepCopyInto(out *RestoreVerification) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerification.
+func (in *RestoreVerification) DeepCopy() *RestoreVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationConfiguration) DeepCopyInto(out *RestoreVerificationConfiguration) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationConfiguration.
+func (in *RestoreVerificationConfiguration) DeepCopy() *RestoreVerificationConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationStatus) DeepCopyInto(out *RestoreVerificationStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationStatus.
+func (in *RestoreVerificationStatus) DeepCopy() *RestoreVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalyrConfiguration) DeepCopyInto(out *ScalyrConfiguration) {
 	*out = *in
@@ -777,6 +1436,23 @@ func (in *ScalyrConfiguration) DeepCopy() *ScalyrConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretBackendConfiguration) DeepCopyInto(out *SecretBackendConfiguration) {
+	*out = *in
+	out.VaultTokenSecretName = in.VaultTokenSecretName
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretBackendConfiguration.
+func (in *SecretBackendConfiguration) DeepCopy() *SecretBackendConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretBackendConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Sidecar) DeepCopyInto(out *Sidecar) {
 	*out = *in
@@ -822,6 +1498,37 @@ func (in *StandbyDescription) DeepCopy() *StandbyDescription {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Subscription) DeepCopyInto(out *Subscription) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CopyData != nil {
+		in, out := &in.CopyData, &out.CopyData
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subscription.
+func (in *Subscription) DeepCopy() *Subscription {
+	if in == nil {
+		return nil
+	}
+	out := new(Subscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSDescription) DeepCopyInto(out *TLSDescription) {
 	*out = *in
@@ -8,21 +8,27 @@ import (
 	"net/http/pprof"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"github.com/zalando/postgres-operator/pkg/cluster"
 	"github.com/zalando/postgres-operator/pkg/spec"
 	"github.com/zalando/postgres-operator/pkg/util"
 	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 )
 
 const (
 	httpAPITimeout  = time.Minute * 1
 	shutdownTimeout = time.Second * 10
 	httpReadTimeout = time.Millisecond * 100
+
+	// every response names the operator instance that manages the clusters
+	leaderHeader = "X-Postgres-Operator-Leader"
 )
 
 // ControllerInformer describes stats methods of a controller
@@ -34,11 +40,15 @@ type controllerInformer interface {
 	ClusterStatus(team, namespace, cluster string) (*cluster.ClusterStatus, error)
 	ClusterLogs(team, namespace, cluster string) ([]*spec.LogEntry, error)
 	ClusterHistory(team, namespace, cluster string) ([]*spec.Diff, error)
+	ClusterPlan(team, namespace, cluster string) (*cluster.ClusterPlan, error)
+	ClusterLogicalBackups(team, namespace, cluster string) (*cluster.LogicalBackupCatalog, error)
 	ClusterDatabasesMap() map[string][]string
 	WorkerLogs(workerID uint32) ([]*spec.LogEntry, error)
 	ListQueue(workerID uint32) (*spec.QueueDump, error)
 	GetWorkersCnt() uint32
 	WorkerStatus(workerID uint32) (*cluster.WorkerStatus, error)
+	IsLeader() bool
+	GetLeader() string
 }
 
 // Server describes HTTP API server
@@ -58,11 +68,15 @@ var (
 	clusterStatusRe  = fmt.Sprintf(`^/clusters/%s/%s/%s/?$`, teamRe, namespaceRe, clusterRe)
 	clusterLogsRe    = fmt.Sprintf(`^/clusters/%s/%s/%s/logs/?$`, teamRe, namespaceRe, clusterRe)
 	clusterHistoryRe = fmt.Sprintf(`^/clusters/%s/%s/%s/history/?$`, teamRe, namespaceRe, clusterRe)
+	clusterPlanRe    = fmt.Sprintf(`^/clusters/%s/%s/%s/plan/?$`, teamRe, namespaceRe, clusterRe)
+	clusterBackupsRe = fmt.Sprintf(`^/clusters/%s/%s/%s/logical-backups/?$`, teamRe, namespaceRe, clusterRe)
 	teamURLRe        = fmt.Sprintf(`^/clusters/%s/?$`, teamRe)
 
 	clusterStatusURL     = regexp.MustCompile(clusterStatusRe)
 	clusterLogsURL       = regexp.MustCompile(clusterLogsRe)
 	clusterHistoryURL    = regexp.MustCompile(clusterHistoryRe)
+	clusterPlanURL       = regexp.MustCompile(clusterPlanRe)
+	clusterBackupsURL    = regexp.MustCompile(clusterBackupsRe)
 	teamURL              = regexp.MustCompile(teamURLRe)
 	workerLogsURL        = regexp.MustCompile(`^/workers/(?P<id>\d+)/logs/?$`)
 	workerEventsQueueURL = regexp.MustCompile(`^/workers/(?P<id>\d+)/queue/?$`)
@@ -86,6 +100,8 @@ func New(controller controllerInformer, port int, logger *logrus.Logger) *Server
 	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
 
+	mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+
 	mux.Handle("/status/", http.HandlerFunc(s.controllerStatus))
 	mux.Handle("/config/", http.HandlerFunc(s.operatorConfig))
 
@@ -95,7 +111,7 @@ func New(controller controllerInformer, port int, logger *logrus.Logger) *Server
 
 	s.http = http.Server{
 		Addr:        fmt.Sprintf(":%d", port),
-		Handler:     http.TimeoutHandler(mux, httpAPITimeout, ""),
+		Handler:     http.TimeoutHandler(s.leaderAware(mux), httpAPITimeout, ""),
 		ReadTimeout: httpReadTimeout,
 	}
 
@@ -134,6 +150,39 @@ func (s *Server) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	s.logger.Errorf("Could not shutdown http server: %v", err)
 }
 
+// servedByStandby tells whether a standby operator instance answers requests for a path. Only the
+// leader knows the clusters and workers, a standby only describes itself.
+func servedByStandby(path string) bool {
+	for _, prefix := range []string{"/status/", "/config/", "/metrics", "/debug/pprof/"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// leaderAware reports the leader with every response. Standby operator instances only serve
+// read-only requests about themselves, everything else has to be sent to the leader.
+func (s *Server) leaderAware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		leader := s.controller.GetLeader()
+		w.Header().Set(leaderHeader, leader)
+
+		readOnly := req.Method == http.MethodGet || req.Method == http.MethodHead
+		if !s.controller.IsLeader() && !(readOnly && servedByStandby(req.URL.Path)) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			err := fmt.Errorf("this operator instance is a standby, send the request to the leader %q", leader)
+			if err2 := json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()}); err2 != nil {
+				s.logger.Errorf("could not encode error response %q: %v", err, err2)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
 func (s *Server) respond(obj interface{}, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	if err != nil {
@@ -191,6 +240,12 @@ func (s *Server) clusters(w http.ResponseWriter, req *http.Request) {
 	} else if matches := util.FindNamedStringSubmatch(clusterHistoryURL, req.URL.Path); matches != nil {
 		namespace := matches["namespace"]
 		resp, err = s.controller.ClusterHistory(matches["team"], namespace, matches["cluster"])
+	} else if matches := util.FindNamedStringSubmatch(clusterPlanURL, req.URL.Path); matches != nil {
+		namespace := matches["namespace"]
+		resp, err = s.controller.ClusterPlan(matches["team"], namespace, matches["cluster"])
+	} else if matches := util.FindNamedStringSubmatch(clusterBackupsURL, req.URL.Path); matches != nil {
+		namespace := matches["namespace"]
+		resp, err = s.controller.ClusterLogicalBackups(matches["team"], namespace, matches["cluster"])
 	} else if req.URL.Path == clustersURL {
 		clusterNamesPerTeam := make(map[string][]string)
 		for team, clusters := range s.controller.TeamClusterList() {
@@ -1,13 +1,19 @@
 package apiserver
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	clusterStatusTest        = "/clusters/test-id/test_namespace/testcluster/"
 	clusterStatusNumericTest = "/clusters/test-id-1/test_namespace/testcluster/"
 	clusterLogsTest          = "/clusters/test-id/test_namespace/testcluster/logs/"
+	clusterPlanTest          = "/clusters/test-id/test_namespace/testcluster/plan/"
+	clusterBackupsTest       = "/clusters/test-id/test_namespace/testcluster/logical-backups/"
 	teamTest                 = "/clusters/test-id/"
 )
 
@@ -24,7 +30,68 @@ func TestUrlRegexps(t *testing.T) {
 		t.Errorf("clusterLogsURL can't match %s", clusterLogsTest)
 	}
 
+	if clusterPlanURL.FindStringSubmatch(clusterPlanTest) == nil {
+		t.Errorf("clusterPlanURL can't match %s", clusterPlanTest)
+	}
+
+	if clusterStatusURL.FindStringSubmatch(clusterPlanTest) != nil {
+		t.Errorf("clusterStatusURL should not match %s", clusterPlanTest)
+	}
+
+	if clusterBackupsURL.FindStringSubmatch(clusterBackupsTest) == nil {
+		t.Errorf("clusterBackupsURL can't match %s", clusterBackupsTest)
+	}
+
 	if teamURL.FindStringSubmatch(teamTest) == nil {
 		t.Errorf("teamURL can't match %s", teamTest)
 	}
 }
+
+type mockController struct {
+	controllerInformer
+	isLeader bool
+}
+
+func (c *mockController) IsLeader() bool {
+	return c.isLeader
+}
+
+func (c *mockController) GetLeader() string {
+	return "postgres-operator-1"
+}
+
+func TestLeaderAware(t *testing.T) {
+	testName := "TestLeaderAware"
+	tests := []struct {
+		subTest  string
+		isLeader bool
+		method   string
+		path     string
+		code     int
+	}{
+		{"leader serves read-only requests", true, http.MethodGet, "/clusters/", http.StatusOK},
+		{"leader serves other requests", true, http.MethodPost, "/clusters/", http.StatusOK},
+		{"standby serves its status", false, http.MethodGet, "/status/", http.StatusOK},
+		{"standby rejects requests for clusters", false, http.MethodGet, "/clusters/acid/", http.StatusServiceUnavailable},
+		{"standby rejects other requests", false, http.MethodPost, "/status/", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		s := &Server{
+			logger:     logrus.New().WithField("test", testName),
+			controller: &mockController{isLeader: tt.isLeader},
+		}
+		handler := s.leaderAware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s %s: Expected status code %d, have %d instead", testName, tt.subTest, tt.code, rec.Code)
+		}
+		if leader := rec.Header().Get(leaderHeader); leader != "postgres-operator-1" {
+			t.Errorf("%s %s: Expected leader header, have %q instead", testName, tt.subTest, leader)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/retryutil"
+)
+
+const (
+	// Spilo takes base backups with the same script and WAL-E/WAL-G environment as the scheduled ones. The script
+	// first prunes the base backups older than BACKUP_NUM_TO_RETAIN days, which on-demand backups leave to the
+	// scheduled ones by retaining the backups for a practically unlimited number of days.
+	basebackupCommand = "envdir /run/etc/wal-e.d/env env BACKUP_NUM_TO_RETAIN=1000000 /scripts/postgres_backup.sh " +
+		constants.PostgresDataPath + "/data 2>&1"
+	serverVersionCommand = `psql -tAc "SHOW server_version_num"`
+	walPositionCommand   = `psql -tAc "SELECT %s, sum(pg_database_size(datname)) FROM pg_database"`
+	walPrefixCommand     = "cat /run/etc/wal-e.d/env/WALE_S3_PREFIX /run/etc/wal-e.d/env/WALE_GS_PREFIX 2>/dev/null | head -n 1"
+
+	// the functions returning the current WAL file were renamed with Postgres 10
+	walFunctionsRenameServerVersion = 100000
+	// allows for the difference between the clocks of the operator and of the storage of the base backups
+	basebackupClockSkew = time.Minute
+
+	logicalBackupContainerName = "logical-backup"
+)
+
+// the name WAL-E and WAL-G give a base backup starts with the WAL file of the start location in its backup label
+var basebackupNameRegexp = regexp.MustCompile(`^base_([0-9A-F]{24})`)
+
+// logicalBackupResult is written by the logical backup container to its termination message
+type logicalBackupResult struct {
+	Location string `json:"location"`
+	Size     int64  `json:"size"`
+}
+
+// Backup takes the on-demand backup requested by a PostgresBackup and reports its progress in the status of
+// the PostgresBackup. It returns once the backup has finished, so callers are expected to run it in the background.
+func (c *Cluster) Backup(backup *acidv1.PostgresBackup) error {
+	c.logger.Infof("taking %s backup %q", backup.Spec.Method, backup.Name)
+	status := backup.Status.DeepCopy()
+
+	var err error
+	switch backup.Spec.Method {
+	case acidv1.BackupMethodBasebackup:
+		err = c.basebackup(backup, status)
+	case acidv1.BackupMethodLogical:
+		err = c.logicalBackup(backup, status)
+	default:
+		err = fmt.Errorf("unknown backup method %q", backup.Spec.Method)
+	}
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	if err != nil {
+		status.Phase = acidv1.BackupPhaseFailed
+		status.Message = err.Error()
+		c.recordBackupEvent(backup, v1.EventTypeWarning, "Backup", "Backup failed: %v", err)
+	} else {
+		status.Phase = acidv1.BackupPhaseSucceeded
+		status.Message = ""
+		c.recordBackupEvent(backup, v1.EventTypeNormal, "Backup", "Backup stored at %q", status.Location)
+	}
+
+	if err2 := c.updatePostgresBackupStatus(backup, status); err2 != nil {
+		return err2
+	}
+	return err
+}
+
+// basebackup takes a base backup in the master pod. The WAL range starts with the WAL file of the start location
+// of the backup and ends with the current WAL file at the end of the backup, the size is the size of all
+// databases at the end of the backup.
+func (c *Cluster) basebackup(backup *acidv1.PostgresBackup, status *acidv1.PostgresBackupStatus) error {
+	if status.Phase == acidv1.BackupPhaseRunning {
+		// the backup runs in the exec session of the previous operator instance, which is gone
+		return fmt.Errorf("the operator restarted while the backup was running, the backup may be incomplete")
+	}
+
+	masterPod, err := c.getClusterMasterPod(c.Name)
+	if err != nil {
+		return fmt.Errorf("could not get master pod: %v", err)
+	}
+	podName := util.NameFromMeta(masterPod.ObjectMeta)
+
+	now := metav1.Now()
+	status.Phase = acidv1.BackupPhaseRunning
+	status.StartTime = &now
+	if err := c.updatePostgresBackupStatus(backup, status); err != nil {
+		return err
+	}
+
+	c.logger.Infof("running base backup %q in the master pod %q", backup.Name, podName)
+	output, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", basebackupCommand)
+	if err != nil {
+		return fmt.Errorf("could not take base backup: %v", err)
+	}
+	c.logger.Debugf("base backup %q: %s", backup.Name, output)
+
+	backupList, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", backupListCommand)
+	if err != nil {
+		return fmt.Errorf("could not list base backups: %v", err)
+	}
+	if status.WALStart, err = basebackupWALStart(backupList, now.Add(-basebackupClockSkew)); err != nil {
+		return err
+	}
+	if status.WALEnd, status.Size, err = c.getWALPosition(&podName); err != nil {
+		return err
+	}
+	if prefix, err := c.ExecCommand(&podName, "/bin/sh", "-c", walPrefixCommand); err != nil {
+		c.logger.Warningf("could not get the location of base backup %q: %v", backup.Name, err)
+	} else if prefix = strings.TrimSpace(prefix); prefix != "" {
+		status.Location = strings.TrimSuffix(prefix, "/") + "/basebackups_005/"
+	}
+	return nil
+}
+
+// basebackupWALStart returns the WAL file the newest base backup in the output of backup-list starts with. The
+// backup has to be modified after the given time, so that an older backup is not taken for the one just taken.
+func basebackupWALStart(output string, since time.Time) (string, error) {
+	walStart, newest := "", time.Time{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		match := basebackupNameRegexp.FindStringSubmatch(fields[0])
+		if match == nil {
+			continue
+		}
+		modified, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			return "", fmt.Errorf("could not parse modification time of base backup %q: %v", fields[0], err)
+		}
+		if modified.After(newest) {
+			walStart, newest = match[1], modified
+		}
+	}
+	if walStart == "" || newest.Before(since) {
+		return "", fmt.Errorf("could not find a base backup taken after %s", since.Format(time.RFC3339))
+	}
+	return walStart, nil
+}
+
+// walPositionSelect returns the expression for the current WAL file for the given server version
+func walPositionSelect(serverVersion int) string {
+	if serverVersion < walFunctionsRenameServerVersion {
+		return "pg_xlogfile_name(pg_current_xlog_location())"
+	}
+	return "pg_walfile_name(pg_current_wal_lsn())"
+}
+
+// getWALPosition returns the current WAL file of the master and the size of all its databases
+func (c *Cluster) getWALPosition(podName *spec.NamespacedName) (string, int64, error) {
+	output, err := c.ExecCommand(podName, "/bin/su", "postgres", "-c", serverVersionCommand)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not get server version: %v", err)
+	}
+	serverVersion, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return "", 0, fmt.Errorf("could not parse server version %q: %v", output, err)
+	}
+
+	output, err = c.ExecCommand(podName, "/bin/su", "postgres", "-c", fmt.Sprintf(walPositionCommand, walPositionSelect(serverVersion)))
+	if err != nil {
+		return "", 0, fmt.Errorf("could not get WAL position: %v", err)
+	}
+	walFile, size, err := parseWALPosition(output)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not get WAL position: %v", err)
+	}
+	return walFile, size, nil
+}
+
+// parseWALPosition parses the WAL file and the database size returned by psql
+func parseWALPosition(output string) (string, int64, error) {
+	fields := strings.Split(strings.TrimSpace(output), "|")
+	if len(fields) != 2 || fields[0] == "" {
+		return "", 0, fmt.Errorf("unexpected output %q", output)
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not parse database size %q: %v", fields[1], err)
+	}
+	return fields[0], size, nil
+}
+
+// logicalBackup runs pg_dumpall in a one-off job built from the logical backup pod template and waits for it.
+// The job survives operator restarts, so a running backup is picked up again.
+func (c *Cluster) logicalBackup(backup *acidv1.PostgresBackup, status *acidv1.PostgresBackupStatus) error {
+	job, err := c.generatePostgresBackupJob(backup)
+	if err != nil {
+		return fmt.Errorf("could not generate logical backup job: %v", err)
+	}
+	if _, err := c.KubeClient.Jobs(c.Namespace).Create(context.TODO(), job, metav1.CreateOptions{}); err != nil {
+		if !k8sutil.ResourceAlreadyExists(err) {
+			return fmt.Errorf("could not create logical backup job: %v", err)
+		}
+		c.logger.Infof("logical backup job %q already exists", job.Name)
+	}
+
+	if status.Phase != acidv1.BackupPhaseRunning {
+		now := metav1.Now()
+		status.Phase = acidv1.BackupPhaseRunning
+		status.StartTime = &now
+		status.JobName = job.Name
+		if err := c.updatePostgresBackupStatus(backup, status); err != nil {
+			return err
+		}
+	}
+
+	var jobErr error
+	err = retryutil.Retry(constants.PostgresBackupCheckInterval, constants.PostgresBackupTimeout,
+		func() (bool, error) {
+			current, err := c.KubeClient.Jobs(c.Namespace).Get(context.TODO(), job.Name, metav1.GetOptions{})
+			if err != nil {
+				c.logger.Warningf("could not get logical backup job %q: %v", job.Name, err)
+				return false, nil
+			}
+			if current.Status.Succeeded > 0 {
+				return true, nil
+			}
+			for _, condition := range current.Status.Conditions {
+				if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+					jobErr = fmt.Errorf("logical backup job %q failed: %s", job.Name, condition.Message)
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+	if err != nil {
+		return fmt.Errorf("logical backup job %q did not finish: %v", job.Name, err)
+	}
+	if jobErr != nil {
+		return jobErr
+	}
+
+	pods, err := c.KubeClient.Pods(c.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	if err != nil {
+		return fmt.Errorf("could not get pods of logical backup job %q: %v", job.Name, err)
+	}
+	result, err := logicalBackupResultFromPods(pods.Items)
+	if err != nil {
+		return fmt.Errorf("could not get the result of logical backup job %q: %v", job.Name, err)
+	}
+	status.Location = result.Location
+	status.Size = result.Size
+	return nil
+}
+
+// logicalBackupResultFromPods reads the result of the logical backup from the termination message of
+// the container that succeeded
+func logicalBackupResultFromPods(pods []v1.Pod) (logicalBackupResult, error) {
+	var result logicalBackupResult
+	for _, pod := range pods {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			terminated := containerStatus.State.Terminated
+			if containerStatus.Name != logicalBackupContainerName || terminated == nil || terminated.ExitCode != 0 {
+				continue
+			}
+			if err := json.Unmarshal([]byte(terminated.Message), &result); err != nil {
+				return result, fmt.Errorf("could not parse termination message %q: %v", terminated.Message, err)
+			}
+			if result.Location == "" {
+				return result, fmt.Errorf("termination message %q has no location", terminated.Message)
+			}
+			return result, nil
+		}
+	}
+	return result, fmt.Errorf("no succeeded %s container found", logicalBackupContainerName)
+}
+
+func (c *Cluster) updatePostgresBackupStatus(backup *acidv1.PostgresBackup, status *acidv1.PostgresBackupStatus) error {
+	statusData, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not marshal status: %v", err)
+	}
+	patchStatus := make(map[string]interface{})
+	if err := json.Unmarshal(statusData, &patchStatus); err != nil {
+		return fmt.Errorf("could not unmarshal status: %v", err)
+	}
+	// a merge patch only removes a message of an earlier phase when it is explicitly set to null
+	if status.Message == "" {
+		patchStatus["message"] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"status": patchStatus})
+	if err != nil {
+		return fmt.Errorf("could not marshal status: %v", err)
+	}
+
+	if _, err := c.KubeClient.AcidV1ClientSet.AcidV1().PostgresBackups(backup.Namespace).Patch(
+		context.TODO(), backup.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("could not update status of backup %q: %v", backup.Name, err)
+	}
+	return nil
+}
+
+func (c *Cluster) recordBackupEvent(backup *acidv1.PostgresBackup, eventType, reason, messageFmt string, args ...interface{}) {
+	if c.eventRecorder == nil {
+		return
+	}
+	c.eventRecorder.Eventf(backup, eventType, reason, messageFmt, args...)
+}
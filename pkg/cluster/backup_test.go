@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func TestParseWALPosition(t *testing.T) {
+	testName := "TestParseWALPosition"
+	tests := []struct {
+		output  string
+		walFile string
+		size    int64
+		err     bool
+	}{
+		{"0000000100000000000000A3|25165824\n", "0000000100000000000000A3", 25165824, false},
+		{"0000000100000000000000A3|", "", 0, true},
+		{"", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		walFile, size, err := parseWALPosition(tt.output)
+		if walFile != tt.walFile || size != tt.size || (err != nil) != tt.err {
+			t.Errorf("%s: expected %q, %d and error %t for %q, got %q, %d and error %v",
+				testName, tt.walFile, tt.size, tt.err, tt.output, walFile, size, err)
+		}
+	}
+}
+
+func TestBasebackupWALStart(t *testing.T) {
+	testName := "TestBasebackupWALStart"
+	since := time.Date(2020, 6, 2, 9, 59, 0, 0, time.UTC)
+	tests := []struct {
+		subTest  string
+		output   string
+		walStart string
+		err      bool
+	}{
+		{
+			subTest: "WAL-E",
+			output: "name\tlast_modified\texpanded_size_bytes\twal_segment_backup_start\twal_segment_offset_backup_start\n" +
+				"base_000000010000000000000002_00000040\t2020-06-01T10:00:00.000Z\t\t000000010000000000000002\t00000040\n" +
+				"base_0000000100000000000000A3_00000028\t2020-06-02T10:00:00.000Z\t\t0000000100000000000000A3\t00000028\n",
+			walStart: "0000000100000000000000A3",
+		},
+		{
+			subTest: "WAL-G",
+			output: "name                          modified             wal_segment_backup_start\n" +
+				"base_0000000200000000000000A3 2020-06-02T10:00:00Z 0000000200000000000000A3\n" +
+				"base_000000010000000000000002 2020-06-01T10:00:00Z 000000010000000000000002\n",
+			walStart: "0000000200000000000000A3",
+		},
+		{
+			subTest: "only older backups",
+			output:  "base_000000010000000000000002 2020-06-01T10:00:00Z 000000010000000000000002\n",
+			err:     true,
+		},
+		{
+			subTest: "no backups",
+			output:  "name\tlast_modified\texpanded_size_bytes\n",
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		walStart, err := basebackupWALStart(tt.output, since)
+		if walStart != tt.walStart || (err != nil) != tt.err {
+			t.Errorf("%s %s: expected %q and error %t, got %q and error %v", testName, tt.subTest, tt.walStart, tt.err, walStart, err)
+		}
+	}
+}
+
+func TestWALPositionSelect(t *testing.T) {
+	testName := "TestWALPositionSelect"
+	if expression := walPositionSelect(90612); expression != "pg_xlogfile_name(pg_current_xlog_location())" {
+		t.Errorf("%s: unexpected expression %q for Postgres 9.6", testName, expression)
+	}
+	if expression := walPositionSelect(120004); expression != "pg_walfile_name(pg_current_wal_lsn())" {
+		t.Errorf("%s: unexpected expression %q for Postgres 12", testName, expression)
+	}
+}
+
+func TestLogicalBackupResultFromPods(t *testing.T) {
+	testName := "TestLogicalBackupResultFromPods"
+	pod := func(exitCode int32, message string) v1.Pod {
+		return v1.Pod{
+			Status: v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name: logicalBackupContainerName,
+						State: v1.ContainerState{
+							Terminated: &v1.ContainerStateTerminated{ExitCode: exitCode, Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+	location := "s3://backups/spilo/acid-test-cluster/logical_backups/pre-migration.sql.gz"
+
+	tests := []struct {
+		subTest  string
+		pods     []v1.Pod
+		expected logicalBackupResult
+		err      bool
+	}{
+		{
+			subTest:  "succeeded backup",
+			pods:     []v1.Pod{pod(0, `{"location": "`+location+`", "size": 1024}`)},
+			expected: logicalBackupResult{Location: location, Size: 1024},
+		},
+		{
+			subTest: "backup without termination message",
+			pods:    []v1.Pod{pod(0, "")},
+			err:     true,
+		},
+		{
+			subTest: "failed backup",
+			pods:    []v1.Pod{pod(1, "")},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		result, err := logicalBackupResultFromPods(tt.pods)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+			continue
+		}
+		if err == nil && result != tt.expected {
+			t.Errorf("%s %s: expected result %v, got %v", testName, tt.subTest, tt.expected, result)
+		}
+	}
+}
+
+func TestGeneratePostgresBackupJob(t *testing.T) {
+	testName := "TestGeneratePostgresBackupJob"
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Auth: config.Auth{
+					SuperUsername: superUserName,
+				},
+			},
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+			Spec: acidv1.PostgresSpec{
+				Resources: acidv1.Resources{
+					ResourceRequests: acidv1.ResourceDescription{CPU: "1", Memory: "10"},
+					ResourceLimits:   acidv1.ResourceDescription{CPU: "1", Memory: "10"},
+				},
+			},
+		}, logger, eventRecorder)
+
+	backup := &acidv1.PostgresBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pre-migration", Namespace: "default", UID: types.UID("1234")},
+		Spec:       acidv1.PostgresBackupSpec{Cluster: "acid-test-cluster", Method: acidv1.BackupMethodLogical},
+	}
+	job, err := cluster.generatePostgresBackupJob(backup)
+	if err != nil {
+		t.Fatalf("%s: could not generate job: %v", testName, err)
+	}
+
+	if job.Name != "postgres-backup-pre-migration" {
+		t.Errorf("%s: unexpected job name %q", testName, job.Name)
+	}
+	if len(job.OwnerReferences) != 1 || job.OwnerReferences[0].UID != backup.UID ||
+		job.OwnerReferences[0].Kind != acidv1.PostgresBackupCRDResourceKind {
+		t.Errorf("%s: expected the job to be owned by the backup, got %v", testName, job.OwnerReferences)
+	}
+	if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != 0 {
+		t.Errorf("%s: expected the job to run only once", testName)
+	}
+
+	found := false
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "LOGICAL_BACKUP_FILE_NAME" {
+			found = env.Value == "pre-migration.sql.gz"
+		}
+	}
+	if !found {
+		t.Errorf("%s: expected the file name of the backup in the environment", testName)
+	}
+}
@@ -0,0 +1,319 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/lib/pq"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+const (
+	// the executed scripts are tracked in every database, so that they survive clones and restores
+	createBootstrapScriptsTableSQL = `CREATE SCHEMA IF NOT EXISTS postgres_operator;
+	CREATE TABLE IF NOT EXISTS postgres_operator.bootstrap_scripts (
+		script text PRIMARY KEY,
+		checksum text NOT NULL,
+		executed_at timestamptz NOT NULL DEFAULT now()
+	);`
+	grantBootstrapScriptsSQL = `GRANT USAGE ON SCHEMA postgres_operator TO %[1]s;
+	GRANT SELECT, INSERT ON postgres_operator.bootstrap_scripts TO %[1]s;`
+	getBootstrapScriptsSQL         = `SELECT script, checksum FROM postgres_operator.bootstrap_scripts;`
+	bootstrapScriptsTableExistsSQL = `SELECT to_regclass('postgres_operator.bootstrap_scripts') IS NOT NULL;`
+	setBootstrapRoleSQL            = `SET LOCAL ROLE %s;`
+	insertBootstrapScriptSQL       = `INSERT INTO postgres_operator.bootstrap_scripts (script, checksum) VALUES ($1, $2);`
+)
+
+// bootstrapScript is a SQL script read from a ConfigMap, named configmap/key
+type bootstrapScript struct {
+	name     string
+	sql      string
+	checksum string
+}
+
+func newBootstrapScript(configMap, key, script string) bootstrapScript {
+	checksum := sha256.Sum256([]byte(script))
+	return bootstrapScript{
+		name:     configMap + "/" + key,
+		sql:      script,
+		checksum: hex.EncodeToString(checksum[:]),
+	}
+}
+
+// pendingBootstrapScripts returns the scripts that have not been executed in the database yet, in their
+// order. Scripts are never executed twice: the name of a script that has changed since its execution is
+// returned with an error, and the scripts after it are held back since they might depend on it.
+func pendingBootstrapScripts(scripts []bootstrapScript, executed map[string]string) ([]bootstrapScript, string, error) {
+	pending := make([]bootstrapScript, 0)
+	for _, script := range scripts {
+		checksum, exists := executed[script.name]
+		if !exists {
+			pending = append(pending, script)
+			continue
+		}
+		if checksum != script.checksum {
+			return pending, script.name, fmt.Errorf("script has changed since it was executed with checksum %s", checksum)
+		}
+	}
+	return pending, "", nil
+}
+
+// readBootstrapScripts reads the scripts of a database from the ConfigMaps in the namespace of the cluster,
+// in the order of the manifest and of the keys of a ConfigMap
+func (c *Cluster) readBootstrapScripts(refs []acidv1.BootstrapScript) ([]bootstrapScript, string, error) {
+	scripts := make([]bootstrapScript, 0)
+	for _, ref := range refs {
+		name := ref.ConfigMap
+		if ref.Key != "" {
+			name += "/" + ref.Key
+		}
+		configMap, err := c.KubeClient.ConfigMaps(c.Namespace).Get(context.TODO(), ref.ConfigMap, metav1.GetOptions{})
+		if err != nil {
+			return nil, name, fmt.Errorf("could not get ConfigMap: %v", err)
+		}
+
+		if ref.Key != "" {
+			script, exists := configMap.Data[ref.Key]
+			if !exists {
+				return nil, name, fmt.Errorf("ConfigMap has no key %q", ref.Key)
+			}
+			scripts = append(scripts, newBootstrapScript(ref.ConfigMap, ref.Key, script))
+			continue
+		}
+		keys := make([]string, 0, len(configMap.Data))
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			scripts = append(scripts, newBootstrapScript(ref.ConfigMap, key, configMap.Data[key]))
+		}
+	}
+	return scripts, "", nil
+}
+
+// syncBootstrapSQL executes the bootstrap scripts of the manifest that have not been executed in their
+// database yet. Scripts that cannot be executed are reported in the cluster status, without failing the
+// sync, and hold back the following scripts of the same database.
+func (c *Cluster) syncBootstrapSQL() error {
+	if len(c.Spec.BootstrapSQL) == 0 && len(c.Status.BootstrapSQLErrors) == 0 {
+		return nil
+	}
+	c.setProcessName("syncing bootstrap SQL")
+
+	datnames := make([]string, 0, len(c.Spec.BootstrapSQL))
+	for datname := range c.Spec.BootstrapSQL {
+		datnames = append(datnames, datname)
+	}
+	sort.Strings(datnames)
+
+	owners := c.databaseOwners()
+	scriptErrors := make([]acidv1.BootstrapScriptError, 0)
+	for _, datname := range datnames {
+		scripts, scriptName, err := c.readBootstrapScripts(c.Spec.BootstrapSQL[datname])
+		if err == nil {
+			scriptName, err = c.executeBootstrapScripts(datname, owners[datname], scripts)
+		}
+		if err != nil {
+			c.logger.Warningf("could not execute bootstrap script %q in database %q: %v", scriptName, datname, err)
+			scriptErrors = append(scriptErrors,
+				acidv1.BootstrapScriptError{Database: datname, Script: scriptName, Message: err.Error()})
+		}
+	}
+
+	c.updateBootstrapSQLErrorsStatus(scriptErrors)
+	return nil
+}
+
+// executeBootstrapScripts executes the pending scripts in the database, each in its own transaction together
+// with its tracking entry. The scripts never run with the privileges of the operator: the connection logs in
+// as the owner of the database or as a login role that is a member of it, so that the scripts cannot gain more
+// privileges than the owner, and the owner owns the objects they create. It returns the name of the script
+// that failed, if any.
+func (c *Cluster) executeBootstrapScripts(datname, owner string, scripts []bootstrapScript) (string, error) {
+	pending, changed, err := c.getPendingBootstrapScripts(datname, owner, scripts)
+	if len(pending) == 0 {
+		return changed, err
+	}
+
+	loginUser, ok := c.bootstrapLoginUser(owner)
+	if !ok {
+		return pending[0].name, fmt.Errorf("no role with known credentials can log in as the owner %q of the database", owner)
+	}
+	conn, connErr := c.openDbConn(c.pgUserConnectionString(datname, loginUser))
+	if connErr != nil {
+		return pending[0].name, fmt.Errorf("could not connect as role %q: %v", loginUser.Name, connErr)
+	}
+	defer func() {
+		if err2 := conn.Close(); err2 != nil {
+			c.logger.Errorf("could not close database connection: %v", err2)
+		}
+	}()
+
+	for _, script := range pending {
+		c.logger.Infof("executing bootstrap script %q in database %q as role %q", script.name, datname, loginUser.Name)
+		if err := c.executeBootstrapScript(conn, owner, script); err != nil {
+			return script.name, err
+		}
+		c.recordEvent(v1.EventTypeNormal, "BootstrapSQL", "Executed script %q in database %q", script.name, datname)
+	}
+	return changed, err
+}
+
+// getPendingBootstrapScripts creates the table of executed scripts in the database, allows the owner to track
+// its scripts there and returns the scripts to execute
+func (c *Cluster) getPendingBootstrapScripts(datname, owner string, scripts []bootstrapScript) ([]bootstrapScript, string, error) {
+	if owner == "" {
+		return nil, "", fmt.Errorf("database has no owner to execute the scripts as")
+	}
+	if err := c.initDbConnWithName(datname); err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+
+	if _, err := c.pgDb.Exec(createBootstrapScriptsTableSQL); err != nil {
+		return nil, "", fmt.Errorf("could not create the table of executed scripts: %v", err)
+	}
+	if _, err := c.pgDb.Exec(fmt.Sprintf(grantBootstrapScriptsSQL, pq.QuoteIdentifier(owner))); err != nil {
+		return nil, "", fmt.Errorf("could not grant access to the table of executed scripts to %q: %v", owner, err)
+	}
+	executed, err := c.getExecutedBootstrapScripts()
+	if err != nil {
+		return nil, "", fmt.Errorf("could not get executed scripts: %v", err)
+	}
+	return pendingBootstrapScripts(scripts, executed)
+}
+
+// bootstrapLoginUser returns the role to log in as to execute scripts as the owner: the owner itself or,
+// if it cannot log in or its password has been rotated, a login role that is a member of the owner,
+// e.g. a rotation role or the default user of a prepared database. Only roles with a known password qualify.
+func (c *Cluster) bootstrapLoginUser(owner string) (spec.PgUser, bool) {
+	if user, exists := c.pgUsers[owner]; exists && canLogInWithPassword(user) {
+		return user, true
+	}
+
+	names := make([]string, 0)
+	for name, user := range c.pgUsers {
+		if !canLogInWithPassword(user) {
+			continue
+		}
+		for _, role := range user.MemberOf {
+			if role == owner {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return spec.PgUser{}, false
+	}
+	sort.Strings(names)
+	return c.pgUsers[names[0]], true
+}
+
+func canLogInWithPassword(user spec.PgUser) bool {
+	if user.Password == "" || util.IsPasswordHash(user.Password) {
+		return false
+	}
+	for _, flag := range user.Flags {
+		if flag == constants.RoleFlagNoLogin {
+			return false
+		}
+	}
+	return true
+}
+
+// executeBootstrapScript runs the script on the connection of the owner, or of a member of it, as the owner.
+// The role is set again before tracking the script, since the script might have changed it.
+func (c *Cluster) executeBootstrapScript(conn *sql.DB, owner string, script bootstrapScript) (err error) {
+	setRoleSQL := fmt.Sprintf(setBootstrapRoleSQL, pq.QuoteIdentifier(owner))
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			if err2 := tx.Rollback(); err2 != nil && err2 != sql.ErrTxDone {
+				c.logger.Errorf("could not roll back transaction: %v", err2)
+			}
+		}
+	}()
+
+	if _, err = tx.Exec(setRoleSQL); err != nil {
+		return fmt.Errorf("could not set role %q: %v", owner, err)
+	}
+	if _, err = tx.Exec(script.sql); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(setRoleSQL); err != nil {
+		return fmt.Errorf("could not set role %q: %v", owner, err)
+	}
+	if _, err = tx.Exec(insertBootstrapScriptSQL, script.name, script.checksum); err != nil {
+		return fmt.Errorf("could not track the executed script: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %v", err)
+	}
+	return nil
+}
+
+// getExecutedBootstrapScripts returns the checksums of the scripts executed in the database of the connection.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) getExecutedBootstrapScripts() (scripts map[string]string, err error) {
+	var rows *sql.Rows
+
+	if rows, err = c.pgDb.Query(getBootstrapScriptsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = fmt.Errorf("error when closing query cursor: %v", err2)
+		}
+	}()
+
+	scripts = make(map[string]string)
+	for rows.Next() {
+		var name, checksum string
+		if err = rows.Scan(&name, &checksum); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		scripts[name] = checksum
+	}
+	return scripts, nil
+}
+
+func (c *Cluster) updateBootstrapSQLErrorsStatus(scriptErrors []acidv1.BootstrapScriptError) {
+	if len(scriptErrors) == 0 && len(c.Status.BootstrapSQLErrors) == 0 ||
+		reflect.DeepEqual(scriptErrors, c.Status.BootstrapSQLErrors) {
+		return
+	}
+	for _, scriptError := range scriptErrors {
+		c.recordEvent(v1.EventTypeWarning, "BootstrapSQL", "Could not execute script %q in database %q: %s",
+			scriptError.Script, scriptError.Database, scriptError.Message)
+	}
+
+	pg, err := c.patchStatus(map[string]interface{}{"bootstrapSQLErrors": scriptErrors})
+	if err != nil {
+		c.logger.Warningf("could not update bootstrap SQL errors status: %v", err)
+		return
+	}
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
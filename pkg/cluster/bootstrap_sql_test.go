@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func TestPendingBootstrapScripts(t *testing.T) {
+	testName := "TestPendingBootstrapScripts"
+	schema := newBootstrapScript("foo-sql", "01-schema.sql", "CREATE TABLE foo (id int);")
+	seed := newBootstrapScript("foo-sql", "02-seed.sql", "INSERT INTO foo VALUES (1);")
+	grants := newBootstrapScript("foo-sql", "03-grants.sql", "GRANT SELECT ON foo TO bar;")
+	scripts := []bootstrapScript{schema, seed, grants}
+
+	tests := []struct {
+		subTest  string
+		executed map[string]string
+		pending  []bootstrapScript
+		changed  string
+	}{
+		{
+			subTest:  "new database",
+			executed: map[string]string{},
+			pending:  []bootstrapScript{schema, seed, grants},
+		},
+		{
+			subTest:  "new script",
+			executed: map[string]string{schema.name: schema.checksum, seed.name: seed.checksum},
+			pending:  []bootstrapScript{grants},
+		},
+		{
+			subTest:  "changed script",
+			executed: map[string]string{schema.name: schema.checksum, seed.name: "0123"},
+			pending:  []bootstrapScript{},
+			changed:  seed.name,
+		},
+	}
+
+	for _, tt := range tests {
+		pending, changed, err := pendingBootstrapScripts(scripts, tt.executed)
+		if !reflect.DeepEqual(pending, tt.pending) {
+			t.Errorf("%s %s: expected pending scripts %v, got %v", testName, tt.subTest, tt.pending, pending)
+		}
+		if changed != tt.changed || (err != nil) != (tt.changed != "") {
+			t.Errorf("%s %s: expected changed script %q, got %q with error %v", testName, tt.subTest, tt.changed, changed, err)
+		}
+	}
+}
+
+func TestReadBootstrapScripts(t *testing.T) {
+	testName := "TestReadBootstrapScripts"
+	cluster := New(Config{OpConfig: config.Config{}}, k8sutil.NewMockKubernetesClient(), acidv1.Postgresql{},
+		logger, eventRecorder)
+
+	tests := []struct {
+		subTest  string
+		refs     []acidv1.BootstrapScript
+		expected []string
+		failed   string
+	}{
+		{
+			subTest:  "all keys of a ConfigMap",
+			refs:     []acidv1.BootstrapScript{{ConfigMap: "infrastructureroles-test"}},
+			expected: []string{"infrastructureroles-test/foobar"},
+		},
+		{
+			subTest:  "key of a ConfigMap",
+			refs:     []acidv1.BootstrapScript{{ConfigMap: "infrastructureroles-test", Key: "foobar"}},
+			expected: []string{"infrastructureroles-test/foobar"},
+		},
+		{
+			subTest: "missing key",
+			refs:    []acidv1.BootstrapScript{{ConfigMap: "infrastructureroles-test", Key: "schema.sql"}},
+			failed:  "infrastructureroles-test/schema.sql",
+		},
+		{
+			subTest: "missing ConfigMap",
+			refs:    []acidv1.BootstrapScript{{ConfigMap: "infrastructureroles-test"}, {ConfigMap: "foo-sql"}},
+			failed:  "foo-sql",
+		},
+	}
+
+	for _, tt := range tests {
+		scripts, failed, err := cluster.readBootstrapScripts(tt.refs)
+		if failed != tt.failed || (err != nil) != (tt.failed != "") {
+			t.Errorf("%s %s: expected failed script %q, got %q with error %v", testName, tt.subTest, tt.failed, failed, err)
+			continue
+		}
+		names := make([]string, 0)
+		for _, script := range scripts {
+			names = append(names, script.name)
+			if script.checksum != newBootstrapScript("infrastructureroles-test", "foobar", "{}").checksum {
+				t.Errorf("%s %s: unexpected checksum %q of script %q", testName, tt.subTest, script.checksum, script.name)
+			}
+		}
+		if tt.expected != nil && !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s %s: expected scripts %v, got %v", testName, tt.subTest, tt.expected, names)
+		}
+	}
+}
+
+func TestBootstrapLoginUser(t *testing.T) {
+	testName := "TestBootstrapLoginUser"
+	cluster := New(Config{OpConfig: config.Config{}}, k8sutil.NewMockKubernetesClient(), acidv1.Postgresql{},
+		logger, eventRecorder)
+	cluster.pgUsers = map[string]spec.PgUser{
+		"zalando":           {Name: "zalando", Password: "secret", Flags: []string{"LOGIN"}},
+		"foo":               {Name: "foo", Flags: []string{"LOGIN"}},
+		"foo_2012311530":    {Name: "foo_2012311530", Password: "secret", Flags: []string{"LOGIN"}, MemberOf: []string{"foo"}},
+		"bar_owner":         {Name: "bar_owner", Flags: []string{"NOLOGIN"}},
+		"bar_owner_user":    {Name: "bar_owner_user", Password: "secret", Flags: []string{"LOGIN"}, MemberOf: []string{"bar_owner"}},
+		"baz_owner":         {Name: "baz_owner", Flags: []string{"NOLOGIN"}},
+		"baz_reader_user":   {Name: "baz_reader_user", Password: "secret", Flags: []string{"LOGIN"}, MemberOf: []string{"baz_reader"}},
+		"hashed":            {Name: "hashed", Password: "md5d25a5a7ebbb1a1dbe1da2a7a5d5a6a1b", Flags: []string{"LOGIN"}},
+		"bar_owner_nologin": {Name: "bar_owner_nologin", Password: "secret", Flags: []string{"NOLOGIN"}, MemberOf: []string{"bar_owner"}},
+	}
+
+	tests := []struct {
+		subTest  string
+		owner    string
+		expected string
+	}{
+		{
+			subTest:  "owner with password",
+			owner:    "zalando",
+			expected: "zalando",
+		},
+		{
+			subTest:  "rotated password of the owner",
+			owner:    "foo",
+			expected: "foo_2012311530",
+		},
+		{
+			subTest:  "default user of a prepared database",
+			owner:    "bar_owner",
+			expected: "bar_owner_user",
+		},
+		{
+			subTest: "prepared database without default users",
+			owner:   "baz_owner",
+		},
+		{
+			subTest: "password hash",
+			owner:   "hashed",
+		},
+		{
+			subTest: "unknown owner",
+			owner:   "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		user, ok := cluster.bootstrapLoginUser(tt.owner)
+		if ok != (tt.expected != "") || user.Name != tt.expected {
+			t.Errorf("%s %s: expected login role %q, got %q", testName, tt.subTest, tt.expected, user.Name)
+		}
+	}
+}
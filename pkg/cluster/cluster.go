@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
 	"github.com/zalando/postgres-operator/pkg/spec"
@@ -28,6 +29,7 @@ import (
 	"github.com/zalando/postgres-operator/pkg/util/config"
 	"github.com/zalando/postgres-operator/pkg/util/constants"
 	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 	"github.com/zalando/postgres-operator/pkg/util/patroni"
 	"github.com/zalando/postgres-operator/pkg/util/teams"
 	"github.com/zalando/postgres-operator/pkg/util/users"
@@ -48,6 +50,7 @@ type Config struct {
 	InfrastructureRoles          map[string]spec.PgUser // inherited from the controller
 	PodServiceAccount            *v1.ServiceAccount
 	PodServiceAccountRoleBinding *rbacv1.RoleBinding
+	SecretBackend                SecretBackend // inherited from the controller, Kubernetes secrets if not set
 }
 
 // K8S objects that are belongs to a connection pool
@@ -98,7 +101,17 @@ type Cluster struct {
 	currentProcess   Process
 	processMu        sync.RWMutex // protects the current operation for reporting, no need to hold the master mutex
 	specMu           sync.RWMutex // protects the spec for reporting, no need to hold the master mutex
+	eventRecorder    record.EventRecorder
 
+	// disruptive actions postponed until the next maintenance window
+	pendingMaintenance []string
+
+	// the Teams API could not be queried when initializing the users, so that
+	// missing team members must not be treated as removed
+	teamMembersUnknown bool
+
+	// users with rotated passwords, keyed by the user name
+	passwordRotations map[string]*passwordRotation
 }
 
 type compareStatefulsetResult struct {
@@ -109,7 +122,7 @@ type compareStatefulsetResult struct {
 }
 
 // New creates a new cluster. This function should be called from a controller.
-func New(cfg Config, kubeClient k8sutil.KubernetesClient, pgSpec acidv1.Postgresql, logger *logrus.Entry) *Cluster {
+func New(cfg Config, kubeClient k8sutil.KubernetesClient, pgSpec acidv1.Postgresql, logger *logrus.Entry, eventRecorder record.EventRecorder) *Cluster {
 	deletePropagationPolicy := metav1.DeletePropagationOrphan
 
 	podEventsQueue := cache.NewFIFO(func(obj interface{}) (string, error) {
@@ -121,6 +134,16 @@ func New(cfg Config, kubeClient k8sutil.KubernetesClient, pgSpec acidv1.Postgres
 		return fmt.Sprintf("%s-%s", e.PodName, e.ResourceVersion), nil
 	})
 
+	if cfg.SecretBackend == nil {
+		cfg.SecretBackend = newKubernetesSecretBackend(kubeClient)
+	}
+
+	userSyncStrategy := users.DefaultUserSyncStrategy{
+		RoleDeletionSuffix:        cfg.OpConfig.RoleDeletionSuffix,
+		PasswordEncryption:        cfg.OpConfig.PasswordEncryption,
+		MigratePasswordEncryption: cfg.OpConfig.EnablePasswordEncryptionMigration,
+	}
+
 	cluster := &Cluster{
 		Config:         cfg,
 		Postgresql:     pgSpec,
@@ -131,10 +154,14 @@ func New(cfg Config, kubeClient k8sutil.KubernetesClient, pgSpec acidv1.Postgres
 			Secrets:   make(map[types.UID]*v1.Secret),
 			Services:  make(map[PostgresRole]*v1.Service),
 			Endpoints: make(map[PostgresRole]*v1.Endpoints)},
-		userSyncStrategy: users.DefaultUserSyncStrategy{},
+		userSyncStrategy: userSyncStrategy,
 		deleteOptions:    metav1.DeleteOptions{PropagationPolicy: &deletePropagationPolicy},
 		podEventsQueue:   podEventsQueue,
 		KubeClient:       kubeClient,
+		eventRecorder:    eventRecorder,
+	}
+	if pgSpec.Status.MaintenancePending() {
+		cluster.pendingMaintenance = append([]string{}, pgSpec.Status.PendingMaintenance...)
 	}
 	cluster.logger = logger.WithField("pkg", "cluster").WithField("cluster-name", cluster.clusterName())
 	cluster.teamsAPIClient = teams.NewTeamsAPI(cfg.OpConfig.TeamsAPIUrl, logger)
@@ -168,23 +195,21 @@ func (c *Cluster) setProcessName(procName string, args ...interface{}) {
 
 // SetStatus of Postgres cluster
 // TODO: eventually switch to updateStatus() for kubernetes 1.11 and above
-func (c *Cluster) setStatus(status string) {
-	var pgStatus acidv1.PostgresStatus
-	pgStatus.PostgresClusterStatus = status
-
-	patch, err := json.Marshal(struct {
-		PgStatus interface{} `json:"status"`
-	}{&pgStatus})
-
-	if err != nil {
-		c.logger.Errorf("could not marshal status: %v", err)
+func (c *Cluster) setStatus(status string, lastErr error) {
+	newStatus := c.Status.DeepCopy()
+	newStatus.PostgresClusterStatus = status
+	newStatus.PendingMaintenance = c.pendingMaintenance
+	newStatus.LastSyncError = ""
+	if lastErr != nil {
+		newStatus.LastSyncError = lastErr.Error()
+	}
+	// the spec counts as observed once the operator has finished acting on it
+	if status != acidv1.ClusterStatusCreating && status != acidv1.ClusterStatusUpdating {
+		newStatus.ObservedGeneration = c.Generation
 	}
+	c.observeStatus(newStatus)
 
-	// we cannot do a full scale update here without fetching the previous manifest (as the resourceVersion may differ),
-	// however, we could do patch without it. In the future, once /status subresource is there (starting Kubernetes 1.11)
-	// we should take advantage of it.
-	newspec, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(c.clusterNamespace()).Patch(
-		context.TODO(), c.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	newspec, err := c.patchStatus(statusPatch(newStatus))
 	if err != nil {
 		c.logger.Errorf("could not update status: %v", err)
 		// return as newspec is empty, see PR654
@@ -194,6 +219,43 @@ func (c *Cluster) setStatus(status string) {
 	c.setSpec(newspec)
 }
 
+// statusPatch converts the status into fields of a merge patch. Empty fields are sent as null,
+// which removes them from the status.
+func statusPatch(status *acidv1.PostgresStatus) map[string]interface{} {
+	patch := map[string]interface{}{
+		"pendingMaintenance": nil,
+		"masterPod":          nil,
+		"instances":          nil,
+		"readyInstances":     nil,
+		"lastSyncError":      nil,
+		"conditions":         nil,
+	}
+	fields := make(map[string]interface{})
+	if data, err := json.Marshal(status); err == nil {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return patch
+		}
+	}
+	for field, value := range fields {
+		patch[field] = value
+	}
+	return patch
+}
+
+// patchStatus changes the given fields of the cluster status and returns the resulting manifest.
+func (c *Cluster) patchStatus(status map[string]interface{}) (*acidv1.Postgresql, error) {
+	patch, err := json.Marshal(map[string]map[string]interface{}{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal status: %v", err)
+	}
+
+	// we cannot do a full scale update here without fetching the previous manifest (as the resourceVersion may differ),
+	// however, we could do patch without it. In the future, once /status subresource is there (starting Kubernetes 1.11)
+	// we should take advantage of it.
+	return c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(c.clusterNamespace()).Patch(
+		context.TODO(), c.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+}
+
 func (c *Cluster) isNewCluster() bool {
 	return c.Status.Creating()
 }
@@ -206,6 +268,7 @@ func (c *Cluster) initUsers() error {
 	// running a sync).
 	c.systemUsers = map[string]spec.PgUser{}
 	c.pgUsers = map[string]spec.PgUser{}
+	c.teamMembersUnknown = false
 
 	c.initSystemUsers()
 
@@ -217,6 +280,10 @@ func (c *Cluster) initUsers() error {
 		return fmt.Errorf("could not init robot users: %v", err)
 	}
 
+	if err := c.initPreparedDatabaseRoles(); err != nil {
+		return fmt.Errorf("could not init default roles of prepared databases: %v", err)
+	}
+
 	if err := c.initHumanUsers(); err != nil {
 		return fmt.Errorf("could not init human users: %v", err)
 	}
@@ -225,12 +292,10 @@ func (c *Cluster) initUsers() error {
 }
 
 // Create creates the new kubernetes objects associated with the cluster.
-func (c *Cluster) Create() error {
+func (c *Cluster) Create() (err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	var (
-		err error
-
 		service *v1.Service
 		ep      *v1.Endpoints
 		ss      *appsv1.StatefulSet
@@ -238,13 +303,16 @@ func (c *Cluster) Create() error {
 
 	defer func() {
 		if err == nil {
-			c.setStatus(acidv1.ClusterStatusRunning) //TODO: are you sure it's running?
+			c.setStatus(acidv1.ClusterStatusRunning, nil) //TODO: are you sure it's running?
+			c.recordEvent(v1.EventTypeNormal, "Create", "Cluster has been created")
 		} else {
-			c.setStatus(acidv1.ClusterStatusAddFailed)
+			c.setStatus(acidv1.ClusterStatusAddFailed, err)
+			c.recordEvent(v1.EventTypeWarning, "Create", "Could not create cluster: %v", err)
 		}
 	}()
 
-	c.setStatus(acidv1.ClusterStatusCreating)
+	c.setStatus(acidv1.ClusterStatusCreating, nil)
+	c.recordEvent(v1.EventTypeNormal, "Create", "Started creation of new cluster resources")
 
 	if err = c.enforceMinResourceLimits(&c.Spec); err != nil {
 		return fmt.Errorf("could not enforce minimum resource limits: %v", err)
@@ -302,6 +370,7 @@ func (c *Cluster) Create() error {
 		return fmt.Errorf("could not create statefulset: %v", err)
 	}
 	c.logger.Infof("statefulset %q has been successfully created", util.NameFromMeta(ss.ObjectMeta))
+	c.recordEvent(v1.EventTypeNormal, "Create", "Statefulset %q has been created, waiting for the pods", util.NameFromMeta(ss.ObjectMeta))
 
 	c.logger.Info("waiting for the cluster being ready")
 
@@ -324,6 +393,18 @@ func (c *Cluster) Create() error {
 			return fmt.Errorf("could not sync databases: %v", err)
 		}
 		c.logger.Infof("databases have been successfully created")
+
+		if err = c.syncExtensions(); err != nil {
+			return fmt.Errorf("could not sync extensions: %v", err)
+		}
+
+		if err = c.syncBootstrapSQL(); err != nil {
+			return fmt.Errorf("could not sync bootstrap SQL: %v", err)
+		}
+
+		if err = c.syncLogicalReplication(); err != nil {
+			return fmt.Errorf("could not sync logical replication: %v", err)
+		}
 	}
 
 	if c.Postgresql.Spec.EnableLogicalBackup {
@@ -579,27 +660,29 @@ func (c *Cluster) enforceMinResourceLimits(spec *acidv1.PostgresSpec) error {
 // logical backup cron jobs are an exception: a user-initiated Update can enable a logical backup job
 // for a cluster that had no such job before. In this case a missing job is not an error.
 func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
-	updateFailed := false
+	var updateErr error
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.setStatus(acidv1.ClusterStatusUpdating)
+	c.setStatus(acidv1.ClusterStatusUpdating, nil)
 	c.setSpec(newSpec)
+	c.recordEvent(v1.EventTypeNormal, "Update", "Started update of the cluster")
 
 	defer func() {
-		if updateFailed {
-			c.setStatus(acidv1.ClusterStatusUpdateFailed)
+		if updateErr != nil {
+			c.setStatus(acidv1.ClusterStatusUpdateFailed, updateErr)
+			c.recordEvent(v1.EventTypeWarning, "Update", "Could not update cluster: %v", updateErr)
 		} else {
-			c.setStatus(acidv1.ClusterStatusRunning)
+			c.setStatus(acidv1.ClusterStatusRunning, nil)
+			c.recordEvent(v1.EventTypeNormal, "Update", "Cluster has been updated")
 		}
 	}()
 
 	if oldSpec.Spec.PostgresqlParam.PgVersion != newSpec.Spec.PostgresqlParam.PgVersion { // PG versions comparison
-		c.logger.Warningf("postgresql version change(%q -> %q) has no effect",
+		// the statefulset sync below triggers the major version upgrade
+		c.logger.Infof("postgresql version change(%q -> %q) requested",
 			oldSpec.Spec.PostgresqlParam.PgVersion, newSpec.Spec.PostgresqlParam.PgVersion)
-		//we need that hack to generate statefulset with the old version
-		newSpec.Spec.PostgresqlParam.PgVersion = oldSpec.Spec.PostgresqlParam.PgVersion
 	}
 
 	// Service
@@ -607,8 +690,8 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 		!reflect.DeepEqual(c.generateService(Replica, &oldSpec.Spec), c.generateService(Replica, &newSpec.Spec)) {
 		c.logger.Debugf("syncing services")
 		if err := c.syncServices(); err != nil {
-			c.logger.Errorf("could not sync services: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync services: %v", err)
+			c.logger.Error(updateErr)
 		}
 	}
 
@@ -619,16 +702,16 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 	if !sameUsers || needConnPool {
 		c.logger.Debugf("syncing secrets")
 		if err := c.initUsers(); err != nil {
-			c.logger.Errorf("could not init users: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not init users: %v", err)
+			c.logger.Error(updateErr)
 		}
 
 		c.logger.Debugf("syncing secrets")
 
 		//TODO: mind the secrets of the deleted/new users
 		if err := c.syncSecrets(); err != nil {
-			c.logger.Errorf("could not sync secrets: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync secrets: %v", err)
+			c.logger.Error(updateErr)
 		}
 	}
 
@@ -638,23 +721,23 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 		c.logVolumeChanges(oldSpec.Spec.Volume, newSpec.Spec.Volume)
 
 		if err := c.syncVolumes(); err != nil {
-			c.logger.Errorf("could not sync persistent volumes: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync persistent volumes: %v", err)
+			c.logger.Error(updateErr)
 		}
 	}
 
 	// Statefulset
 	func() {
 		if err := c.enforceMinResourceLimits(&c.Spec); err != nil {
-			c.logger.Errorf("could not sync resources: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync resources: %v", err)
+			c.logger.Error(updateErr)
 			return
 		}
 
 		oldSs, err := c.generateStatefulSet(&oldSpec.Spec)
 		if err != nil {
-			c.logger.Errorf("could not generate old statefulset spec: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not generate old statefulset spec: %v", err)
+			c.logger.Error(updateErr)
 			return
 		}
 
@@ -663,8 +746,8 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 
 		newSs, err := c.generateStatefulSet(&newSpec.Spec)
 		if err != nil {
-			c.logger.Errorf("could not generate new statefulset spec: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not generate new statefulset spec: %v", err)
+			c.logger.Error(updateErr)
 			return
 		}
 
@@ -672,8 +755,8 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 			c.logger.Debugf("syncing statefulsets")
 			// TODO: avoid generating the StatefulSet object twice by passing it to syncStatefulSet
 			if err := c.syncStatefulSet(); err != nil {
-				c.logger.Errorf("could not sync statefulsets: %v", err)
-				updateFailed = true
+				updateErr = fmt.Errorf("could not sync statefulsets: %v", err)
+				c.logger.Error(updateErr)
 			}
 		}
 	}()
@@ -682,8 +765,8 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 	if oldSpec.Spec.NumberOfInstances != newSpec.Spec.NumberOfInstances {
 		c.logger.Debug("syncing pod disruption budgets")
 		if err := c.syncPodDisruptionBudget(true); err != nil {
-			c.logger.Errorf("could not sync pod disruption budget: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync pod disruption budget: %v", err)
+			c.logger.Error(updateErr)
 		}
 	}
 
@@ -694,8 +777,8 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 		if !oldSpec.Spec.EnableLogicalBackup && newSpec.Spec.EnableLogicalBackup {
 			c.logger.Debugf("creating backup cron job")
 			if err := c.createLogicalBackupJob(); err != nil {
-				c.logger.Errorf("could not create a k8s cron job for logical backups: %v", err)
-				updateFailed = true
+				updateErr = fmt.Errorf("could not create a k8s cron job for logical backups: %v", err)
+				c.logger.Error(updateErr)
 				return
 			}
 		}
@@ -704,21 +787,23 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 		if oldSpec.Spec.EnableLogicalBackup && !newSpec.Spec.EnableLogicalBackup {
 			c.logger.Debugf("deleting backup cron job")
 			if err := c.deleteLogicalBackupJob(); err != nil {
-				c.logger.Errorf("could not delete a k8s cron job for logical backups: %v", err)
-				updateFailed = true
+				updateErr = fmt.Errorf("could not delete a k8s cron job for logical backups: %v", err)
+				c.logger.Error(updateErr)
 				return
 			}
 
 		}
 
-		// apply schedule changes
-		// this is the only parameter of logical backups a user can overwrite in the cluster manifest
+		// apply changes of the schedule, the storage and the retention, the parameters of the job a user can
+		// overwrite in the cluster manifest
 		if (oldSpec.Spec.EnableLogicalBackup && newSpec.Spec.EnableLogicalBackup) &&
-			(newSpec.Spec.LogicalBackupSchedule != oldSpec.Spec.LogicalBackupSchedule) {
-			c.logger.Debugf("updating schedule of the backup cron job")
+			(newSpec.Spec.LogicalBackupSchedule != oldSpec.Spec.LogicalBackupSchedule ||
+				!reflect.DeepEqual(newSpec.Spec.LogicalBackupTarget, oldSpec.Spec.LogicalBackupTarget) ||
+				!reflect.DeepEqual(newSpec.Spec.LogicalBackupRetention, oldSpec.Spec.LogicalBackupRetention)) {
+			c.logger.Debugf("updating the backup cron job")
 			if err := c.syncLogicalBackupJob(); err != nil {
-				c.logger.Errorf("could not sync logical backup jobs: %v", err)
-				updateFailed = true
+				updateErr = fmt.Errorf("could not sync logical backup jobs: %v", err)
+				c.logger.Error(updateErr)
 			}
 		}
 
@@ -728,21 +813,47 @@ func (c *Cluster) Update(oldSpec, newSpec *acidv1.Postgresql) error {
 	if !(c.databaseAccessDisabled() || c.getNumberOfInstances(&c.Spec) <= 0 || c.Spec.StandbyCluster != nil) {
 		c.logger.Debugf("syncing roles")
 		if err := c.syncRoles(); err != nil {
-			c.logger.Errorf("could not sync roles: %v", err)
-			updateFailed = true
+			updateErr = fmt.Errorf("could not sync roles: %v", err)
+			c.logger.Error(updateErr)
 		}
-		if !reflect.DeepEqual(oldSpec.Spec.Databases, newSpec.Spec.Databases) {
+		if !reflect.DeepEqual(oldSpec.Spec.Databases, newSpec.Spec.Databases) ||
+			!reflect.DeepEqual(oldSpec.Spec.PreparedDatabases, newSpec.Spec.PreparedDatabases) {
 			c.logger.Infof("syncing databases")
 			if err := c.syncDatabases(); err != nil {
-				c.logger.Errorf("could not sync databases: %v", err)
-				updateFailed = true
+				updateErr = fmt.Errorf("could not sync databases: %v", err)
+				c.logger.Error(updateErr)
+			}
+		}
+		if !reflect.DeepEqual(oldSpec.Spec.Databases, newSpec.Spec.Databases) ||
+			!reflect.DeepEqual(oldSpec.Spec.Extensions, newSpec.Spec.Extensions) {
+			c.logger.Infof("syncing extensions")
+			if err := c.syncExtensions(); err != nil {
+				updateErr = fmt.Errorf("could not sync extensions: %v", err)
+				c.logger.Error(updateErr)
+			}
+		}
+		if !reflect.DeepEqual(oldSpec.Spec.Databases, newSpec.Spec.Databases) ||
+			!reflect.DeepEqual(oldSpec.Spec.BootstrapSQL, newSpec.Spec.BootstrapSQL) {
+			c.logger.Infof("syncing bootstrap SQL")
+			if err := c.syncBootstrapSQL(); err != nil {
+				updateErr = fmt.Errorf("could not sync bootstrap SQL: %v", err)
+				c.logger.Error(updateErr)
+			}
+		}
+		if !reflect.DeepEqual(oldSpec.Spec.Publications, newSpec.Spec.Publications) ||
+			!reflect.DeepEqual(oldSpec.Spec.Subscriptions, newSpec.Spec.Subscriptions) {
+			c.logger.Infof("syncing logical replication")
+			if err := c.syncLogicalReplication(); err != nil {
+				updateErr = fmt.Errorf("could not sync logical replication: %v", err)
+				c.logger.Error(updateErr)
 			}
 		}
 	}
 
 	// sync connection pool
 	if err := c.syncConnectionPool(oldSpec, newSpec, c.installLookupFunction); err != nil {
-		return fmt.Errorf("could not sync connection pool: %v", err)
+		updateErr = fmt.Errorf("could not sync connection pool: %v", err)
+		return updateErr
 	}
 
 	return nil
@@ -776,6 +887,7 @@ func (c *Cluster) Delete() {
 			c.logger.Warningf("could not delete secret: %v", err)
 		}
 	}
+	c.deletePodSecrets()
 
 	if err := c.deletePodDisruptionBudget(); err != nil {
 		c.logger.Warningf("could not delete pod disruption budget: %v", err)
@@ -808,7 +920,9 @@ func (c *Cluster) Delete() {
 func (c *Cluster) NeedsRepair() (bool, acidv1.PostgresStatus) {
 	c.specMu.RLock()
 	defer c.specMu.RUnlock()
-	return !c.Status.Success(), c.Status
+	// postponed actions are picked up by the repair scan once a maintenance window opens
+	needsRepair := !c.Status.Success() || (c.Status.MaintenancePending() && c.isInMaintenanceWindow())
+	return needsRepair, c.Status
 
 }
 
@@ -916,12 +1030,19 @@ func (c *Cluster) initRobotUsers() error {
 		if c.OpConfig.EnableAdminRoleForUsers {
 			adminRole = c.OpConfig.TeamAdminRole
 		}
+		namespace := ""
+		if c.OpConfig.EnableCrossNamespaceSecrets {
+			if namespace = secretNamespaceOfUser(&c.Spec, username); namespace == c.Namespace {
+				namespace = ""
+			}
+		}
 		newRole := spec.PgUser{
 			Origin:    spec.RoleOriginManifest,
 			Name:      username,
 			Password:  util.RandomPassword(constants.PasswordLength),
 			Flags:     flags,
 			AdminRole: adminRole,
+			Namespace: namespace,
 		}
 		if currentRole, present := c.pgUsers[username]; present {
 			c.pgUsers[username] = c.resolveNameConflict(&currentRole, &newRole)
@@ -1071,16 +1192,20 @@ func (c *Cluster) GetStatus() *ClusterStatus {
 		StatefulSet:         c.GetStatefulSet(),
 		PodDisruptionBudget: c.GetPodDisruptionBudget(),
 		CurrentProcess:      c.GetCurrentProcess(),
+		Paused:              c.IsPaused(),
 
 		Error: fmt.Errorf("error: %s", c.Error),
 	}
 }
 
-// Switchover does a switchover (via Patroni) to a candidate pod
+// Switchover does a switchover (via Patroni) to a candidate pod. It does not check the maintenance windows
+// itself: the rolling update and the major version upgrade only switch over once they have been allowed,
+// while the migration of master pods away from decommissioned nodes is exempt.
 func (c *Cluster) Switchover(curMaster *v1.Pod, candidate spec.NamespacedName) error {
 
 	var err error
 	c.logger.Debugf("switching over from %q to %q", curMaster.Name, candidate)
+	c.recordEvent(v1.EventTypeNormal, "Switchover", "Switching over from %q to %q", curMaster.Name, candidate)
 
 	var wg sync.WaitGroup
 
@@ -1107,11 +1232,13 @@ func (c *Cluster) Switchover(curMaster *v1.Pod, candidate spec.NamespacedName) e
 
 	if err = c.patroni.Switchover(curMaster, candidate.Name); err == nil {
 		c.logger.Debugf("successfully switched over from %q to %q", curMaster.Name, candidate)
+		c.recordEvent(v1.EventTypeNormal, "Switchover", "Successfully switched over from %q to %q", curMaster.Name, candidate)
 		if err = <-podLabelErr; err != nil {
 			err = fmt.Errorf("could not get master pod label: %v", err)
 		}
 	} else {
 		err = fmt.Errorf("could not switch over: %v", err)
+		c.recordEvent(v1.EventTypeWarning, "Switchover", "Switchover from %q to %q failed: %v", curMaster.Name, candidate, err)
 	}
 
 	// signal the role label waiting goroutine to close the shop and go home
@@ -1121,6 +1248,7 @@ func (c *Cluster) Switchover(curMaster *v1.Pod, candidate spec.NamespacedName) e
 	wg.Wait()
 	// close the label waiting channel no sooner than the waiting goroutine terminates.
 	close(podLabelErr)
+	metrics.CountSwitchover(c.Namespace, c.Name, err)
 
 	return err
 
@@ -27,9 +27,20 @@ const (
 	 WHERE a.rolname = ANY($1)
 	 ORDER BY 1;`
 
+	getRoleMembersSQL = `SELECT m.rolname
+	 FROM pg_catalog.pg_auth_members a
+	 JOIN pg_catalog.pg_roles m ON (a.member = m.oid)
+	 JOIN pg_catalog.pg_roles r ON (a.roleid = r.oid)
+	 WHERE r.rolname = $1
+	 ORDER BY 1;`
+
 	getDatabasesSQL       = `SELECT datname, pg_get_userbyid(datdba) AS owner FROM pg_database;`
+	getServerVersionSQL   = `SELECT current_setting('server_version_num')::integer;`
 	createDatabaseSQL     = `CREATE DATABASE "%s" OWNER "%s";`
 	alterDatabaseOwnerSQL = `ALTER DATABASE "%s" OWNER TO "%s";`
+	dropOwnedSQL          = `REASSIGN OWNED BY "%s" TO "%s"; DROP OWNED BY "%s";`
+	dropRoleSQL           = `DROP ROLE IF EXISTS "%s";`
+	resetPasswordSQL      = `ALTER ROLE "%s" PASSWORD NULL;`
 	connectionPoolLookup  = `
 		CREATE SCHEMA IF NOT EXISTS {{.pool_schema}};
 
@@ -52,8 +63,11 @@ const (
 )
 
 func (c *Cluster) pgConnectionString(dbname string) string {
-	password := c.systemUsers[constants.SuperuserKeyName].Password
+	return c.pgUserConnectionString(dbname, c.systemUsers[constants.SuperuserKeyName])
+}
 
+// pgUserConnectionString returns the connection string to log in as the user instead of the superuser
+func (c *Cluster) pgUserConnectionString(dbname string, user spec.PgUser) string {
 	if dbname == "" {
 		dbname = "postgres"
 	}
@@ -61,8 +75,8 @@ func (c *Cluster) pgConnectionString(dbname string) string {
 	return fmt.Sprintf("host='%s' dbname='%s' sslmode=require user='%s' password='%s' connect_timeout='%d'",
 		fmt.Sprintf("%s.%s.svc.%s", c.Name, c.Namespace, c.OpConfig.ClusterDomain),
 		dbname,
-		c.systemUsers[constants.SuperuserKeyName].Name,
-		strings.Replace(password, "$", "\\$", -1),
+		user.Name,
+		strings.Replace(user.Password, "$", "\\$", -1),
 		constants.PostgresConnectTimeout/time.Second)
 }
 
@@ -84,8 +98,19 @@ func (c *Cluster) initDbConnWithName(dbname string) error {
 		return nil
 	}
 
+	conn, err := c.openDbConn(c.pgConnectionString(dbname))
+	if err != nil {
+		return err
+	}
+	c.pgDb = conn
+
+	return nil
+}
+
+// openDbConn opens a single database connection with the connection string, retrying until the database is reachable.
+// The caller is responsible for closing the connection.
+func (c *Cluster) openDbConn(connstring string) (*sql.DB, error) {
 	var conn *sql.DB
-	connstring := c.pgConnectionString(dbname)
 
 	finalerr := retryutil.Retry(constants.PostgresConnectTimeout, constants.PostgresConnectRetryTimeout,
 		func() (bool, error) {
@@ -113,15 +138,13 @@ func (c *Cluster) initDbConnWithName(dbname string) error {
 		})
 
 	if finalerr != nil {
-		return fmt.Errorf("could not init db connection: %v", finalerr)
+		return nil, fmt.Errorf("could not init db connection: %v", finalerr)
 	}
 	// Limit ourselves to a single connection and allow no idle connections.
 	conn.SetMaxOpenConns(1)
 	conn.SetMaxIdleConns(-1)
 
-	c.pgDb = conn
-
-	return nil
+	return conn, nil
 }
 
 func (c *Cluster) connectionIsClosed() bool {
@@ -168,7 +191,7 @@ func (c *Cluster) readPgUsersFromDatabase(userNames []string) (users spec.PgUser
 			return nil, fmt.Errorf("error when processing user rows: %v", err)
 		}
 		flags := makeUserFlags(rolsuper, rolinherit, rolcreaterole, rolcreatedb, rolcanlogin)
-		// XXX: the code assumes the password we get from pg_authid is always MD5
+		// the password from pg_authid is either an MD5 hash or a SCRAM-SHA-256 verifier
 		parameters := make(map[string]string)
 		for _, option := range roloptions {
 			fields := strings.Split(option, "=")
@@ -185,6 +208,16 @@ func (c *Cluster) readPgUsersFromDatabase(userNames []string) (users spec.PgUser
 	return users, nil
 }
 
+// getServerVersion returns the version of the running server in the server_version_num form, e.g. 120004
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getServerVersion() (int, error) {
+	var version int
+	if err := c.pgDb.QueryRow(getServerVersionSQL).Scan(&version); err != nil {
+		return 0, fmt.Errorf("could not get server version: %v", err)
+	}
+	return version, nil
+}
+
 // getDatabases returns the map of current databases with owners
 // The caller is responsible for opening and closing the database connection
 func (c *Cluster) getDatabases() (dbs map[string]string, err error) {
@@ -220,6 +253,93 @@ func (c *Cluster) getDatabases() (dbs map[string]string, err error) {
 	return dbs, err
 }
 
+// getRoleMembers returns the names of the roles that are direct members of the given role.
+func (c *Cluster) getRoleMembers(roleName string) (members []string, err error) {
+	var rows *sql.Rows
+
+	if rows, err = c.pgDb.Query(getRoleMembersSQL, roleName); err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+
+	defer func() {
+		if err2 := rows.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("error when closing query cursor: %v, previous error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("error when closing query cursor: %v", err2)
+			}
+		}
+	}()
+
+	for rows.Next() {
+		var rolname string
+
+		if err = rows.Scan(&rolname); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		members = append(members, rolname)
+	}
+
+	return members, err
+}
+
+// dropRole reassigns the objects of the role to the new owner and revokes its privileges in every
+// database, since both only affect the database of the connection, and drops the role afterwards.
+// The connection to the default database is reopened for the caller.
+func (c *Cluster) dropRole(roleName, newOwner string) (err error) {
+	currentDatabases, err := c.getDatabases()
+	if err != nil {
+		return fmt.Errorf("could not get databases: %v", err)
+	}
+
+	if err = c.closeDbConn(); err != nil {
+		return fmt.Errorf("could not close database connection: %v", err)
+	}
+	defer func() {
+		if !c.connectionIsClosed() {
+			return
+		}
+		if err2 := c.initDbConn(); err2 != nil && err == nil {
+			err = fmt.Errorf("could not init database connection: %v", err2)
+		}
+	}()
+
+	for dbname := range currentDatabases {
+		if dbname == "template0" || dbname == "template1" {
+			continue
+		}
+
+		if err = c.initDbConnWithName(dbname); err != nil {
+			return fmt.Errorf("could not init database connection to %q: %v", dbname, err)
+		}
+		c.logger.Debugf("reassigning objects of the role %q in the database %q", roleName, dbname)
+		_, err = c.pgDb.Exec(fmt.Sprintf(dropOwnedSQL, roleName, newOwner, roleName))
+		if err2 := c.closeDbConn(); err2 != nil {
+			c.logger.Errorf("could not close database connection: %v", err2)
+		}
+		if err != nil {
+			return fmt.Errorf("could not reassign objects in the database %q: %v", dbname, err)
+		}
+	}
+
+	if err = c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	if _, err = c.pgDb.Exec(fmt.Sprintf(dropRoleSQL, roleName)); err != nil {
+		return fmt.Errorf("could not drop role: %v", err)
+	}
+
+	return nil
+}
+
+// resetRolePassword removes the password of the role, so that nobody can log in with it anymore
+func (c *Cluster) resetRolePassword(roleName string) error {
+	if _, err := c.pgDb.Exec(fmt.Sprintf(resetPasswordSQL, roleName)); err != nil {
+		return fmt.Errorf("could not reset password of the role %q: %v", roleName, err)
+	}
+	return nil
+}
+
 // executeCreateDatabase creates new database with the given owner.
 // The caller is responsible for openinging and closing the database connection.
 func (c *Cluster) executeCreateDatabase(datname, owner string) error {
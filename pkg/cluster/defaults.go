@@ -0,0 +1,118 @@
+package cluster
+
+import (
+	"fmt"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+// fields of the Postgres manifest the defaulting webhook can fill in, as named in the operator configuration
+const (
+	DefaultedFieldResources      = "resources"
+	DefaultedFieldDockerImage    = "docker_image"
+	DefaultedFieldConnectionPool = "connection_pool"
+)
+
+// ValidateDefaultedFields checks that all fields configured for the defaulting webhook are known.
+func ValidateDefaultedFields(fields []string) error {
+	for _, field := range fields {
+		switch field {
+		case DefaultedFieldResources, DefaultedFieldDockerImage, DefaultedFieldConnectionPool:
+		default:
+			return fmt.Errorf("unknown defaulted field %q, expected one of %q, %q or %q", field,
+				DefaultedFieldResources, DefaultedFieldDockerImage, DefaultedFieldConnectionPool)
+		}
+	}
+	return nil
+}
+
+// DefaultManifest fills the given fields of the Postgres manifest with the values the operator would
+// otherwise apply implicitly when generating the K8s objects. Values set by the user are kept.
+// It returns the JSON names of the top-level spec fields that have been changed.
+func DefaultManifest(opConfig *config.Config, spec *acidv1.PostgresSpec, fields []string) []string {
+	c := &Cluster{Config: Config{OpConfig: *opConfig}}
+	changed := make([]string, 0)
+
+	for _, field := range fields {
+		switch field {
+		case DefaultedFieldResources:
+			if fillDefaultResources(&spec.Resources, c.makeDefaultResources()) {
+				changed = append(changed, "resources")
+			}
+		case DefaultedFieldDockerImage:
+			if spec.DockerImage == "" && opConfig.DockerImage != "" {
+				spec.DockerImage = opConfig.DockerImage
+				changed = append(changed, "dockerImage")
+			}
+		case DefaultedFieldConnectionPool:
+			if c.needConnectionPoolWorker(spec) && c.defaultConnectionPool(spec) {
+				changed = append(changed, "connectionPool")
+			}
+		}
+	}
+
+	return changed
+}
+
+// defaultConnectionPool mirrors the fallbacks of the connection pool deployment generation.
+func (c *Cluster) defaultConnectionPool(spec *acidv1.PostgresSpec) bool {
+	if spec.ConnectionPool == nil {
+		spec.ConnectionPool = &acidv1.ConnectionPool{}
+	}
+	pool := spec.ConnectionPool
+	poolConfig := c.OpConfig.ConnectionPool
+	changed := false
+
+	setString := func(value *string, defaultValue string) {
+		if effective := util.Coalesce(*value, defaultValue); effective != *value {
+			*value = effective
+			changed = true
+		}
+	}
+	setString(&pool.Schema, poolConfig.Schema)
+	setString(&pool.User, poolConfig.User)
+	setString(&pool.Mode, poolConfig.Mode)
+	setString(&pool.DockerImage, poolConfig.Image)
+
+	if pool.NumberOfInstances == nil {
+		pool.NumberOfInstances = util.CoalesceInt32(poolConfig.NumberOfInstances, k8sutil.Int32ToPointer(1))
+		if *pool.NumberOfInstances < constants.ConnPoolMinInstances {
+			pool.NumberOfInstances = k8sutil.Int32ToPointer(constants.ConnPoolMinInstances)
+		}
+		changed = true
+	}
+	if pool.MaxDBConnections == nil {
+		pool.MaxDBConnections = util.CoalesceInt32(poolConfig.MaxDBConnections,
+			k8sutil.Int32ToPointer(constants.ConnPoolMaxDBConnections))
+		changed = true
+	}
+
+	if fillDefaultResources(&pool.Resources, c.makeDefaultConnPoolResources()) {
+		changed = true
+	}
+
+	return changed
+}
+
+func fillDefaultResources(resources *acidv1.Resources, defaults acidv1.Resources) bool {
+	changed := false
+	for _, quantity := range []struct {
+		value        *string
+		defaultValue string
+	}{
+		{&resources.ResourceRequests.CPU, defaults.ResourceRequests.CPU},
+		{&resources.ResourceRequests.Memory, defaults.ResourceRequests.Memory},
+		{&resources.ResourceLimits.CPU, defaults.ResourceLimits.CPU},
+		{&resources.ResourceLimits.Memory, defaults.ResourceLimits.Memory},
+	} {
+		if *quantity.value == "" && quantity.defaultValue != "" {
+			*quantity.value = quantity.defaultValue
+			changed = true
+		}
+	}
+	return changed
+}
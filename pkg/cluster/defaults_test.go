@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func TestDefaultManifest(t *testing.T) {
+	testName := "TestDefaultManifest"
+	opConfig := &config.Config{
+		DockerImage: "registry.opensource.zalan.do/acid/spilo-12:1.6-p2",
+		Resources: config.Resources{
+			DefaultCPURequest:    "100m",
+			DefaultMemoryRequest: "100Mi",
+			DefaultCPULimit:      "1",
+			DefaultMemoryLimit:   "500Mi",
+		},
+		ConnectionPool: config.ConnectionPool{
+			NumberOfInstances:            k8sutil.Int32ToPointer(1),
+			Schema:                       "pooler",
+			User:                         "pooler",
+			Image:                        "registry.opensource.zalan.do/acid/pgbouncer",
+			Mode:                         "transaction",
+			ConnPoolDefaultCPURequest:    "500m",
+			ConnPoolDefaultMemoryRequest: "100Mi",
+			ConnPoolDefaultCPULimit:      "1",
+			ConnPoolDefaultMemoryLimit:   "100Mi",
+		},
+	}
+	allFields := []string{DefaultedFieldResources, DefaultedFieldDockerImage, DefaultedFieldConnectionPool}
+
+	tests := []struct {
+		subTest  string
+		spec     acidv1.PostgresSpec
+		fields   []string
+		changed  []string
+		expected acidv1.PostgresSpec
+	}{
+		{
+			subTest: "fill all fields",
+			spec: acidv1.PostgresSpec{
+				Resources: acidv1.Resources{
+					ResourceLimits: acidv1.ResourceDescription{Memory: "1Gi"},
+				},
+				EnableConnectionPool: boolToPointer(true),
+			},
+			fields:  allFields,
+			changed: []string{"resources", "dockerImage", "connectionPool"},
+			expected: acidv1.PostgresSpec{
+				Resources: acidv1.Resources{
+					ResourceRequests: acidv1.ResourceDescription{CPU: "100m", Memory: "100Mi"},
+					ResourceLimits:   acidv1.ResourceDescription{CPU: "1", Memory: "1Gi"},
+				},
+				DockerImage:          "registry.opensource.zalan.do/acid/spilo-12:1.6-p2",
+				EnableConnectionPool: boolToPointer(true),
+				ConnectionPool: &acidv1.ConnectionPool{
+					NumberOfInstances: k8sutil.Int32ToPointer(2),
+					Schema:            "pooler",
+					User:              "pooler",
+					Mode:              "transaction",
+					DockerImage:       "registry.opensource.zalan.do/acid/pgbouncer",
+					MaxDBConnections:  k8sutil.Int32ToPointer(60),
+					Resources: acidv1.Resources{
+						ResourceRequests: acidv1.ResourceDescription{CPU: "500m", Memory: "100Mi"},
+						ResourceLimits:   acidv1.ResourceDescription{CPU: "1", Memory: "100Mi"},
+					},
+				},
+			},
+		},
+		{
+			subTest: "only the docker image",
+			spec:    acidv1.PostgresSpec{},
+			fields:  []string{DefaultedFieldDockerImage},
+			changed: []string{"dockerImage"},
+			expected: acidv1.PostgresSpec{
+				DockerImage: "registry.opensource.zalan.do/acid/spilo-12:1.6-p2",
+			},
+		},
+		{
+			subTest: "no connection pool requested",
+			spec:    acidv1.PostgresSpec{DockerImage: "custom-image"},
+			fields:  []string{DefaultedFieldDockerImage, DefaultedFieldConnectionPool},
+			changed: []string{},
+			expected: acidv1.PostgresSpec{
+				DockerImage: "custom-image",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		changed := DefaultManifest(opConfig, &tt.spec, tt.fields)
+		if !reflect.DeepEqual(changed, tt.changed) {
+			t.Errorf("%s %s: Expected changed fields %v, have %v instead", testName, tt.subTest, tt.changed, changed)
+		}
+		if !reflect.DeepEqual(tt.spec, tt.expected) {
+			t.Errorf("%s %s: Expected spec %#v, have %#v instead", testName, tt.subTest, tt.expected, tt.spec)
+		}
+	}
+}
+
+func TestValidateDefaultedFields(t *testing.T) {
+	if err := ValidateDefaultedFields([]string{DefaultedFieldResources, DefaultedFieldConnectionPool}); err != nil {
+		t.Errorf("Expected known fields to be accepted, got %v", err)
+	}
+	if err := ValidateDefaultedFields([]string{"volume"}); err == nil {
+		t.Errorf("Expected unknown field to be rejected")
+	}
+}
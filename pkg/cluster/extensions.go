@@ -0,0 +1,256 @@
+package cluster
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/lib/pq"
+	v1 "k8s.io/api/core/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+const (
+	getExtensionsSQL = `SELECT e.extname, e.extversion, n.nspname
+	 FROM pg_catalog.pg_extension e
+	 JOIN pg_catalog.pg_namespace n ON (n.oid = e.extnamespace);`
+	// identifiers and literals are quoted with pq.QuoteIdentifier and pq.QuoteLiteral
+	createExtensionSQL      = `CREATE EXTENSION IF NOT EXISTS %s`
+	createExtensionSchema   = ` SCHEMA %s`
+	createExtensionVersion  = ` VERSION %s`
+	updateExtensionSQL      = `ALTER EXTENSION %s UPDATE TO %s;`
+	alterExtensionSchemaSQL = `ALTER EXTENSION %s SET SCHEMA %s;`
+)
+
+var (
+	extensionNameRegexp    = regexp.MustCompile(`^[a-zA-Z0-9_][-a-zA-Z0-9_]*$`)
+	extensionVersionRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][-.a-zA-Z0-9_]*$`)
+)
+
+// installedExtension describes an extension in a database
+type installedExtension struct {
+	version string
+	schema  string
+}
+
+// validateExtension checks the name, version and schema of an extension, which end up in SQL statements
+func validateExtension(name string, extension acidv1.Extension) error {
+	if !extensionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid name of the extension %q", name)
+	}
+	if extension.Version != "" && !extensionVersionRegexp.MatchString(extension.Version) {
+		return fmt.Errorf("invalid version %q of the extension %q", extension.Version, name)
+	}
+	if extension.Schema != "" && !databaseNameRegexp.MatchString(extension.Schema) {
+		return fmt.Errorf("invalid schema %q of the extension %q", extension.Schema, name)
+	}
+	return nil
+}
+
+func isAllowedExtension(allowed []string, name string) bool {
+	for _, extension := range allowed {
+		if extension == name {
+			return true
+		}
+	}
+	return false
+}
+
+// syncExtensions creates and updates the extensions listed for the databases of the manifest. Extensions
+// that are not allowed by the operator configuration or fail to be created are reported in the cluster
+// status, without failing the sync. Extensions removed from the manifest are kept in the databases.
+func (c *Cluster) syncExtensions() error {
+	if len(c.Spec.Extensions) == 0 && len(c.Status.ExtensionErrors) == 0 {
+		return nil
+	}
+	c.setProcessName("syncing extensions")
+
+	if err := c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	currentDatabases, err := c.getDatabases()
+	if err2 := c.closeDbConn(); err2 != nil {
+		c.logger.Errorf("could not close database connection: %v", err2)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get current databases: %v", err)
+	}
+
+	datnames := make([]string, 0, len(c.Spec.Extensions))
+	for datname := range c.Spec.Extensions {
+		datnames = append(datnames, datname)
+	}
+	sort.Strings(datnames)
+
+	extensionErrors := make([]acidv1.ExtensionError, 0)
+	for _, datname := range datnames {
+		var errs map[string]error
+		if _, exists := currentDatabases[datname]; !exists {
+			errs = extensionsFailed(c.Spec.Extensions[datname], fmt.Errorf("database does not exist"))
+		} else if err = c.initDbConnWithName(datname); err != nil {
+			errs = extensionsFailed(c.Spec.Extensions[datname], err)
+		} else {
+			errs = c.syncDatabaseExtensions(datname, c.Spec.Extensions[datname])
+			if err2 := c.closeDbConn(); err2 != nil {
+				c.logger.Errorf("could not close database connection: %v", err2)
+			}
+		}
+
+		for name, err := range errs {
+			c.logger.Warningf("could not sync extension %q in database %q: %v", name, datname, err)
+			extensionErrors = append(extensionErrors,
+				acidv1.ExtensionError{Database: datname, Extension: name, Message: err.Error()})
+		}
+	}
+
+	sort.Slice(extensionErrors, func(i, j int) bool {
+		if extensionErrors[i].Database != extensionErrors[j].Database {
+			return extensionErrors[i].Database < extensionErrors[j].Database
+		}
+		return extensionErrors[i].Extension < extensionErrors[j].Extension
+	})
+	c.updateExtensionErrorsStatus(extensionErrors)
+	return nil
+}
+
+func extensionsFailed(extensions map[string]acidv1.Extension, err error) map[string]error {
+	errs := make(map[string]error, len(extensions))
+	for name := range extensions {
+		errs[name] = err
+	}
+	return errs
+}
+
+// extensionStatements returns the statements that create the extension, or bring an installed extension to
+// the schema and version of the manifest. Extensions without a version are only created, not updated.
+func extensionStatements(name string, extension acidv1.Extension, current installedExtension, installed bool) []string {
+	statements := make([]string, 0)
+	if !installed {
+		statement := fmt.Sprintf(createExtensionSQL, pq.QuoteIdentifier(name))
+		if extension.Schema != "" {
+			statement += fmt.Sprintf(createExtensionSchema, pq.QuoteIdentifier(extension.Schema))
+		}
+		if extension.Version != "" {
+			statement += fmt.Sprintf(createExtensionVersion, pq.QuoteLiteral(extension.Version))
+		}
+		return append(statements, statement+";")
+	}
+
+	if extension.Schema != "" && extension.Schema != current.schema {
+		statements = append(statements,
+			fmt.Sprintf(alterExtensionSchemaSQL, pq.QuoteIdentifier(name), pq.QuoteIdentifier(extension.Schema)))
+	}
+	if extension.Version != "" && extension.Version != current.version {
+		statements = append(statements,
+			fmt.Sprintf(updateExtensionSQL, pq.QuoteIdentifier(name), pq.QuoteLiteral(extension.Version)))
+	}
+	return statements
+}
+
+// syncDatabaseExtensions syncs the extensions in the database of the connection and returns the errors
+// per extension. The caller is responsible for opening and closing the database connection.
+func (c *Cluster) syncDatabaseExtensions(datname string, extensions map[string]acidv1.Extension) map[string]error {
+	installed, err := c.getExtensions()
+	if err != nil {
+		return extensionsFailed(extensions, fmt.Errorf("could not get installed extensions: %v", err))
+	}
+
+	changes, errs := c.extensionChanges(extensions, installed)
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, exists := installed[name]; !exists {
+			c.logger.Infof("creating extension %q in database %q", name, datname)
+		} else {
+			c.logger.Infof("updating extension %q in database %q", name, datname)
+		}
+		for _, statement := range changes[name] {
+			if _, err = c.pgDb.Exec(statement); err != nil {
+				errs[name] = err
+				break
+			}
+		}
+		if errs[name] == nil {
+			c.recordEvent(v1.EventTypeNormal, "Extensions", "Synced extension %q in database %q", name, datname)
+		}
+	}
+	return errs
+}
+
+// extensionChanges returns the statements that bring the installed extensions of a database to the manifest,
+// for the extensions that need a change, and the errors of the extensions that cannot be synced
+func (c *Cluster) extensionChanges(extensions map[string]acidv1.Extension,
+	installed map[string]installedExtension) (map[string][]string, map[string]error) {
+	changes := make(map[string][]string)
+	errs := make(map[string]error)
+	for name, extension := range extensions {
+		// the manifest is only validated by the admission webhook, which may not be enabled
+		if err := validateExtension(name, extension); err != nil {
+			errs[name] = err
+			continue
+		}
+		if !isAllowedExtension(c.OpConfig.AllowedExtensions, name) {
+			errs[name] = fmt.Errorf("extension is not allowed by the operator configuration")
+			continue
+		}
+		current, exists := installed[name]
+		if statements := extensionStatements(name, extension, current, exists); len(statements) > 0 {
+			changes[name] = statements
+		}
+	}
+	return changes, errs
+}
+
+// getExtensions returns the extensions installed in the database of the connection.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) getExtensions() (extensions map[string]installedExtension, err error) {
+	var rows *sql.Rows
+
+	if rows, err = c.pgDb.Query(getExtensionsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = fmt.Errorf("error when closing query cursor: %v", err2)
+		}
+	}()
+
+	extensions = make(map[string]installedExtension)
+	for rows.Next() {
+		var name string
+		var extension installedExtension
+
+		if err = rows.Scan(&name, &extension.version, &extension.schema); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		extensions[name] = extension
+	}
+	return extensions, nil
+}
+
+func (c *Cluster) updateExtensionErrorsStatus(extensionErrors []acidv1.ExtensionError) {
+	if len(extensionErrors) == 0 && len(c.Status.ExtensionErrors) == 0 ||
+		reflect.DeepEqual(extensionErrors, c.Status.ExtensionErrors) {
+		return
+	}
+	for _, extensionError := range extensionErrors {
+		c.recordEvent(v1.EventTypeWarning, "Extensions", "Could not sync extension %q in database %q: %s",
+			extensionError.Extension, extensionError.Database, extensionError.Message)
+	}
+
+	pg, err := c.patchStatus(map[string]interface{}{"extensionErrors": extensionErrors})
+	if err != nil {
+		c.logger.Warningf("could not update extension errors status: %v", err)
+		return
+	}
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
@@ -0,0 +1,127 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+func TestExtensionStatements(t *testing.T) {
+	testName := "TestExtensionStatements"
+	tests := []struct {
+		subTest   string
+		extension acidv1.Extension
+		current   installedExtension
+		installed bool
+		expected  []string
+	}{
+		{
+			subTest:  "missing extension",
+			expected: []string{`CREATE EXTENSION IF NOT EXISTS "pg_partman";`},
+		},
+		{
+			subTest:   "missing extension with schema and version",
+			extension: acidv1.Extension{Version: "4.4.0", Schema: "partman"},
+			expected:  []string{`CREATE EXTENSION IF NOT EXISTS "pg_partman" SCHEMA "partman" VERSION '4.4.0';`},
+		},
+		{
+			subTest:   "extension without a version",
+			current:   installedExtension{version: "4.3.0", schema: "public"},
+			installed: true,
+			expected:  []string{},
+		},
+		{
+			subTest:   "extension with an outdated version",
+			extension: acidv1.Extension{Version: "4.4.0"},
+			current:   installedExtension{version: "4.3.0", schema: "public"},
+			installed: true,
+			expected:  []string{`ALTER EXTENSION "pg_partman" UPDATE TO '4.4.0';`},
+		},
+		{
+			subTest:   "extension pinned to its version",
+			extension: acidv1.Extension{Version: "4.3.0"},
+			current:   installedExtension{version: "4.3.0", schema: "public"},
+			installed: true,
+			expected:  []string{},
+		},
+		{
+			subTest:   "extension in another schema",
+			extension: acidv1.Extension{Version: "4.4.0", Schema: "partman"},
+			current:   installedExtension{version: "4.3.0", schema: "public"},
+			installed: true,
+			expected: []string{
+				`ALTER EXTENSION "pg_partman" SET SCHEMA "partman";`,
+				`ALTER EXTENSION "pg_partman" UPDATE TO '4.4.0';`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		statements := extensionStatements("pg_partman", tt.extension, tt.current, tt.installed)
+		if !reflect.DeepEqual(statements, tt.expected) {
+			t.Errorf("%s %s: expected statements %v, got %v", testName, tt.subTest, tt.expected, statements)
+		}
+	}
+}
+
+func TestValidateExtension(t *testing.T) {
+	testName := "TestValidateExtension"
+	tests := []struct {
+		subTest   string
+		name      string
+		extension acidv1.Extension
+		err       bool
+	}{
+		{
+			subTest:   "valid extension",
+			name:      "pg_partman",
+			extension: acidv1.Extension{Version: "4.4.0", Schema: "partman"},
+		},
+		{
+			subTest: "quote in the name",
+			name:    `pg_partman"; DROP SCHEMA public; --`,
+			err:     true,
+		},
+		{
+			subTest:   "quote in the version",
+			name:      "pg_partman",
+			extension: acidv1.Extension{Version: "4.4.0'; DROP SCHEMA public; --"},
+			err:       true,
+		},
+		{
+			subTest:   "quote in the schema",
+			name:      "pg_partman",
+			extension: acidv1.Extension{Schema: `partman" CASCADE; --`},
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		if err := validateExtension(tt.name, tt.extension); (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+		}
+	}
+}
+
+func TestIsAllowedExtension(t *testing.T) {
+	testName := "TestIsAllowedExtension"
+	allowed := []string{"pg_partman", "pg_stat_statements"}
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"pg_partman", true},
+		{"postgis", false},
+		{"pg_partman_bgw", false},
+	}
+
+	for _, tt := range tests {
+		if result := isAllowedExtension(allowed, tt.name); result != tt.expected {
+			t.Errorf("%s: expected %t for extension %q, got %t", testName, tt.expected, tt.name, result)
+		}
+	}
+	if isAllowedExtension(nil, "pg_partman") {
+		t.Errorf("%s: expected no extension to be allowed without an allowlist", testName)
+	}
+}
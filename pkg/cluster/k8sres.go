@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path"
 	"sort"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 
@@ -825,7 +826,7 @@ func (c *Cluster) getNewPgVersion(container v1.Container, newPgVersion string) (
 	}
 
 	if runningPgVersion != newPgVersion {
-		c.logger.Warningf("postgresql version change(%q -> %q) has no effect", runningPgVersion, newPgVersion)
+		c.logger.Infof("postgresql version change(%q -> %q) requires a major version upgrade", runningPgVersion, newPgVersion)
 		newPgVersion = runningPgVersion
 	}
 
@@ -1338,7 +1339,7 @@ func (c *Cluster) generateUserSecrets() map[string]*v1.Secret {
 	namespace := c.Namespace
 	for username, pgUser := range c.pgUsers {
 		//Skip users with no password i.e. human users (they'll be authenticated using pam)
-		secret := c.generateSingleUserSecret(namespace, pgUser)
+		secret := c.generateSingleUserSecret(c.userSecretNamespace(pgUser), pgUser)
 		if secret != nil {
 			secrets[username] = secret
 		}
@@ -1357,7 +1358,7 @@ func (c *Cluster) generateUserSecrets() map[string]*v1.Secret {
 func (c *Cluster) generateSingleUserSecret(namespace string, pgUser spec.PgUser) *v1.Secret {
 	//Skip users with no password i.e. human users (they'll be authenticated using pam)
 	if pgUser.Password == "" {
-		if pgUser.Origin != spec.RoleOriginTeamsAPI {
+		if pgUser.Origin != spec.RoleOriginTeamsAPI && pgUser.Origin != spec.RoleOriginBootstrap {
 			c.logger.Warningf("could not generate secret for a non-teamsAPI role %q: role has no password",
 				pgUser.Name)
 		}
@@ -1377,6 +1378,12 @@ func (c *Cluster) generateSingleUserSecret(namespace string, pgUser spec.PgUser)
 			"password": []byte(pgUser.Password),
 		},
 	}
+	// owner references do not work across namespaces, so the secret refers to its cluster by an annotation
+	if namespace != c.Namespace {
+		secret.Annotations = map[string]string{
+			constants.SecretClusterAnnotationKey: util.NameFromMeta(c.ObjectMeta).String(),
+		}
+	}
 	return &secret
 }
 
@@ -1649,14 +1656,94 @@ func (c *Cluster) getClusterServiceConnectionParameters(clusterName string) (hos
 
 func (c *Cluster) generateLogicalBackupJob() (*batchv1beta1.CronJob, error) {
 
+	// NB: a cron job creates standard batch jobs according to schedule; these batch jobs manage pods and clean-up
+
+	podTemplate, err := c.generateLogicalBackupPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	// configure a batch job
+
+	jobSpec := batchv1.JobSpec{
+		Template: *podTemplate,
+	}
+
+	// configure a cron job
+
+	jobTemplateSpec := batchv1beta1.JobTemplateSpec{
+		Spec: jobSpec,
+	}
+
+	schedule := c.Postgresql.Spec.LogicalBackupSchedule
+	if schedule == "" {
+		schedule = c.OpConfig.LogicalBackupSchedule
+	}
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.getLogicalBackupJobName(),
+			Namespace: c.Namespace,
+			Labels:    c.labelsSet(true),
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          schedule,
+			JobTemplate:       jobTemplateSpec,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		},
+	}
+
+	return cronJob, nil
+}
+
+// generatePostgresBackupJob generates the one-off job that takes the logical backup requested by a PostgresBackup.
+// The job runs once and is owned by the PostgresBackup, so it is garbage collected together with it.
+func (c *Cluster) generatePostgresBackupJob(backup *acidv1.PostgresBackup) (*batchv1.Job, error) {
+	podTemplate, err := c.generateLogicalBackupPodTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	// the dump is uploaded under the name of the backup, so that its location is known in advance
+	container := &podTemplate.Spec.Containers[0]
+	container.Env = append(container.Env, v1.EnvVar{Name: "LOGICAL_BACKUP_FILE_NAME", Value: backup.Name + ".sql.gz"})
+
+	backoffLimit := int32(0)
+	controller := true
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.getPostgresBackupJobName(backup.Name),
+			Namespace: c.Namespace,
+			Labels:    c.labelsSet(true),
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					UID:        backup.UID,
+					APIVersion: acidv1.SchemeGroupVersion.String(),
+					Kind:       acidv1.PostgresBackupCRDResourceKind,
+					Name:       backup.Name,
+					Controller: &controller,
+				},
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template:     *podTemplate,
+		},
+	}
+
+	return job, nil
+}
+
+// generateLogicalBackupPodTemplate generates the pod template shared by the logical backup cron job and
+// the jobs of on-demand logical backups
+func (c *Cluster) generateLogicalBackupPodTemplate() (*v1.PodTemplateSpec, error) {
+
 	var (
 		err                  error
 		podTemplate          *v1.PodTemplateSpec
 		resourceRequirements *v1.ResourceRequirements
 	)
 
-	// NB: a cron job creates standard batch jobs according to schedule; these batch jobs manage pods and clean-up
-
 	c.logger.Debug("Generating logical backup pod template")
 
 	// allocate for the backup pod the same amount of resources as for normal DB pods
@@ -1666,13 +1753,19 @@ func (c *Cluster) generateLogicalBackupJob() (*batchv1beta1.CronJob, error) {
 		return nil, fmt.Errorf("could not generate resource requirements for logical backup pods: %v", err)
 	}
 
-	envVars := c.generateLogicalBackupPodEnvVars()
+	target := c.logicalBackupTarget()
+	if err = target.validate(); err != nil {
+		return nil, err
+	}
+
+	envVars := c.generateLogicalBackupPodEnvVars(target)
+	volumes, volumeMounts := generateLogicalBackupVolumes(target)
 	logicalBackupContainer := generateContainer(
 		"logical-backup",
 		&c.OpConfig.LogicalBackup.LogicalBackupDockerImage,
 		resourceRequirements,
 		envVars,
-		[]v1.VolumeMount{},
+		volumeMounts,
 		c.OpConfig.SpiloPrivileged, // use same value as for normal DB pods
 	)
 
@@ -1717,7 +1810,7 @@ func (c *Cluster) generateLogicalBackupJob() (*batchv1beta1.CronJob, error) {
 		"",
 		c.OpConfig.AdditionalSecretMount,
 		c.OpConfig.AdditionalSecretMountPath,
-		nil); err != nil {
+		volumes); err != nil {
 		return nil, fmt.Errorf("could not generate pod template for logical backup pod: %v", err)
 	}
 
@@ -1725,40 +1818,10 @@ func (c *Cluster) generateLogicalBackupJob() (*batchv1beta1.CronJob, error) {
 	podTemplate.Spec.Affinity = &podAffinity
 	podTemplate.Spec.RestartPolicy = "Never" // affects containers within a pod
 
-	// configure a batch job
-
-	jobSpec := batchv1.JobSpec{
-		Template: *podTemplate,
-	}
-
-	// configure a cron job
-
-	jobTemplateSpec := batchv1beta1.JobTemplateSpec{
-		Spec: jobSpec,
-	}
-
-	schedule := c.Postgresql.Spec.LogicalBackupSchedule
-	if schedule == "" {
-		schedule = c.OpConfig.LogicalBackupSchedule
-	}
-
-	cronJob := &batchv1beta1.CronJob{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      c.getLogicalBackupJobName(),
-			Namespace: c.Namespace,
-			Labels:    c.labelsSet(true),
-		},
-		Spec: batchv1beta1.CronJobSpec{
-			Schedule:          schedule,
-			JobTemplate:       jobTemplateSpec,
-			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
-		},
-	}
-
-	return cronJob, nil
+	return podTemplate, nil
 }
 
-func (c *Cluster) generateLogicalBackupPodEnvVars() []v1.EnvVar {
+func (c *Cluster) generateLogicalBackupPodEnvVars(target logicalBackupTarget) []v1.EnvVar {
 
 	envVars := []v1.EnvVar{
 		{
@@ -1778,22 +1841,10 @@ func (c *Cluster) generateLogicalBackupPodEnvVars() []v1.EnvVar {
 				},
 			},
 		},
-		// Bucket env vars
-		{
-			Name:  "LOGICAL_BACKUP_S3_BUCKET",
-			Value: c.OpConfig.LogicalBackup.LogicalBackupS3Bucket,
-		},
-		{
-			Name:  "LOGICAL_BACKUP_S3_REGION",
-			Value: c.OpConfig.LogicalBackup.LogicalBackupS3Region,
-		},
-		{
-			Name:  "LOGICAL_BACKUP_S3_ENDPOINT",
-			Value: c.OpConfig.LogicalBackup.LogicalBackupS3Endpoint,
-		},
+		// Storage env vars
 		{
-			Name:  "LOGICAL_BACKUP_S3_SSE",
-			Value: c.OpConfig.LogicalBackup.LogicalBackupS3SSE,
+			Name:  "LOGICAL_BACKUP_PROVIDER",
+			Value: target.Provider,
 		},
 		{
 			Name:  "LOGICAL_BACKUP_S3_BUCKET_SCOPE_SUFFIX",
@@ -1833,23 +1884,118 @@ func (c *Cluster) generateLogicalBackupPodEnvVars() []v1.EnvVar {
 		},
 	}
 
-	if c.OpConfig.LogicalBackup.LogicalBackupS3AccessKeyID != "" {
-		envVars = append(envVars, v1.EnvVar{Name: "AWS_ACCESS_KEY_ID", Value: c.OpConfig.LogicalBackup.LogicalBackupS3AccessKeyID})
+	switch target.Provider {
+	case constants.LogicalBackupProviderS3:
+		envVars = append(envVars,
+			v1.EnvVar{Name: "LOGICAL_BACKUP_S3_BUCKET", Value: target.Bucket},
+			v1.EnvVar{Name: "LOGICAL_BACKUP_S3_REGION", Value: c.OpConfig.LogicalBackup.LogicalBackupS3Region},
+			v1.EnvVar{Name: "LOGICAL_BACKUP_S3_ENDPOINT", Value: c.OpConfig.LogicalBackup.LogicalBackupS3Endpoint},
+			v1.EnvVar{Name: "LOGICAL_BACKUP_S3_SSE", Value: c.OpConfig.LogicalBackup.LogicalBackupS3SSE})
+
+		// the keys of the operator are never handed to a bucket of the manifest
+		if target.CredentialsSecret != "" {
+			for _, key := range []string{constants.LogicalBackupS3AccessKeyIDKey, constants.LogicalBackupS3SecretAccessKeyKey} {
+				envVars = append(envVars, v1.EnvVar{
+					Name: key,
+					ValueFrom: &v1.EnvVarSource{
+						SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{
+								Name: target.CredentialsSecret,
+							},
+							Key: key,
+						},
+					},
+				})
+			}
+		} else {
+			if c.OpConfig.LogicalBackup.LogicalBackupS3AccessKeyID != "" {
+				envVars = append(envVars, v1.EnvVar{Name: "AWS_ACCESS_KEY_ID", Value: c.OpConfig.LogicalBackup.LogicalBackupS3AccessKeyID})
+			}
+
+			if c.OpConfig.LogicalBackup.LogicalBackupS3SecretAccessKey != "" {
+				envVars = append(envVars, v1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", Value: c.OpConfig.LogicalBackup.LogicalBackupS3SecretAccessKey})
+			}
+		}
+
+	case constants.LogicalBackupProviderGCS:
+		envVars = append(envVars, v1.EnvVar{Name: "LOGICAL_BACKUP_GCS_BUCKET", Value: target.Bucket})
+
+		if target.CredentialsSecret != "" {
+			envVars = append(envVars, v1.EnvVar{
+				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+				Value: path.Join(constants.LogicalBackupGCSCredentialsMountPath, constants.LogicalBackupGCSCredentialsKey),
+			})
+		}
+
+	case constants.LogicalBackupProviderAzure:
+		envVars = append(envVars,
+			v1.EnvVar{Name: "LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER", Value: target.Container},
+			v1.EnvVar{Name: "AZURE_STORAGE_ACCOUNT", Value: target.StorageAccount})
+
+		if target.CredentialsSecret != "" {
+			envVars = append(envVars, v1.EnvVar{
+				Name: "AZURE_STORAGE_KEY",
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: target.CredentialsSecret,
+						},
+						Key: constants.LogicalBackupAzureStorageKey,
+					},
+				},
+			})
+		}
+
+	case constants.LogicalBackupProviderPVC:
+		envVars = append(envVars, v1.EnvVar{Name: "LOGICAL_BACKUP_PVC_PATH", Value: constants.LogicalBackupPVCMountPath})
 	}
 
-	if c.OpConfig.LogicalBackup.LogicalBackupS3SecretAccessKey != "" {
-		envVars = append(envVars, v1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", Value: c.OpConfig.LogicalBackup.LogicalBackupS3SecretAccessKey})
+	if target.Provider != constants.LogicalBackupProviderS3 {
+		// the operator only reaches S3, so for the other providers the job applies the retention after each dump
+		retention := c.logicalBackupRetention()
+		envVars = append(envVars,
+			v1.EnvVar{Name: "LOGICAL_BACKUP_RETENTION_COUNT", Value: strconv.Itoa(int(retention.Count))},
+			v1.EnvVar{Name: "LOGICAL_BACKUP_RETENTION_DAYS", Value: strconv.Itoa(int(retention.Days))})
 	}
 
 	c.logger.Debugf("Generated logical backup env vars %v", envVars)
 	return envVars
 }
 
+// generateLogicalBackupVolumes generates the volume the logical backup pod needs to reach the storage, if any
+func generateLogicalBackupVolumes(target logicalBackupTarget) ([]v1.Volume, []v1.VolumeMount) {
+	volume := v1.Volume{Name: constants.LogicalBackupVolumeName}
+	volumeMount := v1.VolumeMount{Name: constants.LogicalBackupVolumeName}
+
+	switch {
+	case target.Provider == constants.LogicalBackupProviderPVC:
+		volume.PersistentVolumeClaim = &v1.PersistentVolumeClaimVolumeSource{
+			ClaimName: target.ClaimName,
+		}
+		volumeMount.MountPath = constants.LogicalBackupPVCMountPath
+	case target.Provider == constants.LogicalBackupProviderGCS && target.CredentialsSecret != "":
+		volume.Secret = &v1.SecretVolumeSource{
+			SecretName: target.CredentialsSecret,
+		}
+		volumeMount.MountPath = constants.LogicalBackupGCSCredentialsMountPath
+		volumeMount.ReadOnly = true
+	default:
+		return nil, []v1.VolumeMount{}
+	}
+
+	return []v1.Volume{volume}, []v1.VolumeMount{volumeMount}
+}
+
 // getLogicalBackupJobName returns the name; the job itself may not exists
 func (c *Cluster) getLogicalBackupJobName() (jobName string) {
 	return "logical-backup-" + c.clusterName().Name
 }
 
+// getPostgresBackupJobName returns the name of the job of an on-demand logical backup
+func (c *Cluster) getPostgresBackupJobName(backupName string) string {
+	return "postgres-backup-" + backupName
+}
+
 // Generate pool size related environment variables.
 //
 // MAX_DB_CONN would specify the global maximum for connections to a target
@@ -37,7 +37,7 @@ func TestGenerateSpiloJSONConfiguration(t *testing.T) {
 					ReplicationUsername: replicationUserName,
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	testName := "TestGenerateSpiloConfig"
 	tests := []struct {
@@ -100,7 +100,7 @@ func TestCreateLoadBalancerLogic(t *testing.T) {
 					ReplicationUsername: replicationUserName,
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	testName := "TestCreateLoadBalancerLogic"
 	tests := []struct {
@@ -162,7 +162,8 @@ func TestGeneratePodDisruptionBudget(t *testing.T) {
 				acidv1.Postgresql{
 					ObjectMeta: metav1.ObjectMeta{Name: "myapp-database", Namespace: "myapp"},
 					Spec:       acidv1.PostgresSpec{TeamID: "myapp", NumberOfInstances: 3}},
-				logger),
+				logger,
+				eventRecorder),
 			policyv1beta1.PodDisruptionBudget{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "postgres-myapp-database-pdb",
@@ -185,7 +186,8 @@ func TestGeneratePodDisruptionBudget(t *testing.T) {
 				acidv1.Postgresql{
 					ObjectMeta: metav1.ObjectMeta{Name: "myapp-database", Namespace: "myapp"},
 					Spec:       acidv1.PostgresSpec{TeamID: "myapp", NumberOfInstances: 0}},
-				logger),
+				logger,
+				eventRecorder),
 			policyv1beta1.PodDisruptionBudget{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "postgres-myapp-database-pdb",
@@ -208,7 +210,8 @@ func TestGeneratePodDisruptionBudget(t *testing.T) {
 				acidv1.Postgresql{
 					ObjectMeta: metav1.ObjectMeta{Name: "myapp-database", Namespace: "myapp"},
 					Spec:       acidv1.PostgresSpec{TeamID: "myapp", NumberOfInstances: 3}},
-				logger),
+				logger,
+				eventRecorder),
 			policyv1beta1.PodDisruptionBudget{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "postgres-myapp-database-pdb",
@@ -231,7 +234,8 @@ func TestGeneratePodDisruptionBudget(t *testing.T) {
 				acidv1.Postgresql{
 					ObjectMeta: metav1.ObjectMeta{Name: "myapp-database", Namespace: "myapp"},
 					Spec:       acidv1.PostgresSpec{TeamID: "myapp", NumberOfInstances: 3}},
-				logger),
+				logger,
+				eventRecorder),
 			policyv1beta1.PodDisruptionBudget{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "postgres-myapp-database-databass-budget",
@@ -366,7 +370,7 @@ func TestCloneEnv(t *testing.T) {
 					ReplicationUsername: replicationUserName,
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	for _, tt := range tests {
 		envs := cluster.generateCloneEnvironment(tt.cloneOpts)
@@ -500,7 +504,7 @@ func TestGetPgVersion(t *testing.T) {
 					ReplicationUsername: replicationUserName,
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	for _, tt := range tests {
 		pgVersion, err := cluster.getNewPgVersion(tt.pgContainer, tt.newPgVersion)
@@ -676,7 +680,7 @@ func TestConnPoolPodSpec(t *testing.T) {
 					ConnPoolDefaultMemoryLimit:   "100Mi",
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	var clusterNoDefaultRes = New(
 		Config{
@@ -688,7 +692,7 @@ func TestConnPoolPodSpec(t *testing.T) {
 				},
 				ConnectionPool: config.ConnectionPool{},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	noCheck := func(cluster *Cluster, podSpec *v1.PodTemplateSpec) error { return nil }
 
@@ -801,7 +805,7 @@ func TestConnPoolDeploymentSpec(t *testing.T) {
 					ConnPoolDefaultMemoryLimit:   "100Mi",
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 	cluster.Statefulset = &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "test-sts",
@@ -902,7 +906,7 @@ func TestConnPoolServiceSpec(t *testing.T) {
 					ConnPoolDefaultMemoryLimit:   "100Mi",
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 	cluster.Statefulset = &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "test-sts",
@@ -983,7 +987,7 @@ func TestTLS(t *testing.T) {
 					ReplicationUsername: replicationUserName,
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 	spec = makeSpec(acidv1.TLSDescription{SecretName: "my-secret", CAFile: "ca.crt"})
 	s, err := cluster.generateStatefulSet(&spec)
 	if err != nil {
@@ -1015,3 +1019,207 @@ func TestTLS(t *testing.T) {
 	assert.Contains(t, s.Spec.Template.Spec.Containers[0].Env, v1.EnvVar{Name: "SSL_PRIVATE_KEY_FILE", Value: "/tls/tls.key"})
 	assert.Contains(t, s.Spec.Template.Spec.Containers[0].Env, v1.EnvVar{Name: "SSL_CA_FILE", Value: "/tls/ca.crt"})
 }
+
+func TestGenerateUserSecretsInOtherNamespaces(t *testing.T) {
+	testName := "TestGenerateUserSecretsInOtherNamespaces"
+	tests := []struct {
+		subTest            string
+		crossNamespace     bool
+		expectedNamespaces map[string]string
+	}{
+		{
+			subTest: "cross namespace secrets disabled",
+			expectedNamespaces: map[string]string{
+				"foo_user":          "default",
+				"appspace.app_user": "default",
+				"bar_user":          "default",
+			},
+		},
+		{
+			subTest:        "cross namespace secrets enabled",
+			crossNamespace: true,
+			expectedNamespaces: map[string]string{
+				"foo_user":          "default",
+				"appspace.app_user": "appspace",
+				"bar_user":          "other",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(
+			Config{
+				OpConfig: config.Config{
+					Auth: config.Auth{
+						SecretNameTemplate:          "{username}.{cluster}.credentials",
+						SuperUsername:               superUserName,
+						ReplicationUsername:         replicationUserName,
+						EnableCrossNamespaceSecrets: tt.crossNamespace,
+					},
+				},
+			}, k8sutil.NewMockKubernetesClient(),
+			acidv1.Postgresql{
+				ObjectMeta: metav1.ObjectMeta{Name: "acid-test", Namespace: "default"},
+				Spec: acidv1.PostgresSpec{
+					Users: map[string]acidv1.UserFlags{
+						"foo_user":          {},
+						"appspace.app_user": {},
+						"bar_user":          {},
+					},
+					SecretNamespaces: map[string]string{"bar_user": "other"},
+				},
+			}, logger, eventRecorder)
+		if err := cluster.initRobotUsers(); err != nil {
+			t.Fatalf("%s %s: could not init users: %v", testName, tt.subTest, err)
+		}
+
+		secrets := cluster.generateUserSecrets()
+		for username, namespace := range tt.expectedNamespaces {
+			secret, exists := secrets[username]
+			if !exists {
+				t.Errorf("%s %s: expected a secret of user %q", testName, tt.subTest, username)
+				continue
+			}
+			if secret.Namespace != namespace {
+				t.Errorf("%s %s: expected the secret of user %q in namespace %q, got %q",
+					testName, tt.subTest, username, namespace, secret.Namespace)
+			}
+			clusterAnnotation := secret.Annotations[constants.SecretClusterAnnotationKey]
+			if namespace != "default" && clusterAnnotation != "default/acid-test" {
+				t.Errorf("%s %s: expected the secret of user %q to refer to the cluster, got %q",
+					testName, tt.subTest, username, clusterAnnotation)
+			}
+			if namespace == "default" && clusterAnnotation != "" {
+				t.Errorf("%s %s: expected no cluster annotation on the secret of user %q", testName, tt.subTest, username)
+			}
+		}
+	}
+}
+
+func TestLogicalBackupStorageEnvAndVolumes(t *testing.T) {
+	testName := "TestLogicalBackupStorageEnvAndVolumes"
+	tests := []struct {
+		subTest     string
+		target      *acidv1.LogicalBackupTarget
+		expectedEnv map[string]string
+		absentEnv   []string
+		secretEnv   map[string]string
+		claimName   string
+		secretName  string
+	}{
+		{
+			subTest:     "s3 bucket of the operator",
+			expectedEnv: map[string]string{"LOGICAL_BACKUP_PROVIDER": "s3", "LOGICAL_BACKUP_S3_BUCKET": "s3-backups"},
+			absentEnv:   []string{"LOGICAL_BACKUP_PVC_PATH", "LOGICAL_BACKUP_RETENTION_COUNT"},
+		},
+		{
+			subTest:     "s3 bucket of the manifest",
+			target:      &acidv1.LogicalBackupTarget{Bucket: "team-backups", CredentialsSecret: "team-credentials"},
+			expectedEnv: map[string]string{"LOGICAL_BACKUP_S3_BUCKET": "team-backups"},
+			secretEnv:   map[string]string{"AWS_ACCESS_KEY_ID": "team-credentials", "AWS_SECRET_ACCESS_KEY": "team-credentials"},
+		},
+		{
+			subTest: "gcs with credentials",
+			target:  &acidv1.LogicalBackupTarget{Provider: "gcs"},
+			expectedEnv: map[string]string{
+				"LOGICAL_BACKUP_PROVIDER":        "gcs",
+				"LOGICAL_BACKUP_GCS_BUCKET":      "gcs-backups",
+				"GOOGLE_APPLICATION_CREDENTIALS": "/var/secrets/google/key.json",
+				"LOGICAL_BACKUP_RETENTION_COUNT": "5",
+			},
+			absentEnv:  []string{"LOGICAL_BACKUP_S3_BUCKET"},
+			secretName: "gcs-credentials",
+		},
+		{
+			subTest: "azure with a container of the manifest",
+			target:  &acidv1.LogicalBackupTarget{Provider: "azure", Container: "team-backups", CredentialsSecret: "team-credentials"},
+			expectedEnv: map[string]string{
+				"LOGICAL_BACKUP_AZURE_STORAGE_CONTAINER": "team-backups",
+				"AZURE_STORAGE_ACCOUNT":                  "backupaccount",
+				"LOGICAL_BACKUP_RETENTION_COUNT":         "5",
+			},
+			absentEnv: []string{"AWS_ACCESS_KEY_ID"},
+			secretEnv: map[string]string{"AZURE_STORAGE_KEY": "team-credentials"},
+		},
+		{
+			subTest: "persistent volume claim",
+			target:  &acidv1.LogicalBackupTarget{Provider: "pvc", ClaimName: "team-backups"},
+			expectedEnv: map[string]string{
+				"LOGICAL_BACKUP_PVC_PATH":        "/backup",
+				"LOGICAL_BACKUP_RETENTION_COUNT": "5",
+				"LOGICAL_BACKUP_RETENTION_DAYS":  "0",
+			},
+			claimName: "team-backups",
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(
+			Config{
+				OpConfig: config.Config{
+					LogicalBackup: config.LogicalBackup{
+						LogicalBackupProvider:              "s3",
+						LogicalBackupS3Bucket:              "s3-backups",
+						LogicalBackupS3AccessKeyID:         "access-key",
+						LogicalBackupGCSBucket:             "gcs-backups",
+						LogicalBackupGCSCredentialsSecret:  "gcs-credentials",
+						LogicalBackupAzureStorageAccount:   "backupaccount",
+						LogicalBackupAzureStorageContainer: "backups",
+						LogicalBackupRetentionCount:        5,
+					},
+				},
+			}, k8sutil.KubernetesClient{}, acidv1.Postgresql{
+				ObjectMeta: metav1.ObjectMeta{Name: "acid-test", Namespace: "default"},
+				Spec:       acidv1.PostgresSpec{LogicalBackupTarget: tt.target},
+			}, logger, eventRecorder)
+
+		target := cluster.logicalBackupTarget()
+		if err := target.validate(); err != nil {
+			t.Errorf("%s %s: unexpected invalid target: %v", testName, tt.subTest, err)
+		}
+
+		env, secretEnv := make(map[string]string), make(map[string]string)
+		for _, envVar := range cluster.generateLogicalBackupPodEnvVars(target) {
+			env[envVar.Name] = envVar.Value
+			if envVar.ValueFrom != nil && envVar.ValueFrom.SecretKeyRef != nil {
+				secretEnv[envVar.Name] = envVar.ValueFrom.SecretKeyRef.Name
+			}
+		}
+		for name, secretName := range tt.secretEnv {
+			if secretEnv[name] != secretName {
+				t.Errorf("%s %s: expected env %s from secret %q, got %q", testName, tt.subTest, name, secretName, secretEnv[name])
+			}
+		}
+		for name, value := range tt.expectedEnv {
+			if env[name] != value {
+				t.Errorf("%s %s: expected env %s=%q, got %q", testName, tt.subTest, name, value, env[name])
+			}
+		}
+		for _, name := range tt.absentEnv {
+			if _, exists := env[name]; exists {
+				t.Errorf("%s %s: unexpected env %s", testName, tt.subTest, name)
+			}
+		}
+
+		volumes, volumeMounts := generateLogicalBackupVolumes(target)
+		if len(volumes) != len(volumeMounts) {
+			t.Errorf("%s %s: expected a mount for each of the volumes %v, got %v", testName, tt.subTest, volumes, volumeMounts)
+		}
+		if tt.claimName == "" && tt.secretName == "" {
+			if len(volumes) != 0 {
+				t.Errorf("%s %s: expected no volumes, got %v", testName, tt.subTest, volumes)
+			}
+			continue
+		}
+		if len(volumes) != 1 {
+			t.Errorf("%s %s: expected one volume, got %v", testName, tt.subTest, volumes)
+			continue
+		}
+		if tt.claimName != "" && (volumes[0].PersistentVolumeClaim == nil || volumes[0].PersistentVolumeClaim.ClaimName != tt.claimName) {
+			t.Errorf("%s %s: expected a volume of the claim %q, got %v", testName, tt.subTest, tt.claimName, volumes[0])
+		}
+		if tt.secretName != "" && (volumes[0].Secret == nil || volumes[0].Secret.SecretName != tt.secretName) {
+			t.Errorf("%s %s: expected a volume of the secret %q, got %v", testName, tt.subTest, tt.secretName, volumes[0])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/logicalbackup"
+)
+
+// LogicalBackupCatalog lists the logical backups of a cluster together with the retention applied to them
+type LogicalBackupCatalog struct {
+	Cluster   string                  `json:"cluster"`
+	Location  string                  `json:"location"`
+	Retention logicalbackup.Retention `json:"retention"`
+	Dumps     []logicalbackup.Dump    `json:"dumps"`
+}
+
+// logicalBackupTarget describes the storage the logical backups of the cluster are uploaded to
+type logicalBackupTarget struct {
+	Provider          string
+	Bucket            string
+	StorageAccount    string
+	Container         string
+	ClaimName         string
+	CredentialsSecret string
+	// the manifest sets the bucket, storage account or container, which is never accessed with the credentials
+	// of the operator
+	ManifestLocation bool
+}
+
+// logicalBackupTarget returns the storage configured in the operator for the provider, overridden by the manifest
+func (c *Cluster) logicalBackupTarget() logicalBackupTarget {
+	cfg := c.OpConfig.LogicalBackup
+	override := c.Spec.LogicalBackupTarget
+	if override == nil {
+		override = &acidv1.LogicalBackupTarget{}
+	}
+
+	target := logicalBackupTarget{
+		Provider: util.Coalesce(override.Provider, util.Coalesce(cfg.LogicalBackupProvider, constants.LogicalBackupProviderS3)),
+	}
+	switch target.Provider {
+	case constants.LogicalBackupProviderS3:
+		target.Bucket = cfg.LogicalBackupS3Bucket
+	case constants.LogicalBackupProviderGCS:
+		target.Bucket = cfg.LogicalBackupGCSBucket
+		target.CredentialsSecret = cfg.LogicalBackupGCSCredentialsSecret
+	case constants.LogicalBackupProviderAzure:
+		target.StorageAccount = cfg.LogicalBackupAzureStorageAccount
+		target.Container = cfg.LogicalBackupAzureStorageContainer
+		target.CredentialsSecret = cfg.LogicalBackupAzureCredentialsSecret
+	case constants.LogicalBackupProviderPVC:
+		target.ClaimName = cfg.LogicalBackupPVCClaimName
+	}
+
+	target.Bucket = util.Coalesce(override.Bucket, target.Bucket)
+	target.StorageAccount = util.Coalesce(override.StorageAccount, target.StorageAccount)
+	target.Container = util.Coalesce(override.Container, target.Container)
+	target.ClaimName = util.Coalesce(override.ClaimName, target.ClaimName)
+	target.ManifestLocation = override.Bucket != "" || override.StorageAccount != "" || override.Container != ""
+	if target.ManifestLocation {
+		target.CredentialsSecret = override.CredentialsSecret
+	} else {
+		target.CredentialsSecret = util.Coalesce(override.CredentialsSecret, target.CredentialsSecret)
+	}
+	return target
+}
+
+// validate checks that the settings the provider needs to upload logical backups are present
+func (t logicalBackupTarget) validate() error {
+	if t.ManifestLocation && t.CredentialsSecret == "" && t.Provider != constants.LogicalBackupProviderPVC {
+		return fmt.Errorf("the logical backup location of the manifest requires a credentials secret")
+	}
+
+	switch t.Provider {
+	case constants.LogicalBackupProviderS3:
+		// kept permissive for compatibility with setups where the logical backup image provides the bucket
+	case constants.LogicalBackupProviderGCS:
+		if t.Bucket == "" {
+			return fmt.Errorf("no GCS bucket is configured for logical backups")
+		}
+	case constants.LogicalBackupProviderAzure:
+		if t.StorageAccount == "" || t.Container == "" {
+			return fmt.Errorf("no Azure storage account and container are configured for logical backups")
+		}
+	case constants.LogicalBackupProviderPVC:
+		if t.ClaimName == "" {
+			return fmt.Errorf("no persistent volume claim is configured for logical backups")
+		}
+	default:
+		return fmt.Errorf("unknown logical backup provider %q", t.Provider)
+	}
+	return nil
+}
+
+// logicalBackupStorage returns the storage the operator lists and prunes logical backups in,
+// which is only accessible for S3 and S3-compatible targets. A bucket of the manifest is accessed
+// with the keys of its credentials secret.
+func (c *Cluster) logicalBackupStorage(target logicalBackupTarget) (logicalbackup.Storage, error) {
+	if target.Provider != constants.LogicalBackupProviderS3 {
+		return nil, fmt.Errorf("logical backups stored with the %q provider are not accessible to the operator", target.Provider)
+	}
+	if err := target.validate(); err != nil {
+		return nil, err
+	}
+
+	storage := &logicalbackup.S3Storage{
+		Bucket:          target.Bucket,
+		Region:          c.OpConfig.LogicalBackup.LogicalBackupS3Region,
+		Endpoint:        c.OpConfig.LogicalBackup.LogicalBackupS3Endpoint,
+		AccessKeyID:     c.OpConfig.LogicalBackup.LogicalBackupS3AccessKeyID,
+		SecretAccessKey: c.OpConfig.LogicalBackup.LogicalBackupS3SecretAccessKey,
+	}
+	if target.CredentialsSecret != "" {
+		secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), target.CredentialsSecret, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not get logical backup credentials secret %q: %v", target.CredentialsSecret, err)
+		}
+		storage.AccessKeyID = string(secret.Data[constants.LogicalBackupS3AccessKeyIDKey])
+		storage.SecretAccessKey = string(secret.Data[constants.LogicalBackupS3SecretAccessKeyKey])
+		if storage.AccessKeyID == "" || storage.SecretAccessKey == "" {
+			return nil, fmt.Errorf("logical backup credentials secret %q has no %s and %s", target.CredentialsSecret,
+				constants.LogicalBackupS3AccessKeyIDKey, constants.LogicalBackupS3SecretAccessKeyKey)
+		}
+	}
+	return storage, nil
+}
+
+// logicalBackupPrefix is the prefix the logical backup job uploads the dumps of the cluster to
+func (c *Cluster) logicalBackupPrefix() string {
+	return "spilo/" + c.Name + getBucketScopeSuffix(string(c.Postgresql.GetUID())) + "/logical_backups/"
+}
+
+// logicalBackupRetention returns the retention configured in the operator, overridden by the manifest
+func (c *Cluster) logicalBackupRetention() logicalbackup.Retention {
+	retention := logicalbackup.Retention{
+		Count: c.OpConfig.LogicalBackup.LogicalBackupRetentionCount,
+		Days:  c.OpConfig.LogicalBackup.LogicalBackupRetentionDays,
+	}
+	if override := c.Spec.LogicalBackupRetention; override != nil {
+		if override.Count != nil {
+			retention.Count = *override.Count
+		}
+		if override.Days != nil {
+			retention.Days = *override.Days
+		}
+	}
+	return retention
+}
+
+// LogicalBackups returns the catalog of the dumps of the cluster in the logical backup bucket, the latest first
+func (c *Cluster) LogicalBackups() (*LogicalBackupCatalog, error) {
+	c.specMu.RLock()
+	retention := c.logicalBackupRetention()
+	target := c.logicalBackupTarget()
+	prefix := c.logicalBackupPrefix()
+	c.specMu.RUnlock()
+
+	storage, err := c.logicalBackupStorage(target)
+	if err != nil {
+		return nil, err
+	}
+	dumps, err := storage.ListDumps(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not list logical backups: %v", err)
+	}
+	return &LogicalBackupCatalog{
+		Cluster:   c.Name,
+		Location:  fmt.Sprintf("s3://%s/%s", target.Bucket, prefix),
+		Retention: retention,
+		Dumps:     dumps,
+	}, nil
+}
+
+// pruneLogicalBackups deletes the scheduled dumps of the cluster that exceed the retention
+func (c *Cluster) pruneLogicalBackups(storage logicalbackup.Storage, retention logicalbackup.Retention) error {
+	c.setProcessName("pruning logical backups")
+
+	dumps, err := storage.ListDumps(c.logicalBackupPrefix())
+	if err != nil {
+		return fmt.Errorf("could not list logical backups: %v", err)
+	}
+	for _, dump := range logicalbackup.Expired(dumps, retention, time.Now()) {
+		if err := storage.DeleteDump(dump); err != nil {
+			return fmt.Errorf("could not delete expired logical backup: %v", err)
+		}
+		c.logger.Infof("deleted logical backup %q taken at %s", dump.Location, dump.LastModified.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/logicalbackup"
+)
+
+type mockLogicalBackupStorage struct {
+	dumps   []logicalbackup.Dump
+	prefix  string
+	deleted []string
+}
+
+func (s *mockLogicalBackupStorage) ListDumps(prefix string) ([]logicalbackup.Dump, error) {
+	s.prefix = prefix
+	return s.dumps, nil
+}
+
+func (s *mockLogicalBackupStorage) DeleteDump(dump logicalbackup.Dump) error {
+	s.deleted = append(s.deleted, dump.Name)
+	return nil
+}
+
+func newLogicalBackupTestCluster(retention *acidv1.LogicalBackupRetention) *Cluster {
+	return New(
+		Config{
+			OpConfig: config.Config{
+				LogicalBackup: config.LogicalBackup{
+					LogicalBackupS3Bucket:       "backups",
+					LogicalBackupRetentionCount: 7,
+					LogicalBackupRetentionDays:  30,
+				},
+			},
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default", UID: types.UID("1234")},
+			Spec:       acidv1.PostgresSpec{LogicalBackupRetention: retention},
+		}, logger, eventRecorder)
+}
+
+func TestLogicalBackupRetention(t *testing.T) {
+	testName := "TestLogicalBackupRetention"
+	zero, three := int32(0), int32(3)
+
+	tests := []struct {
+		subTest   string
+		retention *acidv1.LogicalBackupRetention
+		expected  logicalbackup.Retention
+	}{
+		{
+			subTest:  "operator configuration",
+			expected: logicalbackup.Retention{Count: 7, Days: 30},
+		},
+		{
+			subTest:   "count overridden",
+			retention: &acidv1.LogicalBackupRetention{Count: &three},
+			expected:  logicalbackup.Retention{Count: 3, Days: 30},
+		},
+		{
+			subTest:   "age limit disabled",
+			retention: &acidv1.LogicalBackupRetention{Days: &zero},
+			expected:  logicalbackup.Retention{Count: 7, Days: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newLogicalBackupTestCluster(tt.retention)
+		if retention := cluster.logicalBackupRetention(); retention != tt.expected {
+			t.Errorf("%s %s: expected retention %v, got %v", testName, tt.subTest, tt.expected, retention)
+		}
+	}
+}
+
+func TestPruneLogicalBackups(t *testing.T) {
+	testName := "TestPruneLogicalBackups"
+	cluster := newLogicalBackupTestCluster(nil)
+
+	now := time.Now()
+	dumps := make([]logicalbackup.Dump, 0)
+	for day := 0; day < 5; day++ {
+		name := fmt.Sprintf("%d.sql.gz", now.AddDate(0, 0, -day).Unix())
+		dumps = append(dumps, logicalbackup.Dump{Name: name, LastModified: now.AddDate(0, 0, -day), Scheduled: true})
+	}
+	dumps = append(dumps, logicalbackup.Dump{Name: "pre-migration.sql.gz", LastModified: now.AddDate(0, 0, -10)})
+	storage := &mockLogicalBackupStorage{dumps: dumps}
+
+	if err := cluster.pruneLogicalBackups(storage, logicalbackup.Retention{Count: 3}); err != nil {
+		t.Fatalf("%s: could not prune logical backups: %v", testName, err)
+	}
+	if storage.prefix != "spilo/acid-test-cluster/1234/logical_backups/" {
+		t.Errorf("%s: unexpected prefix %q", testName, storage.prefix)
+	}
+	expected := []string{dumps[3].Name, dumps[4].Name}
+	if !reflect.DeepEqual(storage.deleted, expected) {
+		t.Errorf("%s: expected deleted dumps %v, got %v", testName, expected, storage.deleted)
+	}
+}
+
+func TestLogicalBackupTargetValidation(t *testing.T) {
+	testName := "TestLogicalBackupTargetValidation"
+
+	tests := []struct {
+		subTest string
+		target  *acidv1.LogicalBackupTarget
+		err     bool
+	}{
+		{
+			subTest: "s3 bucket of the operator",
+		},
+		{
+			subTest: "s3 bucket of the manifest without credentials",
+			target:  &acidv1.LogicalBackupTarget{Bucket: "team-backups"},
+			err:     true,
+		},
+		{
+			subTest: "gcs without a bucket",
+			target:  &acidv1.LogicalBackupTarget{Provider: "gcs"},
+			err:     true,
+		},
+		{
+			subTest: "azure without a container",
+			target:  &acidv1.LogicalBackupTarget{Provider: "azure", StorageAccount: "backupaccount"},
+			err:     true,
+		},
+		{
+			subTest: "pvc without a claim",
+			target:  &acidv1.LogicalBackupTarget{Provider: "pvc"},
+			err:     true,
+		},
+		{
+			subTest: "pvc",
+			target:  &acidv1.LogicalBackupTarget{Provider: "pvc", ClaimName: "logical-backups"},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newLogicalBackupTestCluster(nil)
+		cluster.Spec.LogicalBackupTarget = tt.target
+		target := cluster.logicalBackupTarget()
+		if err := target.validate(); (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+		}
+
+		// only valid S3 targets can be listed and pruned by the operator
+		_, err := cluster.logicalBackupStorage(target)
+		if isS3 := target.Provider == "s3" && !tt.err; (err == nil) != isS3 {
+			t.Errorf("%s %s: expected storage of the operator %t, got error %v", testName, tt.subTest, isS3, err)
+		}
+	}
+}
@@ -0,0 +1,752 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+const (
+	getPublicationsSQL = `SELECT p.pubname, p.puballtables,
+	 COALESCE(array_agg(t.schemaname || '.' || t.tablename) FILTER (WHERE t.tablename IS NOT NULL), '{}')
+	 FROM pg_catalog.pg_publication p
+	 LEFT JOIN pg_catalog.pg_publication_tables t ON (t.pubname = p.pubname)
+	 GROUP BY p.pubname, p.puballtables;`
+	createPublicationSQL      = `CREATE PUBLICATION %s%s;`
+	alterPublicationTablesSQL = `ALTER PUBLICATION %s SET TABLE %s;`
+	dropPublicationSQL        = `DROP PUBLICATION %s;`
+
+	// subscriptions are stored in a shared catalog and can be queried from any database
+	getSubscriptionsSQL = `SELECT s.subname, d.datname, s.subenabled, s.subconninfo, COALESCE(s.subslotname, ''),
+	 s.subpublications
+	 FROM pg_catalog.pg_subscription s
+	 JOIN pg_catalog.pg_database d ON (d.oid = s.subdbid);`
+	createSubscriptionSQL = `CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s ` +
+		`WITH (create_slot = false, slot_name = %s, copy_data = %t, enabled = %t);`
+	alterSubscriptionConnectionSQL    = `ALTER SUBSCRIPTION %s CONNECTION %s;`
+	alterSubscriptionPublicationSQL   = `ALTER SUBSCRIPTION %s SET PUBLICATION %s WITH (copy_data = %t);`
+	alterSubscriptionNoRefreshSQL     = `ALTER SUBSCRIPTION %s SET PUBLICATION %s WITH (refresh = false);`
+	enableSubscriptionSQL             = `ALTER SUBSCRIPTION %s ENABLE;`
+	disableSubscriptionSQL            = `ALTER SUBSCRIPTION %s DISABLE;`
+	detachSubscriptionSlotSQL         = `ALTER SUBSCRIPTION %s SET (slot_name = NONE);`
+	dropSubscriptionSQL               = `DROP SUBSCRIPTION %s;`
+	getReplicationSlotActiveSQL       = `SELECT active FROM pg_catalog.pg_replication_slots WHERE slot_name = $1;`
+	dropReplicationSlotSQL            = `SELECT pg_catalog.pg_drop_replication_slot($1);`
+	logicalReplicationOutputPlugin    = "pgoutput"
+	logicalReplicationSlotTypeLogical = "logical"
+	// publications and subscriptions were introduced with Postgres 10
+	logicalReplicationMinServerVersion = 100000
+)
+
+var (
+	// slot names only allow lower case letters, numbers and underscores
+	subscriptionNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	tableNameRegexp        = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+)
+
+type currentPublication struct {
+	allTables bool
+	tables    []string
+}
+
+type currentSubscription struct {
+	database     string
+	enabled      bool
+	conninfo     string
+	slotName     string
+	publications []string
+}
+
+// subscriptionTarget holds what a subscription of the manifest resolves to
+type subscriptionTarget struct {
+	conninfo string
+	slotName string
+	// the connection without the password, to show in plans
+	redactedConninfo string
+}
+
+// qualifiedTableName returns the table in the schema.table form, tables without schema are in public
+func qualifiedTableName(table string) string {
+	if strings.Contains(table, ".") {
+		return table
+	}
+	return "public." + table
+}
+
+func quoteTableName(table string) string {
+	parts := strings.SplitN(qualifiedTableName(table), ".", 2)
+	return pq.QuoteIdentifier(parts[0]) + "." + pq.QuoteIdentifier(parts[1])
+}
+
+func publicationTables(publication acidv1.Publication) []string {
+	tables := make([]string, 0, len(publication.Tables))
+	for _, table := range publication.Tables {
+		tables = append(tables, qualifiedTableName(table))
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func quotedNames(names []string, quote func(string) string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quote(name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// conninfoValue quotes a value of a libpq connection string
+func conninfoValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// publicationStatements returns the statements that create the publication or change its tables. A
+// publication of all tables cannot be altered to a publication of some tables, it is recreated instead.
+func publicationStatements(name string, publication acidv1.Publication, current *currentPublication) []string {
+	target := ""
+	if publication.AllTables {
+		target = " FOR ALL TABLES"
+	} else if len(publication.Tables) > 0 {
+		target = " FOR TABLE " + quotedNames(publicationTables(publication), quoteTableName)
+	}
+	quotedName := pq.QuoteIdentifier(name)
+	create := fmt.Sprintf(createPublicationSQL, quotedName, target)
+
+	if current == nil {
+		return []string{create}
+	}
+	if current.allTables != publication.AllTables {
+		return []string{fmt.Sprintf(dropPublicationSQL, quotedName), create}
+	}
+	tables := publicationTables(publication)
+	if !publication.AllTables && len(tables) > 0 && !reflect.DeepEqual(tables, current.tables) {
+		return []string{fmt.Sprintf(alterPublicationTablesSQL, quotedName, quotedNames(tables, quoteTableName))}
+	}
+	return []string{}
+}
+
+// subscriptionStatements returns the statements that create the subscription or bring it to the
+// connection, publications and state of the manifest. Subscriptions do not create their slot, the source
+// cluster reserves it in its Patroni configuration beforehand.
+func subscriptionStatements(name string, subscription acidv1.Subscription, target subscriptionTarget,
+	current *currentSubscription) []string {
+	enabled := subscription.Enabled == nil || *subscription.Enabled
+	copyData := subscription.CopyData == nil || *subscription.CopyData
+	publications := make([]string, len(subscription.Publications))
+	copy(publications, subscription.Publications)
+	sort.Strings(publications)
+	quotedName := pq.QuoteIdentifier(name)
+	quotedPublications := quotedNames(publications, pq.QuoteIdentifier)
+
+	if current == nil {
+		return []string{fmt.Sprintf(createSubscriptionSQL, quotedName, pq.QuoteLiteral(target.conninfo),
+			quotedPublications, pq.QuoteLiteral(target.slotName), copyData, enabled)}
+	}
+
+	statements := make([]string, 0)
+	if current.conninfo != target.conninfo {
+		statements = append(statements, fmt.Sprintf(alterSubscriptionConnectionSQL, quotedName, pq.QuoteLiteral(target.conninfo)))
+	}
+	if enabled && !current.enabled {
+		statements = append(statements, fmt.Sprintf(enableSubscriptionSQL, quotedName))
+	}
+	currentPublications := make([]string, len(current.publications))
+	copy(currentPublications, current.publications)
+	sort.Strings(currentPublications)
+	if !reflect.DeepEqual(publications, currentPublications) {
+		// disabled subscriptions cannot fetch the tables of the new publications
+		if enabled {
+			statements = append(statements, fmt.Sprintf(alterSubscriptionPublicationSQL, quotedName, quotedPublications, copyData))
+		} else {
+			statements = append(statements, fmt.Sprintf(alterSubscriptionNoRefreshSQL, quotedName, quotedPublications))
+		}
+	}
+	if !enabled && current.enabled {
+		statements = append(statements, fmt.Sprintf(disableSubscriptionSQL, quotedName))
+	}
+	return statements
+}
+
+// subscriptionSlotName returns the name of the slot of a subscription in the source cluster, which is
+// unique for the subscribing cluster and tells which subscriptions have been created by the operator
+func subscriptionSlotName(clusterName, name string) string {
+	return strings.Replace(clusterName, "-", "_", -1) + "_" + name
+}
+
+func (c *Cluster) subscriptionSlotName(name string) string {
+	return subscriptionSlotName(c.Name, name)
+}
+
+// subscriberSlots returns the replication slots the subscriptions of the given manifests need in the cluster
+// with the given name. Slots with the same name in the Patroni section of the manifest are left to it.
+func subscriberSlots(clusterName string, manifests []acidv1.Postgresql,
+	patroniSlots map[string]map[string]string) map[string]map[string]string {
+	slots := make(map[string]map[string]string)
+	for _, pg := range manifests {
+		if pg.Name == clusterName {
+			continue
+		}
+		for name, subscription := range pg.Spec.Subscriptions {
+			if subscription.Cluster != clusterName {
+				continue
+			}
+			slotName := subscriptionSlotName(pg.Name, name)
+			if _, exists := patroniSlots[slotName]; exists {
+				continue
+			}
+			slots[slotName] = map[string]string{
+				"type":     logicalReplicationSlotTypeLogical,
+				"database": subscriptionSourceDatabase(subscription),
+				"plugin":   logicalReplicationOutputPlugin,
+			}
+		}
+	}
+	return slots
+}
+
+// subscriberSlotChanges returns the slots to add to or change in the Patroni configuration and the slots
+// reserved earlier that no subscription needs anymore
+func subscriberSlotChanges(desired, current map[string]map[string]string,
+	reserved []string) (map[string]map[string]string, []string) {
+	changes := make(map[string]map[string]string)
+	for name, slot := range desired {
+		if !reflect.DeepEqual(current[name], slot) {
+			changes[name] = slot
+		}
+	}
+	removed := make([]string, 0)
+	for _, name := range reserved {
+		if _, exists := desired[name]; !exists {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return changes, removed
+}
+
+// subscriptionSourceDatabase returns the database of the source cluster, the one of the subscription by default
+func subscriptionSourceDatabase(subscription acidv1.Subscription) string {
+	if subscription.SourceDatabase != "" {
+		return subscription.SourceDatabase
+	}
+	return subscription.Database
+}
+
+// resolveSubscription resolves the connection to the source cluster of a subscription with the credentials
+// of the user from the secrets of that cluster
+func (c *Cluster) resolveSubscription(name string, subscription acidv1.Subscription) (subscriptionTarget, error) {
+	user := subscription.User
+	if user == "" {
+		user = c.OpConfig.SuperUsername
+	}
+	secretName := c.credentialSecretNameForCluster(user, subscription.Cluster)
+	secret, err := c.SecretBackend.Get(c.Namespace, secretName)
+	if err != nil {
+		return subscriptionTarget{}, fmt.Errorf("could not get secret %q of user %q of cluster %q: %v",
+			secretName, user, subscription.Cluster, err)
+	}
+
+	dbname := subscriptionSourceDatabase(subscription)
+	host, port := c.getClusterServiceConnectionParameters(subscription.Cluster)
+	conninfo := func(password string) string {
+		return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=require",
+			conninfoValue(host), conninfoValue(port), conninfoValue(dbname),
+			conninfoValue(string(secret.Data["username"])), conninfoValue(password))
+	}
+
+	return subscriptionTarget{
+		conninfo:         conninfo(string(secret.Data["password"])),
+		slotName:         c.subscriptionSlotName(name),
+		redactedConninfo: conninfo("********"),
+	}, nil
+}
+
+// getClusterMasterPod returns the primary pod of another cluster in the namespace of the cluster
+func (c *Cluster) getClusterMasterPod(clusterName string) (*v1.Pod, error) {
+	lbls := labels.Set{}
+	for k, v := range c.OpConfig.ClusterLabels {
+		lbls[k] = v
+	}
+	lbls[c.OpConfig.ClusterNameLabel] = clusterName
+	lbls[c.OpConfig.PodRoleLabel] = string(Master)
+
+	pods, err := c.KubeClient.Pods(c.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: lbls.String()})
+	if err != nil {
+		return nil, fmt.Errorf("could not get list of pods: %v", err)
+	}
+	if len(pods.Items) != 1 {
+		return nil, fmt.Errorf("expected one master pod of cluster %q, found %d", clusterName, len(pods.Items))
+	}
+	return &pods.Items[0], nil
+}
+
+// checkSourceClusterSlot checks that the source cluster of a subscription has reserved the replication slot
+// of the subscription, which it does in its own sync
+func (c *Cluster) checkSourceClusterSlot(clusterName, slotName string) error {
+	masterPod, err := c.getClusterMasterPod(clusterName)
+	if err != nil {
+		return err
+	}
+	slots, err := c.patroni.GetSlots(masterPod)
+	if err != nil {
+		return fmt.Errorf("could not get replication slots of cluster %q: %v", clusterName, err)
+	}
+	if _, exists := slots[slotName]; !exists {
+		return fmt.Errorf("replication slot %q is not reserved in cluster %q yet", slotName, clusterName)
+	}
+	return nil
+}
+
+// subscriberSlotsSync holds the replication slots of subscriptions to the cluster: the slots the subscriptions
+// need, the changes of the Patroni configuration, where a nil slot is removed, and the reserved slots to drop
+type subscriberSlotsSync struct {
+	masterPod *v1.Pod
+	desired   map[string]map[string]string
+	current   map[string]map[string]string
+	changes   map[string]map[string]string
+	removed   []string
+}
+
+// getSubscriberSlots compares the slots the subscriptions of other clusters need with the slots in the
+// Patroni configuration of the cluster, it returns nil if no slots are needed or reserved
+func (c *Cluster) getSubscriberSlots() (*subscriberSlotsSync, error) {
+	reserved := c.Status.SubscriptionSlots
+	manifests, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(c.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list clusters: %v", err)
+	}
+	desired := subscriberSlots(c.Name, manifests.Items, c.Spec.Patroni.Slots)
+	if len(desired) == 0 && len(reserved) == 0 {
+		return nil, nil
+	}
+
+	masterPod, err := c.getClusterMasterPod(c.Name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := c.patroni.GetSlots(masterPod)
+	if err != nil {
+		return nil, fmt.Errorf("could not get replication slots: %v", err)
+	}
+	changes, removed := subscriberSlotChanges(desired, current, reserved)
+	for _, name := range removed {
+		if _, exists := current[name]; exists {
+			changes[name] = nil
+		}
+	}
+	return &subscriberSlotsSync{
+		masterPod: masterPod,
+		desired:   desired,
+		current:   current,
+		changes:   changes,
+		removed:   removed,
+	}, nil
+}
+
+// syncSubscriberSlots reserves the replication slots of the subscriptions of other clusters to this cluster
+// as permanent slots in its Patroni configuration, so that Patroni creates them on the primary and keeps
+// them across failovers. The cluster owns these slots: once the subscription or the subscribing cluster is
+// gone, it removes the slot from the configuration and drops it. The reserved slots are kept in the status.
+func (c *Cluster) syncSubscriberSlots() error {
+	slots, err := c.getSubscriberSlots()
+	if err != nil || slots == nil {
+		return err
+	}
+
+	if len(slots.changes) > 0 {
+		for name, slot := range slots.changes {
+			if slot == nil {
+				c.logger.Infof("removing replication slot %q of a dropped subscription", name)
+			} else {
+				c.logger.Infof("reserving replication slot %q for a subscription", name)
+			}
+		}
+		if err := c.patroni.SetSlots(slots.masterPod, slots.changes); err != nil {
+			return fmt.Errorf("could not set replication slots: %v", err)
+		}
+	}
+
+	// a slot is only forgotten once it is dropped, a slot still in use is dropped by a later sync
+	stillReserved := make([]string, 0, len(slots.desired))
+	for name := range slots.desired {
+		stillReserved = append(stillReserved, name)
+	}
+	if len(slots.removed) > 0 {
+		if err := c.initDbConn(); err != nil {
+			return fmt.Errorf("could not init database connection: %v", err)
+		}
+		for _, name := range slots.removed {
+			if err := c.dropReplicationSlot(name); err != nil {
+				c.logger.Warningf("could not drop replication slot %q: %v", name, err)
+				stillReserved = append(stillReserved, name)
+			}
+		}
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}
+	sort.Strings(stillReserved)
+	c.updateSubscriptionSlotsStatus(stillReserved)
+	return nil
+}
+
+// dropReplicationSlot drops a slot on the primary unless it is gone already.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) dropReplicationSlot(name string) error {
+	var active bool
+	err := c.pgDb.QueryRow(getReplicationSlotActiveSQL, name).Scan(&active)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not query database: %v", err)
+	}
+	if active {
+		return fmt.Errorf("replication slot is still in use")
+	}
+	if _, err := c.pgDb.Exec(dropReplicationSlotSQL, name); err != nil {
+		return err
+	}
+	c.logger.Infof("dropped replication slot %q", name)
+	return nil
+}
+
+func (c *Cluster) updateSubscriptionSlotsStatus(slots []string) {
+	if len(slots) == 0 && len(c.Status.SubscriptionSlots) == 0 ||
+		reflect.DeepEqual(slots, c.Status.SubscriptionSlots) {
+		return
+	}
+	pg, err := c.patchStatus(map[string]interface{}{"subscriptionSlots": slots})
+	if err != nil {
+		c.logger.Warningf("could not update subscription slots status: %v", err)
+		return
+	}
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
+
+// logicalReplicationChanges holds the publications and subscriptions of the manifest grouped by their
+// database, together with the subscriptions created by the operator that have been removed from the manifest
+type logicalReplicationChanges struct {
+	publications         map[string][]string
+	subscriptions        map[string][]string
+	removedSubscriptions map[string][]string
+	targets              map[string]subscriptionTarget
+	currentSubscriptions map[string]*currentSubscription
+}
+
+// databases returns the databases with publications or subscriptions to sync, in alphabetical order
+func (changes *logicalReplicationChanges) databases() []string {
+	datnames := make([]string, 0)
+	for _, names := range []map[string][]string{changes.publications, changes.subscriptions, changes.removedSubscriptions} {
+		for datname := range names {
+			datnames = append(datnames, datname)
+		}
+	}
+	sort.Strings(datnames)
+
+	unique := make([]string, 0, len(datnames))
+	for i, datname := range datnames {
+		if i == 0 || datnames[i-1] != datname {
+			unique = append(unique, datname)
+		}
+	}
+	return unique
+}
+
+// getLogicalReplicationChanges resolves the publications and subscriptions of the manifest against the
+// subscriptions of the cluster. Publications and subscriptions that cannot be synced are returned as errors,
+// the changes are nil if there is nothing to sync.
+func (c *Cluster) getLogicalReplicationChanges() (*logicalReplicationChanges, []string, error) {
+	errs := make([]string, 0)
+	// the names end up in statements and slot names, the manifest may not have been validated on admission
+	if err := validateLogicalReplication(c.Name, &c.Spec); err != nil {
+		return nil, append(errs, err.Error()), nil
+	}
+
+	if err := c.initDbConn(); err != nil {
+		return nil, errs, fmt.Errorf("could not init database connection: %v", err)
+	}
+	serverVersion, err := c.getServerVersion()
+	currentSubscriptions := make(map[string]*currentSubscription)
+	if err == nil && serverVersion >= logicalReplicationMinServerVersion {
+		currentSubscriptions, err = c.getSubscriptions()
+	}
+	if err2 := c.closeDbConn(); err2 != nil {
+		c.logger.Errorf("could not close database connection: %v", err2)
+	}
+	if err != nil {
+		return nil, errs, fmt.Errorf("could not get current subscriptions: %v", err)
+	}
+	if serverVersion < logicalReplicationMinServerVersion {
+		if len(c.Spec.Publications) > 0 || len(c.Spec.Subscriptions) > 0 {
+			errs = append(errs, "publications and subscriptions require Postgres 10 or newer")
+		}
+		return nil, errs, nil
+	}
+
+	changes := &logicalReplicationChanges{
+		publications:         make(map[string][]string),
+		subscriptions:        make(map[string][]string),
+		removedSubscriptions: make(map[string][]string),
+		targets:              make(map[string]subscriptionTarget),
+		currentSubscriptions: currentSubscriptions,
+	}
+	for name, publication := range c.Spec.Publications {
+		changes.publications[publication.Database] = append(changes.publications[publication.Database], name)
+	}
+	for name, subscription := range c.Spec.Subscriptions {
+		if current, exists := currentSubscriptions[name]; exists && current.database != subscription.Database {
+			errs = append(errs, fmt.Sprintf("subscription %q already exists in database %q", name, current.database))
+			continue
+		}
+		target, err := c.resolveSubscription(name, subscription)
+		if _, exists := currentSubscriptions[name]; err == nil && !exists {
+			err = c.checkSourceClusterSlot(subscription.Cluster, target.slotName)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("could not prepare subscription %q: %v", name, err))
+			continue
+		}
+		changes.targets[name] = target
+		changes.subscriptions[subscription.Database] = append(changes.subscriptions[subscription.Database], name)
+	}
+	for name, current := range currentSubscriptions {
+		if _, exists := c.Spec.Subscriptions[name]; exists || current.slotName != c.subscriptionSlotName(name) {
+			continue
+		}
+		changes.removedSubscriptions[current.database] = append(changes.removedSubscriptions[current.database], name)
+	}
+	if len(c.Spec.Publications) == 0 && len(c.Spec.Subscriptions) == 0 && len(changes.removedSubscriptions) == 0 {
+		return nil, errs, nil
+	}
+	for _, names := range []map[string][]string{changes.publications, changes.subscriptions, changes.removedSubscriptions} {
+		for _, databaseNames := range names {
+			sort.Strings(databaseNames)
+		}
+	}
+	return changes, errs, nil
+}
+
+// syncLogicalReplication creates and changes the publications and subscriptions of the manifest and
+// reserves the slots of the subscriptions of other clusters to this one. Publications removed from the
+// manifest are kept, subscriptions created by the operator are dropped and their slot is dropped by the
+// sync of the source cluster. The errors of single objects do not stop the sync of the others and are
+// returned together.
+func (c *Cluster) syncLogicalReplication() error {
+	c.setProcessName("syncing logical replication")
+	errs := make([]string, 0)
+
+	if err := c.syncSubscriberSlots(); err != nil {
+		errs = append(errs, fmt.Sprintf("could not sync replication slots of subscriptions: %v", err))
+	}
+	changes, changeErrs, err := c.getLogicalReplicationChanges()
+	errs = append(errs, changeErrs...)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		return logicalReplicationError(errs)
+	}
+
+	// the work is grouped by database to connect to each one only once
+	for _, datname := range changes.databases() {
+		if err := c.initDbConnWithName(datname); err != nil {
+			errs = append(errs, fmt.Sprintf("could not init connection to database %q: %v", datname, err))
+			continue
+		}
+		errs = append(errs, c.syncDatabasePublications(datname, changes.publications[datname])...)
+		for _, name := range changes.subscriptions[datname] {
+			if err := c.syncSubscription(name, changes.targets[name], changes.currentSubscriptions[name]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		for _, name := range changes.removedSubscriptions[datname] {
+			if err := c.dropSubscription(name, changes.currentSubscriptions[name]); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}
+
+	return logicalReplicationError(errs)
+}
+
+func logicalReplicationError(errs []string) error {
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// syncDatabasePublications syncs the given publications of the manifest in the database of the connection
+func (c *Cluster) syncDatabasePublications(datname string, names []string) []string {
+	errs := make([]string, 0)
+	if len(names) == 0 {
+		return errs
+	}
+	currentPublications, err := c.getPublications()
+	if err != nil {
+		return append(errs, fmt.Sprintf("could not get publications of database %q: %v", datname, err))
+	}
+
+	changes := c.publicationChanges(names, currentPublications)
+	for _, name := range names {
+		statements := changes[name]
+		if len(statements) == 0 {
+			continue
+		}
+		c.logger.Infof("syncing publication %q in database %q", name, datname)
+		if err := c.execStatements(statements); err != nil {
+			errs = append(errs, fmt.Sprintf("could not sync publication %q: %v", name, err))
+			continue
+		}
+		c.recordEvent(v1.EventTypeNormal, "LogicalReplication", "Synced publication %q in database %q", name, datname)
+	}
+	return errs
+}
+
+// publicationChanges returns the statements that bring the given publications of the manifest to the
+// manifest, for the publications that need a change
+func (c *Cluster) publicationChanges(names []string, currentPublications map[string]currentPublication) map[string][]string {
+	changes := make(map[string][]string)
+	for _, name := range names {
+		var current *currentPublication
+		if publication, exists := currentPublications[name]; exists {
+			current = &publication
+		}
+		if statements := publicationStatements(name, c.Spec.Publications[name], current); len(statements) > 0 {
+			changes[name] = statements
+		}
+	}
+	return changes
+}
+
+// subscriptionChanges returns the statements that bring a subscription of the manifest to the manifest.
+// Subscriptions with the name of a subscription to another slot, not created by the operator, are not touched.
+func (c *Cluster) subscriptionChanges(name string, target subscriptionTarget, current *currentSubscription) ([]string, error) {
+	if current != nil && current.slotName != target.slotName {
+		return nil, fmt.Errorf("subscription %q already exists with the replication slot %q", name, current.slotName)
+	}
+	return subscriptionStatements(name, c.Spec.Subscriptions[name], target, current), nil
+}
+
+// syncSubscription creates or alters a subscription of the manifest in the database of the connection
+func (c *Cluster) syncSubscription(name string, target subscriptionTarget, current *currentSubscription) error {
+	subscription := c.Spec.Subscriptions[name]
+	statements, err := c.subscriptionChanges(name, target, current)
+	if err != nil {
+		return err
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+	c.logger.Infof("syncing subscription %q in database %q to cluster %q", name, subscription.Database, subscription.Cluster)
+	if err := c.execStatements(statements); err != nil {
+		return fmt.Errorf("could not sync subscription %q: %v", name, err)
+	}
+	c.recordEvent(v1.EventTypeNormal, "LogicalReplication", "Synced subscription %q to cluster %q", name, subscription.Cluster)
+	return nil
+}
+
+// dropSubscription drops a subscription removed from the manifest in the database of the connection. The
+// subscription is detached from its slot, which belongs to the source cluster: its sync removes the slot
+// from its Patroni configuration and drops it, otherwise Patroni would recreate a slot dropped from here.
+func (c *Cluster) dropSubscription(name string, current *currentSubscription) error {
+	c.logger.Infof("dropping subscription %q removed from the manifest in database %q", name, current.database)
+
+	if err := c.execStatements(dropSubscriptionStatements(name)); err != nil {
+		return fmt.Errorf("could not drop subscription %q: %v", name, err)
+	}
+	c.recordEvent(v1.EventTypeNormal, "LogicalReplication", "Dropped subscription %q", name)
+	return nil
+}
+
+// dropSubscriptionStatements returns the statements that detach a subscription from its slot and drop it
+func dropSubscriptionStatements(name string) []string {
+	quotedName := pq.QuoteIdentifier(name)
+	return []string{
+		fmt.Sprintf(disableSubscriptionSQL, quotedName),
+		fmt.Sprintf(detachSubscriptionSlotSQL, quotedName),
+		fmt.Sprintf(dropSubscriptionSQL, quotedName),
+	}
+}
+
+func (c *Cluster) execStatements(statements []string) error {
+	for _, statement := range statements {
+		if _, err := c.pgDb.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPublications returns the publications of the database of the connection.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) getPublications() (publications map[string]currentPublication, err error) {
+	var rows *sql.Rows
+
+	if rows, err = c.pgDb.Query(getPublicationsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = fmt.Errorf("error when closing query cursor: %v", err2)
+		}
+	}()
+
+	publications = make(map[string]currentPublication)
+	for rows.Next() {
+		var name string
+		var publication currentPublication
+
+		if err = rows.Scan(&name, &publication.allTables, pq.Array(&publication.tables)); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		sort.Strings(publication.tables)
+		publications[name] = publication
+	}
+	return publications, nil
+}
+
+// getSubscriptions returns the subscriptions of all databases of the cluster.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) getSubscriptions() (subscriptions map[string]*currentSubscription, err error) {
+	var rows *sql.Rows
+
+	if rows, err = c.pgDb.Query(getSubscriptionsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = fmt.Errorf("error when closing query cursor: %v", err2)
+		}
+	}()
+
+	subscriptions = make(map[string]*currentSubscription)
+	for rows.Next() {
+		var name string
+		subscription := &currentSubscription{}
+
+		if err = rows.Scan(&name, &subscription.database, &subscription.enabled, &subscription.conninfo,
+			&subscription.slotName, pq.Array(&subscription.publications)); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		subscriptions[name] = subscription
+	}
+	return subscriptions, nil
+}
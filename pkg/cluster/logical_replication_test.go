@@ -0,0 +1,205 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+func TestPublicationStatements(t *testing.T) {
+	testName := "TestPublicationStatements"
+	tests := []struct {
+		subTest     string
+		publication acidv1.Publication
+		current     *currentPublication
+		expected    []string
+	}{
+		{
+			subTest:     "new publication of tables",
+			publication: acidv1.Publication{Tables: []string{"sales.orders", "customers"}},
+			expected:    []string{`CREATE PUBLICATION "foo" FOR TABLE "public"."customers", "sales"."orders";`},
+		},
+		{
+			subTest:     "new publication of all tables",
+			publication: acidv1.Publication{AllTables: true},
+			expected:    []string{`CREATE PUBLICATION "foo" FOR ALL TABLES;`},
+		},
+		{
+			subTest:     "publication with the same tables",
+			publication: acidv1.Publication{Tables: []string{"sales.orders", "customers"}},
+			current:     &currentPublication{tables: []string{"public.customers", "sales.orders"}},
+			expected:    []string{},
+		},
+		{
+			subTest:     "publication with other tables",
+			publication: acidv1.Publication{Tables: []string{"customers"}},
+			current:     &currentPublication{tables: []string{"public.customers", "sales.orders"}},
+			expected:    []string{`ALTER PUBLICATION "foo" SET TABLE "public"."customers";`},
+		},
+		{
+			subTest:     "publication of all tables changed to some tables",
+			publication: acidv1.Publication{Tables: []string{"customers"}},
+			current:     &currentPublication{allTables: true, tables: []string{}},
+			expected: []string{
+				`DROP PUBLICATION "foo";`,
+				`CREATE PUBLICATION "foo" FOR TABLE "public"."customers";`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		statements := publicationStatements("foo", tt.publication, tt.current)
+		if !reflect.DeepEqual(statements, tt.expected) {
+			t.Errorf("%s %s: expected statements %v, got %v", testName, tt.subTest, tt.expected, statements)
+		}
+	}
+}
+
+func TestSubscriptionStatements(t *testing.T) {
+	testName := "TestSubscriptionStatements"
+	disabled := false
+	target := subscriptionTarget{
+		conninfo: "host='acid-legacy' port='5432' dbname='shop' user='postgres' password='secret' sslmode=require",
+		slotName: "acid_new_foo",
+	}
+	tests := []struct {
+		subTest      string
+		subscription acidv1.Subscription
+		current      *currentSubscription
+		expected     []string
+	}{
+		{
+			subTest:      "new subscription",
+			subscription: acidv1.Subscription{Publications: []string{"orders", "customers"}},
+			expected: []string{`CREATE SUBSCRIPTION "foo" CONNECTION 'host=''acid-legacy'' port=''5432'' dbname=''shop'' ` +
+				`user=''postgres'' password=''secret'' sslmode=require' PUBLICATION "customers", "orders" ` +
+				`WITH (create_slot = false, slot_name = 'acid_new_foo', copy_data = true, enabled = true);`},
+		},
+		{
+			subTest:      "unchanged subscription",
+			subscription: acidv1.Subscription{Publications: []string{"orders", "customers"}},
+			current: &currentSubscription{enabled: true, conninfo: target.conninfo, slotName: target.slotName,
+				publications: []string{"customers", "orders"}},
+			expected: []string{},
+		},
+		{
+			subTest:      "subscription with new credentials and publications",
+			subscription: acidv1.Subscription{Publications: []string{"orders"}},
+			current: &currentSubscription{enabled: true, conninfo: "host='acid-legacy'", slotName: target.slotName,
+				publications: []string{"customers", "orders"}},
+			expected: []string{
+				`ALTER SUBSCRIPTION "foo" CONNECTION 'host=''acid-legacy'' port=''5432'' dbname=''shop'' ` +
+					`user=''postgres'' password=''secret'' sslmode=require';`,
+				`ALTER SUBSCRIPTION "foo" SET PUBLICATION "orders" WITH (copy_data = true);`,
+			},
+		},
+		{
+			subTest:      "disabled subscription",
+			subscription: acidv1.Subscription{Publications: []string{"orders"}, Enabled: &disabled},
+			current: &currentSubscription{enabled: true, conninfo: target.conninfo, slotName: target.slotName,
+				publications: []string{"customers"}},
+			expected: []string{
+				`ALTER SUBSCRIPTION "foo" SET PUBLICATION "orders" WITH (refresh = false);`,
+				`ALTER SUBSCRIPTION "foo" DISABLE;`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		statements := subscriptionStatements("foo", tt.subscription, target, tt.current)
+		if !reflect.DeepEqual(statements, tt.expected) {
+			t.Errorf("%s %s: expected statements %v, got %v", testName, tt.subTest, tt.expected, statements)
+		}
+	}
+}
+
+func TestSubscriberSlots(t *testing.T) {
+	testName := "TestSubscriberSlots"
+	subscriber := func(name string, subscriptions map[string]acidv1.Subscription) acidv1.Postgresql {
+		pg := acidv1.Postgresql{Spec: acidv1.PostgresSpec{Subscriptions: subscriptions}}
+		pg.Name = name
+		return pg
+	}
+	manifests := []acidv1.Postgresql{
+		subscriber("acid-reporting", map[string]acidv1.Subscription{
+			"orders":    {Database: "reports", SourceDatabase: "shop", Cluster: "acid-shop"},
+			"customers": {Database: "crm", Cluster: "acid-shop"},
+			"invoices":  {Database: "reports", Cluster: "acid-billing"},
+		}),
+		subscriber("acid-archive", map[string]acidv1.Subscription{
+			"orders": {Database: "shop", Cluster: "acid-shop"},
+		}),
+		subscriber("acid-shop", map[string]acidv1.Subscription{
+			"loop": {Database: "shop", Cluster: "acid-shop"},
+		}),
+	}
+	patroniSlots := map[string]map[string]string{
+		"acid_archive_orders": {"type": "logical", "database": "shop", "plugin": "wal2json"},
+	}
+
+	expected := map[string]map[string]string{
+		"acid_reporting_orders":    {"type": "logical", "database": "shop", "plugin": "pgoutput"},
+		"acid_reporting_customers": {"type": "logical", "database": "crm", "plugin": "pgoutput"},
+	}
+	if slots := subscriberSlots("acid-shop", manifests, patroniSlots); !reflect.DeepEqual(slots, expected) {
+		t.Errorf("%s: expected slots %v, got %v", testName, expected, slots)
+	}
+}
+
+func TestSubscriberSlotChanges(t *testing.T) {
+	testName := "TestSubscriberSlotChanges"
+	slot := map[string]string{"type": "logical", "database": "shop", "plugin": "pgoutput"}
+	otherSlot := map[string]string{"type": "logical", "database": "crm", "plugin": "pgoutput"}
+
+	tests := []struct {
+		subTest         string
+		desired         map[string]map[string]string
+		current         map[string]map[string]string
+		reserved        []string
+		expectedChanges map[string]map[string]string
+		expectedRemoved []string
+	}{
+		{
+			subTest:         "new subscription",
+			desired:         map[string]map[string]string{"acid_reporting_orders": slot},
+			current:         map[string]map[string]string{},
+			expectedChanges: map[string]map[string]string{"acid_reporting_orders": slot},
+			expectedRemoved: []string{},
+		},
+		{
+			subTest:         "slot already reserved",
+			desired:         map[string]map[string]string{"acid_reporting_orders": slot},
+			current:         map[string]map[string]string{"acid_reporting_orders": slot},
+			reserved:        []string{"acid_reporting_orders"},
+			expectedChanges: map[string]map[string]string{},
+			expectedRemoved: []string{},
+		},
+		{
+			subTest:         "source database changed",
+			desired:         map[string]map[string]string{"acid_reporting_orders": otherSlot},
+			current:         map[string]map[string]string{"acid_reporting_orders": slot},
+			reserved:        []string{"acid_reporting_orders"},
+			expectedChanges: map[string]map[string]string{"acid_reporting_orders": otherSlot},
+			expectedRemoved: []string{},
+		},
+		{
+			subTest:         "subscriptions dropped",
+			desired:         map[string]map[string]string{},
+			current:         map[string]map[string]string{"acid_reporting_orders": slot, "manual": slot},
+			reserved:        []string{"acid_reporting_orders", "acid_archive_orders"},
+			expectedChanges: map[string]map[string]string{},
+			expectedRemoved: []string{"acid_archive_orders", "acid_reporting_orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		changes, removed := subscriberSlotChanges(tt.desired, tt.current, tt.reserved)
+		if !reflect.DeepEqual(changes, tt.expectedChanges) {
+			t.Errorf("%s %s: expected changes %v, got %v", testName, tt.subTest, tt.expectedChanges, changes)
+		}
+		if !reflect.DeepEqual(removed, tt.expectedRemoved) {
+			t.Errorf("%s %s: expected removed slots %v, got %v", testName, tt.subTest, tt.expectedRemoved, removed)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"time"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+// Disruptive actions that are only executed within the maintenance windows of a cluster.
+const (
+	maintenanceRollingUpdate       = "rolling update of pods"
+	maintenanceReplaceStatefulSet  = "replacement of the statefulset"
+	maintenanceRestartParameters   = "change of Postgres parameters requiring a restart"
+	maintenanceScaleDownSwitchover = "switchover before removing the master pod with a scale down"
+)
+
+// isInMaintenanceWindow checks if the cluster is allowed to be disrupted right now.
+func (c *Cluster) isInMaintenanceWindow() bool {
+	return acidv1.IsInMaintenanceWindow(c.Spec.MaintenanceWindows, time.Now())
+}
+
+// allowDisruptiveAction reports whether an action that interrupts client connections can be executed now.
+// Outside of the maintenance windows the action is postponed and remembered to be reported in the status.
+func (c *Cluster) allowDisruptiveAction(action string) bool {
+	if c.isInMaintenanceWindow() {
+		return true
+	}
+
+	c.logger.Infof("postponing %s until the next maintenance window", action)
+	for _, pending := range c.pendingMaintenance {
+		if pending == action {
+			return false
+		}
+	}
+	c.pendingMaintenance = append(c.pendingMaintenance, action)
+	return false
+}
@@ -0,0 +1,201 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	maintenanceMajorVersionUpgrade = "major version upgrade"
+
+	// Spilo ships a script that runs pg_upgrade on the master and rolls back on failures before the point of no return
+	upgradeScript         = "/scripts/inplace_upgrade.py"
+	upgradeCommandPattern = "PGVERSION=%s /usr/bin/python3 %s %d 2>&1"
+	dataVersionFile       = constants.PostgresDataPath + "/data/PG_VERSION"
+)
+
+// isMajorVersionUpgrade checks if moving from the running to the desired version is an upgrade.
+// Downgrades are not supported by pg_upgrade.
+func isMajorVersionUpgrade(runningVersion, desiredVersion string) (bool, error) {
+	running, err := strconv.ParseFloat(runningVersion, 64)
+	if err != nil {
+		return false, fmt.Errorf("could not parse running version %q: %v", runningVersion, err)
+	}
+	desired, err := strconv.ParseFloat(desiredVersion, 64)
+	if err != nil {
+		return false, fmt.Errorf("could not parse desired version %q: %v", desiredVersion, err)
+	}
+	return desired > running, nil
+}
+
+// failedMajorVersionUpgrade tells whether the upgrade from the running to the desired version has failed before.
+// Such an upgrade is not retried on its own: the manifest has to request the running version again, which
+// resets the upgrade status, before the upgrade can be requested anew.
+func failedMajorVersionUpgrade(upgrade *acidv1.MajorVersionUpgradeStatus, runningVersion, desiredVersion string) bool {
+	return upgrade != nil && upgrade.Phase == acidv1.UpgradePhaseFailed &&
+		upgrade.FromVersion == runningVersion && upgrade.ToVersion == desiredVersion
+}
+
+// majorVersionUpgrade upgrades the cluster from the running to the desired Postgres version in place.
+// pg_upgrade runs in the master pod, afterwards all pods are recreated to start with the new binaries.
+func (c *Cluster) majorVersionUpgrade(runningVersion, desiredVersion string) error {
+	if failedMajorVersionUpgrade(c.Status.MajorVersionUpgrade, runningVersion, desiredVersion) {
+		c.logger.Warningf("major version upgrade from %s to %s has failed before, set the version back to %s "+
+			"to reset the upgrade and request version %s again to retry it", runningVersion, desiredVersion,
+			runningVersion, desiredVersion)
+		return nil
+	}
+
+	isUpgrade, err := isMajorVersionUpgrade(runningVersion, desiredVersion)
+	if err != nil {
+		return err
+	}
+	if !isUpgrade {
+		c.logger.Warningf("postgresql version change(%q -> %q) has no effect: downgrades are not supported",
+			runningVersion, desiredVersion)
+		return nil
+	}
+
+	if !c.allowDisruptiveAction(maintenanceMajorVersionUpgrade) {
+		return nil
+	}
+
+	c.setProcessName("upgrading Postgres from version %s to %s", runningVersion, desiredVersion)
+	c.logger.Infof("starting major version upgrade from %s to %s", runningVersion, desiredVersion)
+	c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseChecking, "")
+
+	masterPod, upgraded, err := c.checkMajorVersionUpgrade(desiredVersion)
+	if err != nil {
+		// nothing has been changed yet, so the cluster simply keeps running the old version
+		c.logger.Warningf("pre-upgrade checks failed, keeping Postgres version %s: %v", runningVersion, err)
+		c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseCheckFailed, err.Error())
+		return nil
+	}
+
+	if !upgraded {
+		c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseUpgrading, "")
+		podName := util.NameFromMeta(masterPod.ObjectMeta)
+		command := fmt.Sprintf(upgradeCommandPattern, desiredVersion, upgradeScript, c.getNumberOfInstances(&c.Spec))
+		c.logger.Infof("running pg_upgrade in the master pod %q", podName)
+		output, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", command)
+		if err != nil {
+			c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseFailed, err.Error())
+			return fmt.Errorf("could not run pg_upgrade in the master pod %q: %v", podName, err)
+		}
+		c.logger.Debugf("pg_upgrade output: %s", output)
+	} else {
+		c.logger.Infof("data directory has already been upgraded to version %s", desiredVersion)
+	}
+
+	// the statefulset has to point to the new binaries before any pod restarts
+	c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseRecreatingPods, "")
+	c.Spec.PostgresqlParam.PgVersion = desiredVersion
+	desiredSS, err := c.generateStatefulSet(&c.Spec)
+	if err != nil {
+		return fmt.Errorf("could not generate statefulset: %v", err)
+	}
+	if err := c.updateStatefulSet(desiredSS); err != nil {
+		return fmt.Errorf("could not update statefulset: %v", err)
+	}
+	if err := c.recreatePods(); err != nil {
+		c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseFailed, err.Error())
+		return fmt.Errorf("could not recreate pods: %v", err)
+	}
+
+	c.setMajorVersionUpgradeStatus(runningVersion, desiredVersion, acidv1.UpgradePhaseFinished, "")
+	c.logger.Infof("major version upgrade from %s to %s has finished", runningVersion, desiredVersion)
+	return nil
+}
+
+// checkMajorVersionUpgrade verifies the cluster can be upgraded and returns its master pod.
+// It also reports if the data directory of the master already has the desired version, e.g. after
+// a previous upgrade was interrupted before the statefulset was updated.
+func (c *Cluster) checkMajorVersionUpgrade(desiredVersion string) (*v1.Pod, bool, error) {
+	pods, err := c.listPods()
+	if err != nil {
+		return nil, false, err
+	}
+	if expected := int(c.getNumberOfInstances(&c.Spec)); len(pods) != expected {
+		return nil, false, fmt.Errorf("expected %d pods, found %d", expected, len(pods))
+	}
+
+	var masterPod *v1.Pod
+	for i, pod := range pods {
+		if !podIsReady(&pod) {
+			return nil, false, fmt.Errorf("pod %q is not ready", util.NameFromMeta(pod.ObjectMeta))
+		}
+		if PostgresRole(pod.Labels[c.OpConfig.PodRoleLabel]) == Master {
+			masterPod = &pods[i]
+		}
+	}
+	if masterPod == nil {
+		return nil, false, fmt.Errorf("no master pod found")
+	}
+
+	podName := util.NameFromMeta(masterPod.ObjectMeta)
+	dataVersion, err := c.ExecCommand(&podName, "cat", dataVersionFile)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read the version of the data directory: %v", err)
+	}
+	if strings.TrimSpace(dataVersion) == desiredVersion {
+		return masterPod, true, nil
+	}
+
+	binDir := fmt.Sprintf(pgBinariesLocationTemplate, desiredVersion)
+	if _, err := c.ExecCommand(&podName, "test", "-x", binDir+"/pg_upgrade"); err != nil {
+		return nil, false, fmt.Errorf("image does not contain binaries of Postgres %s: %v", desiredVersion, err)
+	}
+	if _, err := c.ExecCommand(&podName, "test", "-f", upgradeScript); err != nil {
+		return nil, false, fmt.Errorf("image does not support in-place upgrades: %v", err)
+	}
+
+	return masterPod, false, nil
+}
+
+func podIsReady(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setMajorVersionUpgradeStatus reports the progress of the major version upgrade in the cluster status.
+func (c *Cluster) setMajorVersionUpgradeStatus(fromVersion, toVersion, phase, message string) {
+	c.updateMajorVersionUpgradeStatus(&acidv1.MajorVersionUpgradeStatus{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Phase:       phase,
+		Message:     message,
+	})
+}
+
+// resetMajorVersionUpgradeStatus removes an unsuccessful upgrade from the status once the manifest
+// requests the running version again, so the upgrade can be retried.
+func (c *Cluster) resetMajorVersionUpgradeStatus() {
+	if upgrade := c.Status.MajorVersionUpgrade; upgrade != nil && upgrade.Phase != acidv1.UpgradePhaseFinished {
+		c.updateMajorVersionUpgradeStatus(nil)
+	}
+}
+
+func (c *Cluster) updateMajorVersionUpgradeStatus(upgrade *acidv1.MajorVersionUpgradeStatus) {
+	pg, err := c.patchStatus(map[string]interface{}{"majorVersionUpgrade": upgrade})
+	if err != nil {
+		c.logger.Warningf("could not update major version upgrade status: %v", err)
+		return
+	}
+	// only take over the status, the spec in memory may be adjusted by the ongoing sync
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
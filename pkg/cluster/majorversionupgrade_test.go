@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func TestIsMajorVersionUpgrade(t *testing.T) {
+	testName := "TestIsMajorVersionUpgrade"
+	tests := []struct {
+		subTest        string
+		runningVersion string
+		desiredVersion string
+		expected       bool
+		err            bool
+	}{
+		{
+			subTest:        "upgrade from version with decimal point",
+			runningVersion: "9.6",
+			desiredVersion: "10",
+			expected:       true,
+		},
+		{
+			subTest:        "upgrade to the next major version",
+			runningVersion: "11",
+			desiredVersion: "12",
+			expected:       true,
+		},
+		{
+			subTest:        "downgrade is not an upgrade",
+			runningVersion: "12",
+			desiredVersion: "11",
+			expected:       false,
+		},
+		{
+			subTest:        "same version is not an upgrade",
+			runningVersion: "12",
+			desiredVersion: "12",
+			expected:       false,
+		},
+		{
+			subTest:        "invalid desired version",
+			runningVersion: "12",
+			desiredVersion: "twelve",
+			err:            true,
+		},
+	}
+
+	for _, tt := range tests {
+		isUpgrade, err := isMajorVersionUpgrade(tt.runningVersion, tt.desiredVersion)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: Unexpected error: %v", testName, tt.subTest, err)
+		}
+		if isUpgrade != tt.expected {
+			t.Errorf("%s %s: Expected %t, have %t instead", testName, tt.subTest, tt.expected, isUpgrade)
+		}
+	}
+}
+
+func TestPodIsReady(t *testing.T) {
+	testName := "TestPodIsReady"
+	tests := []struct {
+		subTest  string
+		pod      v1.Pod
+		expected bool
+	}{
+		{
+			subTest: "running and ready pod",
+			pod: v1.Pod{Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			}},
+			expected: true,
+		},
+		{
+			subTest: "running pod that is not ready",
+			pod: v1.Pod{Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}},
+			}},
+			expected: false,
+		},
+		{
+			subTest:  "pending pod",
+			pod:      v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if ready := podIsReady(&tt.pod); ready != tt.expected {
+			t.Errorf("%s %s: Expected %t, have %t instead", testName, tt.subTest, tt.expected, ready)
+		}
+	}
+}
+
+func TestFailedMajorVersionUpgrade(t *testing.T) {
+	testName := "TestFailedMajorVersionUpgrade"
+	tests := []struct {
+		subTest  string
+		upgrade  *acidv1.MajorVersionUpgradeStatus
+		expected bool
+	}{
+		{
+			subTest:  "never upgraded",
+			expected: false,
+		},
+		{
+			subTest:  "failed upgrade to the desired version",
+			upgrade:  &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "12", Phase: acidv1.UpgradePhaseFailed},
+			expected: true,
+		},
+		{
+			subTest:  "failed upgrade to another version",
+			upgrade:  &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "13", Phase: acidv1.UpgradePhaseFailed},
+			expected: false,
+		},
+		{
+			subTest:  "failed pre-upgrade checks are retried",
+			upgrade:  &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "12", Phase: acidv1.UpgradePhaseCheckFailed},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if failed := failedMajorVersionUpgrade(tt.upgrade, "11", "12"); failed != tt.expected {
+			t.Errorf("%s %s: Expected %t, have %t instead", testName, tt.subTest, tt.expected, failed)
+		}
+	}
+}
+
+func TestMajorVersionUpgradeNotStarted(t *testing.T) {
+	testName := "TestMajorVersionUpgradeNotStarted"
+	// a maintenance window that is closed for the whole day
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Weekday()
+	closedWindow := acidv1.MaintenanceWindow{
+		Weekday:   tomorrow,
+		StartTime: metav1.NewTime(time.Date(0, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		EndTime:   metav1.NewTime(time.Date(0, time.January, 1, 23, 59, 0, 0, time.UTC)),
+	}
+
+	tests := []struct {
+		subTest        string
+		desiredVersion string
+		upgrade        *acidv1.MajorVersionUpgradeStatus
+		pending        bool
+	}{
+		{
+			subTest:        "upgrade failed before",
+			desiredVersion: "12",
+			upgrade:        &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "12", Phase: acidv1.UpgradePhaseFailed},
+			pending:        false,
+		},
+		{
+			subTest:        "downgrade",
+			desiredVersion: "10",
+			pending:        false,
+		},
+		{
+			subTest:        "retry after the status was reset",
+			desiredVersion: "12",
+			pending:        true,
+		},
+		{
+			subTest:        "upgrade to another version after a failed upgrade",
+			desiredVersion: "13",
+			upgrade:        &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "12", Phase: acidv1.UpgradePhaseFailed},
+			pending:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(Config{}, k8sutil.KubernetesClient{}, acidv1.Postgresql{
+			Spec:   acidv1.PostgresSpec{MaintenanceWindows: []acidv1.MaintenanceWindow{closedWindow}},
+			Status: acidv1.PostgresStatus{MajorVersionUpgrade: tt.upgrade},
+		}, logger, record.NewFakeRecorder(10))
+
+		// the upgrade either stops before touching the cluster or is postponed to the maintenance window
+		if err := cluster.majorVersionUpgrade("11", tt.desiredVersion); err != nil {
+			t.Errorf("%s %s: Unexpected error: %v", testName, tt.subTest, err)
+		}
+		pending := len(cluster.pendingMaintenance) == 1 && cluster.pendingMaintenance[0] == maintenanceMajorVersionUpgrade
+		if pending != tt.pending {
+			t.Errorf("%s %s: Expected pending upgrade %t, have %v instead", testName, tt.subTest, tt.pending,
+				cluster.pendingMaintenance)
+		}
+	}
+}
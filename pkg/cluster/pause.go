@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+// IsPausedManifest tells whether the reconciliation of the cluster is paused by an annotation of its manifest
+func IsPausedManifest(pg *acidv1.Postgresql) bool {
+	return pg != nil && pg.Annotations[constants.PostgresqlPausedAnnotationKey] == "true"
+}
+
+// IsPaused tells whether the operator keeps its hands off the cluster, as reported in the cluster status
+func (c *Cluster) IsPaused() bool {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+
+	condition := c.Status.GetCondition(acidv1.ConditionPaused)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
+// SetPaused reports in the cluster status that the reconciliation has been paused or resumed.
+// The status is kept in memory even if it cannot be written, the next status update persists it.
+func (c *Cluster) SetPaused(paused bool) {
+	if c.IsPaused() == paused {
+		return
+	}
+
+	c.specMu.Lock()
+	now := metav1.Now()
+	if paused {
+		c.Status.SetCondition(newCondition(acidv1.ConditionPaused, v1.ConditionTrue, "Annotation",
+			fmt.Sprintf("reconciliation is paused by the %s annotation", constants.PostgresqlPausedAnnotationKey), now))
+	} else {
+		c.Status.SetCondition(newCondition(acidv1.ConditionPaused, v1.ConditionFalse, "Resumed", "", now))
+	}
+	conditions := c.Status.DeepCopy().Conditions
+	c.specMu.Unlock()
+
+	if _, err := c.patchStatus(map[string]interface{}{"conditions": conditions}); err != nil {
+		c.logger.Errorf("could not update status: %v", err)
+	}
+
+	if paused {
+		c.recordEvent(v1.EventTypeNormal, "Pause", "Reconciliation of the cluster has been paused")
+	} else {
+		c.recordEvent(v1.EventTypeNormal, "Resume", "Reconciliation of the cluster has been resumed")
+	}
+}
@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPausedManifest(t *testing.T) {
+	tests := []struct {
+		subTest     string
+		annotations map[string]string
+		paused      bool
+	}{
+		{"no annotations", nil, false},
+		{"paused", map[string]string{"acid.zalan.do/paused": "true"}, true},
+		{"explicitly not paused", map[string]string{"acid.zalan.do/paused": "false"}, false},
+		{"other annotation", map[string]string{"acid.zalan.do/controller": "true"}, false},
+	}
+
+	for _, tt := range tests {
+		pg := &acidv1.Postgresql{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+		if paused := IsPausedManifest(pg); paused != tt.paused {
+			t.Errorf("TestIsPausedManifest %s: expected paused %t, got %t", tt.subTest, tt.paused, paused)
+		}
+	}
+	if IsPausedManifest(nil) {
+		t.Errorf("TestIsPausedManifest: expected missing manifest not to be paused")
+	}
+}
+
+func TestIsPaused(t *testing.T) {
+	c := &Cluster{}
+	if c.IsPaused() {
+		t.Errorf("Expected cluster without paused condition not to be paused")
+	}
+
+	c.Status.SetCondition(newCondition(acidv1.ConditionPaused, v1.ConditionTrue, "Annotation", "", metav1.Now()))
+	if !c.IsPaused() {
+		t.Errorf("Expected cluster with paused condition to be paused")
+	}
+
+	c.Status.SetCondition(newCondition(acidv1.ConditionPaused, v1.ConditionFalse, "Resumed", "", metav1.Now()))
+	if c.IsPaused() {
+		t.Errorf("Expected resumed cluster not to be paused")
+	}
+}
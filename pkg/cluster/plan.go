@@ -0,0 +1,766 @@
+package cluster
+
+// Computes the changes a sync would apply to a cluster, without applying any of them.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+// actions of a cluster plan
+const (
+	PlanActionCreate  = "create"
+	PlanActionUpdate  = "update"
+	PlanActionReplace = "replace"
+	PlanActionDelete  = "delete"
+)
+
+// ResourceDiff describes the change a sync would apply to a single K8s object, role or database
+type ResourceDiff struct {
+	Kind          string   `json:"kind"`
+	Name          string   `json:"name"`
+	Action        string   `json:"action"`
+	RollingUpdate bool     `json:"rollingUpdate,omitempty"`
+	Reasons       []string `json:"reasons,omitempty"`
+	Diff          string   `json:"diff,omitempty"`
+}
+
+// ClusterPlan lists the changes a sync of the cluster would apply in the order of the sync.
+// Resources that could not be compared with the manifest are reported as errors.
+type ClusterPlan struct {
+	Cluster string         `json:"cluster"`
+	Changes []ResourceDiff `json:"changes"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+// Empty tells whether the plan has neither changes nor errors, i.e. the cluster is in the desired state
+func (p *ClusterPlan) Empty() bool {
+	return len(p.Changes) == 0 && len(p.Errors) == 0
+}
+
+func (p *ClusterPlan) add(diff ResourceDiff) {
+	p.Changes = append(p.Changes, diff)
+}
+
+func (p *ClusterPlan) addError(format string, args ...interface{}) {
+	p.Errors = append(p.Errors, fmt.Sprintf(format, args...))
+}
+
+// objectName returns the full name of an object in the namespace of the cluster
+func (c *Cluster) objectName(name string) string {
+	return spec.NamespacedName{Namespace: c.Namespace, Name: name}.String()
+}
+
+// Plan runs the checks of a sync for the given manifest against the live objects of the cluster and
+// returns the changes the sync would apply. The checks run on a separate copy of the cluster, so
+// neither the K8s objects, the database nor the state of the cluster are modified.
+func (c *Cluster) Plan(newSpec *acidv1.Postgresql) (*ClusterPlan, error) {
+	oldSpec, err := c.GetSpec()
+	if err != nil {
+		return nil, fmt.Errorf("could not get the current spec: %v", err)
+	}
+	pgSpec, err := cloneSpec(newSpec)
+	if err != nil {
+		return nil, fmt.Errorf("could not copy the new spec: %v", err)
+	}
+
+	p := New(c.Config, c.KubeClient, *pgSpec, c.logger.WithField("plan", true), nil)
+	plan := &ClusterPlan{
+		Cluster: p.clusterName().String(),
+		Changes: make([]ResourceDiff, 0),
+	}
+
+	if err := p.initUsers(); err != nil {
+		return nil, fmt.Errorf("could not init users: %v", err)
+	}
+	p.planSecrets(plan)
+	p.planServices(plan)
+	p.planVolumes(plan)
+
+	if err := p.enforceMinResourceLimits(&p.Spec); err != nil {
+		return nil, fmt.Errorf("could not enforce minimum resource limits: %v", err)
+	}
+	p.planStatefulSet(plan)
+	p.planPodDisruptionBudget(plan)
+
+	if p.Spec.EnableLogicalBackup && p.getNumberOfInstances(&p.Spec) > 0 {
+		p.planLogicalBackupJob(plan)
+	}
+	if !(p.databaseAccessDisabled() || p.getNumberOfInstances(&p.Spec) <= 0 || p.Spec.StandbyCluster != nil) {
+		if currentDatabases := p.planDatabaseObjects(plan); currentDatabases != nil {
+			p.planPreparedSchemas(currentDatabases, plan)
+			p.planExtensions(currentDatabases, plan)
+			p.planBootstrapSQL(currentDatabases, plan)
+			p.planLogicalReplication(currentDatabases, plan)
+		}
+	}
+	p.planConnectionPool(oldSpec, plan)
+
+	return plan, nil
+}
+
+// planSecrets also takes over the passwords from the existing secrets, as the sync would
+func (c *Cluster) planSecrets(plan *ClusterPlan) {
+	secrets := c.generateUserSecrets()
+	usernames := make([]string, 0, len(secrets))
+	for username := range secrets {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	for _, secretUsername := range usernames {
+		secretSpec := secrets[secretUsername]
+		secretName := util.NameFromMeta(secretSpec.ObjectMeta).String()
+		secret, err := c.SecretBackend.Get(secretSpec.Namespace, secretSpec.Name)
+		if err != nil {
+			if !k8sutil.ResourceNotFound(err) {
+				plan.addError("could not get secret %q: %v", secretName, err)
+				continue
+			}
+			plan.add(ResourceDiff{
+				Kind:    "Secret",
+				Name:    secretName,
+				Action:  PlanActionCreate,
+				Reasons: []string{fmt.Sprintf("secret of the role %q does not exist", secretUsername)},
+			})
+			continue
+		}
+		if !isSecretOfUser(secret, secretUsername) {
+			continue
+		}
+		userMap, userKey := c.secretUserMap(secretUsername)
+		pwdUser := userMap[userKey]
+		if pwdUser.Password != string(secret.Data["password"]) && pwdUser.Origin == spec.RoleOriginInfrastructure {
+			plan.add(ResourceDiff{
+				Kind:    "Secret",
+				Name:    secretName,
+				Action:  PlanActionUpdate,
+				Reasons: []string{fmt.Sprintf("password of the infrastructure role %q has changed", secretUsername)},
+			})
+			continue
+		}
+		c.useSecretCredentials(userMap, userKey, secret)
+
+		rotationDue := false
+		if pwdUser.Origin == spec.RoleOriginSystem {
+			rotationDue = c.systemPasswordRotationDue(secret)
+		} else if enabled, interval, _ := c.passwordRotationConfig(pwdUser); enabled {
+			rotationDue = time.Since(passwordRotationTime(secret)) >= interval
+		}
+		if rotationDue {
+			plan.add(ResourceDiff{
+				Kind:    "Secret",
+				Name:    secretName,
+				Action:  PlanActionUpdate,
+				Reasons: []string{fmt.Sprintf("password rotation of the role %q is due", secretUsername)},
+			})
+		}
+	}
+}
+
+func (c *Cluster) planServices(plan *ClusterPlan) {
+	for _, role := range []PostgresRole{Master, Replica} {
+		_, err := c.KubeClient.Endpoints(c.Namespace).Get(context.TODO(), c.endpointName(role), metav1.GetOptions{})
+		if err != nil {
+			if k8sutil.ResourceNotFound(err) {
+				plan.add(ResourceDiff{
+					Kind:   "Endpoints",
+					Name:   c.objectName(c.endpointName(role)),
+					Action: PlanActionCreate,
+				})
+			} else {
+				plan.addError("could not get %s endpoint: %v", role, err)
+			}
+		}
+
+		desiredSvc := c.generateService(role, &c.Spec)
+		svc, err := c.KubeClient.Services(c.Namespace).Get(context.TODO(), c.serviceName(role), metav1.GetOptions{})
+		if err != nil {
+			if k8sutil.ResourceNotFound(err) {
+				plan.add(ResourceDiff{
+					Kind:   "Service",
+					Name:   util.NameFromMeta(desiredSvc.ObjectMeta).String(),
+					Action: PlanActionCreate,
+				})
+			} else {
+				plan.addError("could not get %s service: %v", role, err)
+			}
+			continue
+		}
+		if match, reason := k8sutil.SameService(svc, desiredSvc); !match {
+			plan.add(ResourceDiff{
+				Kind:    "Service",
+				Name:    util.NameFromMeta(svc.ObjectMeta).String(),
+				Action:  PlanActionUpdate,
+				Reasons: []string{reason},
+				Diff:    util.PrettyDiff(svc.Spec, desiredSvc.Spec),
+			})
+		}
+	}
+}
+
+func (c *Cluster) planVolumes(plan *ClusterPlan) {
+	vols, manifestSize, err := c.listVolumesWithManifestSize(c.Spec.Volume)
+	if err != nil {
+		plan.addError("could not compare size of the volumes: %v", err)
+		return
+	}
+	for _, pv := range vols {
+		if currentSize := quantityToGigabyte(pv.Spec.Capacity[v1.ResourceStorage]); currentSize != manifestSize {
+			plan.add(ResourceDiff{
+				Kind:    "PersistentVolume",
+				Name:    pv.Name,
+				Action:  PlanActionUpdate,
+				Reasons: []string{fmt.Sprintf("size of %dGi does not match the %dGi of the manifest", currentSize, manifestSize)},
+			})
+		}
+	}
+}
+
+func (c *Cluster) planStatefulSet(plan *ClusterPlan) {
+	var desiredPgVersion string
+
+	sset, err := c.KubeClient.StatefulSets(c.Namespace).Get(context.TODO(), c.statefulSetName(), metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			plan.addError("could not get statefulset: %v", err)
+			return
+		}
+		pods, err := c.listPods()
+		if err != nil {
+			plan.addError("could not list pods of the statefulset: %v", err)
+			return
+		}
+		diff := ResourceDiff{
+			Kind:          "StatefulSet",
+			Name:          c.objectName(c.statefulSetName()),
+			Action:        PlanActionCreate,
+			RollingUpdate: len(pods) > 0,
+		}
+		if len(pods) > 0 {
+			diff.Reasons = []string{"found pods from the previous statefulset"}
+		}
+		plan.add(diff)
+		return
+	}
+
+	podsRollingUpdateRequired := c.mergeRollingUpdateFlagUsingCache(sset)
+	c.Statefulset = sset
+
+	// the running version is kept in the statefulset until the major version upgrade is done
+	for _, container := range sset.Spec.Template.Spec.Containers {
+		if container.Name != "postgres" {
+			continue
+		}
+		pgVersion, err := c.getNewPgVersion(container, c.Spec.PostgresqlParam.PgVersion)
+		if err != nil {
+			plan.addError("could not parse current Postgres version: %v", err)
+			return
+		}
+		if pgVersion != c.Spec.PostgresqlParam.PgVersion {
+			desiredPgVersion = c.Spec.PostgresqlParam.PgVersion
+		}
+		c.Spec.PostgresqlParam.PgVersion = pgVersion
+	}
+
+	desiredSS, err := c.generateStatefulSet(&c.Spec)
+	if err != nil {
+		plan.addError("could not generate statefulset: %v", err)
+		return
+	}
+	c.setRollingUpdateFlagForStatefulSet(desiredSS, podsRollingUpdateRequired)
+
+	cmp := c.compareStatefulSetWith(desiredSS)
+	if cmp.match && !podsRollingUpdateRequired && desiredPgVersion == "" {
+		return
+	}
+
+	diff := ResourceDiff{
+		Kind:          "StatefulSet",
+		Name:          util.NameFromMeta(sset.ObjectMeta).String(),
+		Action:        PlanActionUpdate,
+		RollingUpdate: podsRollingUpdateRequired || cmp.rollingUpdate,
+		Reasons:       cmp.reasons,
+	}
+	if !cmp.match {
+		diff.Diff = util.PrettyDiff(sset.Spec, desiredSS.Spec)
+	}
+	if cmp.replace {
+		diff.Action = PlanActionReplace
+	}
+	if podsRollingUpdateRequired {
+		diff.Reasons = append(diff.Reasons, "the pods have an unfinished rolling update")
+	}
+	if desiredPgVersion != "" {
+		diff.Reasons = append(diff.Reasons, fmt.Sprintf("Postgres has to be upgraded from version %s to %s",
+			c.Spec.PostgresqlParam.PgVersion, desiredPgVersion))
+	}
+	if (cmp.replace || diff.RollingUpdate) && !c.isInMaintenanceWindow() {
+		diff.Reasons = append(diff.Reasons, "disruptive actions are postponed until the next maintenance window")
+	}
+	plan.add(diff)
+}
+
+func (c *Cluster) planPodDisruptionBudget(plan *ClusterPlan) {
+	newPDB := c.generatePodDisruptionBudget()
+	pdb, err := c.KubeClient.PodDisruptionBudgets(c.Namespace).Get(context.TODO(), c.podDisruptionBudgetName(), metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.ResourceNotFound(err) {
+			plan.add(ResourceDiff{
+				Kind:   "PodDisruptionBudget",
+				Name:   util.NameFromMeta(newPDB.ObjectMeta).String(),
+				Action: PlanActionCreate,
+			})
+		} else {
+			plan.addError("could not get pod disruption budget: %v", err)
+		}
+		return
+	}
+	if match, reason := k8sutil.SamePDB(pdb, newPDB); !match {
+		plan.add(ResourceDiff{
+			Kind:    "PodDisruptionBudget",
+			Name:    util.NameFromMeta(pdb.ObjectMeta).String(),
+			Action:  PlanActionUpdate,
+			Reasons: []string{reason},
+			Diff:    util.PrettyDiff(pdb.Spec, newPDB.Spec),
+		})
+	}
+}
+
+func (c *Cluster) planLogicalBackupJob(plan *ClusterPlan) {
+	jobName := c.objectName(c.getLogicalBackupJobName())
+	desiredJob, err := c.generateLogicalBackupJob()
+	if err != nil {
+		plan.addError("could not generate the desired logical backup job state: %v", err)
+		return
+	}
+	job, err := c.KubeClient.CronJobsGetter.CronJobs(c.Namespace).Get(context.TODO(), c.getLogicalBackupJobName(), metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.ResourceNotFound(err) {
+			plan.add(ResourceDiff{Kind: "CronJob", Name: jobName, Action: PlanActionCreate})
+		} else {
+			plan.addError("could not get logical backup job: %v", err)
+		}
+		return
+	}
+	if match, reason := k8sutil.SameLogicalBackupJob(job, desiredJob); !match {
+		diff := ResourceDiff{
+			Kind:   "CronJob",
+			Name:   jobName,
+			Action: PlanActionUpdate,
+			Diff:   util.PrettyDiff(job.Spec, desiredJob.Spec),
+		}
+		if reason != "" {
+			diff.Reasons = []string{reason}
+		}
+		plan.add(diff)
+	}
+}
+
+// planDatabaseObjects compares roles and databases with the manifest, it only runs read-only queries.
+// It returns the current databases with their owners, nil if they could not be read.
+func (c *Cluster) planDatabaseObjects(plan *ClusterPlan) map[string]string {
+	if err := c.initDbConn(); err != nil {
+		plan.addError("could not init database connection: %v", err)
+		return nil
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+
+	if c.needConnectionPool() {
+		connPoolUser := c.systemUsers[constants.ConnectionPoolUserKeyName]
+		if _, exists := c.pgUsers[connPoolUser.Name]; !exists {
+			c.pgUsers[connPoolUser.Name] = connPoolUser
+		}
+	}
+
+	secrets, err := c.listUserSecrets()
+	if err != nil {
+		plan.addError("could not list secrets: %v", err)
+	} else if userNames, err := c.roleNamesToSync(secrets); err != nil {
+		plan.addError("could not find removed roles: %v", err)
+	} else if dbUsers, err := c.readPgUsersFromDatabase(userNames); err != nil {
+		plan.addError("could not get users from the database: %v", err)
+	} else {
+		reqs := c.userSyncStrategy.ProduceSyncRequests(dbUsers, c.pgUsers)
+		sort.SliceStable(reqs, func(i, j int) bool { return reqs[i].User.Name < reqs[j].User.Name })
+		for _, r := range reqs {
+			plan.add(roleDiff(r))
+		}
+	}
+	if c.OpConfig.EnableRoleDrop {
+		for _, role := range c.Status.DeprecatedRoles {
+			if time.Since(role.DeprecationTime.Time) >= c.OpConfig.RoleDropGracePeriod {
+				plan.add(ResourceDiff{Kind: "Role", Name: role.Name + c.OpConfig.RoleDeletionSuffix, Action: PlanActionDelete,
+					Reasons: []string{"the grace period of the deprecated role has passed"}})
+			}
+		}
+	}
+
+	currentDatabases, err := c.getDatabases()
+	if err != nil {
+		plan.addError("could not get current databases: %v", err)
+		return nil
+	}
+	createDatabases, alterOwnerDatabases := databaseChanges(currentDatabases, c.databaseOwners())
+	for _, datname := range sortedKeys(createDatabases) {
+		plan.add(ResourceDiff{Kind: "Database", Name: datname, Action: PlanActionCreate,
+			Reasons: []string{fmt.Sprintf("owner %q", createDatabases[datname])}})
+	}
+	for _, datname := range sortedKeys(alterOwnerDatabases) {
+		plan.add(ResourceDiff{Kind: "Database", Name: datname, Action: PlanActionUpdate,
+			Reasons: []string{fmt.Sprintf("owner %q does not match the owner %q of the manifest",
+				currentDatabases[datname], alterOwnerDatabases[datname])}})
+	}
+	return currentDatabases
+}
+
+// sortedKeys returns the keys of a map of databases or other objects in alphabetical order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// queryDatabase runs read-only queries in a database of the cluster with a connection of its own.
+// Databases the sync would create do not exist yet, so the queries are skipped for them.
+func (c *Cluster) queryDatabase(datname string, currentDatabases map[string]string, query func() error) error {
+	if _, exists := currentDatabases[datname]; !exists {
+		if _, created := c.databaseOwners()[datname]; created {
+			return nil
+		}
+		return fmt.Errorf("database does not exist")
+	}
+	if err := c.initDbConnWithName(datname); err != nil {
+		return err
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+	return query()
+}
+
+// planPreparedSchemas lists the missing schemas of the prepared databases. The default privileges are not
+// compared, the sync grants them again every time.
+func (c *Cluster) planPreparedSchemas(currentDatabases map[string]string, plan *ClusterPlan) {
+	if err := validatePreparedDatabases(&c.Spec); err != nil {
+		plan.addError("could not sync prepared databases: %v", err)
+		return
+	}
+	datnames := make([]string, 0, len(c.Spec.PreparedDatabases))
+	for datname := range c.Spec.PreparedDatabases {
+		datnames = append(datnames, datname)
+	}
+	sort.Strings(datnames)
+
+	for _, datname := range datnames {
+		schemas := preparedSchemas(c.Spec.PreparedDatabases[datname])
+		schemaNames := make([]string, 0, len(schemas))
+		for schemaName := range schemas {
+			schemaNames = append(schemaNames, schemaName)
+		}
+		sort.Strings(schemaNames)
+
+		currentSchemas := make(map[string]bool)
+		err := c.queryDatabase(datname, currentDatabases, func() (err error) {
+			currentSchemas, err = c.getSchemas(schemaNames)
+			return err
+		})
+		if err != nil {
+			plan.addError("could not get schemas of database %q: %v", datname, err)
+			continue
+		}
+		for _, schemaName := range schemaNames {
+			if !currentSchemas[schemaName] {
+				plan.add(ResourceDiff{Kind: "Schema", Name: datname + "/" + schemaName, Action: PlanActionCreate,
+					Reasons: []string{fmt.Sprintf("owner %q", preparedSchemaOwner(datname, schemaName, schemas[schemaName]))}})
+			}
+		}
+	}
+}
+
+func (c *Cluster) planExtensions(currentDatabases map[string]string, plan *ClusterPlan) {
+	datnames := make([]string, 0, len(c.Spec.Extensions))
+	for datname := range c.Spec.Extensions {
+		datnames = append(datnames, datname)
+	}
+	sort.Strings(datnames)
+
+	for _, datname := range datnames {
+		installed := make(map[string]installedExtension)
+		err := c.queryDatabase(datname, currentDatabases, func() (err error) {
+			installed, err = c.getExtensions()
+			return err
+		})
+		if err != nil {
+			plan.addError("could not get extensions of database %q: %v", datname, err)
+			continue
+		}
+
+		changes, errs := c.extensionChanges(c.Spec.Extensions[datname], installed)
+		names := make([]string, 0, len(changes)+len(errs))
+		for name := range changes {
+			names = append(names, name)
+		}
+		for name := range errs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err, failed := errs[name]; failed {
+				plan.addError("could not sync extension %q in database %q: %v", name, datname, err)
+				continue
+			}
+			diff := ResourceDiff{Kind: "Extension", Name: datname + "/" + name, Action: PlanActionUpdate,
+				Diff: strings.Join(changes[name], "\n")}
+			if _, exists := installed[name]; !exists {
+				diff.Action = PlanActionCreate
+			}
+			plan.add(diff)
+		}
+	}
+}
+
+// planBootstrapSQL lists the bootstrap scripts the sync would execute. Scripts that would fail are only
+// found by executing them.
+func (c *Cluster) planBootstrapSQL(currentDatabases map[string]string, plan *ClusterPlan) {
+	datnames := make([]string, 0, len(c.Spec.BootstrapSQL))
+	for datname := range c.Spec.BootstrapSQL {
+		datnames = append(datnames, datname)
+	}
+	sort.Strings(datnames)
+
+	owners := c.databaseOwners()
+	for _, datname := range datnames {
+		scripts, scriptName, err := c.readBootstrapScripts(c.Spec.BootstrapSQL[datname])
+		if err != nil {
+			plan.addError("could not read bootstrap script %q of database %q: %v", scriptName, datname, err)
+			continue
+		}
+		executed := make(map[string]string)
+		err = c.queryDatabase(datname, currentDatabases, func() (err error) {
+			var tableExists bool
+			if err = c.pgDb.QueryRow(bootstrapScriptsTableExistsSQL).Scan(&tableExists); err != nil || !tableExists {
+				return err
+			}
+			executed, err = c.getExecutedBootstrapScripts()
+			return err
+		})
+		if err != nil {
+			plan.addError("could not get executed bootstrap scripts of database %q: %v", datname, err)
+			continue
+		}
+
+		pending, changed, err := pendingBootstrapScripts(scripts, executed)
+		if err != nil {
+			plan.addError("could not execute bootstrap script %q in database %q: %v", changed, datname, err)
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		loginUser, ok := c.bootstrapLoginUser(owners[datname])
+		if !ok {
+			plan.addError("could not execute bootstrap scripts in database %q: no role with known credentials can log in as the owner %q",
+				datname, owners[datname])
+			continue
+		}
+		for _, script := range pending {
+			plan.add(ResourceDiff{Kind: "BootstrapScript", Name: datname + "/" + script.name, Action: PlanActionCreate,
+				Reasons: []string{fmt.Sprintf("executed as role %q", loginUser.Name)}})
+		}
+	}
+}
+
+// planLogicalReplication lists the changes of the replication slots reserved for subscriptions of other
+// clusters and of the publications and subscriptions of the manifest
+func (c *Cluster) planLogicalReplication(currentDatabases map[string]string, plan *ClusterPlan) {
+	if slots, err := c.getSubscriberSlots(); err != nil {
+		plan.addError("could not sync replication slots of subscriptions: %v", err)
+	} else if slots != nil {
+		names := make([]string, 0, len(slots.changes))
+		for name := range slots.changes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if slots.changes[name] == nil {
+				continue
+			}
+			diff := ResourceDiff{Kind: "ReplicationSlot", Name: name, Action: PlanActionCreate,
+				Reasons: []string{"the slot is reserved for a subscription"}}
+			if _, exists := slots.current[name]; exists {
+				diff.Action = PlanActionUpdate
+			}
+			plan.add(diff)
+		}
+		for _, name := range slots.removed {
+			plan.add(ResourceDiff{Kind: "ReplicationSlot", Name: name, Action: PlanActionDelete,
+				Reasons: []string{"no subscription needs the slot anymore"}})
+		}
+	}
+
+	changes, errs, err := c.getLogicalReplicationChanges()
+	for _, changeErr := range errs {
+		plan.addError("%s", changeErr)
+	}
+	if err != nil {
+		plan.addError("%v", err)
+		return
+	}
+	if changes == nil {
+		return
+	}
+
+	for _, datname := range changes.databases() {
+		currentPublications := make(map[string]currentPublication)
+		if len(changes.publications[datname]) > 0 {
+			err := c.queryDatabase(datname, currentDatabases, func() (err error) {
+				currentPublications, err = c.getPublications()
+				return err
+			})
+			if err != nil {
+				plan.addError("could not get publications of database %q: %v", datname, err)
+				continue
+			}
+		}
+		publicationChanges := c.publicationChanges(changes.publications[datname], currentPublications)
+		for _, name := range changes.publications[datname] {
+			if len(publicationChanges[name]) == 0 {
+				continue
+			}
+			diff := ResourceDiff{Kind: "Publication", Name: datname + "/" + name, Action: PlanActionUpdate,
+				Diff: strings.Join(publicationChanges[name], "\n")}
+			if _, exists := currentPublications[name]; !exists {
+				diff.Action = PlanActionCreate
+			}
+			plan.add(diff)
+		}
+
+		for _, name := range changes.subscriptions[datname] {
+			target, current := changes.targets[name], changes.currentSubscriptions[name]
+			statements, err := c.subscriptionChanges(name, target, current)
+			if err != nil {
+				plan.addError("%v", err)
+				continue
+			}
+			if len(statements) == 0 {
+				continue
+			}
+			diff := ResourceDiff{Kind: "Subscription", Name: datname + "/" + name, Action: PlanActionUpdate,
+				Diff: strings.Replace(strings.Join(statements, "\n"), pq.QuoteLiteral(target.conninfo),
+					pq.QuoteLiteral(target.redactedConninfo), -1)}
+			if current == nil {
+				diff.Action = PlanActionCreate
+			}
+			plan.add(diff)
+		}
+		for _, name := range changes.removedSubscriptions[datname] {
+			plan.add(ResourceDiff{Kind: "Subscription", Name: datname + "/" + name, Action: PlanActionDelete,
+				Reasons: []string{"the subscription has been removed from the manifest"},
+				Diff:    strings.Join(dropSubscriptionStatements(name), "\n")})
+		}
+	}
+}
+
+// roleDiff describes a sync request of a role, passwords are never part of the plan
+func roleDiff(r spec.PgSyncUserRequest) ResourceDiff {
+	diff := ResourceDiff{Kind: "Role", Name: r.User.Name, Action: PlanActionUpdate}
+
+	switch r.Kind {
+	case spec.PGSyncUserAdd:
+		diff.Action = PlanActionCreate
+	case spec.PGsyncUserAlter:
+		if r.User.Password != "" {
+			diff.Reasons = append(diff.Reasons, "password does not match the secret")
+		}
+		if len(r.User.MemberOf) > 0 {
+			diff.Reasons = append(diff.Reasons, fmt.Sprintf("missing membership in %s", strings.Join(r.User.MemberOf, ", ")))
+		}
+		if len(r.User.Flags) > 0 {
+			diff.Reasons = append(diff.Reasons, fmt.Sprintf("missing flags %s", strings.Join(r.User.Flags, ", ")))
+		}
+	case spec.PGSyncAlterSet:
+		diff.Reasons = []string{"parameters do not match the manifest"}
+	case spec.PGSyncUserRename:
+		diff.Reasons = []string{"the deprecated role is restored"}
+	case spec.PGSyncUserDeprecate:
+		diff.Reasons = []string{"the role has been removed, it is renamed and set to NOLOGIN"}
+	}
+
+	return diff
+}
+
+func (c *Cluster) planConnectionPool(oldSpec *acidv1.Postgresql, plan *ClusterPlan) {
+	name := c.objectName(c.connPoolName())
+
+	deployment, err := c.KubeClient.Deployments(c.Namespace).Get(context.TODO(), c.connPoolName(), metav1.GetOptions{})
+	if err != nil && !k8sutil.ResourceNotFound(err) {
+		plan.addError("could not get connection pool deployment: %v", err)
+		return
+	}
+	deploymentExists := err == nil
+
+	_, err = c.KubeClient.Services(c.Namespace).Get(context.TODO(), c.connPoolName(), metav1.GetOptions{})
+	if err != nil && !k8sutil.ResourceNotFound(err) {
+		plan.addError("could not get connection pool service: %v", err)
+		return
+	}
+	serviceExists := err == nil
+
+	if !c.needConnectionPool() {
+		if deploymentExists {
+			plan.add(ResourceDiff{Kind: "Deployment", Name: name, Action: PlanActionDelete,
+				Reasons: []string{"connection pool is not enabled"}})
+		}
+		if serviceExists {
+			plan.add(ResourceDiff{Kind: "Service", Name: name, Action: PlanActionDelete,
+				Reasons: []string{"connection pool is not enabled"}})
+		}
+		return
+	}
+
+	if !deploymentExists {
+		plan.add(ResourceDiff{Kind: "Deployment", Name: name, Action: PlanActionCreate})
+	} else {
+		specSync, specReason := c.needSyncConnPoolSpecs(oldSpec.Spec.ConnectionPool, c.Spec.ConnectionPool)
+		defaultsSync, defaultsReason := c.needSyncConnPoolDefaults(c.Spec.ConnectionPool, deployment)
+		if specSync || defaultsSync {
+			diff := ResourceDiff{
+				Kind:    "Deployment",
+				Name:    name,
+				Action:  PlanActionUpdate,
+				Reasons: append(specReason, defaultsReason...),
+			}
+			if desired, err := c.generateConnPoolDeployment(&c.Spec); err == nil {
+				diff.Diff = util.PrettyDiff(deployment.Spec, desired.Spec)
+			} else {
+				plan.addError("could not generate deployment for connection pool: %v", err)
+			}
+			plan.add(diff)
+		}
+	}
+	if !serviceExists {
+		plan.add(ResourceDiff{Kind: "Service", Name: name, Action: PlanActionCreate})
+	}
+}
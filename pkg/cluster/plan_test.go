@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type planAction struct {
+	kind   string
+	action string
+}
+
+func planActions(plan *ClusterPlan) []planAction {
+	actions := make([]planAction, 0)
+	for _, change := range plan.Changes {
+		actions = append(actions, planAction{change.Kind, change.Action})
+	}
+	return actions
+}
+
+func TestPlanConnectionPool(t *testing.T) {
+	testName := "TestPlanConnectionPool"
+	newCluster := func(client k8sutil.KubernetesClient, image string, pool *acidv1.ConnectionPool) *Cluster {
+		cl := New(
+			Config{
+				OpConfig: config.Config{
+					ProtectedRoles: []string{"admin"},
+					Auth: config.Auth{
+						SuperUsername:       superUserName,
+						ReplicationUsername: replicationUserName,
+					},
+					ConnectionPool: config.ConnectionPool{
+						ConnPoolDefaultCPURequest:    "100m",
+						ConnPoolDefaultCPULimit:      "100m",
+						ConnPoolDefaultMemoryRequest: "100Mi",
+						ConnPoolDefaultMemoryLimit:   "100Mi",
+						NumberOfInstances:            int32ToPointer(1),
+						Image:                        image,
+					},
+				},
+			}, client, acidv1.Postgresql{Spec: acidv1.PostgresSpec{ConnectionPool: pool}}, logger, eventRecorder)
+		cl.Statefulset = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-sts"},
+		}
+		return cl
+	}
+
+	tests := []struct {
+		subTest  string
+		cluster  *Cluster
+		expected []planAction
+	}{
+		{
+			subTest: "create missing objects",
+			cluster: newCluster(k8sutil.ClientMissingObjects(), "pooler:1.0", &acidv1.ConnectionPool{}),
+			expected: []planAction{
+				{"Deployment", PlanActionCreate},
+				{"Service", PlanActionCreate},
+			},
+		},
+		{
+			subTest: "update to new defaults",
+			cluster: newCluster(k8sutil.NewMockKubernetesClient(), "pooler:2.0", &acidv1.ConnectionPool{}),
+			expected: []planAction{
+				{"Deployment", PlanActionUpdate},
+			},
+		},
+		{
+			subTest: "delete objects that are not needed",
+			cluster: newCluster(k8sutil.NewMockKubernetesClient(), "pooler:1.0", nil),
+			expected: []planAction{
+				{"Deployment", PlanActionDelete},
+				{"Service", PlanActionDelete},
+			},
+		},
+		{
+			subTest:  "no connection pool",
+			cluster:  newCluster(k8sutil.ClientMissingObjects(), "pooler:1.0", nil),
+			expected: []planAction{},
+		},
+	}
+
+	for _, tt := range tests {
+		plan := &ClusterPlan{Changes: make([]ResourceDiff, 0)}
+		oldSpec := tt.cluster.Postgresql
+		tt.cluster.planConnectionPool(&oldSpec, plan)
+
+		if len(plan.Errors) > 0 {
+			t.Errorf("%s %s: Unexpected errors %v", testName, tt.subTest, plan.Errors)
+		}
+		if actions := planActions(plan); !reflect.DeepEqual(actions, tt.expected) {
+			t.Errorf("%s %s: Expected actions %v, have %v instead", testName, tt.subTest, tt.expected, actions)
+		}
+	}
+}
+
+func TestRoleDiff(t *testing.T) {
+	testName := "TestRoleDiff"
+	tests := []struct {
+		subTest  string
+		request  spec.PgSyncUserRequest
+		expected ResourceDiff
+	}{
+		{
+			subTest: "new role",
+			request: spec.PgSyncUserRequest{
+				Kind: spec.PGSyncUserAdd,
+				User: spec.PgUser{Name: "foo", Password: "secret", Flags: []string{"LOGIN"}},
+			},
+			expected: ResourceDiff{Kind: "Role", Name: "foo", Action: PlanActionCreate},
+		},
+		{
+			subTest: "changed password and membership",
+			request: spec.PgSyncUserRequest{
+				Kind: spec.PGsyncUserAlter,
+				User: spec.PgUser{Name: "foo", Password: "md5secret", MemberOf: []string{"admin", "bar"}},
+			},
+			expected: ResourceDiff{Kind: "Role", Name: "foo", Action: PlanActionUpdate,
+				Reasons: []string{"password does not match the secret", "missing membership in admin, bar"}},
+		},
+		{
+			subTest: "changed parameters",
+			request: spec.PgSyncUserRequest{
+				Kind: spec.PGSyncAlterSet,
+				User: spec.PgUser{Name: "foo", Parameters: map[string]string{"search_path": "data"}},
+			},
+			expected: ResourceDiff{Kind: "Role", Name: "foo", Action: PlanActionUpdate,
+				Reasons: []string{"parameters do not match the manifest"}},
+		},
+		{
+			subTest: "removed role",
+			request: spec.PgSyncUserRequest{
+				Kind: spec.PGSyncUserDeprecate,
+				User: spec.PgUser{Name: "foo", Password: "md5secret"},
+			},
+			expected: ResourceDiff{Kind: "Role", Name: "foo", Action: PlanActionUpdate,
+				Reasons: []string{"the role has been removed, it is renamed and set to NOLOGIN"}},
+		},
+	}
+
+	for _, tt := range tests {
+		if diff := roleDiff(tt.request); !reflect.DeepEqual(diff, tt.expected) {
+			t.Errorf("%s %s: Expected %#v, have %#v instead", testName, tt.subTest, tt.expected, diff)
+		}
+	}
+}
+
+func TestPlanNewDatabaseObjects(t *testing.T) {
+	testName := "TestPlanNewDatabaseObjects"
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				AllowedExtensions: []string{"pg_partman"},
+			},
+		}, k8sutil.NewMockKubernetesClient(),
+		acidv1.Postgresql{
+			Spec: acidv1.PostgresSpec{
+				Databases:         map[string]string{"foo": "zalando"},
+				PreparedDatabases: map[string]acidv1.PreparedDatabase{"bar": {DefaultUsers: true}},
+				Extensions: map[string]map[string]acidv1.Extension{
+					"foo": {"pg_partman": {Schema: "partman"}, "plpython3u": {}},
+					"baz": {"pg_partman": {}},
+				},
+				BootstrapSQL: map[string][]acidv1.BootstrapScript{
+					"foo": {{ConfigMap: "infrastructureroles-test"}},
+					"bar": {{ConfigMap: "infrastructureroles-test"}},
+				},
+			},
+		}, logger, eventRecorder)
+	cluster.pgUsers = map[string]spec.PgUser{
+		"bar_owner_user": {Name: "bar_owner_user", Password: "secret", MemberOf: []string{"bar_owner"}},
+	}
+
+	// none of the databases exist yet, so no database connection is needed
+	currentDatabases := map[string]string{}
+	plan := &ClusterPlan{Changes: make([]ResourceDiff, 0)}
+	cluster.planPreparedSchemas(currentDatabases, plan)
+	cluster.planExtensions(currentDatabases, plan)
+	cluster.planBootstrapSQL(currentDatabases, plan)
+
+	expectedChanges := []ResourceDiff{
+		{Kind: "Schema", Name: "bar/data", Action: PlanActionCreate, Reasons: []string{`owner "bar_data_owner"`}},
+		{Kind: "Extension", Name: "foo/pg_partman", Action: PlanActionCreate,
+			Diff: `CREATE EXTENSION IF NOT EXISTS "pg_partman" SCHEMA "partman";`},
+		{Kind: "BootstrapScript", Name: "bar/infrastructureroles-test/foobar", Action: PlanActionCreate,
+			Reasons: []string{`executed as role "bar_owner_user"`}},
+	}
+	if !reflect.DeepEqual(plan.Changes, expectedChanges) {
+		t.Errorf("%s: Expected changes %#v, have %#v instead", testName, expectedChanges, plan.Changes)
+	}
+	expectedErrors := []string{
+		`could not get extensions of database "baz": database does not exist`,
+		`could not sync extension "plpython3u" in database "foo": extension is not allowed by the operator configuration`,
+		`could not execute bootstrap scripts in database "foo": no role with known credentials can log in as the owner "zalando"`,
+	}
+	if !reflect.DeepEqual(plan.Errors, expectedErrors) {
+		t.Errorf("%s: Expected errors %#v, have %#v instead", testName, expectedErrors, plan.Errors)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/zalando/postgres-operator/pkg/spec"
 	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 )
 
 func (c *Cluster) listPods() ([]v1.Pod, error) {
@@ -176,11 +177,11 @@ func (c *Cluster) masterCandidate(oldNodeName string) (*v1.Pod, error) {
 	return &replicas[rand.Intn(len(replicas))], nil
 }
 
-// MigrateMasterPod migrates master pod via failover to a replica
-func (c *Cluster) MigrateMasterPod(podName spec.NamespacedName) error {
+// MigrateMasterPod migrates master pod via failover to a replica. The migration is triggered by draining
+// the node and not subject to the maintenance windows, the pod would be evicted otherwise.
+func (c *Cluster) MigrateMasterPod(podName spec.NamespacedName) (err error) {
 	var (
 		masterCandidatePod *v1.Pod
-		err                error
 		eol                bool
 	)
 
@@ -204,6 +205,10 @@ func (c *Cluster) MigrateMasterPod(podName spec.NamespacedName) error {
 		c.logger.Warningf("no action needed: pod %q is not the master (anymore)", podName)
 		return nil
 	}
+	defer func() {
+		metrics.CountPodMigration(c.Namespace, c.Name, string(Master), err)
+	}()
+
 	// we must have a statefulset in the cluster for the migration to work
 	if c.Statefulset == nil {
 		var sset *appsv1.StatefulSet
@@ -249,7 +254,7 @@ func (c *Cluster) MigrateMasterPod(podName spec.NamespacedName) error {
 }
 
 // MigrateReplicaPod recreates pod on a new node
-func (c *Cluster) MigrateReplicaPod(podName spec.NamespacedName, fromNodeName string) error {
+func (c *Cluster) MigrateReplicaPod(podName spec.NamespacedName, fromNodeName string) (err error) {
 	replicaPod, err := c.KubeClient.Pods(podName.Namespace).Get(context.TODO(), podName.Name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("could not get pod: %v", err)
@@ -265,6 +270,9 @@ func (c *Cluster) MigrateReplicaPod(podName spec.NamespacedName, fromNodeName st
 	if role := PostgresRole(replicaPod.Labels[c.OpConfig.PodRoleLabel]); role != Replica {
 		return fmt.Errorf("check failed: pod %q is not a replica", podName)
 	}
+	defer func() {
+		metrics.CountPodMigration(c.Namespace, c.Name, string(Replica), err)
+	}()
 
 	_, err = c.movePodFromEndOfLifeNode(replicaPod)
 	if err != nil {
@@ -308,6 +316,7 @@ func (c *Cluster) recreatePods() error {
 		return fmt.Errorf("could not get the list of pods: %v", err)
 	}
 	c.logger.Infof("there are %d pods in the cluster to recreate", len(pods.Items))
+	c.recordEvent(v1.EventTypeNormal, "Update", "Performing rolling update of %d pods", len(pods.Items))
 
 	var (
 		masterPod, newMasterPod, newPod *v1.Pod
@@ -323,6 +332,7 @@ func (c *Cluster) recreatePods() error {
 
 		podName := util.NameFromMeta(pods.Items[i].ObjectMeta)
 		if newPod, err = c.recreatePod(podName); err != nil {
+			c.recordEvent(v1.EventTypeWarning, "Update", "Rolling update failed: could not recreate replica pod %q: %v", podName, err)
 			return fmt.Errorf("could not recreate replica pod %q: %v", util.NameFromMeta(pod.ObjectMeta), err)
 		}
 		if newRole := PostgresRole(newPod.Labels[c.OpConfig.PodRoleLabel]); newRole == Replica {
@@ -347,6 +357,7 @@ func (c *Cluster) recreatePods() error {
 			return fmt.Errorf("could not recreate old master pod %q: %v", util.NameFromMeta(masterPod.ObjectMeta), err)
 		}
 	}
+	c.recordEvent(v1.EventTypeNormal, "Update", "Rolling update done - pods have been recreated")
 
 	return nil
 }
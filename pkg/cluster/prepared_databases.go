@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+const (
+	// schema of prepared databases that do not define any schemas
+	defaultPreparedSchema = "data"
+
+	getSchemasSQL             = `SELECT nspname FROM pg_catalog.pg_namespace WHERE nspname = ANY($1);`
+	createSchemaSQL           = `CREATE SCHEMA IF NOT EXISTS %s AUTHORIZATION %s;`
+	alterDefaultPrivilegesSQL = `
+		ALTER DEFAULT PRIVILEGES FOR ROLE %[1]s IN SCHEMA %[2]s GRANT SELECT ON TABLES TO %[3]s;
+		ALTER DEFAULT PRIVILEGES FOR ROLE %[1]s IN SCHEMA %[2]s GRANT SELECT ON SEQUENCES TO %[3]s;
+		ALTER DEFAULT PRIVILEGES FOR ROLE %[1]s IN SCHEMA %[2]s GRANT INSERT, UPDATE, DELETE, TRUNCATE ON TABLES TO %[4]s;
+		ALTER DEFAULT PRIVILEGES FOR ROLE %[1]s IN SCHEMA %[2]s GRANT USAGE, UPDATE ON SEQUENCES TO %[4]s;
+		GRANT USAGE ON SCHEMA %[2]s TO %[3]s;`
+)
+
+// preparedSchemas returns the schemas of a prepared database, or the default schema if none are defined
+func preparedSchemas(preparedDB acidv1.PreparedDatabase) map[string]acidv1.PreparedSchema {
+	if len(preparedDB.PreparedSchemas) == 0 {
+		return map[string]acidv1.PreparedSchema{defaultPreparedSchema: {}}
+	}
+	return preparedDB.PreparedSchemas
+}
+
+// hasDefaultRoles tells whether a schema of a prepared database gets its own owner, reader and writer roles
+func hasDefaultRoles(schema acidv1.PreparedSchema) bool {
+	return schema.DefaultRoles == nil || *schema.DefaultRoles
+}
+
+// preparedSchemaOwner returns the role owning a schema of a prepared database, the owner of the schema if
+// it has its own default roles, otherwise the owner of the database
+func preparedSchemaOwner(preparedDbName, schemaName string, schema acidv1.PreparedSchema) string {
+	if hasDefaultRoles(schema) {
+		return preparedDbName + "_" + schemaName + constants.OwnerRoleNameSuffix
+	}
+	return preparedDbName + constants.OwnerRoleNameSuffix
+}
+
+// preparedSearchPath lists the schemas of a prepared database after the schema named like the role
+func preparedSearchPath(schemas map[string]acidv1.PreparedSchema) string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(append([]string{`"$user"`}, names...), ", ")
+}
+
+// initPreparedDatabaseRoles defines the default roles of the prepared databases and their schemas:
+// NOLOGIN owner, reader and writer roles, where writers are members of the reader role, and optionally
+// login users that are members of one of them. The owner of a database administers the roles of its
+// schemas, and is administered by the team admin role if roles of manifest users are as well.
+func (c *Cluster) initPreparedDatabaseRoles() error {
+	// the names end up in role names and SQL statements, the manifest may not have been validated on admission
+	if err := validatePreparedDatabases(&c.Spec); err != nil {
+		return err
+	}
+	adminRole := ""
+	if c.OpConfig.EnableAdminRoleForUsers {
+		adminRole = c.OpConfig.TeamAdminRole
+	}
+
+	for preparedDbName, preparedDB := range c.Spec.PreparedDatabases {
+		schemas := preparedSchemas(preparedDB)
+		searchPath := preparedSearchPath(schemas)
+
+		if err := c.initDefaultRoles(preparedDbName, adminRole, searchPath, preparedDB.DefaultUsers); err != nil {
+			return fmt.Errorf("could not init default roles of database %q: %v", preparedDbName, err)
+		}
+		for schemaName, schema := range schemas {
+			if !hasDefaultRoles(schema) {
+				continue
+			}
+			prefix := preparedDbName + "_" + schemaName
+			if err := c.initDefaultRoles(prefix, preparedDbName+constants.OwnerRoleNameSuffix, searchPath, schema.DefaultUsers); err != nil {
+				return fmt.Errorf("could not init default roles of schema %q in database %q: %v", schemaName, preparedDbName, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) initDefaultRoles(prefix, adminRole, searchPath string, withUsers bool) error {
+	ownerRole := prefix + constants.OwnerRoleNameSuffix
+	defaultRoles := []struct {
+		suffix   string
+		memberOf string
+	}{
+		{constants.OwnerRoleNameSuffix, ""},
+		{constants.ReaderRoleNameSuffix, ""},
+		{constants.WriterRoleNameSuffix, constants.ReaderRoleNameSuffix},
+	}
+
+	for _, defaultRole := range defaultRoles {
+		roleName := prefix + defaultRole.suffix
+		roleAdmin := ownerRole
+		if roleName == ownerRole {
+			roleAdmin = adminRole
+		}
+		memberOf := make([]string, 0)
+		if defaultRole.memberOf != "" {
+			memberOf = append(memberOf, prefix+defaultRole.memberOf)
+		}
+		newRoles := []spec.PgUser{{
+			Origin:     spec.RoleOriginBootstrap,
+			Name:       roleName,
+			Flags:      []string{constants.RoleFlagNoLogin},
+			MemberOf:   memberOf,
+			Parameters: map[string]string{"search_path": searchPath},
+			AdminRole:  roleAdmin,
+		}}
+		if withUsers {
+			newRoles = append(newRoles, spec.PgUser{
+				Origin:     spec.RoleOriginBootstrap,
+				Name:       roleName + constants.UserRoleNameSuffix,
+				Password:   util.RandomPassword(constants.PasswordLength),
+				Flags:      []string{constants.RoleFlagLogin},
+				MemberOf:   []string{roleName},
+				Parameters: map[string]string{"search_path": searchPath},
+				AdminRole:  ownerRole,
+			})
+		}
+
+		for _, newRole := range newRoles {
+			if !isValidUsername(newRole.Name) {
+				return fmt.Errorf("invalid role name: %q", newRole.Name)
+			}
+			if c.shouldAvoidProtectedOrSystemRole(newRole.Name, "default role of a prepared database") {
+				continue
+			}
+			if currentRole, present := c.pgUsers[newRole.Name]; present {
+				c.pgUsers[newRole.Name] = c.resolveNameConflict(&currentRole, &newRole)
+			} else {
+				c.pgUsers[newRole.Name] = newRole
+			}
+		}
+	}
+	return nil
+}
+
+// syncPreparedDatabases creates the missing schemas of the prepared databases and sets the default
+// privileges, so that the reader and writer roles get access to the objects created by the owner roles.
+// The databases are expected to exist. The caller is responsible for closing the connection to the
+// default database beforehand.
+func (c *Cluster) syncPreparedDatabases() error {
+	c.setProcessName("syncing prepared databases")
+	if err := validatePreparedDatabases(&c.Spec); err != nil {
+		return err
+	}
+
+	for preparedDbName, preparedDB := range c.Spec.PreparedDatabases {
+		if err := c.initDbConnWithName(preparedDbName); err != nil {
+			return fmt.Errorf("could not init connection to database %q: %v", preparedDbName, err)
+		}
+		err := c.syncPreparedSchemas(preparedDbName, preparedSchemas(preparedDB))
+		if err2 := c.closeDbConn(); err2 != nil {
+			c.logger.Errorf("could not close database connection: %v", err2)
+		}
+		if err != nil {
+			return fmt.Errorf("could not sync prepared database %q: %v", preparedDbName, err)
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) syncPreparedSchemas(preparedDbName string, schemas map[string]acidv1.PreparedSchema) error {
+	schemaNames := make([]string, 0, len(schemas))
+	for schemaName := range schemas {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+
+	currentSchemas, err := c.getSchemas(schemaNames)
+	if err != nil {
+		return fmt.Errorf("could not get current schemas: %v", err)
+	}
+
+	dbOwner := preparedDbName + constants.OwnerRoleNameSuffix
+	dbReader := preparedDbName + constants.ReaderRoleNameSuffix
+	dbWriter := preparedDbName + constants.WriterRoleNameSuffix
+	for _, schemaName := range schemaNames {
+		schemaOwner := preparedSchemaOwner(preparedDbName, schemaName, schemas[schemaName])
+		if !currentSchemas[schemaName] {
+			c.logger.Infof("creating schema %q in database %q owned by %q", schemaName, preparedDbName, schemaOwner)
+			if _, err = c.pgDb.Exec(fmt.Sprintf(createSchemaSQL, pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(schemaOwner))); err != nil {
+				return fmt.Errorf("could not create schema %q: %v", schemaName, err)
+			}
+		}
+
+		// readers and writers of the database have access to the objects of every schema
+		privileges := [][4]string{{dbOwner, schemaName, dbReader, dbWriter}}
+		if schemaOwner != dbOwner {
+			schemaPrefix := preparedDbName + "_" + schemaName
+			privileges = append(privileges,
+				[4]string{schemaOwner, schemaName, dbReader, dbWriter},
+				[4]string{schemaOwner, schemaName, schemaPrefix + constants.ReaderRoleNameSuffix,
+					schemaPrefix + constants.WriterRoleNameSuffix})
+		}
+		for _, p := range privileges {
+			if _, err = c.pgDb.Exec(fmt.Sprintf(alterDefaultPrivilegesSQL, pq.QuoteIdentifier(p[0]),
+				pq.QuoteIdentifier(p[1]), pq.QuoteIdentifier(p[2]), pq.QuoteIdentifier(p[3]))); err != nil {
+				return fmt.Errorf("could not alter default privileges of role %q in schema %q: %v", p[0], schemaName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getSchemas tells which of the given schemas exist in the database of the connection.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) getSchemas(schemaNames []string) (schemas map[string]bool, err error) {
+	rows, err := c.pgDb.Query(getSchemasSQL, pq.Array(schemaNames))
+	if err != nil {
+		return nil, fmt.Errorf("could not query database: %v", err)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = fmt.Errorf("error when closing query cursor: %v", err2)
+		}
+	}()
+
+	schemas = make(map[string]bool)
+	for rows.Next() {
+		var schemaName string
+		if err = rows.Scan(&schemaName); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		schemas[schemaName] = true
+	}
+	return schemas, nil
+}
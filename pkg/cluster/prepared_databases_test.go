@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func TestPreparedSearchPath(t *testing.T) {
+	testName := "TestPreparedSearchPath"
+	tests := []struct {
+		preparedDB acidv1.PreparedDatabase
+		expected   string
+	}{
+		{acidv1.PreparedDatabase{}, `"$user", data`},
+		{acidv1.PreparedDatabase{PreparedSchemas: map[string]acidv1.PreparedSchema{"foo": {}, "bar": {}}}, `"$user", bar, foo`},
+	}
+
+	for _, tt := range tests {
+		if result := preparedSearchPath(preparedSchemas(tt.preparedDB)); result != tt.expected {
+			t.Errorf("%s: expected search path %q, got %q", testName, tt.expected, result)
+		}
+	}
+}
+
+func TestInitPreparedDatabaseRoles(t *testing.T) {
+	testName := "TestInitPreparedDatabaseRoles"
+	noDefaultRoles := false
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Auth: config.Auth{
+					SuperUsername:       superUserName,
+					ReplicationUsername: replicationUserName,
+				},
+			},
+		}, k8sutil.NewMockKubernetesClient(),
+		acidv1.Postgresql{
+			Spec: acidv1.PostgresSpec{
+				PreparedDatabases: map[string]acidv1.PreparedDatabase{
+					"foo": {
+						PreparedSchemas: map[string]acidv1.PreparedSchema{
+							"bar": {DefaultUsers: true},
+							"baz": {DefaultRoles: &noDefaultRoles},
+						},
+					},
+				},
+			},
+		}, logger, eventRecorder)
+	cluster.pgUsers = map[string]spec.PgUser{}
+
+	if err := cluster.initPreparedDatabaseRoles(); err != nil {
+		t.Fatalf("%s: could not init roles: %v", testName, err)
+	}
+
+	searchPath := map[string]string{"search_path": `"$user", bar, baz`}
+	expected := map[string]spec.PgUser{
+		"foo_owner":  {Name: "foo_owner", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{}},
+		"foo_reader": {Name: "foo_reader", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{}, AdminRole: "foo_owner"},
+		"foo_writer": {Name: "foo_writer", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{"foo_reader"}, AdminRole: "foo_owner"},
+		"foo_bar_owner": {Name: "foo_bar_owner", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{},
+			AdminRole: "foo_owner"},
+		"foo_bar_reader": {Name: "foo_bar_reader", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{},
+			AdminRole: "foo_bar_owner"},
+		"foo_bar_writer": {Name: "foo_bar_writer", Flags: []string{constants.RoleFlagNoLogin}, MemberOf: []string{"foo_bar_reader"},
+			AdminRole: "foo_bar_owner"},
+		"foo_bar_owner_user": {Name: "foo_bar_owner_user", Flags: []string{constants.RoleFlagLogin}, MemberOf: []string{"foo_bar_owner"},
+			AdminRole: "foo_bar_owner"},
+		"foo_bar_reader_user": {Name: "foo_bar_reader_user", Flags: []string{constants.RoleFlagLogin}, MemberOf: []string{"foo_bar_reader"},
+			AdminRole: "foo_bar_owner"},
+		"foo_bar_writer_user": {Name: "foo_bar_writer_user", Flags: []string{constants.RoleFlagLogin}, MemberOf: []string{"foo_bar_writer"},
+			AdminRole: "foo_bar_owner"},
+	}
+
+	if len(cluster.pgUsers) != len(expected) {
+		t.Errorf("%s: expected %d roles, got %d: %v", testName, len(expected), len(cluster.pgUsers), cluster.pgUsers)
+	}
+	for name, expectedRole := range expected {
+		role, exists := cluster.pgUsers[name]
+		if !exists {
+			t.Errorf("%s: expected role %q", testName, name)
+			continue
+		}
+		if role.Origin != spec.RoleOriginBootstrap {
+			t.Errorf("%s: expected role %q to be a bootstrapped role, got %s", testName, name, role.Origin)
+		}
+		isUser := expectedRole.Flags[0] == constants.RoleFlagLogin
+		if (role.Password != "") != isUser {
+			t.Errorf("%s: expected only login users to have a password, role %q has %q", testName, name, role.Password)
+		}
+		role.Origin, role.Password = 0, ""
+		expectedRole.Parameters = searchPath
+		if !reflect.DeepEqual(role, expectedRole) {
+			t.Errorf("%s: expected role %#v, got %#v", testName, expectedRole, role)
+		}
+	}
+}
+
+func TestInitPreparedDatabaseRolesInvalidSchema(t *testing.T) {
+	testName := "TestInitPreparedDatabaseRolesInvalidSchema"
+	cluster := New(
+		Config{}, k8sutil.NewMockKubernetesClient(),
+		acidv1.Postgresql{
+			Spec: acidv1.PostgresSpec{
+				PreparedDatabases: map[string]acidv1.PreparedDatabase{
+					"foo": {PreparedSchemas: map[string]acidv1.PreparedSchema{`bar"; DROP SCHEMA public; --`: {}}},
+				},
+			},
+		}, logger, eventRecorder)
+	cluster.pgUsers = map[string]spec.PgUser{}
+
+	if err := cluster.initPreparedDatabaseRoles(); err == nil {
+		t.Errorf("%s: expected an error for the invalid schema name", testName)
+	}
+	if len(cluster.pgUsers) != 0 {
+		t.Errorf("%s: expected no roles, got %v", testName, cluster.pgUsers)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
 	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
 	"github.com/zalando/postgres-operator/pkg/util/retryutil"
 )
@@ -235,42 +236,47 @@ func getPodIndex(podName string) (int32, error) {
 	return int32(res), nil
 }
 
-func (c *Cluster) preScaleDown(newStatefulSet *appsv1.StatefulSet) error {
+// preScaleDown switches over to the first pod if the scale down would remove the master pod. Outside of
+// the maintenance windows the switchover is postponed, which is reported so that the scale down waits as well.
+func (c *Cluster) preScaleDown(newStatefulSet *appsv1.StatefulSet) (postponed bool, err error) {
 	masterPod, err := c.getRolePods(Master)
 	if err != nil {
-		return fmt.Errorf("could not get master pod: %v", err)
+		return false, fmt.Errorf("could not get master pod: %v", err)
 	}
 	if len(masterPod) == 0 {
-		return fmt.Errorf("no master pod is running in the cluster")
+		return false, fmt.Errorf("no master pod is running in the cluster")
 	}
 
 	podNum, err := getPodIndex(masterPod[0].Name)
 	if err != nil {
-		return fmt.Errorf("could not get pod number: %v", err)
+		return false, fmt.Errorf("could not get pod number: %v", err)
 	}
 
 	//Check if scale down affects current master pod
 	if *newStatefulSet.Spec.Replicas >= podNum+1 {
-		return nil
+		return false, nil
+	}
+	if !c.allowDisruptiveAction(maintenanceScaleDownSwitchover) {
+		return true, nil
 	}
 
 	podName := fmt.Sprintf("%s-0", c.Statefulset.Name)
 	masterCandidatePod, err := c.KubeClient.Pods(c.clusterNamespace()).Get(context.TODO(), podName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("could not get master candidate pod: %v", err)
+		return false, fmt.Errorf("could not get master candidate pod: %v", err)
 	}
 
 	// some sanity check
 	if !util.MapContains(masterCandidatePod.Labels, c.OpConfig.ClusterLabels) ||
 		!util.MapContains(masterCandidatePod.Labels, map[string]string{c.OpConfig.ClusterNameLabel: c.Name}) {
-		return fmt.Errorf("pod %q does not belong to cluster", podName)
+		return false, fmt.Errorf("pod %q does not belong to cluster", podName)
 	}
 
 	if err := c.patroni.Switchover(&masterPod[0], masterCandidatePod.Name); err != nil {
-		return fmt.Errorf("could not failover: %v", err)
+		return false, fmt.Errorf("could not failover: %v", err)
 	}
 
-	return nil
+	return false, nil
 }
 
 // setRollingUpdateFlagForStatefulSet sets the indicator or the rolling update requirement
@@ -375,9 +381,16 @@ func (c *Cluster) updateStatefulSet(newStatefulSet *appsv1.StatefulSet) error {
 
 	//scale down
 	if *c.Statefulset.Spec.Replicas > *newStatefulSet.Spec.Replicas {
-		if err := c.preScaleDown(newStatefulSet); err != nil {
+		postponed, err := c.preScaleDown(newStatefulSet)
+		if err != nil {
 			c.logger.Warningf("could not scale down: %v", err)
 		}
+		if postponed {
+			// removing the master pod without a switchover would cause a failover
+			newStatefulSet = newStatefulSet.DeepCopy()
+			replicas := *c.Statefulset.Spec.Replicas
+			newStatefulSet.Spec.Replicas = &replicas
+		}
 	}
 	c.logger.Debugf("updating statefulset")
 
@@ -723,7 +736,7 @@ func (c *Cluster) deleteEndpoint(role PostgresRole) error {
 func (c *Cluster) deleteSecret(secret *v1.Secret) error {
 	c.setProcessName("deleting secret %q", util.NameFromMeta(secret.ObjectMeta))
 	c.logger.Debugf("deleting secret %q", util.NameFromMeta(secret.ObjectMeta))
-	err := c.KubeClient.Secrets(secret.Namespace).Delete(context.TODO(), secret.Name, c.deleteOptions)
+	err := c.SecretBackend.Delete(secret.Namespace, secret.Name)
 	if err != nil {
 		return err
 	}
@@ -733,6 +746,25 @@ func (c *Cluster) deleteSecret(secret *v1.Secret) error {
 	return err
 }
 
+// deletePodSecrets removes the Kubernetes secrets kept for the pods when credentials are stored in another
+// secret backend. Like the credentials in the backend, the ones of the superuser and the replication user
+// are kept for the case the cluster is created again from its volumes.
+func (c *Cluster) deletePodSecrets() {
+	if c.SecretBackend.Name() == constants.SecretBackendKubernetes {
+		return
+	}
+	for _, systemUser := range c.systemUsers {
+		if systemUser.Name == c.OpConfig.SuperUsername || systemUser.Name == c.OpConfig.ReplicationUsername {
+			continue
+		}
+		secretName := c.credentialSecretName(systemUser.Name)
+		err := c.KubeClient.Secrets(c.Namespace).Delete(context.TODO(), secretName, c.deleteOptions)
+		if err != nil && !k8sutil.ResourceNotFound(err) {
+			c.logger.Warningf("could not delete pod secret %q: %v", secretName, err)
+		}
+	}
+}
+
 func (c *Cluster) createRoles() (err error) {
 	// TODO: figure out what to do with duplicate names (humans and robots) among pgUsers
 	return c.syncRoles()
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zalando/postgres-operator/pkg/util"
+)
+
+const (
+	// the same WAL-E/WAL-G choice Spilo makes for its scheduled base backups
+	backupListCommand = `envdir /run/etc/wal-e.d/env sh -c 'if [ "$USE_WALG_BACKUP" = "true" ]; then wal-g backup-list; else wal-e backup-list; fi' 2>/dev/null`
+	// removes the base backups and the WAL under the prefix of the cluster itself, not the one it was cloned from
+	deleteBackupsCommand = `envdir /run/etc/wal-e.d/env sh -c 'if [ "$USE_WALG_BACKUP" = "true" ]; then wal-g delete everything FORCE --confirm; else wal-e delete --confirm everything; fi'`
+	// the history file of the timeline the recovery switched to ends with the LSN the recovery stopped at. The
+	// timeline is taken from the name of the current WAL file, the first 8 hex digits, as the timeline of the last
+	// checkpoint still is the one of the recovery until the first checkpoint after a fast promotion.
+	timelineHistoryCommand = `psql -tAc "SELECT pg_read_file('pg_wal/' || substr(pg_walfile_name(pg_current_wal_lsn()), 1, 8) || '.history')" 2>&1`
+)
+
+var lsnRegexp = regexp.MustCompile(`^[0-9A-Fa-f]+/[0-9A-Fa-f]+$`)
+
+// ListBasebackups returns the modification times of the base backups of the cluster in its WAL bucket
+func (c *Cluster) ListBasebackups() ([]time.Time, error) {
+	masterPod, err := c.getClusterMasterPod(c.Name)
+	if err != nil {
+		return nil, fmt.Errorf("could not get master pod: %v", err)
+	}
+	podName := util.NameFromMeta(masterPod.ObjectMeta)
+
+	output, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", backupListCommand)
+	if err != nil {
+		return nil, fmt.Errorf("could not list base backups: %v", err)
+	}
+	return parseBackupList(output)
+}
+
+// DeleteBackups deletes all base backups and the archived WAL of the cluster from its WAL bucket. The cluster
+// keeps archiving its WAL until its pods are gone.
+func (c *Cluster) DeleteBackups() error {
+	masterPod, err := c.getClusterMasterPod(c.Name)
+	if err != nil {
+		return fmt.Errorf("could not get master pod: %v", err)
+	}
+	podName := util.NameFromMeta(masterPod.ObjectMeta)
+
+	c.logger.Infof("deleting base backups and WAL of cluster %q", c.Name)
+	if _, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", deleteBackupsCommand); err != nil {
+		return fmt.Errorf("could not delete backups: %v", err)
+	}
+	return nil
+}
+
+// parseBackupList parses the output of backup-list of WAL-E and WAL-G. Both print a header and then one line
+// per backup with its name and modification time in the first two columns.
+func parseBackupList(output string) ([]time.Time, error) {
+	result := make([]time.Time, 0)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] == "name" {
+			continue
+		}
+		modified, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse modification time of base backup %q: %v", fields[0], err)
+		}
+		result = append(result, modified)
+	}
+	return result, nil
+}
+
+// RecoveryEndLSN returns the LSN at which the point-in-time recovery of a cloned cluster stopped
+func (c *Cluster) RecoveryEndLSN() (string, error) {
+	masterPod, err := c.getClusterMasterPod(c.Name)
+	if err != nil {
+		return "", fmt.Errorf("could not get master pod: %v", err)
+	}
+	podName := util.NameFromMeta(masterPod.ObjectMeta)
+
+	output, err := c.ExecCommand(&podName, "/bin/su", "postgres", "-c", timelineHistoryCommand)
+	if err != nil {
+		return "", fmt.Errorf("could not read timeline history: %v", err)
+	}
+	return parseTimelineHistory(output)
+}
+
+// parseTimelineHistory returns the switch point of the last entry of a timeline history file. Every entry
+// consists of the parent timeline, the switch point and the reason, separated by tabs.
+func parseTimelineHistory(history string) (string, error) {
+	lsn := ""
+	for _, line := range strings.Split(history, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		lsn = fields[1]
+	}
+	if !lsnRegexp.MatchString(lsn) {
+		return "", fmt.Errorf("no switch point found in timeline history %q", strings.TrimSpace(history))
+	}
+	return lsn, nil
+}
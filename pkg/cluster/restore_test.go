@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBackupList(t *testing.T) {
+	testName := "TestParseBackupList"
+	tests := []struct {
+		subTest  string
+		output   string
+		expected []time.Time
+		err      bool
+	}{
+		{
+			subTest: "WAL-E",
+			output: "name\tlast_modified\texpanded_size_bytes\twal_segment_backup_start\twal_segment_offset_backup_start\n" +
+				"base_000000010000000000000002_00000040\t2020-06-01T10:00:00.000Z\t\t000000010000000000000002\t00000040\n",
+			expected: []time.Time{time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			subTest: "WAL-G",
+			output: "name                          modified             wal_segment_backup_start\n" +
+				"base_000000010000000000000002 2020-06-01T10:00:00Z 000000010000000000000002\n" +
+				"base_000000010000000000000004 2020-06-02T10:00:00Z 000000010000000000000004\n",
+			expected: []time.Time{time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC), time.Date(2020, 6, 2, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			subTest:  "no backups",
+			output:   "name\tlast_modified\texpanded_size_bytes\n",
+			expected: []time.Time{},
+		},
+		{
+			subTest: "invalid modification time",
+			output:  "base_000000010000000000000002 yesterday\n",
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		backups, err := parseBackupList(tt.output)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(backups, tt.expected) {
+			t.Errorf("%s %s: expected base backups %v, got %v", testName, tt.subTest, tt.expected, backups)
+		}
+	}
+}
+
+func TestParseTimelineHistory(t *testing.T) {
+	testName := "TestParseTimelineHistory"
+	tests := []struct {
+		history string
+		lsn     string
+		err     bool
+	}{
+		{"1\t0/3000148\tbefore 2020-06-01 10:00:00+00\n", "0/3000148", false},
+		{"1\t0/3000148\tno recovery target specified\n\n2\t1/A2000060\tbefore 2020-06-02 10:00:00+00\n", "1/A2000060", false},
+		{"ERROR:  could not open file \"pg_wal/00000001.history\": No such file or directory\n", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		lsn, err := parseTimelineHistory(tt.history)
+		if lsn != tt.lsn || (err != nil) != tt.err {
+			t.Errorf("%s: expected %q and error %t for %q, got %q and error %v", testName, tt.lsn, tt.err, tt.history, lsn, err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+const (
+	restoreVerificationDefaultDatabase = "postgres"
+	// only succeeds once the clone has finished the recovery and was promoted
+	restoreVerificationDefaultCheck = "SELECT NOT pg_is_in_recovery()"
+	// checks run as the owner of the database, unless it is a superuser
+	getRestoreVerificationRoleSQL = `SELECT CASE WHEN NOT r.rolsuper THEN r.rolname
+	 WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = 'pg_monitor') THEN 'pg_monitor' END
+	 FROM pg_catalog.pg_database d
+	 JOIN pg_catalog.pg_roles r ON (r.oid = d.datdba)
+	 WHERE d.datname = current_database();`
+	setLocalRoleSQL = `SET LOCAL ROLE %s;`
+)
+
+// restoreVerificationStatement returns the statement of a check without a trailing semicolon. Checks have to
+// be a single statement, which is why they cannot contain any other semicolon.
+func restoreVerificationStatement(check string) (string, error) {
+	statement := strings.TrimSpace(check)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+	if statement == "" {
+		return "", fmt.Errorf("restore verification checks cannot be empty")
+	}
+	if strings.Contains(statement, ";") {
+		return "", fmt.Errorf("restore verification check %q has to be a single statement", check)
+	}
+	return statement, nil
+}
+
+// restoreVerificationInterval returns the time between two verifications that the cluster can be restored from
+// its backups, or zero when the cluster is not verified
+func (c *Cluster) restoreVerificationInterval() time.Duration {
+	verification := c.Spec.RestoreVerification
+	if verification == nil && !c.OpConfig.EnableRestoreVerification {
+		return 0
+	}
+	// neither the clones of the verification nor standby clusters, which have no backups of their own, are verified
+	if _, isClone := c.Annotations[constants.RestoreVerificationAnnotationKey]; isClone || c.Spec.StandbyCluster != nil {
+		return 0
+	}
+
+	interval := c.OpConfig.RestoreVerificationInterval
+	if verification != nil && verification.Interval != "" {
+		clusterInterval, err := time.ParseDuration(verification.Interval)
+		if err != nil {
+			c.logger.Warningf("could not parse restore verification interval %q, using %v: %v",
+				verification.Interval, interval, err)
+		} else {
+			interval = clusterInterval
+		}
+	}
+	if interval < 0 {
+		return 0
+	}
+	return interval
+}
+
+// RestoreVerificationDue checks whether the cluster has to be verified again. A verification that was still
+// running when the operator stopped is resumed.
+func (c *Cluster) RestoreVerificationDue(now time.Time) bool {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+
+	interval := c.restoreVerificationInterval()
+	if interval == 0 || c.Spec.NumberOfInstances <= 0 || !c.Status.Running() {
+		return false
+	}
+	status := c.Status.RestoreVerification
+	if status == nil || status.StartTime == nil || status.Phase == acidv1.VerificationPhaseRunning {
+		return true
+	}
+	return !now.Before(status.StartTime.Add(interval))
+}
+
+// RestoreVerificationChecks returns the database the checks of the restore verification run in and the checks
+func (c *Cluster) RestoreVerificationChecks() (string, []string) {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+
+	database, checks := restoreVerificationDefaultDatabase, []string{restoreVerificationDefaultCheck}
+	if verification := c.Spec.RestoreVerification; verification != nil {
+		if verification.Database != "" {
+			database = verification.Database
+		}
+		if len(verification.Checks) > 0 {
+			checks = verification.Checks
+		}
+	}
+	return database, checks
+}
+
+// GetRestoreVerificationStatus returns a copy of the status of the last restore verification, nil if the
+// cluster was never verified
+func (c *Cluster) GetRestoreVerificationStatus() *acidv1.RestoreVerificationStatus {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+	return c.Status.RestoreVerification.DeepCopy()
+}
+
+// SetRestoreVerificationStatus stores the status of the restore verification in the status of the cluster
+func (c *Cluster) SetRestoreVerificationStatus(status *acidv1.RestoreVerificationStatus) error {
+	statusData, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not marshal restore verification status: %v", err)
+	}
+	patchStatus := make(map[string]interface{})
+	if err := json.Unmarshal(statusData, &patchStatus); err != nil {
+		return fmt.Errorf("could not unmarshal restore verification status: %v", err)
+	}
+	// a merge patch only removes the fields of an earlier verification when they are explicitly set to null
+	for _, field := range []string{"completionTime", "recoveryTime", "message"} {
+		if _, ok := patchStatus[field]; !ok {
+			patchStatus[field] = nil
+		}
+	}
+
+	pg, err := c.patchStatus(map[string]interface{}{"restoreVerification": patchStatus})
+	if err != nil {
+		return fmt.Errorf("could not update restore verification status: %v", err)
+	}
+	// only take over the status, the spec in memory may be adjusted by an ongoing sync
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+	return nil
+}
+
+// RunRestoreVerificationChecks runs the checks of a restore verification in a restored cluster. Every check
+// has to be a single statement returning a single row with a true boolean. The checks run in read-only
+// transactions as the owner of the database, or as the pg_monitor role if the owner is a superuser. The
+// subscriptions restored from the source cluster are dropped beforehand.
+func (c *Cluster) RunRestoreVerificationChecks(database string, checks []string) error {
+	if c.databaseAccessDisabled() {
+		return fmt.Errorf("database access is disabled")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.dropRestoredSubscriptions(); err != nil {
+		return err
+	}
+
+	c.setProcessName("running restore verification checks")
+	if err := c.initDbConnWithName(database); err != nil {
+		return fmt.Errorf("could not connect to database %q: %v", database, err)
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+
+	var role sql.NullString
+	if err := c.pgDb.QueryRow(getRestoreVerificationRoleSQL).Scan(&role); err != nil {
+		return fmt.Errorf("could not get the role to run the checks as: %v", err)
+	}
+	if !role.Valid {
+		return fmt.Errorf("the owner of database %q is a superuser and there is no pg_monitor role to run the checks as", database)
+	}
+
+	for _, check := range checks {
+		if err := c.runRestoreVerificationCheck(role.String, check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropRestoredSubscriptions drops the subscriptions a restored cluster took over from its source. They still
+// use the connections and slots of the source, the slots are detached before the subscriptions are dropped
+// so that dropping them does not drop the slots on the publishing cluster as well.
+func (c *Cluster) dropRestoredSubscriptions() error {
+	c.setProcessName("dropping restored subscriptions")
+	if err := c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	serverVersion, err := c.getServerVersion()
+	subscriptions := make(map[string]*currentSubscription)
+	if err == nil && serverVersion >= logicalReplicationMinServerVersion {
+		subscriptions, err = c.getSubscriptions()
+	}
+	if err2 := c.closeDbConn(); err2 != nil {
+		c.logger.Errorf("could not close database connection: %v", err2)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get restored subscriptions: %v", err)
+	}
+
+	databases := make(map[string][]string)
+	for name, subscription := range subscriptions {
+		databases[subscription.database] = append(databases[subscription.database], name)
+	}
+	for datname, names := range databases {
+		if err := c.initDbConnWithName(datname); err != nil {
+			return fmt.Errorf("could not init connection to database %q: %v", datname, err)
+		}
+		for _, name := range names {
+			c.logger.Infof("dropping restored subscription %q in database %q", name, datname)
+			if err = c.execStatements(dropSubscriptionStatements(name)); err != nil {
+				err = fmt.Errorf("could not drop restored subscription %q: %v", name, err)
+				break
+			}
+		}
+		if err2 := c.closeDbConn(); err2 != nil {
+			c.logger.Errorf("could not close database connection: %v", err2)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) runRestoreVerificationCheck(role, check string) (err error) {
+	statement, err := restoreVerificationStatement(check)
+	if err != nil {
+		return err
+	}
+
+	tx, err := c.pgDb.BeginTx(context.TODO(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %v", err)
+	}
+	defer func() {
+		if err2 := tx.Rollback(); err2 != nil && err == nil {
+			err = fmt.Errorf("could not roll back the transaction of check %q: %v", check, err2)
+		}
+	}()
+
+	if _, err = tx.Exec(fmt.Sprintf(setLocalRoleSQL, pq.QuoteIdentifier(role))); err != nil {
+		return fmt.Errorf("could not set role %q: %v", role, err)
+	}
+	// a prepared statement cannot consist of several statements
+	stmt, err := tx.Prepare(statement)
+	if err != nil {
+		return fmt.Errorf("could not prepare check %q: %v", check, err)
+	}
+	defer stmt.Close()
+
+	var passed bool
+	if err = stmt.QueryRow().Scan(&passed); err != nil {
+		return fmt.Errorf("could not run check %q: %v", check, err)
+	}
+	if !passed {
+		return fmt.Errorf("check %q failed", check)
+	}
+	return nil
+}
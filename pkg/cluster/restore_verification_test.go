@@ -0,0 +1,161 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+func newRestoreVerificationTestCluster(enabled bool, pg acidv1.Postgresql) *Cluster {
+	pg.Name = "acid-test-cluster"
+	pg.Namespace = "default"
+	if pg.Spec.NumberOfInstances == 0 {
+		pg.Spec.NumberOfInstances = 2
+	}
+	if pg.Status.PostgresClusterStatus == "" {
+		pg.Status.PostgresClusterStatus = acidv1.ClusterStatusRunning
+	}
+	return New(
+		Config{
+			OpConfig: config.Config{
+				RestoreVerification: config.RestoreVerification{
+					EnableRestoreVerification:   enabled,
+					RestoreVerificationInterval: 168 * time.Hour,
+				},
+			},
+		}, k8sutil.KubernetesClient{}, pg, logger, eventRecorder)
+}
+
+func TestRestoreVerificationDue(t *testing.T) {
+	testName := "TestRestoreVerificationDue"
+	now := time.Now()
+	yesterday := metav1.NewTime(now.Add(-24 * time.Hour))
+	lastMonth := metav1.NewTime(now.AddDate(0, -1, 0))
+
+	tests := []struct {
+		subTest  string
+		enabled  bool
+		pg       acidv1.Postgresql
+		expected bool
+	}{
+		{
+			subTest:  "disabled",
+			expected: false,
+		},
+		{
+			subTest:  "never verified",
+			enabled:  true,
+			expected: true,
+		},
+		{
+			subTest: "verified within the interval of the operator",
+			enabled: true,
+			pg: acidv1.Postgresql{
+				Status: acidv1.PostgresStatus{RestoreVerification: &acidv1.RestoreVerificationStatus{
+					Phase: acidv1.VerificationPhaseSucceeded, StartTime: &yesterday}},
+			},
+			expected: false,
+		},
+		{
+			subTest: "interval of the cluster passed",
+			pg: acidv1.Postgresql{
+				Spec: acidv1.PostgresSpec{RestoreVerification: &acidv1.RestoreVerification{Interval: "12h"}},
+				Status: acidv1.PostgresStatus{RestoreVerification: &acidv1.RestoreVerificationStatus{
+					Phase: acidv1.VerificationPhaseFailed, StartTime: &yesterday}},
+			},
+			expected: true,
+		},
+		{
+			subTest: "interrupted verification",
+			enabled: true,
+			pg: acidv1.Postgresql{
+				Status: acidv1.PostgresStatus{RestoreVerification: &acidv1.RestoreVerificationStatus{
+					Phase: acidv1.VerificationPhaseRunning, StartTime: &lastMonth}},
+			},
+			expected: true,
+		},
+		{
+			subTest: "clone of a verification",
+			enabled: true,
+			pg: acidv1.Postgresql{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"acid.zalan.do/restore-verification-of": "acid-source-cluster"},
+				},
+			},
+			expected: false,
+		},
+		{
+			subTest: "standby cluster",
+			enabled: true,
+			pg: acidv1.Postgresql{
+				Spec: acidv1.PostgresSpec{StandbyCluster: &acidv1.StandbyDescription{S3WalPath: "s3://standby"}},
+			},
+			expected: false,
+		},
+		{
+			subTest: "cluster not running",
+			enabled: true,
+			pg: acidv1.Postgresql{
+				Status: acidv1.PostgresStatus{PostgresClusterStatus: acidv1.ClusterStatusSyncFailed},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newRestoreVerificationTestCluster(tt.enabled, tt.pg)
+		if due := cluster.RestoreVerificationDue(now); due != tt.expected {
+			t.Errorf("%s %s: expected due %t, got %t", testName, tt.subTest, tt.expected, due)
+		}
+	}
+}
+
+func TestRestoreVerificationChecks(t *testing.T) {
+	testName := "TestRestoreVerificationChecks"
+
+	cluster := newRestoreVerificationTestCluster(true, acidv1.Postgresql{})
+	database, checks := cluster.RestoreVerificationChecks()
+	if database != "postgres" || !reflect.DeepEqual(checks, []string{"SELECT NOT pg_is_in_recovery()"}) {
+		t.Errorf("%s: unexpected default checks %v in database %q", testName, checks, database)
+	}
+
+	orderCheck := []string{"SELECT count(*) > 0 FROM orders"}
+	cluster = newRestoreVerificationTestCluster(false, acidv1.Postgresql{
+		Spec: acidv1.PostgresSpec{RestoreVerification: &acidv1.RestoreVerification{Database: "shop", Checks: orderCheck}},
+	})
+	database, checks = cluster.RestoreVerificationChecks()
+	if database != "shop" || !reflect.DeepEqual(checks, orderCheck) {
+		t.Errorf("%s: expected the checks of the manifest, got %v in database %q", testName, checks, database)
+	}
+}
+
+func TestRestoreVerificationStatement(t *testing.T) {
+	testName := "TestRestoreVerificationStatement"
+	tests := []struct {
+		check     string
+		statement string
+		err       bool
+	}{
+		{"SELECT count(*) > 0 FROM orders", "SELECT count(*) > 0 FROM orders", false},
+		{" SELECT NOT pg_is_in_recovery(); ", "SELECT NOT pg_is_in_recovery()", false},
+		{"SELECT true; DROP TABLE orders", "", true},
+		{"SELECT true;;", "", true},
+		{" ; ", "", true},
+	}
+
+	for _, tt := range tests {
+		statement, err := restoreVerificationStatement(tt.check)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %t for check %q, got %v", testName, tt.err, tt.check, err)
+		}
+		if statement != tt.statement {
+			t.Errorf("%s: expected statement %q for check %q, got %q", testName, tt.statement, tt.check, statement)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+// roleNamesToSync returns the names of the roles to read from the database: the roles defined for the
+// cluster, the deprecated roles that might be restored with their names and, if the deprecation is
+// enabled, the roles that have been removed from the manifest or the Teams API, which are partly found
+// by the credential secrets of the cluster.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) roleNamesToSync(secrets []v1.Secret) ([]string, error) {
+	userNames := make([]string, 0, len(c.pgUsers))
+	for _, u := range c.pgUsers {
+		userNames = append(userNames, u.Name)
+	}
+
+	if c.OpConfig.EnableRoleDeprecation {
+		removedRoles, err := c.removedRoles(secrets)
+		if err != nil {
+			return nil, err
+		}
+		userNames = append(userNames, removedRoles...)
+	}
+
+	if c.OpConfig.RoleDeletionSuffix == "" {
+		return userNames, nil
+	}
+	deprecatedNames := make([]string, 0, len(userNames))
+	for _, name := range userNames {
+		deprecatedNames = append(deprecatedNames, name+c.OpConfig.RoleDeletionSuffix)
+	}
+
+	return append(userNames, deprecatedNames...), nil
+}
+
+// removedRoles returns the names of the roles the operator has created for the cluster that are not
+// defined anymore. Robot and infrastructure roles are found by their credential secrets, the roles of
+// team members by their membership in the PAM role. Protected and system roles are never returned.
+func (c *Cluster) removedRoles(secrets []v1.Secret) ([]string, error) {
+	removed := make([]string, 0)
+	isRemoved := func(name string) bool {
+		if _, exists := c.pgUsers[name]; exists {
+			return false
+		}
+		for _, systemUser := range c.systemUsers {
+			if systemUser.Name == name {
+				return false
+			}
+		}
+		if c.Spec.ConnectionPool != nil && c.Spec.ConnectionPool.User == name {
+			return false
+		}
+		return !c.isProtectedUsername(name) && !c.isSystemUsername(name) &&
+			name != c.OpConfig.ConnectionPool.User &&
+			name != c.OpConfig.PamRoleName && name != c.OpConfig.TeamAdminRole &&
+			!strings.HasSuffix(name, c.OpConfig.RoleDeletionSuffix)
+	}
+
+	if c.OpConfig.InfrastructureRolesSecretName != (spec.NamespacedName{}) && len(c.InfrastructureRoles) == 0 {
+		c.logger.Warningf("infrastructure roles are not available, skipping the deprecation of roles with secrets")
+	} else {
+		for _, secret := range secrets {
+			usernames := []string{string(secret.Data["username"])}
+			// secrets of users with rotated passwords hold the credentials of the current rotation role
+			if rotatedUser := secret.Annotations[constants.PasswordRotationUserAnnotationKey]; rotatedUser != "" {
+				usernames = append([]string{rotatedUser}, usernames...)
+			}
+			if usernames[0] == "" || secret.Name != c.credentialSecretName(usernames[0]) {
+				continue
+			}
+			for _, username := range usernames {
+				if isRemoved(username) {
+					removed = append(removed, username)
+				}
+			}
+		}
+	}
+
+	if c.OpConfig.EnableTeamsAPI && c.OpConfig.PamRoleName != "" {
+		if c.teamMembersUnknown {
+			c.logger.Warningf("team members are not available, skipping the deprecation of their roles")
+			return removed, nil
+		}
+		members, err := c.getRoleMembers(c.OpConfig.PamRoleName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get members of the role %q: %v", c.OpConfig.PamRoleName, err)
+		}
+		for _, member := range members {
+			if isRemoved(member) {
+				removed = append(removed, member)
+			}
+		}
+	}
+
+	return removed, nil
+}
+
+// processDeprecatedRoles deletes the secrets of the roles deprecated by the last sync and keeps track
+// of the deprecated roles in the cluster status. Deprecated roles are dropped once their grace period
+// has passed, if configured.
+func (c *Cluster) processDeprecatedRoles(reqs []spec.PgSyncUserRequest, secrets []v1.Secret) {
+	deprecatedRoles := make(map[string]acidv1.DeprecatedRole)
+	for _, role := range c.Status.DeprecatedRoles {
+		deprecatedRoles[role.Name] = role
+	}
+	changed := false
+
+	for _, r := range reqs {
+		switch r.Kind {
+		case spec.PGSyncUserDeprecate:
+			c.logger.Infof("role %q has been deprecated and renamed to %q",
+				r.User.Name, r.User.Name+c.OpConfig.RoleDeletionSuffix)
+			c.recordEvent(v1.EventTypeNormal, "Roles", "Deprecated role %q, it has been removed from the cluster definition", r.User.Name)
+			c.deleteRoleSecret(r.User.Name, secrets)
+			deprecatedRoles[r.User.Name] = acidv1.DeprecatedRole{Name: r.User.Name, DeprecationTime: metav1.Now()}
+			changed = true
+		case spec.PGSyncUserRename:
+			c.logger.Infof("deprecated role %q has been restored", r.User.Name)
+			c.recordEvent(v1.EventTypeNormal, "Roles", "Restored deprecated role %q", r.User.Name)
+			if _, exists := deprecatedRoles[r.User.Name]; exists {
+				delete(deprecatedRoles, r.User.Name)
+				changed = true
+			}
+		}
+	}
+
+	if c.OpConfig.EnableRoleDrop {
+		for name, role := range deprecatedRoles {
+			if time.Since(role.DeprecationTime.Time) < c.OpConfig.RoleDropGracePeriod {
+				continue
+			}
+			roleName := name + c.OpConfig.RoleDeletionSuffix
+			c.logger.Infof("dropping the deprecated role %q", roleName)
+			if err := c.dropRole(roleName, c.OpConfig.SuperUsername); err != nil {
+				c.logger.Warningf("could not drop the deprecated role %q: %v", roleName, err)
+				c.recordEvent(v1.EventTypeWarning, "Roles", "Could not drop deprecated role %q: %v", roleName, err)
+				continue
+			}
+			c.recordEvent(v1.EventTypeNormal, "Roles", "Dropped deprecated role %q", roleName)
+			delete(deprecatedRoles, name)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+	var roles []acidv1.DeprecatedRole
+	for _, role := range deprecatedRoles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	c.updateDeprecatedRolesStatus(roles)
+}
+
+// deleteRoleSecret removes the credential secret of a deprecated role, if there is one. The secret is
+// looked up among all secrets of the cluster, as it might live in another namespace.
+func (c *Cluster) deleteRoleSecret(username string, secrets []v1.Secret) {
+	secretName := c.credentialSecretName(username)
+	for i := range secrets {
+		if secrets[i].Name != secretName {
+			continue
+		}
+		if err := c.deleteSecret(&secrets[i]); err != nil && !k8sutil.ResourceNotFound(err) {
+			c.logger.Warningf("could not delete secret %q of the deprecated role %q: %v", secretName, username, err)
+		}
+	}
+}
+
+// deleteMovedSecrets removes the credential secrets that users left behind in their former namespace when
+// their secret namespace was changed. An old secret is only removed once the secret in the new namespace
+// exists, so that the credentials stay available if it cannot be created.
+func (c *Cluster) deleteMovedSecrets(secrets []v1.Secret) {
+	if !c.OpConfig.EnableCrossNamespaceSecrets {
+		return
+	}
+
+	existing := make(map[spec.NamespacedName]bool)
+	for _, secret := range secrets {
+		existing[util.NameFromMeta(secret.ObjectMeta)] = true
+	}
+	for _, pgUser := range c.pgUsers {
+		secretName := c.credentialSecretName(pgUser.Name)
+		namespace := c.userSecretNamespace(pgUser)
+		if !existing[spec.NamespacedName{Namespace: namespace, Name: secretName}] {
+			continue
+		}
+		for i := range secrets {
+			if secrets[i].Name != secretName || secrets[i].Namespace == namespace {
+				continue
+			}
+			c.logger.Infof("deleting secret %q of role %q, which has moved to namespace %q",
+				util.NameFromMeta(secrets[i].ObjectMeta), pgUser.Name, namespace)
+			if err := c.deleteSecret(&secrets[i]); err != nil && !k8sutil.ResourceNotFound(err) {
+				c.logger.Warningf("could not delete secret %q of role %q: %v", util.NameFromMeta(secrets[i].ObjectMeta), pgUser.Name, err)
+			}
+		}
+	}
+}
+
+func (c *Cluster) updateDeprecatedRolesStatus(roles []acidv1.DeprecatedRole) {
+	pg, err := c.patchStatus(map[string]interface{}{"deprecatedRoles": roles})
+	if err != nil {
+		c.logger.Warningf("could not update deprecated roles status: %v", err)
+		return
+	}
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+type mockSecretBackend struct {
+	deleted []string
+}
+
+func (b *mockSecretBackend) Name() string { return "mock" }
+
+func (b *mockSecretBackend) Get(namespace, name string) (*v1.Secret, error) { return nil, nil }
+
+func (b *mockSecretBackend) List(namespace string, selector labels.Selector) ([]v1.Secret, error) {
+	return nil, nil
+}
+
+func (b *mockSecretBackend) Create(secret *v1.Secret) (*v1.Secret, error) { return secret, nil }
+
+func (b *mockSecretBackend) Update(secret *v1.Secret) (*v1.Secret, error) { return secret, nil }
+
+func (b *mockSecretBackend) Delete(namespace, name string) error {
+	b.deleted = append(b.deleted, namespace+"/"+name)
+	return nil
+}
+
+func TestDeleteMovedSecrets(t *testing.T) {
+	testName := "TestDeleteMovedSecrets"
+	backend := &mockSecretBackend{}
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Auth: config.Auth{
+					SecretNameTemplate:          "{username}.{cluster}.credentials",
+					EnableCrossNamespaceSecrets: true,
+				},
+			},
+			SecretBackend: backend,
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test", Namespace: "default"},
+		}, logger, eventRecorder)
+	cluster.pgUsers = map[string]spec.PgUser{
+		// moved from the namespace of the cluster to the namespace "other"
+		"foo_user": {Name: "foo_user", Namespace: "other", Password: "foo"},
+		// moved to the namespace "missing", where the secret could not be created
+		"bar_user": {Name: "bar_user", Namespace: "missing", Password: "bar"},
+		"baz_user": {Name: "baz_user", Password: "baz"},
+	}
+
+	secret := func(namespace, username string) v1.Secret {
+		return v1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      cluster.credentialSecretName(username),
+		}}
+	}
+	secrets := []v1.Secret{
+		secret("default", "foo_user"),
+		secret("other", "foo_user"),
+		secret("default", "bar_user"),
+		secret("default", "baz_user"),
+	}
+
+	cluster.deleteMovedSecrets(secrets)
+	expected := []string{"default/foo-user.acid-test.credentials"}
+	if !reflect.DeepEqual(backend.deleted, expected) {
+		t.Errorf("%s: expected deleted secrets %v, got %v", testName, expected, backend.deleted)
+	}
+}
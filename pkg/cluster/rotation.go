@@ -0,0 +1,306 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+const (
+	// rotation roles are named after the user and the UTC time of the rotation, e.g. foo_2012311530,
+	// so that rotations on the same day, e.g. after changing the interval, do not reuse the role
+	rotationRoleDateFormat      = "0601021504"
+	minPasswordRotationInterval = 24 * time.Hour
+	maxRoleNameLength           = 63
+)
+
+var rotationRoleRegexp = regexp.MustCompile(`_\d{10}$`)
+
+// passwordRotation describes the credentials of a user with a rotated password
+type passwordRotation struct {
+	user string    // the role the password belongs to
+	role string    // the login role of the credentials, the user itself or a rotation role
+	time time.Time // time of the last rotation
+	// the secret with the new credentials, to be updated once the roles are synced
+	secret *v1.Secret
+}
+
+// passwordRotationConfig tells whether the password of the user is rotated, the interval of the rotation
+// and whether the password is changed in place instead of creating a new rotation role.
+func (c *Cluster) passwordRotationConfig(user spec.PgUser) (enabled bool, interval time.Duration, inPlace bool) {
+	if user.Origin != spec.RoleOriginManifest {
+		return false, 0, false
+	}
+	rotation, exists := c.Spec.PasswordRotation[user.Name]
+	if !exists && !c.OpConfig.EnablePasswordRotation {
+		return false, 0, false
+	}
+
+	interval = c.OpConfig.PasswordRotationInterval
+	if rotation.Interval != "" {
+		userInterval, err := time.ParseDuration(rotation.Interval)
+		if err != nil {
+			c.logger.Warningf("could not parse password rotation interval %q of user %q, using %v: %v",
+				rotation.Interval, user.Name, interval, err)
+		} else {
+			interval = userInterval
+		}
+	}
+	if interval < minPasswordRotationInterval {
+		interval = minPasswordRotationInterval
+	}
+
+	inPlace = rotation.InPlace
+	if !inPlace && len(user.Name)+len(rotationRoleDateFormat)+1 > maxRoleNameLength {
+		c.logger.Warningf("name of the user %q is too long for rotation roles, rotating its password in place", user.Name)
+		inPlace = true
+	}
+
+	return true, interval, inPlace
+}
+
+// passwordRotationTime returns the time of the last rotation of the credentials in the secret
+func passwordRotationTime(secret *v1.Secret) time.Time {
+	if rotationTime, err := time.Parse(time.RFC3339, secret.Annotations[constants.PasswordRotationTimeAnnotationKey]); err == nil {
+		return rotationTime
+	}
+	return secret.CreationTimestamp.Time
+}
+
+// isSecretOfUser checks that the secret holds the credentials of the user or of one of its rotation roles
+func isSecretOfUser(secret *v1.Secret, username string) bool {
+	return string(secret.Data["username"]) == username ||
+		secret.Annotations[constants.PasswordRotationUserAnnotationKey] == username
+}
+
+// isRotationRoleOf checks if the role has been created for the rotation of the password of the user
+func isRotationRoleOf(roleName, username string) bool {
+	return len(roleName) == len(username)+len(rotationRoleDateFormat)+1 &&
+		roleName[:len(username)] == username && rotationRoleRegexp.MatchString(roleName)
+}
+
+func rotationUser(user spec.PgUser, roleName, password string) spec.PgUser {
+	// sessions of the rotation role act as the user, so that objects are owned by the user
+	return spec.PgUser{
+		Origin:     user.Origin,
+		Name:       roleName,
+		Password:   password,
+		Flags:      []string{constants.RoleFlagLogin},
+		MemberOf:   []string{user.Name},
+		Parameters: map[string]string{"role": user.Name},
+	}
+}
+
+// useSecretCredentials updates the user with the password from its secret. If the secret holds the
+// credentials of a rotation role, the rotation role is synced with that password instead, and the user
+// keeps the password it has in the database.
+func (c *Cluster) useSecretCredentials(userMap map[string]spec.PgUser, userKey string, secret *v1.Secret) {
+	user := userMap[userKey]
+	secretUsername := string(secret.Data["username"])
+	password := string(secret.Data["password"])
+
+	if secretUsername == user.Name {
+		user.Password = password
+	} else {
+		user.Password = ""
+		c.pgUsers[secretUsername] = rotationUser(user, secretUsername, password)
+	}
+	userMap[userKey] = user
+}
+
+// preparePasswordRotation generates new credentials for a manifest user once the rotation interval has
+// passed since the last rotation. The role is altered or the new rotation role is created by the sync of
+// the roles, the secret is updated afterwards by applyPasswordRotations.
+func (c *Cluster) preparePasswordRotation(user spec.PgUser, secret *v1.Secret) {
+	enabled, interval, inPlace := c.passwordRotationConfig(user)
+	if !enabled {
+		return
+	}
+	rotation := &passwordRotation{
+		user: user.Name,
+		role: string(secret.Data["username"]),
+		time: passwordRotationTime(secret),
+	}
+	c.passwordRotations[user.Name] = rotation
+
+	now := time.Now()
+	if now.Sub(rotation.time) < interval {
+		return
+	}
+	password := util.RandomPassword(constants.PasswordLength)
+
+	roleName := user.Name
+	if inPlace {
+		user.Password = password
+		c.pgUsers[user.Name] = user
+	} else {
+		roleName = fmt.Sprintf("%s_%s", user.Name, now.UTC().Format(rotationRoleDateFormat))
+		user.Password = ""
+		c.pgUsers[user.Name] = user
+		c.pgUsers[roleName] = rotationUser(user, roleName, password)
+	}
+	c.logger.Infof("rotating the password of user %q, the new credentials are valid for role %q", user.Name, roleName)
+
+	rotated := secret.DeepCopy()
+	if rotated.Annotations == nil {
+		rotated.Annotations = make(map[string]string)
+	}
+	rotated.Annotations[constants.PasswordRotationTimeAnnotationKey] = now.Format(time.RFC3339)
+	if inPlace {
+		delete(rotated.Annotations, constants.PasswordRotationUserAnnotationKey)
+	} else {
+		rotated.Annotations[constants.PasswordRotationUserAnnotationKey] = user.Name
+	}
+	rotated.Data["username"] = []byte(roleName)
+	rotated.Data["password"] = []byte(password)
+	rotation.secret = rotated
+}
+
+// applyPasswordRotations stores the rotated credentials in the secrets of the users once the roles
+// have been altered or created in the database.
+func (c *Cluster) applyPasswordRotations() error {
+	for _, rotation := range c.passwordRotations {
+		if rotation.secret == nil {
+			continue
+		}
+		secret, err := c.SecretBackend.Update(rotation.secret)
+		if err != nil {
+			return fmt.Errorf("could not update secret %q with the rotated password of user %q: %v",
+				rotation.secret.Name, rotation.user, err)
+		}
+		c.Secrets[secret.UID] = secret
+		c.recordEvent(v1.EventTypeNormal, "Secrets", "Rotated password of user %q", rotation.user)
+
+		rotation.role = string(secret.Data["username"])
+		rotation.time = passwordRotationTime(secret)
+		rotation.secret = nil
+	}
+	return nil
+}
+
+// cleanupRotatedRoles removes the previous credentials of users once the overlap period after the last
+// rotation has passed: previous rotation roles are dropped, their objects are reassigned to the user, and
+// the user loses its own password if the credentials have moved to a rotation role.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) cleanupRotatedRoles() {
+	for _, rotation := range c.passwordRotations {
+		if time.Since(rotation.time) < c.OpConfig.PasswordRotationOverlapPeriod {
+			continue
+		}
+		members, err := c.getRoleMembers(rotation.user)
+		if err != nil {
+			c.logger.Warningf("could not get members of user %q: %v", rotation.user, err)
+			continue
+		}
+		for _, member := range members {
+			if member == rotation.role || !isRotationRoleOf(member, rotation.user) {
+				continue
+			}
+			c.logger.Infof("dropping the previous rotation role %q of user %q", member, rotation.user)
+			if err := c.dropRole(member, rotation.user); err != nil {
+				c.logger.Warningf("could not drop the previous rotation role %q: %v", member, err)
+				continue
+			}
+		}
+
+		if rotation.role == rotation.user {
+			continue
+		}
+		dbUsers, err := c.readPgUsersFromDatabase([]string{rotation.user})
+		if err != nil {
+			c.logger.Warningf("could not get user %q from the database: %v", rotation.user, err)
+			continue
+		}
+		if dbUsers[rotation.user].Password == "" {
+			continue
+		}
+		c.logger.Infof("removing the previous password of user %q", rotation.user)
+		if err := c.resetRolePassword(rotation.user); err != nil {
+			c.logger.Warningf("could not remove the previous password of user %q: %v", rotation.user, err)
+		}
+	}
+}
+
+// systemPasswordRotationDue checks if the password of a system user in the secret has to be rotated
+func (c *Cluster) systemPasswordRotationDue(secret *v1.Secret) bool {
+	if !c.OpConfig.EnableSystemPasswordRotation {
+		return false
+	}
+	interval := c.OpConfig.PasswordRotationInterval
+	if interval < minPasswordRotationInterval {
+		interval = minPasswordRotationInterval
+	}
+	return time.Since(passwordRotationTime(secret)) >= interval
+}
+
+// rotateSystemPasswords changes the passwords of system users in place. Spilo only reads them when
+// starting the pods, so the pods are rolled afterwards. To not disrupt clients outside of the maintenance
+// windows, the rotation is postponed until the next window.
+func (c *Cluster) rotateSystemPasswords(secrets map[string]*v1.Secret) (err error) {
+	if len(secrets) == 0 {
+		return nil
+	}
+	if c.databaseAccessDisabled() || c.Statefulset == nil || c.Spec.StandbyCluster != nil ||
+		c.getNumberOfInstances(&c.Spec) <= 0 {
+		return nil
+	}
+	if !c.isInMaintenanceWindow() {
+		c.logger.Infof("postponing the rotation of system passwords until the next maintenance window")
+		return nil
+	}
+
+	if err = c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init db connection: %v", err)
+	}
+	defer func() {
+		if err2 := c.closeDbConn(); err2 != nil && err == nil {
+			err = fmt.Errorf("could not close database connection: %v", err2)
+		}
+	}()
+
+	rotated := false
+	for userKey, secret := range secrets {
+		user := c.systemUsers[userKey]
+		newUser := user
+		newUser.Password = util.RandomPassword(constants.PasswordLength)
+
+		c.logger.Infof("rotating the password of system user %q", user.Name)
+		alterRequest := []spec.PgSyncUserRequest{{Kind: spec.PGsyncUserAlter, User: newUser}}
+		if err = c.userSyncStrategy.ExecuteSyncRequests(alterRequest, c.pgDb); err != nil {
+			return fmt.Errorf("could not change password of system user %q: %v", user.Name, err)
+		}
+
+		newSecret := secret.DeepCopy()
+		if newSecret.Annotations == nil {
+			newSecret.Annotations = make(map[string]string)
+		}
+		newSecret.Annotations[constants.PasswordRotationTimeAnnotationKey] = time.Now().Format(time.RFC3339)
+		newSecret.Data["password"] = []byte(newUser.Password)
+		if newSecret, err = c.SecretBackend.Update(newSecret); err != nil {
+			// the pods still use the password from the secret
+			revertRequest := []spec.PgSyncUserRequest{{Kind: spec.PGsyncUserAlter, User: user}}
+			if err2 := c.userSyncStrategy.ExecuteSyncRequests(revertRequest, c.pgDb); err2 != nil {
+				c.logger.Errorf("could not restore password of system user %q: %v", user.Name, err2)
+			}
+			return fmt.Errorf("could not update secret of system user %q: %v", user.Name, err)
+		}
+		c.Secrets[newSecret.UID] = newSecret
+		c.systemUsers[userKey] = newUser
+		c.recordEvent(v1.EventTypeNormal, "Secrets", "Rotated password of system user %q", user.Name)
+		rotated = true
+	}
+
+	if rotated {
+		c.logger.Infof("pods are rolled to use the rotated system passwords")
+		if err = c.applyRollingUpdateFlagforStatefulSet(true); err != nil {
+			return fmt.Errorf("could not set rolling update flag for the statefulset: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsRotationRoleOf(t *testing.T) {
+	testName := "TestIsRotationRoleOf"
+	tests := []struct {
+		role     string
+		user     string
+		expected bool
+	}{
+		{"foo_2012311530", "foo", true},
+		{"foo_bar_2012311530", "foo_bar", true},
+		{"foo_bar_2012311530", "foo", false},
+		{"foo_201231", "foo", false},
+		{"foo_deleted", "foo", false},
+		{"bar_2012311530", "foo", false},
+	}
+
+	for _, tt := range tests {
+		if result := isRotationRoleOf(tt.role, tt.user); result != tt.expected {
+			t.Errorf("%s: expected %t for role %q of user %q, got %t", testName, tt.expected, tt.role, tt.user, result)
+		}
+	}
+}
+
+func TestPreparePasswordRotation(t *testing.T) {
+	testName := "TestPreparePasswordRotation"
+	now := time.Now()
+	user := spec.PgUser{Origin: spec.RoleOriginManifest, Name: "foo", Password: "secret", Flags: []string{"LOGIN"}}
+	newSecret := func(rotationTime time.Time) *v1.Secret {
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo.acid-test.credentials",
+				Annotations: map[string]string{constants.PasswordRotationTimeAnnotationKey: rotationTime.Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{"username": []byte("foo"), "password": []byte("secret")},
+		}
+	}
+
+	tests := []struct {
+		subTest          string
+		enabled          bool
+		rotation         map[string]acidv1.PasswordRotation
+		secret           *v1.Secret
+		expectRotation   bool
+		expectSecretUser string // the time of the rotation is appended to the names of rotation roles
+	}{
+		{
+			subTest: "rotation disabled",
+			secret:  newSecret(now.Add(-1000 * time.Hour)),
+		},
+		{
+			subTest:        "rotation not due",
+			enabled:        true,
+			secret:         newSecret(now.Add(-time.Hour)),
+			expectRotation: true,
+		},
+		{
+			subTest:          "new rotation role",
+			enabled:          true,
+			secret:           newSecret(now.Add(-100 * 24 * time.Hour)),
+			expectRotation:   true,
+			expectSecretUser: "foo_",
+		},
+		{
+			subTest:          "per-user interval and in-place rotation",
+			rotation:         map[string]acidv1.PasswordRotation{"foo": {Interval: "48h", InPlace: true}},
+			secret:           newSecret(now.Add(-72 * time.Hour)),
+			expectRotation:   true,
+			expectSecretUser: "foo",
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(
+			Config{
+				OpConfig: config.Config{
+					Auth: config.Auth{
+						SuperUsername:            superUserName,
+						ReplicationUsername:      replicationUserName,
+						EnablePasswordRotation:   tt.enabled,
+						PasswordRotationInterval: 90 * 24 * time.Hour,
+					},
+				},
+			}, k8sutil.NewMockKubernetesClient(),
+			acidv1.Postgresql{Spec: acidv1.PostgresSpec{PasswordRotation: tt.rotation}}, logger, eventRecorder)
+		cluster.pgUsers = map[string]spec.PgUser{"foo": user}
+		cluster.passwordRotations = make(map[string]*passwordRotation)
+
+		cluster.preparePasswordRotation(user, tt.secret)
+
+		rotation, exists := cluster.passwordRotations["foo"]
+		if exists != tt.expectRotation {
+			t.Errorf("%s %s: expected rotation %t, got %t", testName, tt.subTest, tt.expectRotation, exists)
+			continue
+		}
+		if !exists {
+			continue
+		}
+		if tt.expectSecretUser == "" {
+			if rotation.secret != nil {
+				t.Errorf("%s %s: expected no new credentials", testName, tt.subTest)
+			}
+			continue
+		}
+		if rotation.secret == nil {
+			t.Errorf("%s %s: expected new credentials", testName, tt.subTest)
+			continue
+		}
+
+		expectSecretUser := tt.expectSecretUser
+		if expectSecretUser != "foo" {
+			expectSecretUser += passwordRotationTime(rotation.secret).UTC().Format(rotationRoleDateFormat)
+		}
+		secretUser := string(rotation.secret.Data["username"])
+		password := string(rotation.secret.Data["password"])
+		if secretUser != expectSecretUser {
+			t.Errorf("%s %s: expected secret of role %q, got %q", testName, tt.subTest, expectSecretUser, secretUser)
+		}
+		if password == "secret" || cluster.pgUsers[secretUser].Password != password {
+			t.Errorf("%s %s: expected the new password for role %q", testName, tt.subTest, secretUser)
+		}
+		if secretUser != "foo" {
+			if cluster.pgUsers["foo"].Password != "" {
+				t.Errorf("%s %s: expected the user to keep its password in the database", testName, tt.subTest)
+			}
+			if memberOf := cluster.pgUsers[secretUser].MemberOf; len(memberOf) != 1 || memberOf[0] != "foo" {
+				t.Errorf("%s %s: expected the rotation role to be a member of the user, got %v", testName, tt.subTest, memberOf)
+			}
+			if rotation.secret.Annotations[constants.PasswordRotationUserAnnotationKey] != "foo" {
+				t.Errorf("%s %s: expected the secret to be annotated with the user", testName, tt.subTest)
+			}
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+const (
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultTokenSecretKey  = "token"
+	vaultTokenEnvVarName = "VAULT_TOKEN"
+	vaultLabelsKey       = "labels"
+	vaultAnnotationsKey  = "annotations"
+	vaultRequestTimeout  = 30 * time.Second
+)
+
+var secretsResource = schema.GroupResource{Resource: "secrets"}
+
+// SecretBackend stores the credentials the operator generates for the roles of a cluster. Credentials are
+// passed around as Kubernetes secrets, so that every backend keeps the name, labels, annotations and data
+// of a secret. Backends report missing and already existing credentials with the errors of the Kubernetes API.
+// List returns the secrets of all namespaces if the namespace is empty.
+type SecretBackend interface {
+	Name() string
+	Get(namespace, name string) (*v1.Secret, error)
+	List(namespace string, selector labels.Selector) ([]v1.Secret, error)
+	Create(secret *v1.Secret) (*v1.Secret, error)
+	Update(secret *v1.Secret) (*v1.Secret, error)
+	Delete(namespace, name string) error
+}
+
+// NewSecretBackend returns the secret backend configured for the operator
+func NewSecretBackend(cfg *config.Config, kubeClient k8sutil.KubernetesClient) (SecretBackend, error) {
+	switch cfg.SecretBackendType {
+	case "", constants.SecretBackendKubernetes:
+		return newKubernetesSecretBackend(kubeClient), nil
+	case constants.SecretBackendVault:
+		if cfg.VaultAddress == "" {
+			return nil, fmt.Errorf("vault address is not set")
+		}
+		return &vaultSecretBackend{
+			address:         strings.TrimSuffix(cfg.VaultAddress, "/"),
+			mount:           strings.Trim(cfg.VaultKVMount, "/"),
+			pathPrefix:      strings.Trim(cfg.VaultPathPrefix, "/"),
+			tokenSecretName: cfg.VaultTokenSecretName,
+			kubeClient:      kubeClient,
+			httpClient:      &http.Client{Timeout: vaultRequestTimeout},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown secret backend %q", cfg.SecretBackendType)
+}
+
+// kubernetesSecretBackend stores credentials in Kubernetes secrets in the namespace of the cluster
+type kubernetesSecretBackend struct {
+	kubeClient k8sutil.KubernetesClient
+}
+
+func newKubernetesSecretBackend(kubeClient k8sutil.KubernetesClient) *kubernetesSecretBackend {
+	return &kubernetesSecretBackend{kubeClient: kubeClient}
+}
+
+func (b *kubernetesSecretBackend) Name() string {
+	return constants.SecretBackendKubernetes
+}
+
+func (b *kubernetesSecretBackend) Get(namespace, name string) (*v1.Secret, error) {
+	return b.kubeClient.Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func (b *kubernetesSecretBackend) List(namespace string, selector labels.Selector) ([]v1.Secret, error) {
+	secrets, err := b.kubeClient.Secrets(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Items, nil
+}
+
+func (b *kubernetesSecretBackend) Create(secret *v1.Secret) (*v1.Secret, error) {
+	return b.kubeClient.Secrets(secret.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+}
+
+func (b *kubernetesSecretBackend) Update(secret *v1.Secret) (*v1.Secret, error) {
+	return b.kubeClient.Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+}
+
+func (b *kubernetesSecretBackend) Delete(namespace, name string) error {
+	return b.kubeClient.Secrets(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
+
+// vaultSecretBackend stores credentials in a key/value secrets engine (version 2) with the HTTP API of
+// HashiCorp Vault, at <mount>/<path prefix>/<namespace>/<secret name>. The data of the secret is kept in
+// the top-level keys of an entry, e.g. username and password, and the labels and annotations of the secret
+// in the labels and annotations keys. The version of an entry is used as the resource version of the
+// secret, so that updates of outdated credentials fail with a conflict.
+type vaultSecretBackend struct {
+	address         string
+	mount           string
+	pathPrefix      string
+	tokenSecretName spec.NamespacedName
+	kubeClient      k8sutil.KubernetesClient
+	httpClient      *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+type vaultEntry struct {
+	Data     map[string]interface{} `json:"data"`
+	Metadata vaultEntryMetadata     `json:"metadata"`
+}
+
+type vaultEntryMetadata struct {
+	CreatedTime time.Time `json:"created_time"`
+	Version     int       `json:"version"`
+}
+
+type vaultResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []string        `json:"errors"`
+}
+
+func (b *vaultSecretBackend) Name() string {
+	return constants.SecretBackendVault
+}
+
+func (b *vaultSecretBackend) secretPath(namespace, name string) string {
+	return path.Join(b.pathPrefix, namespace, name)
+}
+
+func (b *vaultSecretBackend) Get(namespace, name string) (*v1.Secret, error) {
+	var entry vaultEntry
+
+	status, err := b.request(http.MethodGet, "data", b.secretPath(namespace, name), nil, &entry)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, apierrors.NewNotFound(secretsResource, name)
+	}
+
+	return b.secretFromEntry(namespace, name, &entry), nil
+}
+
+func (b *vaultSecretBackend) List(namespace string, selector labels.Selector) ([]v1.Secret, error) {
+	var keys struct {
+		Keys []string `json:"keys"`
+	}
+
+	status, err := b.request("LIST", "metadata", b.secretPath(namespace, ""), nil, &keys)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+
+	sort.Strings(keys.Keys)
+	secrets := make([]v1.Secret, 0)
+	for _, key := range keys.Keys {
+		if strings.HasSuffix(key, "/") {
+			// entries are stored per namespace, deeper sub-paths are not created by the operator
+			if namespace != "" {
+				continue
+			}
+			namespaceSecrets, err := b.List(strings.TrimSuffix(key, "/"), selector)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, namespaceSecrets...)
+			continue
+		}
+		if namespace == "" {
+			continue
+		}
+		secret, err := b.Get(namespace, key)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if selector.Matches(labels.Set(secret.Labels)) {
+			secrets = append(secrets, *secret)
+		}
+	}
+	return secrets, nil
+}
+
+func (b *vaultSecretBackend) Create(secret *v1.Secret) (*v1.Secret, error) {
+	// the check-and-set version 0 only allows writing entries that do not exist
+	return b.write(secret, 0)
+}
+
+func (b *vaultSecretBackend) Update(secret *v1.Secret) (*v1.Secret, error) {
+	version := -1
+	if secret.ResourceVersion != "" {
+		var err error
+		if version, err = strconv.Atoi(secret.ResourceVersion); err != nil {
+			return nil, fmt.Errorf("could not parse resource version %q of secret %q: %v",
+				secret.ResourceVersion, secret.Name, err)
+		}
+	}
+	return b.write(secret, version)
+}
+
+func (b *vaultSecretBackend) Delete(namespace, name string) error {
+	status, err := b.request(http.MethodDelete, "metadata", b.secretPath(namespace, name), nil, nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return apierrors.NewNotFound(secretsResource, name)
+	}
+	return nil
+}
+
+// write stores the secret in a new version of the entry. A negative version overwrites any existing entry.
+func (b *vaultSecretBackend) write(secret *v1.Secret, version int) (*v1.Secret, error) {
+	data := make(map[string]interface{}, len(secret.Data)+2)
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	if len(secret.Labels) > 0 {
+		data[vaultLabelsKey] = secret.Labels
+	}
+	if len(secret.Annotations) > 0 {
+		data[vaultAnnotationsKey] = secret.Annotations
+	}
+	body := map[string]interface{}{"data": data}
+	if version >= 0 {
+		body["options"] = map[string]int{"cas": version}
+	}
+
+	var metadata vaultEntryMetadata
+	status, err := b.request(http.MethodPost, "data", b.secretPath(secret.Namespace, secret.Name), body, &metadata)
+	if err != nil {
+		if status == http.StatusBadRequest && version == 0 {
+			return nil, apierrors.NewAlreadyExists(secretsResource, secret.Name)
+		}
+		if status == http.StatusBadRequest && version > 0 {
+			return nil, apierrors.NewConflict(secretsResource, secret.Name, err)
+		}
+		return nil, err
+	}
+
+	result := secret.DeepCopy()
+	result.UID = types.UID(path.Join(b.mount, b.secretPath(secret.Namespace, secret.Name)))
+	result.ResourceVersion = strconv.Itoa(metadata.Version)
+	if result.CreationTimestamp.IsZero() {
+		result.CreationTimestamp = metav1.NewTime(metadata.CreatedTime)
+	}
+	return result, nil
+}
+
+func (b *vaultSecretBackend) secretFromEntry(namespace, name string, entry *vaultEntry) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               types.UID(path.Join(b.mount, b.secretPath(namespace, name))),
+			ResourceVersion:   strconv.Itoa(entry.Metadata.Version),
+			CreationTimestamp: metav1.NewTime(entry.Metadata.CreatedTime),
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: make(map[string][]byte),
+	}
+	for key, value := range entry.Data {
+		switch value := value.(type) {
+		case string:
+			secret.Data[key] = []byte(value)
+		case map[string]interface{}:
+			values := make(map[string]string, len(value))
+			for k, v := range value {
+				values[k] = fmt.Sprintf("%v", v)
+			}
+			if key == vaultLabelsKey {
+				secret.Labels = values
+			} else if key == vaultAnnotationsKey {
+				secret.Annotations = values
+			}
+		}
+	}
+	return secret
+}
+
+// request calls the API of the secrets engine and decodes the data of the response into the result. It returns
+// the status code of the response, and an error for all responses but the successful ones and 404 Not Found.
+func (b *vaultSecretBackend) request(method, endpoint, secretPath string, body interface{}, result interface{}) (int, error) {
+	token, err := b.getToken()
+	if err != nil {
+		return 0, err
+	}
+
+	var payload []byte
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return 0, fmt.Errorf("could not marshal request: %v", err)
+		}
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", b.address, b.mount, endpoint, secretPath)
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("could not create request: %v", err)
+	}
+	req.Header.Set(vaultTokenHeader, token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not send request to %s: %v", b.address, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("could not read response: %v", err)
+	}
+	var response vaultResponse
+	if len(respBody) > 0 {
+		if err = json.Unmarshal(respBody, &response); err != nil {
+			return resp.StatusCode, fmt.Errorf("could not decode response: %v", err)
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusForbidden:
+		// the token might have been renewed in the meantime
+		b.resetToken()
+		return resp.StatusCode, fmt.Errorf("permission denied for %s %s", method, secretPath)
+	case resp.StatusCode >= 300:
+		return resp.StatusCode, fmt.Errorf("%s %s failed with status %d: %s",
+			method, secretPath, resp.StatusCode, strings.Join(response.Errors, ", "))
+	}
+
+	if result != nil && len(response.Data) > 0 {
+		if err = json.Unmarshal(response.Data, result); err != nil {
+			return resp.StatusCode, fmt.Errorf("could not decode response data: %v", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// getToken reads the token from the configured Kubernetes secret, or from the environment of the operator
+func (b *vaultSecretBackend) getToken() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token != "" {
+		return b.token, nil
+	}
+	if b.tokenSecretName == (spec.NamespacedName{}) {
+		b.token = os.Getenv(vaultTokenEnvVarName)
+		if b.token == "" {
+			return "", fmt.Errorf("neither the vault token secret nor the %s environment variable is set", vaultTokenEnvVarName)
+		}
+		return b.token, nil
+	}
+
+	secret, err := b.kubeClient.Secrets(b.tokenSecretName.Namespace).Get(context.TODO(), b.tokenSecretName.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not get vault token secret %q: %v", b.tokenSecretName, err)
+	}
+	token, ok := secret.Data[vaultTokenSecretKey]
+	if !ok {
+		return "", fmt.Errorf("vault token secret %q has no %q key", b.tokenSecretName, vaultTokenSecretKey)
+	}
+	b.token = strings.TrimSpace(string(token))
+	return b.token, nil
+}
+
+func (b *vaultSecretBackend) resetToken() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.token = ""
+}
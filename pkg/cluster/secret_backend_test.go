@@ -0,0 +1,258 @@
+package cluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+const testVaultToken = "dev-root-token"
+
+type fakeVaultEntry struct {
+	data    map[string]interface{}
+	version int
+	created time.Time
+}
+
+// fakeVault mimics the key/value secrets engine (version 2) of a Vault server in dev mode, mounted at secret/
+type fakeVault struct {
+	mu      sync.Mutex
+	entries map[string]*fakeVaultEntry
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(status int, body interface{}) {
+		w.WriteHeader(status)
+		if body != nil {
+			json.NewEncoder(w).Encode(body)
+		}
+	}
+	if r.Header.Get(vaultTokenHeader) != testVaultToken {
+		reply(http.StatusForbidden, map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+	switch {
+	case strings.HasPrefix(path, "data/") && r.Method == http.MethodGet:
+		entry, ok := f.entries[strings.TrimPrefix(path, "data/")]
+		if !ok {
+			reply(http.StatusNotFound, map[string][]string{"errors": {}})
+			return
+		}
+		reply(http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"data":     entry.data,
+			"metadata": map[string]interface{}{"created_time": entry.created, "version": entry.version},
+		}})
+	case strings.HasPrefix(path, "data/") && r.Method == http.MethodPost:
+		var body struct {
+			Data    map[string]interface{} `json:"data"`
+			Options map[string]int         `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			reply(http.StatusBadRequest, map[string][]string{"errors": {err.Error()}})
+			return
+		}
+		key := strings.TrimPrefix(path, "data/")
+		entry, ok := f.entries[key]
+		if !ok {
+			entry = &fakeVaultEntry{}
+			f.entries[key] = entry
+		}
+		if cas, ok := body.Options["cas"]; ok && cas != entry.version {
+			reply(http.StatusBadRequest, map[string][]string{"errors": {"check-and-set parameter did not match the current version"}})
+			return
+		}
+		entry.data = body.Data
+		entry.version++
+		entry.created = time.Now().UTC()
+		reply(http.StatusOK, map[string]interface{}{"data": map[string]interface{}{
+			"created_time": entry.created, "version": entry.version,
+		}})
+	case strings.HasPrefix(path, "metadata/") && r.Method == "LIST":
+		prefix := strings.TrimSuffix(strings.TrimPrefix(path, "metadata/"), "/") + "/"
+		// like Vault, only the direct children of the path are listed, folders with a trailing slash
+		keys := make([]string, 0)
+		folders := make(map[string]bool)
+		for key := range f.entries {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			child := strings.TrimPrefix(key, prefix)
+			if i := strings.Index(child, "/"); i >= 0 {
+				if folder := child[:i+1]; !folders[folder] {
+					folders[folder] = true
+					keys = append(keys, folder)
+				}
+				continue
+			}
+			keys = append(keys, child)
+		}
+		if len(keys) == 0 {
+			reply(http.StatusNotFound, map[string][]string{"errors": {}})
+			return
+		}
+		reply(http.StatusOK, map[string]interface{}{"data": map[string][]string{"keys": keys}})
+	case strings.HasPrefix(path, "metadata/") && r.Method == http.MethodDelete:
+		delete(f.entries, strings.TrimPrefix(path, "metadata/"))
+		reply(http.StatusNoContent, nil)
+	default:
+		reply(http.StatusMethodNotAllowed, map[string][]string{"errors": {"unsupported request"}})
+	}
+}
+
+func newTestVaultBackend(address, token string) *vaultSecretBackend {
+	return &vaultSecretBackend{
+		address:    address,
+		mount:      "secret",
+		pathPrefix: "postgres-operator",
+		httpClient: http.DefaultClient,
+		token:      token,
+	}
+}
+
+func TestVaultSecretBackend(t *testing.T) {
+	testName := "TestVaultSecretBackend"
+	server := httptest.NewServer(&fakeVault{entries: make(map[string]*fakeVaultEntry)})
+	defer server.Close()
+	backend := newTestVaultBackend(server.URL, testVaultToken)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo.acid-test.credentials",
+			Namespace:   "default",
+			Labels:      map[string]string{"cluster-name": "acid-test"},
+			Annotations: map[string]string{constants.PasswordRotationTimeAnnotationKey: "2020-01-01T00:00:00Z"},
+		},
+		Data: map[string][]byte{"username": []byte("foo"), "password": []byte("secret")},
+	}
+
+	created, err := backend.Create(secret)
+	if err != nil {
+		t.Fatalf("%s: could not create secret: %v", testName, err)
+	}
+	if created.ResourceVersion != "1" || created.UID == "" {
+		t.Errorf("%s: expected version 1 and a uid of the created secret, got %q and %q",
+			testName, created.ResourceVersion, created.UID)
+	}
+	if _, err = backend.Create(secret); !apierrors.IsAlreadyExists(err) {
+		t.Errorf("%s: expected an already exists error when creating the secret again, got %v", testName, err)
+	}
+
+	stored, err := backend.Get("default", secret.Name)
+	if err != nil {
+		t.Fatalf("%s: could not get secret: %v", testName, err)
+	}
+	if !reflect.DeepEqual(stored.Data, secret.Data) || !reflect.DeepEqual(stored.Labels, secret.Labels) ||
+		!reflect.DeepEqual(stored.Annotations, secret.Annotations) {
+		t.Errorf("%s: expected secret %#v, got %#v", testName, secret, stored)
+	}
+
+	stored.Data["password"] = []byte("new secret")
+	updated, err := backend.Update(stored)
+	if err != nil {
+		t.Fatalf("%s: could not update secret: %v", testName, err)
+	}
+	if updated.ResourceVersion != "2" {
+		t.Errorf("%s: expected version 2 of the updated secret, got %q", testName, updated.ResourceVersion)
+	}
+	if _, err = backend.Update(stored); !apierrors.IsConflict(err) {
+		t.Errorf("%s: expected a conflict when updating an outdated secret, got %v", testName, err)
+	}
+
+	for _, tt := range []struct {
+		selector labels.Selector
+		expected int
+	}{
+		{labels.SelectorFromSet(labels.Set{"cluster-name": "acid-test"}), 1},
+		{labels.SelectorFromSet(labels.Set{"cluster-name": "acid-other"}), 0},
+	} {
+		secrets, err := backend.List("default", tt.selector)
+		if err != nil {
+			t.Errorf("%s: could not list secrets: %v", testName, err)
+		} else if len(secrets) != tt.expected {
+			t.Errorf("%s: expected %d secrets for selector %q, got %d", testName, tt.expected, tt.selector, len(secrets))
+		}
+	}
+
+	other := secret.DeepCopy()
+	other.Namespace = "appspace"
+	if _, err = backend.Create(other); err != nil {
+		t.Fatalf("%s: could not create secret in another namespace: %v", testName, err)
+	}
+	secrets, err := backend.List("", labels.SelectorFromSet(labels.Set{"cluster-name": "acid-test"}))
+	if err != nil {
+		t.Errorf("%s: could not list secrets of all namespaces: %v", testName, err)
+	} else if len(secrets) != 2 {
+		t.Errorf("%s: expected 2 secrets in all namespaces, got %d", testName, len(secrets))
+	}
+
+	if err = backend.Delete("default", secret.Name); err != nil {
+		t.Errorf("%s: could not delete secret: %v", testName, err)
+	}
+	if _, err = backend.Get("default", secret.Name); !apierrors.IsNotFound(err) {
+		t.Errorf("%s: expected a not found error for the deleted secret, got %v", testName, err)
+	}
+
+	if _, err = newTestVaultBackend(server.URL, "wrong-token").Get("default", secret.Name); err == nil {
+		t.Errorf("%s: expected an error for a wrong token", testName)
+	}
+}
+
+func TestNewSecretBackend(t *testing.T) {
+	testName := "TestNewSecretBackend"
+	tests := []struct {
+		subTest  string
+		backend  config.SecretBackend
+		expected string
+		err      bool
+	}{
+		{
+			subTest:  "default backend",
+			expected: constants.SecretBackendKubernetes,
+		},
+		{
+			subTest:  "vault backend",
+			backend:  config.SecretBackend{SecretBackendType: constants.SecretBackendVault, VaultAddress: "http://127.0.0.1:8200"},
+			expected: constants.SecretBackendVault,
+		},
+		{
+			subTest: "vault backend without address",
+			backend: config.SecretBackend{SecretBackendType: constants.SecretBackendVault},
+			err:     true,
+		},
+		{
+			subTest: "unknown backend",
+			backend: config.SecretBackend{SecretBackendType: "etcd"},
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		backend, err := NewSecretBackend(&config.Config{SecretBackend: tt.backend}, k8sutil.NewMockKubernetesClient())
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+			continue
+		}
+		if err == nil && backend.Name() != tt.expected {
+			t.Errorf("%s %s: expected backend %q, got %q", testName, tt.subTest, tt.expected, backend.Name())
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// observeStatus fills the given status with the observed state of the cluster resources.
+// Errors while observing resources are logged, the affected fields are left out.
+func (c *Cluster) observeStatus(status *acidv1.PostgresStatus) {
+	now := metav1.Now()
+
+	status.Instances = c.getNumberOfInstances(&c.Spec)
+	status.ReadyInstances = 0
+	status.MasterPod = ""
+	if pods, err := c.listPods(); err != nil {
+		c.logger.Warningf("could not list pods to observe cluster status: %v", err)
+	} else {
+		for _, pod := range pods {
+			if podIsReady(&pod) {
+				status.ReadyInstances++
+			}
+			if PostgresRole(pod.Labels[c.OpConfig.PodRoleLabel]) == Master {
+				status.MasterPod = pod.Name
+			}
+		}
+	}
+
+	switch status.PostgresClusterStatus {
+	case acidv1.ClusterStatusCreating, acidv1.ClusterStatusUpdating:
+		status.SetCondition(newCondition(acidv1.ConditionSyncing, v1.ConditionTrue, status.PostgresClusterStatus, "", now))
+	default:
+		status.SetCondition(newCondition(acidv1.ConditionSyncing, v1.ConditionFalse, status.PostgresClusterStatus, "", now))
+	}
+
+	status.SetCondition(readyCondition(status, now))
+	status.SetCondition(upgradePendingCondition(status, now))
+
+	if c.Spec.EnableLogicalBackup {
+		status.SetCondition(c.backupHealthyCondition(now))
+	} else {
+		status.RemoveCondition(acidv1.ConditionBackupHealthy)
+	}
+
+	if c.needConnectionPool() {
+		status.SetCondition(c.poolerReadyCondition(now))
+	} else {
+		status.RemoveCondition(acidv1.ConditionPoolerReady)
+	}
+}
+
+// setSyncingCondition reports a sync of the cluster as soon as it starts. The cluster status is left alone, so
+// that a running cluster stays ready during the periodic syncs; the status set at the end of the sync resets
+// the condition.
+func (c *Cluster) setSyncingCondition() {
+	status := c.Status.DeepCopy()
+	status.SetCondition(newCondition(acidv1.ConditionSyncing, v1.ConditionTrue, "Sync", "", metav1.Now()))
+
+	pg, err := c.patchStatus(map[string]interface{}{"conditions": status.Conditions})
+	if err != nil {
+		c.logger.Warningf("could not update the syncing condition: %v", err)
+		return
+	}
+	c.specMu.Lock()
+	c.Status = pg.Status
+	c.specMu.Unlock()
+}
+
+func newCondition(conditionType acidv1.ConditionType, conditionStatus v1.ConditionStatus,
+	reason, message string, now metav1.Time) acidv1.Condition {
+	return acidv1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+func readyCondition(status *acidv1.PostgresStatus, now metav1.Time) acidv1.Condition {
+	switch {
+	case !status.Running():
+		return newCondition(acidv1.ConditionReady, v1.ConditionFalse, status.PostgresClusterStatus, status.LastSyncError, now)
+	case status.MasterPod == "":
+		return newCondition(acidv1.ConditionReady, v1.ConditionFalse, "NoMaster", "no master pod found", now)
+	case status.ReadyInstances < status.Instances:
+		return newCondition(acidv1.ConditionReady, v1.ConditionFalse, "PodsNotReady",
+			fmt.Sprintf("%d of %d pods are ready", status.ReadyInstances, status.Instances), now)
+	}
+	return newCondition(acidv1.ConditionReady, v1.ConditionTrue, status.PostgresClusterStatus, "", now)
+}
+
+func upgradePendingCondition(status *acidv1.PostgresStatus, now metav1.Time) acidv1.Condition {
+	if upgrade := status.MajorVersionUpgrade; upgrade != nil {
+		switch upgrade.Phase {
+		case acidv1.UpgradePhaseChecking, acidv1.UpgradePhaseUpgrading, acidv1.UpgradePhaseRecreatingPods:
+			return newCondition(acidv1.ConditionUpgradePending, v1.ConditionTrue, "MajorVersionUpgrade",
+				fmt.Sprintf("upgrading from %s to %s", upgrade.FromVersion, upgrade.ToVersion), now)
+		}
+	}
+	if status.MaintenancePending() {
+		return newCondition(acidv1.ConditionUpgradePending, v1.ConditionTrue, "MaintenanceWindow",
+			strings.Join(status.PendingMaintenance, ", "), now)
+	}
+	return newCondition(acidv1.ConditionUpgradePending, v1.ConditionFalse, "", "", now)
+}
+
+// backupHealthyCondition reports the outcome of the most recent logical backup job that has finished.
+func (c *Cluster) backupHealthyCondition(now metav1.Time) acidv1.Condition {
+	selector := labels.Set{
+		c.OpConfig.ClusterNameLabel: c.Name,
+		"application":               "spilo-logical-backup",
+	}
+	pods, err := c.KubeClient.Pods(c.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return newCondition(acidv1.ConditionBackupHealthy, v1.ConditionUnknown, "ListFailed",
+			fmt.Sprintf("could not list logical backup pods: %v", err), now)
+	}
+
+	finished := make([]v1.Pod, 0)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			finished = append(finished, pod)
+		}
+	}
+	if len(finished) == 0 {
+		return newCondition(acidv1.ConditionBackupHealthy, v1.ConditionUnknown, "NoBackupYet", "", now)
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+	})
+	if latest := finished[0]; latest.Status.Phase == v1.PodFailed {
+		return newCondition(acidv1.ConditionBackupHealthy, v1.ConditionFalse, "BackupFailed",
+			fmt.Sprintf("logical backup pod %q failed", latest.Name), now)
+	}
+	return newCondition(acidv1.ConditionBackupHealthy, v1.ConditionTrue, "BackupSucceeded", "", now)
+}
+
+// poolerReadyCondition checks if all replicas of the connection pooler deployment are available.
+func (c *Cluster) poolerReadyCondition(now metav1.Time) acidv1.Condition {
+	deployment, err := c.KubeClient.Deployments(c.Namespace).Get(context.TODO(), c.connPoolName(), metav1.GetOptions{})
+	if err != nil {
+		return newCondition(acidv1.ConditionPoolerReady, v1.ConditionFalse, "DeploymentNotFound",
+			fmt.Sprintf("could not get connection pooler deployment: %v", err), now)
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.AvailableReplicas < replicas {
+		return newCondition(acidv1.ConditionPoolerReady, v1.ConditionFalse, "ReplicasNotAvailable",
+			fmt.Sprintf("%d of %d replicas are available", deployment.Status.AvailableReplicas, replicas), now)
+	}
+	return newCondition(acidv1.ConditionPoolerReady, v1.ConditionTrue, "ReplicasAvailable", "", now)
+}
@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusPatch(t *testing.T) {
+	patch := statusPatch(&acidv1.PostgresStatus{
+		PostgresClusterStatus: acidv1.ClusterStatusRunning,
+		ObservedGeneration:    3,
+		MasterPod:             "acid-test-cluster-0",
+	})
+
+	if patch["PostgresClusterStatus"] != acidv1.ClusterStatusRunning {
+		t.Errorf("Expected cluster status %q, got %v", acidv1.ClusterStatusRunning, patch["PostgresClusterStatus"])
+	}
+	if patch["masterPod"] != "acid-test-cluster-0" {
+		t.Errorf("Expected master pod to be set, got %v", patch["masterPod"])
+	}
+	if patch["observedGeneration"] != float64(3) {
+		t.Errorf("Expected observed generation 3, got %v", patch["observedGeneration"])
+	}
+	for _, field := range []string{"lastSyncError", "pendingMaintenance", "conditions"} {
+		if value, ok := patch[field]; !ok || value != nil {
+			t.Errorf("Expected empty field %q to be removed with null, got %v", field, value)
+		}
+	}
+}
+
+func TestReadyCondition(t *testing.T) {
+	testName := "TestReadyCondition"
+	now := metav1.Now()
+	tests := []struct {
+		subTest  string
+		status   acidv1.PostgresStatus
+		expected v1.ConditionStatus
+		reason   string
+	}{
+		{
+			subTest: "running cluster with all pods ready",
+			status: acidv1.PostgresStatus{
+				PostgresClusterStatus: acidv1.ClusterStatusRunning,
+				MasterPod:             "acid-test-cluster-0",
+				Instances:             2,
+				ReadyInstances:        2,
+			},
+			expected: v1.ConditionTrue,
+			reason:   acidv1.ClusterStatusRunning,
+		},
+		{
+			subTest: "running cluster with a pod not ready",
+			status: acidv1.PostgresStatus{
+				PostgresClusterStatus: acidv1.ClusterStatusRunning,
+				MasterPod:             "acid-test-cluster-0",
+				Instances:             2,
+				ReadyInstances:        1,
+			},
+			expected: v1.ConditionFalse,
+			reason:   "PodsNotReady",
+		},
+		{
+			subTest: "running cluster without master",
+			status: acidv1.PostgresStatus{
+				PostgresClusterStatus: acidv1.ClusterStatusRunning,
+				Instances:             1,
+				ReadyInstances:        1,
+			},
+			expected: v1.ConditionFalse,
+			reason:   "NoMaster",
+		},
+		{
+			subTest: "failed sync",
+			status: acidv1.PostgresStatus{
+				PostgresClusterStatus: acidv1.ClusterStatusSyncFailed,
+				LastSyncError:         "could not sync services",
+			},
+			expected: v1.ConditionFalse,
+			reason:   acidv1.ClusterStatusSyncFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		condition := readyCondition(&tt.status, now)
+		if condition.Type != acidv1.ConditionReady {
+			t.Errorf("%s %s: Expected condition type %s, have %s instead",
+				testName, tt.subTest, acidv1.ConditionReady, condition.Type)
+		}
+		if condition.Status != tt.expected || condition.Reason != tt.reason {
+			t.Errorf("%s %s: Expected %s with reason %q, have %s with reason %q instead",
+				testName, tt.subTest, tt.expected, tt.reason, condition.Status, condition.Reason)
+		}
+	}
+}
+
+func TestUpgradePendingCondition(t *testing.T) {
+	now := metav1.Now()
+
+	status := acidv1.PostgresStatus{PendingMaintenance: []string{maintenanceRollingUpdate}}
+	if condition := upgradePendingCondition(&status, now); condition.Status != v1.ConditionTrue {
+		t.Errorf("Expected pending maintenance to be reported, got %#v", condition)
+	}
+
+	status = acidv1.PostgresStatus{MajorVersionUpgrade: &acidv1.MajorVersionUpgradeStatus{
+		FromVersion: "11", ToVersion: "12", Phase: acidv1.UpgradePhaseFinished}}
+	if condition := upgradePendingCondition(&status, now); condition.Status != v1.ConditionFalse {
+		t.Errorf("Expected finished upgrade not to be pending, got %#v", condition)
+	}
+}
@@ -3,6 +3,7 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
@@ -14,6 +15,7 @@ import (
 	"github.com/zalando/postgres-operator/pkg/util"
 	"github.com/zalando/postgres-operator/pkg/util/constants"
 	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 	"github.com/zalando/postgres-operator/pkg/util/volumes"
 )
 
@@ -26,13 +28,17 @@ func (c *Cluster) Sync(newSpec *acidv1.Postgresql) error {
 
 	oldSpec := c.Postgresql
 	c.setSpec(newSpec)
+	c.recordEvent(v1.EventTypeNormal, "Sync", "Started sync of the cluster")
+	c.setSyncingCondition()
 
 	defer func() {
 		if err != nil {
 			c.logger.Warningf("error while syncing cluster state: %v", err)
-			c.setStatus(acidv1.ClusterStatusSyncFailed)
-		} else if !c.Status.Running() {
-			c.setStatus(acidv1.ClusterStatusRunning)
+			c.setStatus(acidv1.ClusterStatusSyncFailed, err)
+			c.recordEvent(v1.EventTypeWarning, "Sync", "Could not sync cluster: %v", err)
+		} else {
+			c.setStatus(acidv1.ClusterStatusRunning, nil)
+			c.recordEvent(v1.EventTypeNormal, "Sync", "Cluster has been synced")
 		}
 	}()
 
@@ -43,7 +49,7 @@ func (c *Cluster) Sync(newSpec *acidv1.Postgresql) error {
 
 	c.logger.Debugf("syncing secrets")
 
-	//TODO: mind the secrets of the deleted/new users
+	// secrets of removed users are deleted when their roles are deprecated
 	if err = c.syncSecrets(); err != nil {
 		err = fmt.Errorf("could not sync secrets: %v", err)
 		return err
@@ -94,6 +100,19 @@ func (c *Cluster) Sync(newSpec *acidv1.Postgresql) error {
 			err = fmt.Errorf("could not sync the logical backup job: %v", err)
 			return err
 		}
+
+		// an unavailable bucket must not block the sync of the cluster; backups on volumes are pruned by the job itself
+		target := c.logicalBackupTarget()
+		if retention := c.logicalBackupRetention(); retention.Enabled() && target.Provider == constants.LogicalBackupProviderS3 {
+			c.logger.Debug("pruning logical backups")
+			storage, err := c.logicalBackupStorage(target)
+			if err == nil {
+				err = c.pruneLogicalBackups(storage, retention)
+			}
+			if err != nil {
+				c.logger.Warningf("could not prune logical backups: %v", err)
+			}
+		}
 	}
 
 	// create database objects unless we are running without pods or disabled that feature explicitly
@@ -108,6 +127,21 @@ func (c *Cluster) Sync(newSpec *acidv1.Postgresql) error {
 			err = fmt.Errorf("could not sync databases: %v", err)
 			return err
 		}
+		c.logger.Debugf("syncing extensions")
+		if err = c.syncExtensions(); err != nil {
+			err = fmt.Errorf("could not sync extensions: %v", err)
+			return err
+		}
+		c.logger.Debugf("syncing bootstrap SQL")
+		if err = c.syncBootstrapSQL(); err != nil {
+			err = fmt.Errorf("could not sync bootstrap SQL: %v", err)
+			return err
+		}
+		c.logger.Debugf("syncing logical replication")
+		if err = c.syncLogicalReplication(); err != nil {
+			err = fmt.Errorf("could not sync logical replication: %v", err)
+			return err
+		}
 	}
 
 	// sync connection pool
@@ -254,8 +288,13 @@ func (c *Cluster) syncPodDisruptionBudget(isUpdate bool) error {
 func (c *Cluster) syncStatefulSet() error {
 	var (
 		podsRollingUpdateRequired bool
+		desiredPgVersion          string
 	)
 	// NB: Be careful to consider the codepath that acts on podsRollingUpdateRequired before returning early.
+
+	// disruptive actions still required are postponed again below when outside of the maintenance windows
+	c.pendingMaintenance = nil
+
 	sset, err := c.KubeClient.StatefulSets(c.Namespace).Get(context.TODO(), c.statefulSetName(), metav1.GetOptions{})
 	if err != nil {
 		if !k8sutil.ResourceNotFound(err) {
@@ -301,6 +340,10 @@ func (c *Cluster) syncStatefulSet() error {
 			if err != nil {
 				return fmt.Errorf("could not parse current Postgres version: %v", err)
 			}
+			// keep the running version in the statefulset until the major version upgrade is done
+			if pgVersion != c.Spec.PostgresqlParam.PgVersion {
+				desiredPgVersion = c.Spec.PostgresqlParam.PgVersion
+			}
 			c.Spec.PostgresqlParam.PgVersion = pgVersion
 		}
 
@@ -323,7 +366,7 @@ func (c *Cluster) syncStatefulSet() error {
 				if err := c.updateStatefulSet(desiredSS); err != nil {
 					return fmt.Errorf("could not update statefulset: %v", err)
 				}
-			} else {
+			} else if c.allowDisruptiveAction(maintenanceReplaceStatefulSet) {
 				if err := c.replaceStatefulSet(desiredSS); err != nil {
 					return fmt.Errorf("could not replace statefulset: %v", err)
 				}
@@ -340,7 +383,7 @@ func (c *Cluster) syncStatefulSet() error {
 
 	// if we get here we also need to re-create the pods (either leftovers from the old
 	// statefulset or those that got their configuration from the outdated statefulset)
-	if podsRollingUpdateRequired {
+	if podsRollingUpdateRequired && c.allowDisruptiveAction(maintenanceRollingUpdate) {
 		c.logger.Debugln("performing rolling update")
 		if err := c.recreatePods(); err != nil {
 			return fmt.Errorf("could not recreate pods: %v", err)
@@ -350,6 +393,14 @@ func (c *Cluster) syncStatefulSet() error {
 			c.logger.Warningf("could not clear rolling update for the statefulset: %v", err)
 		}
 	}
+
+	if desiredPgVersion != "" {
+		if err := c.majorVersionUpgrade(c.Spec.PostgresqlParam.PgVersion, desiredPgVersion); err != nil {
+			return fmt.Errorf("could not upgrade Postgres to version %s: %v", desiredPgVersion, err)
+		}
+	} else {
+		c.resetMajorVersionUpgradeStatus()
+	}
 	return nil
 }
 
@@ -383,17 +434,79 @@ func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration() error {
 	}
 	// try all pods until the first one that is successful, as it doesn't matter which pod
 	// carries the request to change configuration through
+	configured := false
 	for _, pod := range pods {
 		podName := util.NameFromMeta(pod.ObjectMeta)
+		changedOptions := optionsToSet
+		if currentOptions, err := c.patroni.GetPostgresParameters(&pod); err != nil {
+			c.logger.Warningf("could not get postgres parameters with a pod %s, setting all options: %v", podName, err)
+		} else {
+			changedOptions = changedPostgresParameters(optionsToSet, currentOptions)
+		}
+		if len(changedOptions) == 0 {
+			configured = true
+			break
+		}
+
 		c.logger.Debugf("calling Patroni API on a pod %s to set the following Postgres options: %v",
-			podName, optionsToSet)
-		if err = c.patroni.SetPostgresParameters(&pod, optionsToSet); err == nil {
-			return nil
+			podName, changedOptions)
+		if err = c.patroni.SetPostgresParameters(&pod, changedOptions); err == nil {
+			configured = true
+			break
 		}
 		c.logger.Warningf("could not patch postgres parameters with a pod %s: %v", podName, err)
 	}
-	return fmt.Errorf("could not reach Patroni API to set Postgres options: failed on every pod (%d total)",
-		len(pods))
+	if !configured {
+		return fmt.Errorf("could not reach Patroni API to set Postgres options: failed on every pod (%d total)",
+			len(pods))
+	}
+	return c.restartPendingInstances(pods)
+}
+
+// changedPostgresParameters returns the options whose value differs from the Patroni configuration
+func changedPostgresParameters(options, currentOptions map[string]string) map[string]string {
+	changedOptions := make(map[string]string)
+	for k, v := range options {
+		if currentValue, ok := currentOptions[k]; !ok || currentValue != v {
+			changedOptions[k] = v
+		}
+	}
+	return changedOptions
+}
+
+// restartPendingInstances restarts the Postgres instances that Patroni reports with a pending restart, e.g.
+// after a change of max_connections. Only the restart interrupts the clients, so it is limited to the
+// maintenance windows. Patroni only notices the changed configuration with its next cycle, so the instances
+// are usually restarted by the sync after the one that changed the options. The master is restarted last.
+func (c *Cluster) restartPendingInstances(pods []v1.Pod) error {
+	pendingPods := make([]*v1.Pod, 0)
+	for i, pod := range pods {
+		member, err := c.patroni.GetMemberData(&pods[i])
+		if err != nil {
+			c.logger.Warningf("could not get Patroni member data of pod %s: %v", util.NameFromMeta(pod.ObjectMeta), err)
+			continue
+		}
+		if member.PendingRestart {
+			pendingPods = append(pendingPods, &pods[i])
+		}
+	}
+	if len(pendingPods) == 0 || !c.allowDisruptiveAction(maintenanceRestartParameters) {
+		return nil
+	}
+
+	sort.SliceStable(pendingPods, func(i, j int) bool {
+		return PostgresRole(pendingPods[i].Labels[c.OpConfig.PodRoleLabel]) != Master &&
+			PostgresRole(pendingPods[j].Labels[c.OpConfig.PodRoleLabel]) == Master
+	})
+	for _, pod := range pendingPods {
+		podName := util.NameFromMeta(pod.ObjectMeta)
+		c.logger.Infof("restarting Postgres in pod %q to apply changed parameters", podName)
+		if err := c.patroni.Restart(pod); err != nil {
+			return fmt.Errorf("could not restart Postgres in pod %q: %v", podName, err)
+		}
+		c.recordEvent(v1.EventTypeNormal, "Restart", "Restarted Postgres in pod %q to apply changed parameters", podName)
+	}
+	return nil
 }
 
 func (c *Cluster) syncSecrets() error {
@@ -403,54 +516,123 @@ func (c *Cluster) syncSecrets() error {
 	)
 	c.setProcessName("syncing secrets")
 	secrets := c.generateUserSecrets()
+	c.passwordRotations = make(map[string]*passwordRotation)
+	systemRotations := make(map[string]*v1.Secret)
 
 	for secretUsername, secretSpec := range secrets {
-		if secret, err = c.KubeClient.Secrets(secretSpec.Namespace).Create(context.TODO(), secretSpec, metav1.CreateOptions{}); err == nil {
+		if secret, err = c.SecretBackend.Create(secretSpec); err == nil {
 			c.Secrets[secret.UID] = secret
 			c.logger.Debugf("created new secret %q, uid: %q", util.NameFromMeta(secret.ObjectMeta), secret.UID)
+			c.recordEvent(v1.EventTypeNormal, "Secrets", "Created secret %q for role %q", util.NameFromMeta(secret.ObjectMeta), secretUsername)
 			continue
 		}
 		if k8sutil.ResourceAlreadyExists(err) {
-			var userMap map[string]spec.PgUser
-			if secret, err = c.KubeClient.Secrets(secretSpec.Namespace).Get(context.TODO(), secretSpec.Name, metav1.GetOptions{}); err != nil {
+			if secret, err = c.SecretBackend.Get(secretSpec.Namespace, secretSpec.Name); err != nil {
 				return fmt.Errorf("could not get current secret: %v", err)
 			}
-			if secretUsername != string(secret.Data["username"]) {
+			if !isSecretOfUser(secret, secretUsername) {
 				c.logger.Warningf("secret %q does not contain the role %q", secretSpec.Name, secretUsername)
 				continue
 			}
+			// secrets are removed with the cluster, also the ones created before a restart of the operator
+			c.Secrets[secret.UID] = secret
 			c.logger.Debugf("secret %q already exists, fetching its password", util.NameFromMeta(secret.ObjectMeta))
-			if secretUsername == c.systemUsers[constants.SuperuserKeyName].Name {
-				secretUsername = constants.SuperuserKeyName
-				userMap = c.systemUsers
-			} else if secretUsername == c.systemUsers[constants.ReplicationUserKeyName].Name {
-				secretUsername = constants.ReplicationUserKeyName
-				userMap = c.systemUsers
-			} else {
-				userMap = c.pgUsers
-			}
-			pwdUser := userMap[secretUsername]
+			userMap, userKey := c.secretUserMap(secretUsername)
+			pwdUser := userMap[userKey]
 			// if this secret belongs to the infrastructure role and the password has changed - replace it in the secret
 			if pwdUser.Password != string(secret.Data["password"]) &&
 				pwdUser.Origin == spec.RoleOriginInfrastructure {
 
 				c.logger.Debugf("updating the secret %q from the infrastructure roles", secretSpec.Name)
-				if _, err = c.KubeClient.Secrets(secretSpec.Namespace).Update(context.TODO(), secretSpec, metav1.UpdateOptions{}); err != nil {
+				if _, err = c.SecretBackend.Update(secretSpec); err != nil {
 					return fmt.Errorf("could not update infrastructure role secret for role %q: %v", secretUsername, err)
 				}
 			} else {
 				// for non-infrastructure role - update the role with the password from the secret
-				pwdUser.Password = string(secret.Data["password"])
-				userMap[secretUsername] = pwdUser
+				c.useSecretCredentials(userMap, userKey, secret)
+				if pwdUser.Origin == spec.RoleOriginSystem {
+					if c.systemPasswordRotationDue(secret) {
+						systemRotations[userKey] = secret
+					}
+				} else {
+					c.preparePasswordRotation(userMap[userKey], secret)
+				}
 			}
+		} else if secretSpec.Namespace != c.Namespace {
+			// a missing namespace only affects the users whose secrets belong there; their roles keep
+			// the current password until the secret can be created
+			c.logger.Warningf("could not create secret %q for role %q: %v", util.NameFromMeta(secretSpec.ObjectMeta), secretUsername, err)
+			c.recordEvent(v1.EventTypeWarning, "Secrets", "Could not create secret for role %q in namespace %q: %v",
+				secretUsername, secretSpec.Namespace, err)
+			userMap, userKey := c.secretUserMap(secretUsername)
+			pwdUser := userMap[userKey]
+			pwdUser.Password = ""
+			userMap[userKey] = pwdUser
 		} else {
+			c.recordEvent(v1.EventTypeWarning, "Secrets", "Could not create secret for role %q: %v", secretUsername, err)
 			return fmt.Errorf("could not create secret for user %q: %v", secretUsername, err)
 		}
 	}
 
+	// system passwords are rotated once the current ones are known from all secrets
+	if err = c.rotateSystemPasswords(systemRotations); err != nil {
+		return fmt.Errorf("could not rotate system passwords: %v", err)
+	}
+
+	if err = c.syncPodSecrets(); err != nil {
+		return fmt.Errorf("could not sync pod secrets: %v", err)
+	}
+
+	return nil
+}
+
+// syncPodSecrets keeps Kubernetes secrets with the credentials of the system users and the connection pool
+// user when the credentials are stored in another secret backend, since the pods of the cluster and of the
+// connection pool read them from their environment.
+func (c *Cluster) syncPodSecrets() error {
+	if c.SecretBackend.Name() == constants.SecretBackendKubernetes {
+		return nil
+	}
+
+	for _, systemUser := range c.systemUsers {
+		secretSpec := c.generateSingleUserSecret(c.Namespace, systemUser)
+		if secretSpec == nil {
+			continue
+		}
+		secret, err := c.KubeClient.Secrets(secretSpec.Namespace).Get(context.TODO(), secretSpec.Name, metav1.GetOptions{})
+		if k8sutil.ResourceNotFound(err) {
+			if _, err = c.KubeClient.Secrets(secretSpec.Namespace).Create(context.TODO(), secretSpec, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("could not create pod secret for user %q: %v", systemUser.Name, err)
+			}
+			c.logger.Debugf("created pod secret %q", util.NameFromMeta(secretSpec.ObjectMeta))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get pod secret of user %q: %v", systemUser.Name, err)
+		}
+		if string(secret.Data["username"]) == systemUser.Name && string(secret.Data["password"]) == systemUser.Password {
+			continue
+		}
+		secret.Data = secretSpec.Data
+		if _, err = c.KubeClient.Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("could not update pod secret of user %q: %v", systemUser.Name, err)
+		}
+		c.logger.Debugf("updated pod secret %q", util.NameFromMeta(secret.ObjectMeta))
+	}
+
 	return nil
 }
 
+// secretUserMap returns the map of users the role of a secret belongs to and the key of the role in that map
+func (c *Cluster) secretUserMap(secretUsername string) (map[string]spec.PgUser, string) {
+	if secretUsername == c.systemUsers[constants.SuperuserKeyName].Name {
+		return c.systemUsers, constants.SuperuserKeyName
+	} else if secretUsername == c.systemUsers[constants.ReplicationUserKeyName].Name {
+		return c.systemUsers, constants.ReplicationUserKeyName
+	}
+	return c.pgUsers, secretUsername
+}
+
 func (c *Cluster) syncRoles() (err error) {
 	c.setProcessName("syncing roles")
 
@@ -474,19 +656,24 @@ func (c *Cluster) syncRoles() (err error) {
 		}
 	}()
 
-	for _, u := range c.pgUsers {
-		userNames = append(userNames, u.Name)
-	}
-
 	if c.needConnectionPool() {
 		connPoolUser := c.systemUsers[constants.ConnectionPoolUserKeyName]
-		userNames = append(userNames, connPoolUser.Name)
 
 		if _, exists := c.pgUsers[connPoolUser.Name]; !exists {
 			c.pgUsers[connPoolUser.Name] = connPoolUser
 		}
 	}
 
+	// the secrets are listed once and looked up for every removed role
+	secrets, err := c.listUserSecrets()
+	if err != nil {
+		return fmt.Errorf("could not list secrets: %v", err)
+	}
+
+	if userNames, err = c.roleNamesToSync(secrets); err != nil {
+		return fmt.Errorf("could not find removed roles: %v", err)
+	}
+
 	dbUsers, err = c.readPgUsersFromDatabase(userNames)
 	if err != nil {
 		return fmt.Errorf("error getting users from the database: %v", err)
@@ -496,6 +683,12 @@ func (c *Cluster) syncRoles() (err error) {
 	if err = c.userSyncStrategy.ExecuteSyncRequests(pgSyncRequests, c.pgDb); err != nil {
 		return fmt.Errorf("error executing sync statements: %v", err)
 	}
+	if err = c.applyPasswordRotations(); err != nil {
+		return err
+	}
+	c.processDeprecatedRoles(pgSyncRequests, secrets)
+	c.deleteMovedSecrets(secrets)
+	c.cleanupRotatedRoles()
 
 	return nil
 }
@@ -511,9 +704,14 @@ func (c *Cluster) syncVolumes() error {
 	if !act {
 		return nil
 	}
-	if err := c.resizeVolumes(c.Spec.Volume, []volumes.VolumeResizer{&volumes.EBSVolumeResizer{AWSRegion: c.OpConfig.AWSRegion}}); err != nil {
+	c.recordEvent(v1.EventTypeNormal, "VolumeResize", "Resizing volumes to %s", c.Spec.Volume.Size)
+	err = c.resizeVolumes(c.Spec.Volume, []volumes.VolumeResizer{&volumes.EBSVolumeResizer{AWSRegion: c.OpConfig.AWSRegion}})
+	metrics.CountVolumeResize(c.Namespace, c.Name, err)
+	if err != nil {
+		c.recordEvent(v1.EventTypeWarning, "VolumeResize", "Could not resize volumes: %v", err)
 		return fmt.Errorf("could not sync volumes: %v", err)
 	}
+	c.recordEvent(v1.EventTypeNormal, "VolumeResize", "Volumes have been resized to %s", c.Spec.Volume.Size)
 
 	c.logger.Infof("volumes have been synced successfully")
 
@@ -523,13 +721,13 @@ func (c *Cluster) syncVolumes() error {
 func (c *Cluster) syncDatabases() error {
 	c.setProcessName("syncing databases")
 
-	createDatabases := make(map[string]string)
-	alterOwnerDatabases := make(map[string]string)
-
 	if err := c.initDbConn(); err != nil {
 		return fmt.Errorf("could not init database connection")
 	}
 	defer func() {
+		if c.connectionIsClosed() {
+			return
+		}
 		if err := c.closeDbConn(); err != nil {
 			c.logger.Errorf("could not close database connection: %v", err)
 		}
@@ -540,19 +738,7 @@ func (c *Cluster) syncDatabases() error {
 		return fmt.Errorf("could not get current databases: %v", err)
 	}
 
-	for datname, newOwner := range c.Spec.Databases {
-		currentOwner, exists := currentDatabases[datname]
-		if !exists {
-			createDatabases[datname] = newOwner
-		} else if currentOwner != newOwner {
-			alterOwnerDatabases[datname] = newOwner
-		}
-	}
-
-	if len(createDatabases)+len(alterOwnerDatabases) == 0 {
-		return nil
-	}
-
+	createDatabases, alterOwnerDatabases := databaseChanges(currentDatabases, c.databaseOwners())
 	for datname, owner := range createDatabases {
 		if err = c.executeCreateDatabase(datname, owner); err != nil {
 			return err
@@ -564,7 +750,43 @@ func (c *Cluster) syncDatabases() error {
 		}
 	}
 
-	return nil
+	if len(c.Spec.PreparedDatabases) == 0 {
+		return nil
+	}
+	// schemas are created within the prepared databases, each with its own connection
+	if err = c.closeDbConn(); err != nil {
+		return fmt.Errorf("could not close database connection: %v", err)
+	}
+	return c.syncPreparedDatabases()
+}
+
+// databaseChanges returns the databases of the manifest to create and the ones whose owner has to change,
+// both with the owner of the manifest
+func databaseChanges(currentDatabases, databases map[string]string) (map[string]string, map[string]string) {
+	createDatabases := make(map[string]string)
+	alterOwnerDatabases := make(map[string]string)
+	for datname, newOwner := range databases {
+		currentOwner, exists := currentDatabases[datname]
+		if !exists {
+			createDatabases[datname] = newOwner
+		} else if currentOwner != newOwner {
+			alterOwnerDatabases[datname] = newOwner
+		}
+	}
+	return createDatabases, alterOwnerDatabases
+}
+
+// databaseOwners returns the databases of the manifest with their owners, including the prepared
+// databases owned by their default owner roles
+func (c *Cluster) databaseOwners() map[string]string {
+	databases := make(map[string]string, len(c.Spec.Databases)+len(c.Spec.PreparedDatabases))
+	for datname, owner := range c.Spec.Databases {
+		databases[datname] = owner
+	}
+	for datname := range c.Spec.PreparedDatabases {
+		databases[datname] = datname + constants.OwnerRoleNameSuffix
+	}
+	return databases
 }
 
 func (c *Cluster) syncLogicalBackupJob() error {
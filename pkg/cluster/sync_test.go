@@ -68,7 +68,7 @@ func TestConnPoolSynchronization(t *testing.T) {
 					NumberOfInstances:            int32ToPointer(1),
 				},
 			},
-		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger)
+		}, k8sutil.KubernetesClient{}, acidv1.Postgresql{}, logger, eventRecorder)
 
 	cluster.Statefulset = &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -210,3 +210,15 @@ func TestConnPoolSynchronization(t *testing.T) {
 		}
 	}
 }
+
+func TestChangedPostgresParameters(t *testing.T) {
+	testName := "TestChangedPostgresParameters"
+	options := map[string]string{"max_connections": "1000000", "wal_log_hints": "on", "wal_level": "logical"}
+	current := map[string]string{"max_connections": "1000000", "wal_log_hints": "off"}
+
+	changed := changedPostgresParameters(options, current)
+	expected := map[string]string{"wal_log_hints": "on", "wal_level": "logical"}
+	if fmt.Sprint(changed) != fmt.Sprint(expected) {
+		t.Errorf("%s: expected changed options %v, got %v", testName, expected, changed)
+	}
+}
@@ -50,6 +50,7 @@ type Process struct {
 type WorkerStatus struct {
 	CurrentCluster types.NamespacedName
 	CurrentProcess Process
+	Retries        int // failed attempts to process events of the current cluster
 }
 
 // ClusterStatus describes status of the cluster
@@ -65,6 +66,7 @@ type ClusterStatus struct {
 
 	CurrentProcess Process
 	Worker         uint32
+	Paused         bool
 	Status         acidv1.PostgresStatus
 	Spec           acidv1.PostgresSpec
 	Error          error
@@ -17,6 +17,8 @@ import (
 	policybeta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/reference"
 
 	acidzalando "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do"
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
@@ -225,12 +227,14 @@ func (c *Cluster) getTeamMembers(teamID string) ([]string, error) {
 	token, err := c.oauthTokenGetter.getOAuthToken()
 	if err != nil {
 		c.logger.Warnf("could not get oauth token to authenticate to team service API, returning empty list of team members: %v", err)
+		c.teamMembersUnknown = true
 		return []string{}, nil
 	}
 
 	teamInfo, err := c.teamsAPIClient.TeamInfo(teamID, token)
 	if err != nil {
 		c.logger.Warnf("could not get team info for team %q, returning empty list of team members: %v", teamID, err)
+		c.teamMembersUnknown = true
 		return []string{}, nil
 	}
 
@@ -472,6 +476,48 @@ func (c *Cluster) credentialSecretNameForCluster(username string, clusterName st
 		"tprgroup", acidzalando.GroupName)
 }
 
+// secretNamespaceOfUser returns the namespace for the credential secret of a user defined in the manifest,
+// either from the secretNamespaces section or from the namespace.username form of the name. An empty string
+// stands for the namespace of the cluster.
+func secretNamespaceOfUser(pgSpec *acidv1.PostgresSpec, username string) string {
+	if namespace, exists := pgSpec.SecretNamespaces[username]; exists {
+		return namespace
+	}
+	if i := strings.Index(username, "."); i > 0 {
+		return username[:i]
+	}
+	return ""
+}
+
+// userSecretNamespace returns the namespace of the credential secret of a user
+func (c *Cluster) userSecretNamespace(pgUser spec.PgUser) string {
+	if pgUser.Namespace != "" {
+		return pgUser.Namespace
+	}
+	return c.Namespace
+}
+
+// listUserSecrets returns the credential secrets of the cluster, including the ones in other namespaces.
+// Secrets outside of the cluster namespace are recognized by the annotation with the name of the cluster.
+func (c *Cluster) listUserSecrets() ([]v1.Secret, error) {
+	if !c.OpConfig.EnableCrossNamespaceSecrets {
+		return c.SecretBackend.List(c.Namespace, c.labelsSet(false).AsSelector())
+	}
+
+	secrets, err := c.SecretBackend.List("", c.labelsSet(false).AsSelector())
+	if err != nil {
+		return nil, err
+	}
+	clusterName := util.NameFromMeta(c.ObjectMeta).String()
+	result := make([]v1.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret.Namespace == c.Namespace || secret.Annotations[constants.SecretClusterAnnotationKey] == clusterName {
+			result = append(result, secret)
+		}
+	}
+	return result, nil
+}
+
 func masterCandidate(replicas []spec.NamespacedName) spec.NamespacedName {
 	return replicas[rand.Intn(len(replicas))]
 }
@@ -506,6 +552,36 @@ func (c *Cluster) GetSpec() (*acidv1.Postgresql, error) {
 	return cloneSpec(&c.Postgresql)
 }
 
+// GetPostgresClusterStatus returns the status of a Postgres cluster in a thread-safe manner
+func (c *Cluster) GetPostgresClusterStatus() string {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+	return c.Status.PostgresClusterStatus
+}
+
+// GetReference returns a reference to the postgresql object of the cluster, i.e. to emit events for it
+func (c *Cluster) GetReference() *v1.ObjectReference {
+	c.specMu.RLock()
+	defer c.specMu.RUnlock()
+	ref, err := reference.GetReference(scheme.Scheme, &c.Postgresql)
+	if err != nil {
+		c.logger.Errorf("could not get reference for postgresql object %q: %v", c.clusterName(), err)
+	}
+	return ref
+}
+
+// recordEvent emits a K8s event on the postgresql object, so that it shows up in kubectl describe
+func (c *Cluster) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if c.eventRecorder == nil {
+		return
+	}
+	ref := c.GetReference()
+	if ref == nil {
+		return
+	}
+	c.eventRecorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
 func (c *Cluster) patroniUsesKubernetes() bool {
 	return c.OpConfig.EtcdHost == ""
 }
@@ -0,0 +1,373 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateManifest checks a new or changed Postgres manifest before it is stored, so that errors
+// the operator would only detect while acting on the cluster are reported to the user right away.
+// The old manifest is nil for new clusters. Updates that leave the spec unchanged, e.g. of the
+// annotations that pause the cluster, are always accepted.
+func ValidateManifest(opConfig *config.Config, oldSpec, newSpec *acidv1.Postgresql) error {
+	// errors of the cluster name and the clone description are recorded while decoding the manifest
+	if newSpec.Error != "" {
+		return fmt.Errorf("invalid manifest: %s", newSpec.Error)
+	}
+
+	// the spec was accepted before and must not become unchangeable by a change of the operator configuration
+	if oldSpec != nil && reflect.DeepEqual(oldSpec.Spec, newSpec.Spec) {
+		return nil
+	}
+
+	spec := &newSpec.Spec
+	if err := validateResources("resources", &spec.Resources); err != nil {
+		return err
+	}
+	for _, sidecar := range spec.Sidecars {
+		if err := validateResources(fmt.Sprintf("resources of the sidecar %q", sidecar.Name), &sidecar.Resources); err != nil {
+			return err
+		}
+	}
+	if spec.ConnectionPool != nil {
+		if err := validateResources("resources of the connection pool", &spec.ConnectionPool.Resources); err != nil {
+			return err
+		}
+	}
+	if spec.Volume.Size != "" {
+		if _, err := resource.ParseQuantity(spec.Volume.Size); err != nil {
+			return fmt.Errorf("could not parse volume size %q: %v", spec.Volume.Size, err)
+		}
+	}
+
+	for username, flags := range spec.Users {
+		if !isValidUsername(username) {
+			return fmt.Errorf("invalid username: %q", username)
+		}
+		if _, err := normalizeUserFlags(flags); err != nil {
+			return fmt.Errorf("invalid flags for user %q: %v", username, err)
+		}
+	}
+
+	for username, rotation := range spec.PasswordRotation {
+		if _, exists := spec.Users[username]; !exists {
+			return fmt.Errorf("password rotation is configured for the undefined user %q", username)
+		}
+		if rotation.Interval == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(rotation.Interval); err != nil {
+			return fmt.Errorf("could not parse password rotation interval of user %q: %v", username, err)
+		}
+	}
+
+	if err := validateSecretNamespaces(opConfig, spec); err != nil {
+		return err
+	}
+
+	if err := validatePreparedDatabases(spec); err != nil {
+		return err
+	}
+
+	if err := validateExtensions(spec); err != nil {
+		return err
+	}
+
+	if err := validateBootstrapSQL(spec); err != nil {
+		return err
+	}
+
+	if err := validateLogicalReplication(newSpec.Name, spec); err != nil {
+		return err
+	}
+
+	// the limits of the operator may have been lowered since the number of instances was last changed
+	if oldSpec == nil || oldSpec.Spec.NumberOfInstances != spec.NumberOfInstances ||
+		(oldSpec.Spec.StandbyCluster == nil) != (spec.StandbyCluster == nil) {
+		if err := validateNumberOfInstances(opConfig, spec); err != nil {
+			return err
+		}
+	}
+
+	if retention := spec.LogicalBackupRetention; retention != nil {
+		if (retention.Count != nil && *retention.Count < 0) || (retention.Days != nil && *retention.Days < 0) {
+			return fmt.Errorf("logical backup retention cannot be negative")
+		}
+	}
+
+	if target := spec.LogicalBackupTarget; target != nil {
+		switch target.Provider {
+		case "", constants.LogicalBackupProviderS3, constants.LogicalBackupProviderGCS,
+			constants.LogicalBackupProviderAzure, constants.LogicalBackupProviderPVC:
+		default:
+			return fmt.Errorf("unknown logical backup provider %q", target.Provider)
+		}
+		// the credentials of the operator must not give access to any storage a manifest names
+		if (target.Bucket != "" || target.StorageAccount != "" || target.Container != "") && target.CredentialsSecret == "" {
+			return fmt.Errorf("logicalBackupTarget requires a credentialsSecret when it sets the bucket, storage account or container")
+		}
+	}
+
+	if err := validateRestoreVerification(spec); err != nil {
+		return err
+	}
+
+	if oldSpec == nil {
+		return nil
+	}
+
+	if oldSpec.Spec.TeamID != spec.TeamID {
+		return fmt.Errorf("teamId cannot be changed from %q to %q", oldSpec.Spec.TeamID, spec.TeamID)
+	}
+
+	if oldSpec.Spec.Volume.Size != "" && spec.Volume.Size != "" {
+		isSmaller, err := util.IsSmallerQuantity(spec.Volume.Size, oldSpec.Spec.Volume.Size)
+		if err != nil {
+			return fmt.Errorf("could not compare volume sizes: %v", err)
+		}
+		if isSmaller {
+			return fmt.Errorf("volume size cannot be decreased from %s to %s", oldSpec.Spec.Volume.Size, spec.Volume.Size)
+		}
+	}
+
+	if oldVersion, newVersion := oldSpec.Spec.PgVersion, spec.PgVersion; oldVersion != newVersion {
+		isUpgrade, err := isMajorVersionUpgrade(oldVersion, newVersion)
+		if err != nil {
+			return err
+		}
+		// going back to the running version after a failed upgrade resets the upgrade, so that it can be retried
+		if !isUpgrade && !failedMajorVersionUpgrade(oldSpec.Status.MajorVersionUpgrade, newVersion, oldVersion) {
+			return fmt.Errorf("postgresql version cannot be decreased from %s to %s", oldVersion, newVersion)
+		}
+	}
+
+	return nil
+}
+
+func validateResources(description string, resources *acidv1.Resources) error {
+	for _, quantity := range []struct {
+		name  string
+		value string
+	}{
+		{"CPU request", resources.ResourceRequests.CPU},
+		{"memory request", resources.ResourceRequests.Memory},
+		{"CPU limit", resources.ResourceLimits.CPU},
+		{"memory limit", resources.ResourceLimits.Memory},
+	} {
+		if quantity.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantity.value); err != nil {
+			return fmt.Errorf("could not parse %s in %s %q: %v", quantity.name, description, quantity.value, err)
+		}
+	}
+	return nil
+}
+
+// validateNumberOfInstances rejects instance counts the operator would otherwise silently adjust.
+func validateNumberOfInstances(opConfig *config.Config, spec *acidv1.PostgresSpec) error {
+	instances := spec.NumberOfInstances
+	if instances < 0 {
+		return fmt.Errorf("numberOfInstances cannot be negative")
+	}
+	// standby clusters always run a single pod, see getNumberOfInstances
+	if spec.StandbyCluster != nil {
+		return nil
+	}
+	if opConfig.MaxInstances >= 0 && instances > opConfig.MaxInstances {
+		return fmt.Errorf("numberOfInstances %d is higher than the maximum of %d", instances, opConfig.MaxInstances)
+	}
+	if opConfig.MinInstances >= 0 && instances < opConfig.MinInstances {
+		return fmt.Errorf("numberOfInstances %d is lower than the minimum of %d", instances, opConfig.MinInstances)
+	}
+	return nil
+}
+
+// validateSecretNamespaces checks the namespaces of the credential secrets of the users. Users in the
+// namespace.username form only have their secrets in other namespaces with cross namespace secrets enabled.
+func validateSecretNamespaces(opConfig *config.Config, spec *acidv1.PostgresSpec) error {
+	if !opConfig.EnableCrossNamespaceSecrets {
+		if len(spec.SecretNamespaces) > 0 {
+			return fmt.Errorf("secret namespaces are configured, but secrets in other namespaces are not enabled")
+		}
+		return nil
+	}
+	for username := range spec.SecretNamespaces {
+		if _, exists := spec.Users[username]; !exists {
+			return fmt.Errorf("secret namespace is configured for the undefined user %q", username)
+		}
+	}
+	for username := range spec.Users {
+		namespace := secretNamespaceOfUser(spec, username)
+		if namespace == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid secret namespace %q of user %q: %s", namespace, username, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// validatePreparedDatabases checks the names of the prepared databases, their schemas and the default
+// roles derived from them
+func validatePreparedDatabases(spec *acidv1.PostgresSpec) error {
+	for preparedDbName, preparedDB := range spec.PreparedDatabases {
+		if !databaseNameRegexp.MatchString(preparedDbName) {
+			return fmt.Errorf("invalid name of the prepared database %q", preparedDbName)
+		}
+		if _, exists := spec.Databases[preparedDbName]; exists {
+			return fmt.Errorf("database %q is defined both as a database and as a prepared database", preparedDbName)
+		}
+		prefixes := []string{preparedDbName}
+		for schemaName, schema := range preparedDB.PreparedSchemas {
+			if !databaseNameRegexp.MatchString(schemaName) {
+				return fmt.Errorf("invalid name of the schema %q in the prepared database %q", schemaName, preparedDbName)
+			}
+			if hasDefaultRoles(schema) {
+				prefixes = append(prefixes, preparedDbName+"_"+schemaName)
+			}
+		}
+		for _, prefix := range prefixes {
+			// the longest name of a default role is the one of the login user of the writer role
+			roleName := prefix + constants.WriterRoleNameSuffix + constants.UserRoleNameSuffix
+			if !isValidUsername(roleName) || len(roleName) > maxRoleNameLength {
+				return fmt.Errorf("invalid name of the default role %q of the prepared database %q", roleName, preparedDbName)
+			}
+		}
+	}
+	return nil
+}
+
+// validateExtensions checks the names, versions and schemas of the extensions. Whether an extension is
+// allowed is only checked during the sync, since the allowed extensions can change with the configuration.
+func validateExtensions(spec *acidv1.PostgresSpec) error {
+	for datname, extensions := range spec.Extensions {
+		if !databaseNameRegexp.MatchString(datname) {
+			return fmt.Errorf("invalid name of the database %q with extensions", datname)
+		}
+		for name, extension := range extensions {
+			if err := validateExtension(name, extension); err != nil {
+				return fmt.Errorf("%v in database %q", err, datname)
+			}
+		}
+	}
+	return nil
+}
+
+// validateBootstrapSQL checks that the bootstrap scripts refer to databases created by the operator and
+// to valid ConfigMap names and keys
+func validateBootstrapSQL(spec *acidv1.PostgresSpec) error {
+	for datname, scripts := range spec.BootstrapSQL {
+		_, isDatabase := spec.Databases[datname]
+		_, isPreparedDatabase := spec.PreparedDatabases[datname]
+		if !isDatabase && !isPreparedDatabase {
+			return fmt.Errorf("bootstrap SQL is defined for the database %q, which is not defined in the manifest", datname)
+		}
+		for _, script := range scripts {
+			if errs := validation.IsDNS1123Subdomain(script.ConfigMap); len(errs) > 0 {
+				return fmt.Errorf("invalid ConfigMap %q of the bootstrap SQL of database %q: %s",
+					script.ConfigMap, datname, strings.Join(errs, ", "))
+			}
+			if script.Key == "" {
+				continue
+			}
+			if errs := validation.IsConfigMapKey(script.Key); len(errs) > 0 {
+				return fmt.Errorf("invalid key %q of the bootstrap SQL of database %q: %s",
+					script.Key, datname, strings.Join(errs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// validateRestoreVerification checks the interval and the database of the restore verification. The checks
+// themselves can only be run against the restored clone.
+func validateRestoreVerification(spec *acidv1.PostgresSpec) error {
+	verification := spec.RestoreVerification
+	if verification == nil {
+		return nil
+	}
+	if verification.Interval != "" {
+		interval, err := time.ParseDuration(verification.Interval)
+		if err != nil {
+			return fmt.Errorf("could not parse restore verification interval: %v", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("restore verification interval has to be positive")
+		}
+	}
+	if verification.Database != "" && !databaseNameRegexp.MatchString(verification.Database) {
+		return fmt.Errorf("invalid database %q of the restore verification", verification.Database)
+	}
+	for _, check := range verification.Checks {
+		if _, err := restoreVerificationStatement(check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLogicalReplication checks the publications and subscriptions. The slot of a subscription is named
+// after the cluster and the subscription and has to be a valid identifier as well.
+func validateLogicalReplication(clusterName string, spec *acidv1.PostgresSpec) error {
+	for name, publication := range spec.Publications {
+		if !databaseNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid name of the publication %q", name)
+		}
+		if !databaseNameRegexp.MatchString(publication.Database) {
+			return fmt.Errorf("invalid database %q of the publication %q", publication.Database, name)
+		}
+		if publication.AllTables == (len(publication.Tables) > 0) {
+			return fmt.Errorf("publication %q has to define either tables or allTables", name)
+		}
+		for _, table := range publication.Tables {
+			if !tableNameRegexp.MatchString(table) {
+				return fmt.Errorf("invalid table %q of the publication %q", table, name)
+			}
+		}
+	}
+
+	for name, subscription := range spec.Subscriptions {
+		if !subscriptionNameRegexp.MatchString(name) {
+			return fmt.Errorf("invalid name of the subscription %q, only lower case letters, numbers and underscores are allowed", name)
+		}
+		slotName := strings.Replace(clusterName, "-", "_", -1) + "_" + name
+		if len(slotName) > maxRoleNameLength {
+			return fmt.Errorf("name of the replication slot %q of the subscription %q is too long", slotName, name)
+		}
+		if !databaseNameRegexp.MatchString(subscription.Database) {
+			return fmt.Errorf("invalid database %q of the subscription %q", subscription.Database, name)
+		}
+		if subscription.SourceDatabase != "" && !databaseNameRegexp.MatchString(subscription.SourceDatabase) {
+			return fmt.Errorf("invalid source database %q of the subscription %q", subscription.SourceDatabase, name)
+		}
+		if subscription.Cluster == "" || subscription.Cluster == clusterName {
+			return fmt.Errorf("subscription %q has to refer to another cluster", name)
+		}
+		if errs := validation.IsDNS1123Label(subscription.Cluster); len(errs) > 0 {
+			return fmt.Errorf("invalid cluster %q of the subscription %q: %s", subscription.Cluster, name, strings.Join(errs, ", "))
+		}
+		if len(subscription.Publications) == 0 {
+			return fmt.Errorf("subscription %q has no publications", name)
+		}
+		for _, publication := range subscription.Publications {
+			if !databaseNameRegexp.MatchString(publication) {
+				return fmt.Errorf("invalid publication %q of the subscription %q", publication, name)
+			}
+		}
+		if subscription.User != "" && !isValidUsername(subscription.User) {
+			return fmt.Errorf("invalid user %q of the subscription %q", subscription.User, name)
+		}
+	}
+	return nil
+}
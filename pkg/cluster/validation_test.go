@@ -0,0 +1,442 @@
+package cluster
+
+import (
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+)
+
+func TestValidateManifest(t *testing.T) {
+	testName := "TestValidateManifest"
+	opConfig := &config.Config{
+		Resources: config.Resources{
+			MinInstances: -1,
+			MaxInstances: 5,
+		},
+	}
+
+	newManifest := func(modify func(*acidv1.PostgresSpec)) *acidv1.Postgresql {
+		pg := &acidv1.Postgresql{
+			Spec: acidv1.PostgresSpec{
+				TeamID:            "acid",
+				NumberOfInstances: 2,
+				PostgresqlParam:   acidv1.PostgresqlParam{PgVersion: "11"},
+				Volume:            acidv1.Volume{Size: "10Gi"},
+				Users:             map[string]acidv1.UserFlags{"foo_user": {"createdb"}},
+			},
+		}
+		if modify != nil {
+			modify(&pg.Spec)
+		}
+		return pg
+	}
+
+	upgradedManifest := func(phase string) *acidv1.Postgresql {
+		pg := newManifest(func(spec *acidv1.PostgresSpec) {
+			spec.PgVersion = "12"
+		})
+		pg.Status.MajorVersionUpgrade = &acidv1.MajorVersionUpgradeStatus{FromVersion: "11", ToVersion: "12", Phase: phase}
+		return pg
+	}
+
+	tests := []struct {
+		subTest string
+		oldSpec *acidv1.Postgresql
+		newSpec *acidv1.Postgresql
+		err     bool
+	}{
+		{
+			subTest: "valid new manifest",
+			newSpec: newManifest(nil),
+		},
+		{
+			subTest: "manifest with decoding error",
+			newSpec: &acidv1.Postgresql{Error: "name must match {TEAM}-{NAME} format"},
+			err:     true,
+		},
+		{
+			subTest: "invalid memory limit",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Resources.ResourceLimits.Memory = "1 GB"
+			}),
+			err: true,
+		},
+		{
+			subTest: "invalid user flag",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Users["foo_user"] = acidv1.UserFlags{"superpower"}
+			}),
+			err: true,
+		},
+		{
+			subTest: "password rotation of a user",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PasswordRotation = map[string]acidv1.PasswordRotation{"foo_user": {Interval: "720h"}}
+			}),
+		},
+		{
+			subTest: "password rotation of an undefined user",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PasswordRotation = map[string]acidv1.PasswordRotation{"bar_user": {}}
+			}),
+			err: true,
+		},
+		{
+			subTest: "invalid password rotation interval",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PasswordRotation = map[string]acidv1.PasswordRotation{"foo_user": {Interval: "30 days"}}
+			}),
+			err: true,
+		},
+		{
+			subTest: "secret namespaces without cross namespace secrets",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.SecretNamespaces = map[string]string{"foo_user": "appspace"}
+			}),
+			err: true,
+		},
+		{
+			subTest: "prepared database with schemas",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PreparedDatabases = map[string]acidv1.PreparedDatabase{
+					"foo": {DefaultUsers: true, PreparedSchemas: map[string]acidv1.PreparedSchema{"bar": {}}},
+				}
+			}),
+		},
+		{
+			subTest: "prepared database that is also a database",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Databases = map[string]string{"foo": "foo_user"}
+				spec.PreparedDatabases = map[string]acidv1.PreparedDatabase{"foo": {}}
+			}),
+			err: true,
+		},
+		{
+			subTest: "prepared database with an invalid schema name",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PreparedDatabases = map[string]acidv1.PreparedDatabase{
+					"foo": {PreparedSchemas: map[string]acidv1.PreparedSchema{"bar-baz": {}}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "prepared database with an upper case name",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PreparedDatabases = map[string]acidv1.PreparedDatabase{"Foo": {}}
+			}),
+			err: true,
+		},
+		{
+			subTest: "extensions of a database",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Extensions = map[string]map[string]acidv1.Extension{
+					"foo": {"pg_partman": {Version: "4.4.0", Schema: "partman"}, "uuid-ossp": {}},
+				}
+			}),
+		},
+		{
+			subTest: "extension with an invalid version",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Extensions = map[string]map[string]acidv1.Extension{
+					"foo": {"pg_partman": {Version: "4.4'; DROP TABLE bar; --"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "extension with an invalid schema",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Extensions = map[string]map[string]acidv1.Extension{
+					"foo": {"pg_partman": {Schema: "part man"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "bootstrap SQL of a database",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Databases = map[string]string{"foo": "foo_user"}
+				spec.BootstrapSQL = map[string][]acidv1.BootstrapScript{
+					"foo": {{ConfigMap: "foo-schema"}, {ConfigMap: "foo-seed", Key: "01-users.sql"}},
+				}
+			}),
+		},
+		{
+			subTest: "bootstrap SQL of an undefined database",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.BootstrapSQL = map[string][]acidv1.BootstrapScript{
+					"foo": {{ConfigMap: "foo-schema"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "bootstrap SQL with an invalid key",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Databases = map[string]string{"foo": "foo_user"}
+				spec.BootstrapSQL = map[string][]acidv1.BootstrapScript{
+					"foo": {{ConfigMap: "foo-schema", Key: "../schema.sql"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "publication and subscription",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Publications = map[string]acidv1.Publication{
+					"orders": {Database: "foo", Tables: []string{"orders", "sales.order_items"}},
+				}
+				spec.Subscriptions = map[string]acidv1.Subscription{
+					"legacy_orders": {Database: "foo", Cluster: "acid-legacy", Publications: []string{"orders"}},
+				}
+			}),
+		},
+		{
+			subTest: "publication with tables and all tables",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Publications = map[string]acidv1.Publication{
+					"orders": {Database: "foo", Tables: []string{"orders"}, AllTables: true},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "subscription with an upper case name",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Subscriptions = map[string]acidv1.Subscription{
+					"LegacyOrders": {Database: "foo", Cluster: "acid-legacy", Publications: []string{"orders"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "subscription without a cluster",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Subscriptions = map[string]acidv1.Subscription{
+					"legacy_orders": {Database: "foo", Publications: []string{"orders"}},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "too many instances",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+			}),
+			err: true,
+		},
+		{
+			subTest: "instances above a lowered maximum kept",
+			oldSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+			}),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+				spec.Users = map[string]acidv1.UserFlags{"bar_user": {}}
+			}),
+		},
+		{
+			subTest: "instances above a lowered maximum increased",
+			oldSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+			}),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 7
+			}),
+			err: true,
+		},
+		{
+			subTest: "unchanged spec of a manifest that became invalid",
+			oldSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Bucket: "team-backups"}
+			}),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Bucket: "team-backups"}
+			}),
+		},
+		{
+			subTest: "standby clusters ignore instance bounds",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.NumberOfInstances = 6
+				spec.StandbyCluster = &acidv1.StandbyDescription{S3WalPath: "s3://bucket/path"}
+			}),
+		},
+		{
+			subTest: "logical backup retention",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				count, days := int32(7), int32(0)
+				spec.LogicalBackupRetention = &acidv1.LogicalBackupRetention{Count: &count, Days: &days}
+			}),
+		},
+		{
+			subTest: "negative logical backup retention",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				days := int32(-1)
+				spec.LogicalBackupRetention = &acidv1.LogicalBackupRetention{Days: &days}
+			}),
+			err: true,
+		},
+		{
+			subTest: "logical backup target",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Provider: "pvc", ClaimName: "logical-backups"}
+			}),
+		},
+		{
+			subTest: "unknown logical backup provider",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Provider: "ftp"}
+			}),
+			err: true,
+		},
+		{
+			subTest: "logical backup bucket with credentials",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Bucket: "team-backups", CredentialsSecret: "team-credentials"}
+			}),
+		},
+		{
+			subTest: "logical backup bucket without credentials",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.LogicalBackupTarget = &acidv1.LogicalBackupTarget{Bucket: "team-backups"}
+			}),
+			err: true,
+		},
+		{
+			subTest: "restore verification",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.RestoreVerification = &acidv1.RestoreVerification{
+					Interval: "24h",
+					Database: "foo",
+					Checks:   []string{"SELECT count(*) > 0 FROM orders;"},
+				}
+			}),
+		},
+		{
+			subTest: "invalid restore verification interval",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.RestoreVerification = &acidv1.RestoreVerification{Interval: "weekly"}
+			}),
+			err: true,
+		},
+		{
+			subTest: "empty restore verification check",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.RestoreVerification = &acidv1.RestoreVerification{Checks: []string{" "}}
+			}),
+			err: true,
+		},
+		{
+			subTest: "restore verification check with several statements",
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.RestoreVerification = &acidv1.RestoreVerification{
+					Checks: []string{"SELECT true; DROP TABLE orders"},
+				}
+			}),
+			err: true,
+		},
+		{
+			subTest: "volume resize and major version upgrade",
+			oldSpec: newManifest(nil),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Volume.Size = "20Gi"
+				spec.PgVersion = "12"
+			}),
+		},
+		{
+			subTest: "volume shrink",
+			oldSpec: newManifest(nil),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.Volume.Size = "5Gi"
+			}),
+			err: true,
+		},
+		{
+			subTest: "version downgrade",
+			oldSpec: newManifest(nil),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PgVersion = "10"
+			}),
+			err: true,
+		},
+		{
+			subTest: "version set back after a failed upgrade",
+			oldSpec: upgradedManifest(acidv1.UpgradePhaseFailed),
+			newSpec: newManifest(nil),
+		},
+		{
+			subTest: "version set back after a finished upgrade",
+			oldSpec: upgradedManifest(acidv1.UpgradePhaseFinished),
+			newSpec: newManifest(nil),
+			err:     true,
+		},
+		{
+			subTest: "version set below the running version after a failed upgrade",
+			oldSpec: upgradedManifest(acidv1.UpgradePhaseFailed),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.PgVersion = "10"
+			}),
+			err: true,
+		},
+		{
+			subTest: "team change",
+			oldSpec: newManifest(nil),
+			newSpec: newManifest(func(spec *acidv1.PostgresSpec) {
+				spec.TeamID = "foo"
+			}),
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		err := ValidateManifest(opConfig, tt.oldSpec, tt.newSpec)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: Expected error %t, have %v instead", testName, tt.subTest, tt.err, err)
+		}
+	}
+}
+
+func TestValidateSecretNamespaces(t *testing.T) {
+	testName := "TestValidateSecretNamespaces"
+	opConfig := &config.Config{Auth: config.Auth{EnableCrossNamespaceSecrets: true}}
+	tests := []struct {
+		subTest          string
+		users            map[string]acidv1.UserFlags
+		secretNamespaces map[string]string
+		err              bool
+	}{
+		{
+			subTest: "namespace in the username",
+			users:   map[string]acidv1.UserFlags{"appspace.app_user": {}},
+		},
+		{
+			subTest:          "namespace of a user",
+			users:            map[string]acidv1.UserFlags{"app_user": {}},
+			secretNamespaces: map[string]string{"app_user": "appspace"},
+		},
+		{
+			subTest:          "namespace of an undefined user",
+			users:            map[string]acidv1.UserFlags{"app_user": {}},
+			secretNamespaces: map[string]string{"foo_user": "appspace"},
+			err:              true,
+		},
+		{
+			subTest:          "invalid namespace",
+			users:            map[string]acidv1.UserFlags{"app_user": {}},
+			secretNamespaces: map[string]string{"app_user": "App_Space"},
+			err:              true,
+		},
+	}
+
+	for _, tt := range tests {
+		spec := &acidv1.PostgresSpec{Users: tt.users, SecretNamespaces: tt.secretNamespaces}
+		err := validateSecretNamespaces(opConfig, spec)
+		if (err != nil) != tt.err {
+			t.Errorf("%s %s: expected error %t, got %v", testName, tt.subTest, tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"sync"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+)
+
+func (c *Controller) runPostgresBackupInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.postgresBackupInformer.Run(stopCh)
+}
+
+func (c *Controller) postgresBackupAdd(obj interface{}) {
+	backup := c.postgresBackupCheck(obj)
+	if backup != nil {
+		c.startBackup(backup)
+	}
+}
+
+func (c *Controller) postgresBackupUpdate(prev, cur interface{}) {
+	// backups whose cluster was not known yet are picked up on the next resync
+	backup := c.postgresBackupCheck(cur)
+	if backup != nil {
+		c.startBackup(backup)
+	}
+}
+
+func (c *Controller) postgresBackupCheck(obj interface{}) *acidv1.PostgresBackup {
+	backup, ok := obj.(*acidv1.PostgresBackup)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresBackup spec")
+		return nil
+	}
+	if backup.Status.Finished() {
+		return nil
+	}
+	return backup
+}
+
+// startBackup takes the backup in the background. Only the operator instance that manages the cluster of
+// the backup takes it, and it takes every backup only once at a time.
+func (c *Controller) startBackup(backup *acidv1.PostgresBackup) {
+	if c.opConfig.EnableDryRun {
+		c.logger.Infof("dry run: backup %q is not taken", backup.Name)
+		return
+	}
+
+	clusterName := spec.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.Cluster}
+	c.clustersMu.RLock()
+	cl, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if !ok {
+		c.logger.Debugf("cluster %q of backup %q is not managed by this operator", clusterName, backup.Name)
+		return
+	}
+
+	if _, running := c.runningBackups.LoadOrStore(backup.UID, true); running {
+		return
+	}
+	go func() {
+		defer c.runningBackups.Delete(backup.UID)
+		if err := cl.Backup(backup.DeepCopy()); err != nil {
+			c.logger.Errorf("could not take backup %q of cluster %q: %v", backup.Name, clusterName, err)
+		}
+	}()
+}
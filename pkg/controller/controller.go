@@ -12,7 +12,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/record"
 
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
 	"github.com/zalando/postgres-operator/pkg/apiserver"
@@ -22,11 +25,16 @@ import (
 	"github.com/zalando/postgres-operator/pkg/util/config"
 	"github.com/zalando/postgres-operator/pkg/util/constants"
 	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 	"github.com/zalando/postgres-operator/pkg/util/ringlog"
+	"github.com/zalando/postgres-operator/pkg/webhook"
 
 	acidv1informer "github.com/zalando/postgres-operator/pkg/generated/informers/externalversions/acid.zalan.do/v1"
 )
 
+// eventComponentName is the source of the events emitted by the operator
+const eventComponentName = "postgres-operator"
+
 // Controller represents operator controller
 type Controller struct {
 	config   spec.ControllerConfig
@@ -35,6 +43,13 @@ type Controller struct {
 	logger     *logrus.Entry
 	KubeClient k8sutil.KubernetesClient
 	apiserver  *apiserver.Server
+	webhook    *webhook.Server
+
+	identity      string
+	leaderElector *leaderelection.LeaderElector
+
+	eventRecorder    record.EventRecorder
+	eventBroadcaster record.EventBroadcaster
 
 	stopCh chan struct{}
 
@@ -48,12 +63,17 @@ type Controller struct {
 	clusterHistory   map[spec.NamespacedName]ringlog.RingLogger // history of the cluster changes
 	teamClusters     map[string][]spec.NamespacedName
 
-	postgresqlInformer cache.SharedIndexInformer
-	podInformer        cache.SharedIndexInformer
-	nodesInformer      cache.SharedIndexInformer
-	podCh              chan cluster.PodEvent
-
-	clusterEventQueues    []*cache.FIFO // [workerID]Queue
+	postgresqlInformer      cache.SharedIndexInformer
+	postgresBackupInformer  cache.SharedIndexInformer
+	postgresRestoreInformer cache.SharedIndexInformer
+	podInformer             cache.SharedIndexInformer
+	nodesInformer           cache.SharedIndexInformer
+	podCh                   chan cluster.PodEvent
+	runningBackups          sync.Map
+	runningRestores         sync.Map
+	runningVerifications    sync.Map
+
+	clusterEventQueues    []*clusterEventQueue // [workerID]Queue
 	lastClusterSyncTime   int64
 	lastClusterRepairTime int64
 
@@ -61,12 +81,20 @@ type Controller struct {
 
 	PodServiceAccount            *v1.ServiceAccount
 	PodServiceAccountRoleBinding *rbacv1.RoleBinding
+	secretBackend                cluster.SecretBackend
 }
 
 // NewController creates a new controller
 func NewController(controllerConfig *spec.ControllerConfig, controllerId string) *Controller {
 	logger := logrus.New()
 
+	// events are recorded for the postgresql objects, so the scheme has to know about them
+	if err := acidv1.AddToScheme(scheme.Scheme); err != nil {
+		logger.Fatalf("could not register the postgresql types: %v", err)
+	}
+	eventBroadcaster := record.NewBroadcaster()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: eventComponentName})
+
 	c := &Controller{
 		config:           *controllerConfig,
 		opConfig:         &config.Config{},
@@ -80,6 +108,8 @@ func NewController(controllerConfig *spec.ControllerConfig, controllerId string)
 		teamClusters:     make(map[string][]spec.NamespacedName),
 		stopCh:           make(chan struct{}),
 		podCh:            make(chan cluster.PodEvent),
+		eventRecorder:    recorder,
+		eventBroadcaster: eventBroadcaster,
 	}
 	logger.Hooks.Add(c)
 
@@ -243,17 +273,24 @@ func (c *Controller) initRoleBinding() {
 
 func (c *Controller) initController() {
 	c.initClients()
+	c.eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: c.KubeClient.EventsGetter.Events("")})
 	c.controllerID = os.Getenv("CONTROLLER_ID")
 
 	if configObjectName := os.Getenv("POSTGRES_OPERATOR_CONFIGURATION_OBJECT"); configObjectName != "" {
-		if err := c.createConfigurationCRD(c.opConfig.EnableCRDValidation); err != nil {
+		// the dry run mode is part of the configuration, which is read before registering the CRD to not
+		// touch the CRD in the dry run mode. Without the CRD the configuration can only be read afterwards.
+		cfg, err := c.readOperatorConfigurationFromCRD(spec.GetOperatorNamespace(), configObjectName)
+		if err == nil && cfg.Configuration.EnableDryRun {
+			c.logger.Infof("dry run: the OperatorConfiguration CustomResourceDefinition is not registered")
+		} else if err := c.createConfigurationCRD(c.opConfig.EnableCRDValidation); err != nil {
 			c.logger.Fatalf("could not register Operator Configuration CustomResourceDefinition: %v", err)
 		}
-		if cfg, err := c.readOperatorConfigurationFromCRD(spec.GetOperatorNamespace(), configObjectName); err != nil {
-			c.logger.Fatalf("unable to read operator configuration: %v", err)
-		} else {
-			c.opConfig = c.importConfigurationFromCRD(&cfg.Configuration)
+		if err != nil {
+			if cfg, err = c.readOperatorConfigurationFromCRD(spec.GetOperatorNamespace(), configObjectName); err != nil {
+				c.logger.Fatalf("unable to read operator configuration: %v", err)
+			}
 		}
+		c.opConfig = c.importConfigurationFromCRD(&cfg.Configuration)
 	} else {
 		c.initOperatorConfig()
 	}
@@ -262,8 +299,14 @@ func (c *Controller) initController() {
 
 	c.modifyConfigFromEnvironment()
 
-	if err := c.createPostgresCRD(c.opConfig.EnableCRDValidation); err != nil {
+	if c.opConfig.EnableDryRun {
+		c.logger.Infof("dry run: the Postgres CustomResourceDefinitions are not registered")
+	} else if err := c.createPostgresCRD(c.opConfig.EnableCRDValidation); err != nil {
 		c.logger.Fatalf("could not register Postgres CustomResourceDefinition: %v", err)
+	} else if err := c.createPostgresBackupCRD(c.opConfig.EnableCRDValidation); err != nil {
+		c.logger.Fatalf("could not register PostgresBackup CustomResourceDefinition: %v", err)
+	} else if err := c.createPostgresRestoreCRD(c.opConfig.EnableCRDValidation); err != nil {
+		c.logger.Fatalf("could not register PostgresRestore CustomResourceDefinition: %v", err)
 	}
 
 	c.initPodServiceAccount()
@@ -275,26 +318,40 @@ func (c *Controller) initController() {
 
 	c.logger.Infof("config: %s", c.opConfig.MustMarshal())
 
+	secretBackend, err := cluster.NewSecretBackend(c.opConfig, c.KubeClient)
+	if err != nil {
+		c.logger.Fatalf("could not init secret backend: %v", err)
+	}
+	c.secretBackend = secretBackend
+
 	if infraRoles, err := c.getInfrastructureRoles(&c.opConfig.InfrastructureRolesSecretName); err != nil {
 		c.logger.Warningf("could not get infrastructure roles: %v", err)
 	} else {
 		c.config.InfrastructureRoles = infraRoles
 	}
 
-	c.clusterEventQueues = make([]*cache.FIFO, c.opConfig.Workers)
+	c.clusterEventQueues = make([]*clusterEventQueue, c.opConfig.Workers)
+	// the worker logs are read by the REST API, which also runs before the workers start
 	c.workerLogs = make(map[uint32]ringlog.RingLogger, c.opConfig.Workers)
 	for i := range c.clusterEventQueues {
-		c.clusterEventQueues[i] = cache.NewFIFO(func(obj interface{}) (string, error) {
-			e, ok := obj.(ClusterEvent)
-			if !ok {
-				return "", fmt.Errorf("could not cast to ClusterEvent")
-			}
+		c.clusterEventQueues[i] = newClusterEventQueue(fmt.Sprintf("worker-%d", i),
+			constants.ClusterEventRetryBaseDelay, constants.ClusterEventRetryMaxDelay)
+		c.workerLogs[uint32(i)] = ringlog.New(c.opConfig.RingLogLines)
+	}
 
-			return queueClusterKey(e.EventType, e.UID), nil
-		})
+	if err := metrics.Registry.Register(&controllerCollector{controller: c}); err != nil {
+		c.logger.Warningf("could not register controller metrics: %v", err)
 	}
 
 	c.apiserver = apiserver.New(c, c.opConfig.APIPort, c.logger.Logger)
+	if c.opConfig.EnableDefaultingWebhook {
+		if err := cluster.ValidateDefaultedFields(c.opConfig.DefaultedFields); err != nil {
+			c.logger.Warningf("defaulting webhook ignores unknown fields: %v", err)
+		}
+	}
+	if c.opConfig.EnableAdmissionWebhook || c.opConfig.EnableDefaultingWebhook {
+		c.webhook = webhook.New(c, c.opConfig.WebhookPort, c.opConfig.WebhookTLSCertFile, c.opConfig.WebhookTLSKeyFile, c.logger.Logger)
+	}
 }
 
 func (c *Controller) initSharedInformers() {
@@ -311,6 +368,30 @@ func (c *Controller) initSharedInformers() {
 		DeleteFunc: c.postgresqlDelete,
 	})
 
+	// on-demand backups, the jobs they create are garbage collected with them
+	c.postgresBackupInformer = acidv1informer.NewPostgresBackupInformer(
+		c.KubeClient.AcidV1ClientSet,
+		c.opConfig.WatchedNamespace,
+		constants.QueueResyncPeriodTPR,
+		cache.Indexers{})
+
+	c.postgresBackupInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.postgresBackupAdd,
+		UpdateFunc: c.postgresBackupUpdate,
+	})
+
+	// restores into new clusters, which outlive the restore
+	c.postgresRestoreInformer = acidv1informer.NewPostgresRestoreInformer(
+		c.KubeClient.AcidV1ClientSet,
+		c.opConfig.WatchedNamespace,
+		constants.QueueResyncPeriodTPR,
+		cache.Indexers{})
+
+	c.postgresRestoreInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.postgresRestoreAdd,
+		UpdateFunc: c.postgresRestoreUpdate,
+	})
+
 	// Pods
 	podLw := &cache.ListWatch{
 		ListFunc:  c.podListFunc,
@@ -351,11 +432,37 @@ func (c *Controller) initSharedInformers() {
 // Run starts background controller processes
 func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	c.initController()
+	if c.config.EnableLeaderElection && c.opConfig.EnableDryRun {
+		c.logger.Infof("leader election is disabled in the dry run mode")
+	} else if c.config.EnableLeaderElection {
+		if err := c.initLeaderElection(stopCh, wg); err != nil {
+			c.logger.Fatalf("could not initialize leader election: %v", err)
+		}
+	}
 
+	// the REST API and the admission webhook are served by all operator instances, also by the standby ones
+	wg.Add(1)
+	go c.apiserver.Run(stopCh, wg)
+
+	if c.webhook != nil {
+		wg.Add(1)
+		go c.webhook.Run(stopCh, wg)
+	}
+
+	if c.leaderElector == nil {
+		c.runController(stopCh, wg)
+		return
+	}
+
+	wg.Add(1)
+	go c.runLeaderElection(stopCh, wg)
+}
+
+// runController starts the workers and informers that manage the Postgres clusters
+func (c *Controller) runController(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	// start workers reading from the events queue to prevent the initial sync from blocking on it.
 	for i := range c.clusterEventQueues {
 		wg.Add(1)
-		c.workerLogs[uint32(i)] = ringlog.New(c.opConfig.RingLogLines)
 		go c.processClusterEventsQueue(i, stopCh, wg)
 	}
 
@@ -364,11 +471,13 @@ func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 		panic("could not acquire initial list of clusters")
 	}
 
-	wg.Add(5)
+	wg.Add(7)
 	go c.runPodInformer(stopCh, wg)
 	go c.runPostgresqlInformer(stopCh, wg)
+	go c.runPostgresBackupInformer(stopCh, wg)
+	go c.runPostgresRestoreInformer(stopCh, wg)
 	go c.clusterResync(stopCh, wg)
-	go c.apiserver.Run(stopCh, wg)
+	go c.restoreVerificationScheduler(stopCh, wg)
 	go c.kubeNodesInformer(stopCh, wg)
 
 	c.logger.Info("started working in background")
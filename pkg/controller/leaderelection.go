@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/zalando/postgres-operator/pkg/spec"
+)
+
+const leaseName = "postgres-operator"
+
+// initLeaderElection prepares the election of the operator instance that manages the Postgres clusters.
+// All instances compete for a Lease object in the operator namespace; operators with different
+// controller IDs use separate leases since they manage different clusters. The controller starts
+// working once this instance becomes the leader. Losing the leadership terminates the operator,
+// since the running workers cannot be stopped safely while another instance takes over.
+func (c *Controller) initLeaderElection(stopCh <-chan struct{}, wg *sync.WaitGroup) error {
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("could not determine the identity of the operator instance: %v", err)
+	}
+	c.identity = identity
+
+	name := leaseName
+	if c.controllerID != "" {
+		name = fmt.Sprintf("%s-%s", leaseName, c.controllerID)
+	}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: spec.GetOperatorNamespace(),
+		},
+		Client: c.KubeClient.LeasesGetter,
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+
+	c.leaderElector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   c.config.LeaderElectionLeaseDuration,
+		RenewDeadline:   c.config.LeaderElectionRenewDeadline,
+		RetryPeriod:     c.config.LeaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				c.logger.Infof("operator instance %q became the leader", identity)
+				c.runController(stopCh, wg)
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					c.logger.Infof("operator instance %q released the leadership", identity)
+				default:
+					c.logger.Fatalf("operator instance %q lost the leadership", identity)
+				}
+			},
+			OnNewLeader: func(leader string) {
+				c.logger.Infof("operator instance %q is the leader", leader)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("could not create leader elector: %v", err)
+	}
+
+	return nil
+}
+
+// runLeaderElection blocks until the operator is stopped or the leadership is lost.
+func (c *Controller) runLeaderElection(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	c.logger.Infof("operator instance %q is waiting for leadership", c.identity)
+	c.leaderElector.Run(ctx)
+}
+
+// IsLeader tells whether this operator instance manages the Postgres clusters
+func (c *Controller) IsLeader() bool {
+	if c.leaderElector == nil {
+		return true
+	}
+	return c.leaderElector.IsLeader()
+}
+
+// GetLeader returns the identity of the operator instance that manages the Postgres clusters
+func (c *Controller) GetLeader() string {
+	if c.leaderElector == nil {
+		return c.identity
+	}
+	return c.leaderElector.GetLeader()
+}
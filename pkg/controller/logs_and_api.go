@@ -35,6 +35,24 @@ func (c *Controller) ClusterStatus(team, namespace, cluster string) (*cluster.Cl
 	return status, nil
 }
 
+// ClusterLogicalBackups provides the catalog of the logical backups of the cluster
+func (c *Controller) ClusterLogicalBackups(team, namespace, cluster string) (*cluster.LogicalBackupCatalog, error) {
+
+	clusterName := spec.NamespacedName{
+		Namespace: namespace,
+		Name:      team + "-" + cluster,
+	}
+
+	c.clustersMu.RLock()
+	cl, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("could not find cluster")
+	}
+
+	return cl.LogicalBackups()
+}
+
 // ClusterDatabasesMap returns for each cluster the list of databases running there
 func (c *Controller) ClusterDatabasesMap() map[string][]string {
 
@@ -58,9 +76,16 @@ func (c *Controller) ClusterDatabasesMap() map[string][]string {
 	return m
 }
 
-// TeamClusterList returns team-clusters map
+// TeamClusterList returns a copy of the team-clusters map
 func (c *Controller) TeamClusterList() map[string][]spec.NamespacedName {
-	return c.teamClusters
+	c.clustersMu.RLock()
+	defer c.clustersMu.RUnlock()
+
+	teamClusters := make(map[string][]spec.NamespacedName, len(c.teamClusters))
+	for team, clusters := range c.teamClusters {
+		teamClusters[team] = append([]spec.NamespacedName(nil), clusters...)
+	}
+	return teamClusters
 }
 
 // GetConfig returns controller config
@@ -81,13 +106,16 @@ func (c *Controller) GetStatus() *spec.ControllerStatus {
 
 	queueSizes := make(map[int]int, c.opConfig.Workers)
 	for workerID, queue := range c.clusterEventQueues {
-		queueSizes[workerID] = len(queue.ListKeys())
+		queueSizes[workerID] = queue.len()
 	}
 
 	return &spec.ControllerStatus{
 		LastSyncTime:    atomic.LoadInt64(&c.lastClusterSyncTime),
 		Clusters:        clustersCnt,
 		WorkerQueueSize: queueSizes,
+		Identity:        c.identity,
+		Leader:          c.GetLeader(),
+		IsLeader:        c.IsLeader(),
 	}
 }
 
@@ -173,7 +201,7 @@ func (c *Controller) Fire(e *logrus.Entry) error {
 	if logEntry.Worker == nil {
 		return nil
 	}
-	c.workerLogs[*logEntry.Worker].Insert(logEntry) // workerLogs map is immutable. No need to lock it
+	c.workerLogs[*logEntry.Worker].Insert(logEntry) // workerLogs map is immutable after initController. No need to lock it
 
 	return nil
 }
@@ -184,11 +212,7 @@ func (c *Controller) ListQueue(workerID uint32) (*spec.QueueDump, error) {
 		return nil, fmt.Errorf("could not find worker")
 	}
 
-	q := c.clusterEventQueues[workerID]
-	return &spec.QueueDump{
-		Keys: q.ListKeys(),
-		List: q.List(),
-	}, nil
+	return c.clusterEventQueues[workerID].dump(), nil
 }
 
 // GetWorkersCnt returns number of the workers
@@ -207,13 +231,39 @@ func (c *Controller) WorkerStatus(workerID uint32) (*cluster.WorkerStatus, error
 	if !ok {
 		return nil, fmt.Errorf("could not cast to Cluster struct")
 	}
+	clusterName := util.NameFromMeta(cl.ObjectMeta)
 
 	return &cluster.WorkerStatus{
-		CurrentCluster: types.NamespacedName(util.NameFromMeta(cl.ObjectMeta)),
+		CurrentCluster: types.NamespacedName(clusterName),
 		CurrentProcess: cl.GetCurrentProcess(),
+		Retries:        c.clusterEventQueues[workerID].retries(clusterName),
 	}, nil
 }
 
+// ClusterPlan returns the changes a sync of the cluster with its current manifest would apply
+func (c *Controller) ClusterPlan(team, namespace, name string) (*cluster.ClusterPlan, error) {
+
+	clusterName := spec.NamespacedName{
+		Namespace: namespace,
+		Name:      team + "-" + name,
+	}
+
+	c.clustersMu.RLock()
+	cl, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("could not find cluster")
+	}
+
+	manifest, err := c.currentManifest(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	c.mergeDeprecatedPostgreSQLSpecParameters(&manifest.Spec)
+
+	return cl.Plan(manifest)
+}
+
 // ClusterHistory dumps history of cluster changes
 func (c *Controller) ClusterHistory(team, namespace, name string) ([]*spec.Diff, error) {
 
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	workerQueueDepthDesc = prometheus.NewDesc(
+		"postgres_operator_worker_queue_depth",
+		"Number of cluster events waiting in the queue of a worker.",
+		[]string{"worker"}, nil)
+
+	clustersDesc = prometheus.NewDesc(
+		"postgres_operator_clusters",
+		"Number of Postgres clusters managed by the operator per status.",
+		[]string{"status"}, nil)
+)
+
+// controllerCollector reports the state of the controller at the time of the scrape
+type controllerCollector struct {
+	controller *Controller
+}
+
+// Describe implements prometheus.Collector
+func (cc *controllerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- workerQueueDepthDesc
+	ch <- clustersDesc
+}
+
+// Collect implements prometheus.Collector
+func (cc *controllerCollector) Collect(ch chan<- prometheus.Metric) {
+	c := cc.controller
+
+	for i := uint32(0); i < c.GetWorkersCnt(); i++ {
+		q, err := c.ListQueue(i)
+		if err != nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(workerQueueDepthDesc, prometheus.GaugeValue,
+			float64(len(q.Keys)), strconv.FormatUint(uint64(i), 10))
+	}
+
+	statuses := make(map[string]int)
+	c.clustersMu.RLock()
+	for _, cl := range c.clusters {
+		statuses[cl.GetPostgresClusterStatus()]++
+	}
+	c.clustersMu.RUnlock()
+
+	for status, count := range statuses {
+		ch <- prometheus.MustNewConstMetric(clustersDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
@@ -125,6 +125,11 @@ func (c *Controller) attemptToMoveMasterPodsOffNode(node *v1.Node) error {
 			continue
 		}
 
+		if cl.IsPaused() {
+			c.logger.Warningf("could not move pod %q: reconciliation of the cluster is paused", podName)
+			continue
+		}
+
 		if !clusters[cl] {
 			clusters[cl] = true
 		}
@@ -173,6 +178,11 @@ func (c *Controller) nodeDelete(obj interface{}) {
 }
 
 func (c *Controller) moveMasterPodsOffNode(node *v1.Node) {
+	if c.opConfig.EnableDryRun {
+		c.logger.Infof("dry run: master pods would be moved off the node %q", node.Name)
+		return
+	}
+
 	// retry to move master until configured timeout is reached
 	err := retryutil.Retry(1*time.Minute, c.opConfig.MasterPodMoveTimeout,
 		func() (bool, error) {
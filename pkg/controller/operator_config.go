@@ -7,6 +7,7 @@ import (
 	"time"
 
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/cluster"
 	"github.com/zalando/postgres-operator/pkg/util"
 	"github.com/zalando/postgres-operator/pkg/util/config"
 	"github.com/zalando/postgres-operator/pkg/util/constants"
@@ -41,6 +42,9 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *acidv1.OperatorConfigur
 	result.MaxInstances = fromCRD.MaxInstances
 	result.ResyncPeriod = time.Duration(fromCRD.ResyncPeriod)
 	result.RepairPeriod = time.Duration(fromCRD.RepairPeriod)
+	result.EnablePausedClusterDeletion = fromCRD.EnablePausedClusterDeletion
+	result.EnableDryRun = fromCRD.EnableDryRun
+	result.AllowedExtensions = fromCRD.AllowedExtensions
 	result.SetMemoryRequestToLimit = fromCRD.SetMemoryRequestToLimit
 	result.ShmVolume = fromCRD.ShmVolume
 	result.Sidecars = fromCRD.Sidecars
@@ -48,6 +52,17 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *acidv1.OperatorConfigur
 	// user config
 	result.SuperUsername = fromCRD.PostgresUsersConfiguration.SuperUsername
 	result.ReplicationUsername = fromCRD.PostgresUsersConfiguration.ReplicationUsername
+	result.EnableRoleDeprecation = fromCRD.PostgresUsersConfiguration.EnableRoleDeprecation
+	result.RoleDeletionSuffix = util.Coalesce(fromCRD.PostgresUsersConfiguration.RoleDeletionSuffix, "_deleted")
+	result.EnableRoleDrop = fromCRD.PostgresUsersConfiguration.EnableRoleDrop
+	result.RoleDropGracePeriod = time.Duration(fromCRD.PostgresUsersConfiguration.RoleDropGracePeriod)
+	result.EnablePasswordRotation = fromCRD.PostgresUsersConfiguration.EnablePasswordRotation
+	result.PasswordRotationInterval = time.Duration(fromCRD.PostgresUsersConfiguration.PasswordRotationInterval)
+	result.PasswordRotationOverlapPeriod = time.Duration(fromCRD.PostgresUsersConfiguration.PasswordRotationOverlapPeriod)
+	result.EnableSystemPasswordRotation = fromCRD.PostgresUsersConfiguration.EnableSystemPasswordRotation
+	result.PasswordEncryption = util.Coalesce(fromCRD.PostgresUsersConfiguration.PasswordEncryption, "md5")
+	result.EnablePasswordEncryptionMigration = fromCRD.PostgresUsersConfiguration.EnablePasswordEncryptionMigration
+	result.EnableCrossNamespaceSecrets = fromCRD.PostgresUsersConfiguration.EnableCrossNamespaceSecrets
 
 	// kubernetes config
 	result.CustomPodAnnotations = fromCRD.Kubernetes.CustomPodAnnotations
@@ -113,12 +128,21 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *acidv1.OperatorConfigur
 	// logical backup config
 	result.LogicalBackupSchedule = fromCRD.LogicalBackup.Schedule
 	result.LogicalBackupDockerImage = fromCRD.LogicalBackup.DockerImage
+	result.LogicalBackupProvider = util.Coalesce(fromCRD.LogicalBackup.Provider, "s3")
 	result.LogicalBackupS3Bucket = fromCRD.LogicalBackup.S3Bucket
 	result.LogicalBackupS3Region = fromCRD.LogicalBackup.S3Region
 	result.LogicalBackupS3Endpoint = fromCRD.LogicalBackup.S3Endpoint
 	result.LogicalBackupS3AccessKeyID = fromCRD.LogicalBackup.S3AccessKeyID
 	result.LogicalBackupS3SecretAccessKey = fromCRD.LogicalBackup.S3SecretAccessKey
 	result.LogicalBackupS3SSE = fromCRD.LogicalBackup.S3SSE
+	result.LogicalBackupGCSBucket = fromCRD.LogicalBackup.GCSBucket
+	result.LogicalBackupGCSCredentialsSecret = fromCRD.LogicalBackup.GCSCredentialsSecret
+	result.LogicalBackupAzureStorageAccount = fromCRD.LogicalBackup.AzureStorageAccount
+	result.LogicalBackupAzureStorageContainer = fromCRD.LogicalBackup.AzureStorageContainer
+	result.LogicalBackupAzureCredentialsSecret = fromCRD.LogicalBackup.AzureCredentialsSecret
+	result.LogicalBackupPVCClaimName = fromCRD.LogicalBackup.PVCClaimName
+	result.LogicalBackupRetentionCount = fromCRD.LogicalBackup.RetentionCount
+	result.LogicalBackupRetentionDays = fromCRD.LogicalBackup.RetentionDays
 
 	// debug config
 	result.DebugLogging = fromCRD.OperatorDebug.DebugLogging
@@ -141,6 +165,22 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *acidv1.OperatorConfigur
 	result.RingLogLines = fromCRD.LoggingRESTAPI.RingLogLines
 	result.ClusterHistoryEntries = fromCRD.LoggingRESTAPI.ClusterHistoryEntries
 
+	// admission webhook config
+	result.EnableAdmissionWebhook = fromCRD.AdmissionWebhook.EnableAdmissionWebhook
+	result.WebhookPort = fromCRD.AdmissionWebhook.WebhookPort
+	result.WebhookTLSCertFile = fromCRD.AdmissionWebhook.WebhookTLSCertFile
+	result.WebhookTLSKeyFile = fromCRD.AdmissionWebhook.WebhookTLSKeyFile
+	result.EnableDefaultingWebhook = fromCRD.AdmissionWebhook.EnableDefaultingWebhook
+	if len(fromCRD.AdmissionWebhook.DefaultedFields) > 0 {
+		result.DefaultedFields = fromCRD.AdmissionWebhook.DefaultedFields
+	} else {
+		result.DefaultedFields = []string{
+			cluster.DefaultedFieldResources,
+			cluster.DefaultedFieldDockerImage,
+			cluster.DefaultedFieldConnectionPool,
+		}
+	}
+
 	// Scalyr config
 	result.ScalyrAPIKey = fromCRD.Scalyr.ScalyrAPIKey
 	result.ScalyrImage = fromCRD.Scalyr.ScalyrImage
@@ -196,5 +236,17 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *acidv1.OperatorConfigur
 		fromCRD.ConnectionPool.MaxDBConnections,
 		int32ToPointer(constants.ConnPoolMaxDBConnections))
 
+	// secret backend config
+	result.SecretBackendType = util.Coalesce(fromCRD.SecretBackend.Type, constants.SecretBackendKubernetes)
+	result.VaultAddress = fromCRD.SecretBackend.VaultAddress
+	result.VaultKVMount = util.Coalesce(fromCRD.SecretBackend.VaultKVMount, "secret")
+	result.VaultPathPrefix = util.Coalesce(fromCRD.SecretBackend.VaultPathPrefix, "postgres-operator")
+	result.VaultTokenSecretName = fromCRD.SecretBackend.VaultTokenSecretName
+
+	// restore verification config
+	result.EnableRestoreVerification = fromCRD.RestoreVerification.EnableRestoreVerification
+	result.RestoreVerificationInterval = time.Duration(fromCRD.RestoreVerification.Interval)
+	result.RestoreVerificationTimeout = time.Duration(fromCRD.RestoreVerification.Timeout)
+
 	return result
 }
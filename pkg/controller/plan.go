@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/zalando/postgres-operator/pkg/cluster"
+)
+
+// planEvent replaces processEvent in the dry run mode. Instead of acting on the event it logs the changes
+// the operator would apply to the cluster, neither the cluster objects nor the manifest are modified.
+func (c *Controller) planEvent(lg *logrus.Entry, event ClusterEvent, cl *cluster.Cluster, clusterFound bool) error {
+	clusterName := event.clusterName()
+
+	switch event.EventType {
+	case EventRepair:
+		// the periodic syncs report the plan already
+		return nil
+	case EventDelete:
+		if !clusterFound {
+			return nil
+		}
+		if cluster.IsPausedManifest(event.OldSpec) && !c.opConfig.EnablePausedClusterDeletion {
+			lg.Infoln("dry run: reconciliation of the cluster is paused, its K8s objects would be left in place")
+		} else {
+			lg.Infoln("dry run: the K8s objects of the cluster would be deleted")
+		}
+		c.removeCluster(clusterName, strings.ToLower(cl.Spec.TeamID))
+		return nil
+	}
+
+	if !clusterFound {
+		cl = c.addCluster(lg, clusterName, event.NewSpec)
+	}
+	if cluster.IsPausedManifest(event.NewSpec) {
+		lg.Infof("dry run: reconciliation of the cluster is paused, the %q event would be skipped", event.EventType)
+		return nil
+	}
+	c.mergeDeprecatedPostgreSQLSpecParameters(&event.NewSpec.Spec)
+
+	plan, err := cl.Plan(event.NewSpec)
+	if err != nil {
+		lg.Errorf("dry run: could not plan the changes to the cluster: %v", err)
+		return err
+	}
+	logClusterPlan(lg, plan)
+
+	return nil
+}
+
+func logClusterPlan(lg *logrus.Entry, plan *cluster.ClusterPlan) {
+	if plan.Empty() {
+		lg.Infoln("dry run: the cluster is in the desired state")
+		return
+	}
+	for _, change := range plan.Changes {
+		msg := "dry run: would %s %s %q"
+		if change.RollingUpdate {
+			msg += " and perform a rolling update of the pods"
+		}
+		lg.Infof(msg, change.Action, change.Kind, change.Name)
+		for _, reason := range change.Reasons {
+			lg.Infof("reason: %s", reason)
+		}
+		if change.Diff != "" {
+			lg.Debugf("diff\n%s\n", change.Diff)
+		}
+	}
+	for _, planErr := range plan.Errors {
+		lg.Warningf("dry run: %s", planErr)
+	}
+}
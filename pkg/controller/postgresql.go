@@ -13,13 +13,13 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/tools/cache"
 
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
 	"github.com/zalando/postgres-operator/pkg/cluster"
 	"github.com/zalando/postgres-operator/pkg/spec"
 	"github.com/zalando/postgres-operator/pkg/util"
 	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 	"github.com/zalando/postgres-operator/pkg/util/ringlog"
 )
 
@@ -101,7 +101,9 @@ func (c *Controller) queueEvents(list *acidv1.PostgresqlList, event EventType) {
 		activeClustersCnt++
 		// check if that cluster needs repair
 		if event == EventRepair {
-			if pg.Status.Success() {
+			// clusters with postponed disruptive actions are repaired within their maintenance windows
+			if pg.Status.Success() && !(pg.Status.MaintenancePending() &&
+				acidv1.IsInMaintenanceWindow(pg.Spec.MaintenanceWindows, time.Now())) {
 				continue
 			} else {
 				clustersToRepair++
@@ -157,7 +159,7 @@ func (c *Controller) acquireInitialListOfClusters() error {
 }
 
 func (c *Controller) addCluster(lg *logrus.Entry, clusterName spec.NamespacedName, pgSpec *acidv1.Postgresql) *cluster.Cluster {
-	cl := cluster.New(c.makeClusterConfig(), c.KubeClient, *pgSpec, lg)
+	cl := cluster.New(c.makeClusterConfig(), c.KubeClient, *pgSpec, lg, c.eventRecorder)
 	cl.Run(c.stopCh)
 	teamName := strings.ToLower(cl.Spec.TeamID)
 
@@ -172,19 +174,50 @@ func (c *Controller) addCluster(lg *logrus.Entry, clusterName spec.NamespacedNam
 	return cl
 }
 
-func (c *Controller) processEvent(event ClusterEvent) {
+// removeCluster stops tracking a deleted cluster
+func (c *Controller) removeCluster(clusterName spec.NamespacedName, teamName string) {
+	defer metrics.ForgetCluster(clusterName.Namespace, clusterName.Name)
+	defer c.clustersMu.Unlock()
+	c.clustersMu.Lock()
+
+	delete(c.clusters, clusterName)
+	delete(c.clusterLogs, clusterName)
+	delete(c.clusterHistory, clusterName)
+	for i, val := range c.teamClusters[teamName] {
+		if val == clusterName {
+			copy(c.teamClusters[teamName][i:], c.teamClusters[teamName][i+1:])
+			c.teamClusters[teamName][len(c.teamClusters[teamName])-1] = spec.NamespacedName{}
+			c.teamClusters[teamName] = c.teamClusters[teamName][:len(c.teamClusters[teamName])-1]
+			break
+		}
+	}
+}
+
+// processEvent acts on a single cluster event. The returned error indicates that the event should be retried.
+func (c *Controller) processEvent(event ClusterEvent) error {
 	var clusterName spec.NamespacedName
 	var clHistory ringlog.RingLogger
 
 	lg := c.logger.WithField("worker", event.WorkerID)
 
-	if event.EventType == EventAdd || event.EventType == EventSync || event.EventType == EventRepair {
+	if event.EventType == EventAdd || event.EventType == EventSync || event.EventType == EventRepair || event.EventType == EventRetry {
 		clusterName = util.NameFromMeta(event.NewSpec.ObjectMeta)
 	} else {
 		clusterName = util.NameFromMeta(event.OldSpec.ObjectMeta)
 	}
 	lg = lg.WithField("cluster-name", clusterName)
 
+	if event.EventType == EventRetry {
+		// act on the current manifest, since newer events may have been processed after the failure
+		newSpec, err := c.currentManifest(clusterName)
+		if err != nil {
+			lg.Infof("skipping retry of the cluster: %v", err)
+			return nil
+		}
+		event.NewSpec = newSpec
+		event.EventType = EventSync
+	}
+
 	c.clustersMu.RLock()
 	cl, clusterFound := c.clusters[clusterName]
 	if clusterFound {
@@ -194,11 +227,35 @@ func (c *Controller) processEvent(event ClusterEvent) {
 
 	defer c.curWorkerCluster.Store(event.WorkerID, nil)
 
+	if c.opConfig.EnableDryRun {
+		return c.planEvent(lg, event, cl, clusterFound)
+	}
+
+	if event.EventType != EventDelete {
+		if cluster.IsPausedManifest(event.NewSpec) {
+			// keep track of the cluster, so that the paused state is reported
+			if !clusterFound {
+				cl = c.addCluster(lg, clusterName, event.NewSpec)
+			}
+			cl.SetPaused(true)
+			lg.Infof("reconciliation of the cluster is paused, skipping %q event", event.EventType)
+			return nil
+		}
+		if clusterFound && cl.IsPaused() {
+			cl.SetPaused(false)
+			lg.Infoln("reconciliation of the cluster has been resumed")
+			// the old spec of an update only contains the last change made while paused, sync the whole manifest
+			if event.EventType == EventUpdate {
+				event.EventType = EventSync
+			}
+		}
+	}
+
 	if event.EventType == EventRepair {
 		runRepair, lastOperationStatus := cl.NeedsRepair()
 		if !runRepair {
 			lg.Debugf("Observed cluster status %s, repair is not required", lastOperationStatus)
-			return
+			return nil
 		}
 		lg.Debugf("Observed cluster status %s, running sync scan to repair the cluster", lastOperationStatus)
 		event.EventType = EventSync
@@ -224,7 +281,7 @@ func (c *Controller) processEvent(event ClusterEvent) {
 	case EventAdd:
 		if clusterFound {
 			lg.Debugf("cluster already exists")
-			return
+			return nil
 		}
 
 		lg.Infof("creation of the cluster started")
@@ -233,11 +290,14 @@ func (c *Controller) processEvent(event ClusterEvent) {
 
 		c.curWorkerCluster.Store(event.WorkerID, cl)
 
-		if err := cl.Create(); err != nil {
+		start := time.Now()
+		err := cl.Create()
+		metrics.ObserveClusterOperation(metrics.OperationCreate, clusterName.Namespace, clusterName.Name, time.Since(start), err)
+		if err != nil {
 			cl.Error = fmt.Sprintf("could not create cluster: %v", err)
 			lg.Error(cl.Error)
 
-			return
+			return err
 		}
 
 		lg.Infoln("cluster has been created")
@@ -246,14 +306,17 @@ func (c *Controller) processEvent(event ClusterEvent) {
 
 		if !clusterFound {
 			lg.Warningln("cluster does not exist")
-			return
+			return nil
 		}
 		c.curWorkerCluster.Store(event.WorkerID, cl)
-		if err := cl.Update(event.OldSpec, event.NewSpec); err != nil {
+		start := time.Now()
+		err := cl.Update(event.OldSpec, event.NewSpec)
+		metrics.ObserveClusterOperation(metrics.OperationUpdate, clusterName.Namespace, clusterName.Name, time.Since(start), err)
+		if err != nil {
 			cl.Error = fmt.Sprintf("could not update cluster: %v", err)
 			lg.Error(cl.Error)
 
-			return
+			return err
 		}
 		cl.Error = ""
 		lg.Infoln("cluster has been updated")
@@ -266,31 +329,19 @@ func (c *Controller) processEvent(event ClusterEvent) {
 	case EventDelete:
 		if !clusterFound {
 			lg.Errorf("unknown cluster: %q", clusterName)
-			return
+			return nil
 		}
-		lg.Infoln("deletion of the cluster started")
-
 		teamName := strings.ToLower(cl.Spec.TeamID)
 
-		c.curWorkerCluster.Store(event.WorkerID, cl)
-		cl.Delete()
-
-		func() {
-			defer c.clustersMu.Unlock()
-			c.clustersMu.Lock()
-
-			delete(c.clusters, clusterName)
-			delete(c.clusterLogs, clusterName)
-			delete(c.clusterHistory, clusterName)
-			for i, val := range c.teamClusters[teamName] {
-				if val == clusterName {
-					copy(c.teamClusters[teamName][i:], c.teamClusters[teamName][i+1:])
-					c.teamClusters[teamName][len(c.teamClusters[teamName])-1] = spec.NamespacedName{}
-					c.teamClusters[teamName] = c.teamClusters[teamName][:len(c.teamClusters[teamName])-1]
-					break
-				}
-			}
-		}()
+		if cluster.IsPausedManifest(event.OldSpec) && !c.opConfig.EnablePausedClusterDeletion {
+			lg.Warningln("reconciliation of the cluster is paused, its K8s objects are left in place")
+		} else {
+			lg.Infoln("deletion of the cluster started")
+			c.curWorkerCluster.Store(event.WorkerID, cl)
+			cl.Delete()
+		}
+
+		c.removeCluster(clusterName, teamName)
 
 		lg.Infof("cluster has been deleted")
 	case EventSync:
@@ -302,40 +353,84 @@ func (c *Controller) processEvent(event ClusterEvent) {
 		}
 
 		c.curWorkerCluster.Store(event.WorkerID, cl)
-		if err := cl.Sync(event.NewSpec); err != nil {
+		start := time.Now()
+		err := cl.Sync(event.NewSpec)
+		metrics.ObserveClusterOperation(metrics.OperationSync, clusterName.Namespace, clusterName.Name, time.Since(start), err)
+		if err != nil {
 			cl.Error = fmt.Sprintf("could not sync cluster: %v", err)
 			lg.Error(cl.Error)
-			return
+			return err
 		}
 		cl.Error = ""
 
 		lg.Infof("cluster has been synced")
 	}
+
+	return nil
+}
+
+// currentManifest returns a copy of the latest manifest of the cluster known to the informer
+func (c *Controller) currentManifest(clusterName spec.NamespacedName) (*acidv1.Postgresql, error) {
+	obj, exists, err := c.postgresqlInformer.GetStore().GetByKey(clusterName.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not get the manifest: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("the manifest does not exist anymore")
+	}
+	pg, ok := obj.(*acidv1.Postgresql)
+	if !ok {
+		return nil, fmt.Errorf("could not cast to postgresql spec")
+	}
+	if pg.Error != "" {
+		return nil, fmt.Errorf("the manifest is invalid: %s", pg.Error)
+	}
+
+	return pg.Clone(), nil
+}
+
+// retryClusterEvent schedules a sync of the cluster after a failed event, backing off exponentially
+// with the number of consecutive failures. Successful events reset the backoff.
+func (c *Controller) retryClusterEvent(queue *clusterEventQueue, event ClusterEvent, err error) {
+	clusterName := event.clusterName()
+	if err == nil || event.EventType == EventDelete {
+		queue.forget(clusterName)
+		return
+	}
+
+	retry := ClusterEvent{
+		EventTime: time.Now(),
+		EventType: EventRetry,
+		UID:       event.UID,
+		NewSpec:   event.NewSpec,
+		WorkerID:  event.WorkerID,
+	}
+	if retry.NewSpec == nil {
+		retry.NewSpec = event.OldSpec
+	}
+	delay := queue.addRateLimited(retry, clusterName)
+	c.logger.WithField("worker", event.WorkerID).WithField("cluster-name", clusterName).
+		Infof("%q event failed, retrying in %v (retry %d)", event.EventType, delay, queue.retries(clusterName))
 }
 
 func (c *Controller) processClusterEventsQueue(idx int, stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	queue := c.clusterEventQueues[idx]
 	go func() {
 		<-stopCh
-		c.clusterEventQueues[idx].Close()
+		queue.shutDown()
 	}()
 
 	for {
-		obj, err := c.clusterEventQueues[idx].Pop(cache.PopProcessFunc(func(interface{}) error { return nil }))
-		if err != nil {
-			if err == cache.ErrFIFOClosed {
-				return
-			}
-			c.logger.Errorf("error when processing cluster events queue: %v", err)
-			continue
-		}
-		event, ok := obj.(ClusterEvent)
+		key, event, ok := queue.get()
 		if !ok {
-			c.logger.Errorf("could not cast to ClusterEvent")
+			return
 		}
 
-		c.processEvent(event)
+		err := c.processEvent(event)
+		c.retryClusterEvent(queue, event, err)
+		queue.done(key)
 	}
 }
 
@@ -345,10 +440,6 @@ func (c *Controller) warnOnDeprecatedPostgreSQLSpecParameters(spec *acidv1.Postg
 		c.logger.Warningf("Parameter %q is deprecated. Consider setting %q instead", deprecated, replacement)
 	}
 
-	noeffect := func(param string, explanation string) {
-		c.logger.Warningf("Parameter %q takes no effect. %s", param, explanation)
-	}
-
 	if spec.UseLoadBalancer != nil {
 		deprecate("useLoadBalancer", "enableMasterLoadBalancer")
 	}
@@ -356,10 +447,6 @@ func (c *Controller) warnOnDeprecatedPostgreSQLSpecParameters(spec *acidv1.Postg
 		deprecate("replicaLoadBalancer", "enableReplicaLoadBalancer")
 	}
 
-	if len(spec.MaintenanceWindows) > 0 {
-		noeffect("maintenanceWindows", "Not implemented.")
-	}
-
 	if (spec.UseLoadBalancer != nil || spec.ReplicaLoadBalancer != nil) &&
 		(spec.EnableReplicaLoadBalancer != nil || spec.EnableMasterLoadBalancer != nil) {
 		c.logger.Warnf("Both old and new load balancer parameters are present in the manifest, ignoring old ones")
@@ -437,30 +524,15 @@ func (c *Controller) queueClusterEvent(informerOldSpec, informerNewSpec *acidv1.
 	}
 
 	lg := c.logger.WithField("worker", workerID).WithField("cluster-name", clusterName)
-	if err := c.clusterEventQueues[workerID].Add(clusterEvent); err != nil {
-		lg.Errorf("error while queueing cluster event: %v", clusterEvent)
-	}
+	c.clusterEventQueues[workerID].add(clusterEvent)
 	lg.Infof("%q event has been queued", eventType)
 
 	if eventType != EventDelete {
 		return
 	}
 	// A delete event discards all prior requests for that cluster.
-	for _, evType := range []EventType{EventAdd, EventSync, EventUpdate, EventRepair} {
-		obj, exists, err := c.clusterEventQueues[workerID].GetByKey(queueClusterKey(evType, uid))
-		if err != nil {
-			lg.Warningf("could not get event from the queue: %v", err)
-			continue
-		}
-
-		if !exists {
-			continue
-		}
-
-		err = c.clusterEventQueues[workerID].Delete(obj)
-		if err != nil {
-			lg.Warningf("could not delete event from the queue: %v", err)
-		} else {
+	for _, evType := range []EventType{EventAdd, EventSync, EventUpdate, EventRepair, EventRetry} {
+		if c.clusterEventQueues[workerID].discard(queueClusterKey(evType, uid)) {
 			lg.Debugf("event %q has been discarded for the cluster", evType)
 		}
 	}
@@ -471,6 +543,7 @@ func (c *Controller) postgresqlAdd(obj interface{}) {
 	if pg != nil {
 		// We will not get multiple Add events for the same cluster
 		c.queueClusterEvent(nil, pg, EventAdd)
+		c.queueSubscriptionSourceSyncs(pg)
 	}
 
 	return
@@ -482,9 +555,16 @@ func (c *Controller) postgresqlUpdate(prev, cur interface{}) {
 	if pgOld != nil && pgNew != nil {
 		// Avoid the inifinite recursion for status updates
 		if reflect.DeepEqual(pgOld.Spec, pgNew.Spec) {
+			// pausing or resuming the reconciliation only changes an annotation
+			if cluster.IsPausedManifest(pgOld) != cluster.IsPausedManifest(pgNew) {
+				c.queueClusterEvent(nil, pgNew, EventSync)
+			}
 			return
 		}
 		c.queueClusterEvent(pgOld, pgNew, EventUpdate)
+		if !reflect.DeepEqual(pgOld.Spec.Subscriptions, pgNew.Spec.Subscriptions) {
+			c.queueSubscriptionSourceSyncs(pgOld, pgNew)
+		}
 	}
 
 	return
@@ -494,11 +574,33 @@ func (c *Controller) postgresqlDelete(obj interface{}) {
 	pg := c.postgresqlCheck(obj)
 	if pg != nil {
 		c.queueClusterEvent(pg, nil, EventDelete)
+		c.queueSubscriptionSourceSyncs(pg)
 	}
 
 	return
 }
 
+// queueSubscriptionSourceSyncs queues a sync of the source clusters of the subscriptions of the given
+// manifests, which reserve the replication slots of the subscriptions and drop them once they are unused
+func (c *Controller) queueSubscriptionSourceSyncs(manifests ...*acidv1.Postgresql) {
+	sources := make(map[spec.NamespacedName]bool)
+	for _, pg := range manifests {
+		for _, subscription := range pg.Spec.Subscriptions {
+			sources[spec.NamespacedName{Namespace: pg.Namespace, Name: subscription.Cluster}] = true
+		}
+	}
+	for source := range sources {
+		pg, err := c.currentManifest(source)
+		if err != nil {
+			c.logger.Debugf("could not queue a sync of the source cluster %q of subscriptions: %v", source, err)
+			continue
+		}
+		if c.hasOwnership(pg) {
+			c.queueClusterEvent(nil, pg, EventSync)
+		}
+	}
+}
+
 func (c *Controller) postgresqlCheck(obj interface{}) *acidv1.Postgresql {
 	pg, ok := obj.(*acidv1.Postgresql)
 	if !ok {
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/zalando/postgres-operator/pkg/spec"
+)
+
+// clusterEventQueue is the work queue of a single worker. The underlying work queue only holds the keys
+// of the events, the events themselves are kept aside. An event replaces a pending event of the same type
+// for the same cluster, so that e.g. repeated sync events are only processed once and always with the
+// latest manifest. Failed events are retried with an exponential backoff per cluster.
+type clusterEventQueue struct {
+	queue       workqueue.DelayingInterface
+	rateLimiter workqueue.RateLimiter
+
+	mu     sync.Mutex
+	events map[string]ClusterEvent
+}
+
+func newClusterEventQueue(name string, baseDelay, maxDelay time.Duration) *clusterEventQueue {
+	return &clusterEventQueue{
+		queue:       workqueue.NewNamedDelayingQueue(name),
+		rateLimiter: workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		events:      make(map[string]ClusterEvent),
+	}
+}
+
+// add queues the event, replacing a pending event with the same key
+func (q *clusterEventQueue) add(event ClusterEvent) {
+	key := queueClusterKey(event.EventType, event.UID)
+
+	q.mu.Lock()
+	q.events[key] = event
+	q.mu.Unlock()
+
+	q.queue.Add(key)
+}
+
+// addRateLimited queues the event once the backoff of the cluster has passed and returns the delay.
+// A pending event with the same key takes precedence, since it has been created later.
+func (q *clusterEventQueue) addRateLimited(event ClusterEvent, clusterName spec.NamespacedName) time.Duration {
+	key := queueClusterKey(event.EventType, event.UID)
+
+	q.mu.Lock()
+	if _, ok := q.events[key]; !ok {
+		q.events[key] = event
+	}
+	q.mu.Unlock()
+
+	delay := q.rateLimiter.When(clusterName)
+	q.queue.AddAfter(key, delay)
+
+	return delay
+}
+
+// get blocks until an event is available. It returns false once the queue is shut down.
+// The key of the event has to be passed to done after processing.
+func (q *clusterEventQueue) get() (string, ClusterEvent, bool) {
+	for {
+		item, shutdown := q.queue.Get()
+		if shutdown {
+			return "", ClusterEvent{}, false
+		}
+		key := item.(string)
+
+		q.mu.Lock()
+		event, ok := q.events[key]
+		delete(q.events, key)
+		q.mu.Unlock()
+
+		if ok {
+			return key, event, true
+		}
+		// the event has been discarded or processed already
+		q.queue.Done(key)
+	}
+}
+
+// done marks the event as processed, events queued in the meantime become available again
+func (q *clusterEventQueue) done(key string) {
+	q.queue.Done(key)
+}
+
+// discard drops a pending event, it returns false if there was none
+func (q *clusterEventQueue) discard(key string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.events[key]; !ok {
+		return false
+	}
+	delete(q.events, key)
+
+	return true
+}
+
+// forget resets the backoff of the cluster
+func (q *clusterEventQueue) forget(clusterName spec.NamespacedName) {
+	q.rateLimiter.Forget(clusterName)
+}
+
+// retries returns the number of failed attempts to process events of the cluster since the last success
+func (q *clusterEventQueue) retries(clusterName spec.NamespacedName) int {
+	return q.rateLimiter.NumRequeues(clusterName)
+}
+
+// dump returns the keys and the pending events ordered by the key, and the retries of their clusters
+func (q *clusterEventQueue) dump() *spec.QueueDump {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	keys := make([]string, 0, len(q.events))
+	for key := range q.events {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	list := make([]interface{}, 0, len(keys))
+	retries := make(map[string]int)
+	for _, key := range keys {
+		event := q.events[key]
+		list = append(list, event)
+		clusterName := event.clusterName()
+		if n := q.retries(clusterName); n > 0 {
+			retries[clusterName.String()] = n
+		}
+	}
+
+	return &spec.QueueDump{
+		Keys:    keys,
+		List:    list,
+		Retries: retries,
+	}
+}
+
+// len returns the number of pending events
+func (q *clusterEventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.events)
+}
+
+func (q *clusterEventQueue) shutDown() {
+	q.queue.ShutDown()
+}
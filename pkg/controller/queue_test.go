@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newQueueTestEvent(eventType EventType, generation int64) ClusterEvent {
+	return ClusterEvent{
+		EventType: eventType,
+		UID:       types.UID("test-uid"),
+		NewSpec: &acidv1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test", Namespace: "default", Generation: generation},
+		},
+	}
+}
+
+func TestClusterEventQueueDeduplication(t *testing.T) {
+	testName := "TestClusterEventQueueDeduplication"
+	queue := newClusterEventQueue("test", time.Millisecond, time.Second)
+	defer queue.shutDown()
+
+	queue.add(newQueueTestEvent(EventAdd, 1))
+	queue.add(newQueueTestEvent(EventSync, 1))
+	queue.add(newQueueTestEvent(EventSync, 2))
+
+	if n := queue.len(); n != 2 {
+		t.Errorf("%s: expected 2 pending events, got %d", testName, n)
+	}
+
+	if !queue.discard(queueClusterKey(EventAdd, "test-uid")) {
+		t.Errorf("%s: expected the add event to be discarded", testName)
+	}
+	if queue.discard(queueClusterKey(EventUpdate, "test-uid")) {
+		t.Errorf("%s: expected no update event to be discarded", testName)
+	}
+
+	key, event, ok := queue.get()
+	if !ok {
+		t.Fatalf("%s: expected an event from the queue", testName)
+	}
+	queue.done(key)
+	if event.EventType != EventSync || event.NewSpec.Generation != 2 {
+		t.Errorf("%s: expected the latest sync event, got %q of generation %d",
+			testName, event.EventType, event.NewSpec.Generation)
+	}
+	if n := queue.len(); n != 0 {
+		t.Errorf("%s: expected an empty queue, got %d pending events", testName, n)
+	}
+}
+
+func TestClusterEventQueueRetries(t *testing.T) {
+	testName := "TestClusterEventQueueRetries"
+	queue := newClusterEventQueue("test", time.Millisecond, time.Second)
+	defer queue.shutDown()
+	clusterName := spec.NamespacedName{Namespace: "default", Name: "acid-test"}
+
+	first := queue.addRateLimited(newQueueTestEvent(EventRetry, 1), clusterName)
+	second := queue.addRateLimited(newQueueTestEvent(EventRetry, 2), clusterName)
+	if second <= first {
+		t.Errorf("%s: expected the delay to grow, got %v after %v", testName, second, first)
+	}
+
+	dump := queue.dump()
+	if len(dump.Keys) != 1 {
+		t.Errorf("%s: expected retries to be merged into one event, got keys %v", testName, dump.Keys)
+	}
+	if retries := dump.Retries[clusterName.String()]; retries != 2 {
+		t.Errorf("%s: expected 2 retries in the queue dump, got %d", testName, retries)
+	}
+
+	key, event, ok := queue.get()
+	if !ok {
+		t.Fatalf("%s: expected an event from the queue", testName)
+	}
+	queue.done(key)
+	if event.NewSpec.Generation != 1 {
+		t.Errorf("%s: expected the first pending retry to be kept, got generation %d", testName, event.NewSpec.Generation)
+	}
+
+	queue.forget(clusterName)
+	if retries := queue.retries(clusterName); retries != 0 {
+		t.Errorf("%s: expected retries to be reset, got %d", testName, retries)
+	}
+}
@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/cluster"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+	"github.com/zalando/postgres-operator/pkg/util/retryutil"
+)
+
+// restoreTimeLayout is the layout of the timestamp of a clone, which requires an explicit offset
+const restoreTimeLayout = "2006-01-02T15:04:05-07:00"
+
+func (c *Controller) runPostgresRestoreInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.postgresRestoreInformer.Run(stopCh)
+}
+
+func (c *Controller) postgresRestoreAdd(obj interface{}) {
+	restore := c.postgresRestoreCheck(obj)
+	if restore != nil {
+		c.startRestore(restore)
+	}
+}
+
+func (c *Controller) postgresRestoreUpdate(prev, cur interface{}) {
+	// restores whose source cluster was not known yet are picked up on the next resync
+	restore := c.postgresRestoreCheck(cur)
+	if restore != nil {
+		c.startRestore(restore)
+	}
+}
+
+func (c *Controller) postgresRestoreCheck(obj interface{}) *acidv1.PostgresRestore {
+	restore, ok := obj.(*acidv1.PostgresRestore)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresRestore spec")
+		return nil
+	}
+	if restore.Status.Finished() {
+		return nil
+	}
+	return restore
+}
+
+// startRestore runs the restore in the background. Only the operator instance that manages the source cluster
+// of the restore runs it, and it runs every restore only once at a time.
+func (c *Controller) startRestore(restore *acidv1.PostgresRestore) {
+	if c.opConfig.EnableDryRun {
+		c.logger.Infof("dry run: restore %q is not run", restore.Name)
+		return
+	}
+
+	sourceName, err := c.restoreSourceCluster(restore)
+	if err != nil {
+		c.logger.Warningf("could not get source cluster of restore %q: %v", restore.Name, err)
+		return
+	}
+	clusterName := spec.NamespacedName{Namespace: restore.Namespace, Name: sourceName}
+	c.clustersMu.RLock()
+	cl, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if !ok {
+		c.logger.Debugf("source cluster %q of restore %q is not managed by this operator", clusterName, restore.Name)
+		return
+	}
+
+	if _, running := c.runningRestores.LoadOrStore(restore.UID, true); running {
+		return
+	}
+	go func() {
+		defer c.runningRestores.Delete(restore.UID)
+		if err := c.restore(restore.DeepCopy(), cl); err != nil {
+			c.logger.Errorf("could not restore cluster %q into %q: %v", clusterName, restore.Spec.Cluster, err)
+		}
+	}()
+}
+
+// restoreSourceCluster returns the name of the cluster a restore recovers, which is either given directly
+// or is the cluster of the referenced backup
+func (c *Controller) restoreSourceCluster(restore *acidv1.PostgresRestore) (string, error) {
+	if restore.Spec.SourceCluster != "" {
+		return restore.Spec.SourceCluster, nil
+	}
+	if restore.Spec.Backup == "" {
+		return "", fmt.Errorf("neither a source cluster nor a backup is specified")
+	}
+	backup, err := c.KubeClient.AcidV1ClientSet.AcidV1().PostgresBackups(restore.Namespace).Get(
+		context.TODO(), restore.Spec.Backup, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("could not get backup %q: %v", restore.Spec.Backup, err)
+	}
+	return backup.Spec.Cluster, nil
+}
+
+// restore recovers the source cluster into a new cluster and reports its progress in the status of the
+// PostgresRestore. It returns once the new cluster runs or the restore failed.
+func (c *Controller) restore(restore *acidv1.PostgresRestore, source *cluster.Cluster) error {
+	c.logger.Infof("restoring cluster %q into %q", source.Name, restore.Spec.Cluster)
+	status := restore.Status.DeepCopy()
+
+	err := c.runRestore(restore, source, status)
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	if err != nil {
+		status.Phase = acidv1.RestorePhaseFailed
+		status.Message = err.Error()
+		c.eventRecorder.Eventf(restore, v1.EventTypeWarning, "Restore", "Restore failed: %v", err)
+	} else {
+		status.Phase = acidv1.RestorePhaseSucceeded
+		status.Message = ""
+		c.eventRecorder.Eventf(restore, v1.EventTypeNormal, "Restore",
+			"Cluster %q recovered to %s at LSN %s", restore.Spec.Cluster, status.TargetTime, status.ReachedLSN)
+	}
+
+	if err2 := c.updatePostgresRestoreStatus(restore, status); err2 != nil {
+		return err2
+	}
+	return err
+}
+
+func (c *Controller) runRestore(restore *acidv1.PostgresRestore, source *cluster.Cluster, status *acidv1.PostgresRestoreStatus) error {
+	sourcePg, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(restore.Namespace).Get(
+		context.TODO(), source.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get source cluster %q: %v", source.Name, err)
+	}
+
+	if status.Phase != acidv1.RestorePhaseRunning {
+		targetTime, err := c.restoreTargetTime(restore, source)
+		if err != nil {
+			return err
+		}
+		now := metav1.Now()
+		status.Phase = acidv1.RestorePhaseRunning
+		status.StartTime = &now
+		status.TargetTime = targetTime
+		if err := c.updatePostgresRestoreStatus(restore, status); err != nil {
+			return err
+		}
+	}
+
+	pg := restoreManifest(restore, sourcePg, status.TargetTime)
+	if err := c.createRestoredCluster(pg); err != nil {
+		return err
+	}
+
+	cl, err := c.waitForRestoredCluster(pg, constants.PostgresRestoreTimeout)
+	if err != nil {
+		return err
+	}
+	if status.ReachedLSN, err = cl.RecoveryEndLSN(); err != nil {
+		return fmt.Errorf("could not get the LSN cluster %q recovered to: %v", pg.Name, err)
+	}
+	return nil
+}
+
+// waitForRestoredCluster waits until a restored cluster has finished the recovery and returns the cluster
+func (c *Controller) waitForRestoredCluster(pg *acidv1.Postgresql, timeout time.Duration) (*cluster.Cluster, error) {
+	c.logger.Infof("waiting for cluster %q to recover to %s", pg.Name, pg.Spec.Clone.EndTimestamp)
+	err := retryutil.Retry(constants.PostgresRestoreCheckInterval, timeout,
+		func() (bool, error) {
+			current, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(pg.Namespace).Get(
+				context.TODO(), pg.Name, metav1.GetOptions{})
+			if err != nil {
+				if k8sutil.ResourceNotFound(err) {
+					return false, fmt.Errorf("cluster %q was deleted", pg.Name)
+				}
+				c.logger.Warningf("could not get cluster %q: %v", pg.Name, err)
+				return false, nil
+			}
+			if current.Status.PostgresClusterStatus == acidv1.ClusterStatusInvalid {
+				return false, fmt.Errorf("manifest of cluster %q is invalid: %s", pg.Name, current.Error)
+			}
+			// creating a cluster fails when the recovery takes longer than the pods may take to become
+			// ready, the next sync picks the cluster up again once the recovery has finished
+			return current.Status.Running(), nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("cluster %q did not recover: %v", pg.Name, err)
+	}
+
+	clusterName := spec.NamespacedName{Namespace: pg.Namespace, Name: pg.Name}
+	c.clustersMu.RLock()
+	cl, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("cluster %q is not managed by this operator", clusterName)
+	}
+	return cl, nil
+}
+
+// restoreTargetTime validates that a base backup to recover from exists and returns the point in time to
+// recover to. Without a target time the restore recovers to the end of the referenced backup or to the
+// latest state of the source cluster.
+func (c *Controller) restoreTargetTime(restore *acidv1.PostgresRestore, source *cluster.Cluster) (string, error) {
+	var (
+		targetTime time.Time
+		err        error
+	)
+	if restore.Spec.TargetTime != "" {
+		if targetTime, err = time.Parse(time.RFC3339, restore.Spec.TargetTime); err != nil {
+			return "", fmt.Errorf("could not parse target time %q: %v", restore.Spec.TargetTime, err)
+		}
+	}
+
+	if restore.Spec.Backup != "" {
+		backup, err := c.KubeClient.AcidV1ClientSet.AcidV1().PostgresBackups(restore.Namespace).Get(
+			context.TODO(), restore.Spec.Backup, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("could not get backup %q: %v", restore.Spec.Backup, err)
+		}
+		completionTime, err := validateRestoreBackup(backup, source.Name, targetTime)
+		if err != nil {
+			return "", err
+		}
+		if targetTime.IsZero() {
+			targetTime = completionTime
+		}
+		return targetTime.UTC().Format(restoreTimeLayout), nil
+	}
+
+	if targetTime.IsZero() {
+		targetTime = time.Now()
+	}
+	backups, err := source.ListBasebackups()
+	if err != nil {
+		return "", err
+	}
+	for _, modified := range backups {
+		if !modified.After(targetTime) {
+			return targetTime.UTC().Format(restoreTimeLayout), nil
+		}
+	}
+	return "", fmt.Errorf("no base backup of cluster %q was taken before %s", source.Name, targetTime.UTC().Format(restoreTimeLayout))
+}
+
+// validateRestoreBackup checks that a backup is a finished base backup of the source cluster taken before the
+// target time, and returns the time the backup finished
+func validateRestoreBackup(backup *acidv1.PostgresBackup, sourceCluster string, targetTime time.Time) (time.Time, error) {
+	if backup.Spec.Cluster != sourceCluster {
+		return time.Time{}, fmt.Errorf("backup %q is a backup of cluster %q, not of %q", backup.Name, backup.Spec.Cluster, sourceCluster)
+	}
+	if backup.Spec.Method != acidv1.BackupMethodBasebackup {
+		return time.Time{}, fmt.Errorf("backup %q is a %s backup, only base backups can be recovered to a point in time",
+			backup.Name, backup.Spec.Method)
+	}
+	if backup.Status.Phase != acidv1.BackupPhaseSucceeded || backup.Status.CompletionTime == nil {
+		return time.Time{}, fmt.Errorf("backup %q has not succeeded", backup.Name)
+	}
+	completionTime := backup.Status.CompletionTime.Time
+	if !targetTime.IsZero() && targetTime.Before(completionTime) {
+		return time.Time{}, fmt.Errorf("target time %s is before backup %q finished at %s",
+			targetTime.UTC().Format(restoreTimeLayout), backup.Name, completionTime.UTC().Format(restoreTimeLayout))
+	}
+	return completionTime, nil
+}
+
+// restoreManifest generates the postgresql manifest of the restored cluster from the manifest of the source
+// cluster
+func restoreManifest(restore *acidv1.PostgresRestore, source *acidv1.Postgresql, targetTime string) *acidv1.Postgresql {
+	return cloneManifest(restore.Spec.Cluster, source, targetTime)
+}
+
+// cloneManifest generates the manifest of a cluster that clones the source cluster from its WAL archive up to
+// the target time. The clone does not take over the standby and subscription settings of the source, which
+// would make it compete with the source.
+func cloneManifest(name string, source *acidv1.Postgresql, targetTime string) *acidv1.Postgresql {
+	pg := &acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: source.Namespace,
+			Labels:    make(map[string]string),
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+	for key, value := range source.Labels {
+		pg.Labels[key] = value
+	}
+	if controllerID, ok := source.Annotations[constants.PostgresqlControllerAnnotationKey]; ok {
+		pg.Annotations = map[string]string{constants.PostgresqlControllerAnnotationKey: controllerID}
+	}
+
+	pg.Spec.Clone = acidv1.CloneDescription{
+		ClusterName:  source.Name,
+		UID:          string(source.UID),
+		EndTimestamp: targetTime,
+	}
+	pg.Spec.StandbyCluster = nil
+	pg.Spec.Subscriptions = nil
+	return pg
+}
+
+// createRestoredCluster creates the postgresql object of the restored cluster. An existing object is only
+// accepted when it is the clone created by an earlier attempt of the same restore.
+func (c *Controller) createRestoredCluster(pg *acidv1.Postgresql) error {
+	_, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(pg.Namespace).Create(context.TODO(), pg, metav1.CreateOptions{})
+	if err == nil {
+		c.logger.Infof("created cluster %q cloning %q", pg.Name, pg.Spec.Clone.ClusterName)
+		return nil
+	}
+	if !k8sutil.ResourceAlreadyExists(err) {
+		return fmt.Errorf("could not create cluster %q: %v", pg.Name, err)
+	}
+
+	existing, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(pg.Namespace).Get(context.TODO(), pg.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get cluster %q: %v", pg.Name, err)
+	}
+	if existing.Spec.Clone.UID != pg.Spec.Clone.UID || existing.Spec.Clone.EndTimestamp != pg.Spec.Clone.EndTimestamp {
+		return fmt.Errorf("cluster %q already exists", pg.Name)
+	}
+	return nil
+}
+
+func (c *Controller) updatePostgresRestoreStatus(restore *acidv1.PostgresRestore, status *acidv1.PostgresRestoreStatus) error {
+	statusData, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not marshal status: %v", err)
+	}
+	patchStatus := make(map[string]interface{})
+	if err := json.Unmarshal(statusData, &patchStatus); err != nil {
+		return fmt.Errorf("could not unmarshal status: %v", err)
+	}
+	// a merge patch only removes a message of an earlier phase when it is explicitly set to null
+	if status.Message == "" {
+		patchStatus["message"] = nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{"status": patchStatus})
+	if err != nil {
+		return fmt.Errorf("could not marshal status: %v", err)
+	}
+
+	if _, err := c.KubeClient.AcidV1ClientSet.AcidV1().PostgresRestores(restore.Namespace).Patch(
+		context.TODO(), restore.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("could not update status of restore %q: %v", restore.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRestoreManifest(t *testing.T) {
+	restore := &acidv1.PostgresRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore-before-migration", Namespace: "default"},
+		Spec:       acidv1.PostgresRestoreSpec{Cluster: "acid-restored-cluster", SourceCluster: "acid-test-cluster"},
+	}
+	source := &acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "acid-test-cluster",
+			Namespace:   "default",
+			UID:         types.UID("efd12e58-5786-11e8-b5a7-06148230260c"),
+			Labels:      map[string]string{"environment": "test"},
+			Annotations: map[string]string{"acid.zalan.do/controller": "postgresql-test", "owner": "team"},
+		},
+		Spec: acidv1.PostgresSpec{
+			TeamID:            "acid",
+			NumberOfInstances: 2,
+			Clone:             acidv1.CloneDescription{ClusterName: "acid-origin", UID: "1234"},
+			StandbyCluster:    &acidv1.StandbyDescription{S3WalPath: "s3://standby"},
+			Subscriptions:     map[string]acidv1.Subscription{"orders": {}},
+		},
+	}
+
+	pg := restoreManifest(restore, source, "2020-06-01T10:00:00+00:00")
+
+	if pg.Name != "acid-restored-cluster" || pg.Namespace != "default" {
+		t.Errorf("unexpected restored cluster %s/%s", pg.Namespace, pg.Name)
+	}
+	expectedClone := acidv1.CloneDescription{
+		ClusterName:  "acid-test-cluster",
+		UID:          "efd12e58-5786-11e8-b5a7-06148230260c",
+		EndTimestamp: "2020-06-01T10:00:00+00:00",
+	}
+	if pg.Spec.Clone != expectedClone {
+		t.Errorf("expected clone description %#v, got %#v", expectedClone, pg.Spec.Clone)
+	}
+	if pg.Spec.NumberOfInstances != 2 || pg.Spec.TeamID != "acid" {
+		t.Errorf("expected the spec of the source cluster to be copied, got %#v", pg.Spec)
+	}
+	if pg.Spec.StandbyCluster != nil || pg.Spec.Subscriptions != nil {
+		t.Errorf("expected the restored cluster to be neither a standby nor a subscriber")
+	}
+	if pg.Labels["environment"] != "test" || pg.Annotations["acid.zalan.do/controller"] != "postgresql-test" {
+		t.Errorf("expected labels and controller annotation of the source cluster, got %v and %v", pg.Labels, pg.Annotations)
+	}
+	if _, ok := pg.Annotations["owner"]; ok {
+		t.Errorf("expected other annotations of the source cluster not to be copied")
+	}
+	if source.Spec.StandbyCluster == nil || source.Spec.Clone.ClusterName != "acid-origin" {
+		t.Errorf("expected the source cluster to stay unchanged")
+	}
+}
+
+func TestValidateRestoreBackup(t *testing.T) {
+	completionTime := metav1.NewTime(time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC))
+	backup := func(cluster, method, phase string) *acidv1.PostgresBackup {
+		return &acidv1.PostgresBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "pre-migration"},
+			Spec:       acidv1.PostgresBackupSpec{Cluster: cluster, Method: method},
+			Status:     acidv1.PostgresBackupStatus{Phase: phase, CompletionTime: &completionTime},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		backup     *acidv1.PostgresBackup
+		targetTime time.Time
+		err        bool
+	}{
+		{
+			"base backup without target time",
+			backup("acid-test-cluster", acidv1.BackupMethodBasebackup, acidv1.BackupPhaseSucceeded),
+			time.Time{},
+			false,
+		},
+		{
+			"target time after the backup",
+			backup("acid-test-cluster", acidv1.BackupMethodBasebackup, acidv1.BackupPhaseSucceeded),
+			completionTime.Add(time.Hour),
+			false,
+		},
+		{
+			"target time before the backup",
+			backup("acid-test-cluster", acidv1.BackupMethodBasebackup, acidv1.BackupPhaseSucceeded),
+			completionTime.Add(-time.Hour),
+			true,
+		},
+		{
+			"backup of another cluster",
+			backup("acid-other-cluster", acidv1.BackupMethodBasebackup, acidv1.BackupPhaseSucceeded),
+			time.Time{},
+			true,
+		},
+		{
+			"logical backup",
+			backup("acid-test-cluster", acidv1.BackupMethodLogical, acidv1.BackupPhaseSucceeded),
+			time.Time{},
+			true,
+		},
+		{
+			"failed backup",
+			backup("acid-test-cluster", acidv1.BackupMethodBasebackup, acidv1.BackupPhaseFailed),
+			time.Time{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		finished, err := validateRestoreBackup(tt.backup, "acid-test-cluster", tt.targetTime)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if err == nil && !finished.Equal(completionTime.Time) {
+			t.Errorf("%s: expected completion time %v, got %v", tt.name, completionTime, finished)
+		}
+	}
+}
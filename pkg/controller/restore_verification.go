@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/cluster"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+	"github.com/zalando/postgres-operator/pkg/util/k8sutil"
+)
+
+const (
+	// restoreVerificationCloneSuffix is appended to the name of a cluster to name the clone of its verification
+	restoreVerificationCloneSuffix = "-verify"
+	// restoreVerificationWorkersParameter keeps the clone from starting the apply workers of subscriptions
+	restoreVerificationWorkersParameter = "max_logical_replication_workers"
+)
+
+// restoreVerificationScheduler periodically starts the restore verifications of the clusters that are due
+func (c *Controller) restoreVerificationScheduler(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(constants.RestoreVerificationCheckInterval)
+
+	for {
+		select {
+		case <-ticker.C:
+			c.startDueRestoreVerifications()
+		case <-stopCh:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (c *Controller) startDueRestoreVerifications() {
+	if c.opConfig.EnableDryRun {
+		return
+	}
+
+	now := time.Now()
+	due := make([]*cluster.Cluster, 0)
+	c.clustersMu.RLock()
+	for _, cl := range c.clusters {
+		// the clone would only be verified once the reconciliation of the cluster is resumed
+		if !cl.IsPaused() && cl.RestoreVerificationDue(now) {
+			due = append(due, cl)
+		}
+	}
+	c.clustersMu.RUnlock()
+
+	for _, cl := range due {
+		c.startRestoreVerification(cl)
+	}
+}
+
+// startRestoreVerification runs the verification of a cluster in the background, every cluster is verified
+// only once at a time
+func (c *Controller) startRestoreVerification(source *cluster.Cluster) {
+	clusterName := util.NameFromMeta(source.ObjectMeta)
+	if _, running := c.runningVerifications.LoadOrStore(clusterName, true); running {
+		return
+	}
+	go func() {
+		defer c.runningVerifications.Delete(clusterName)
+		if err := c.verifyRestore(source); err != nil {
+			c.logger.Errorf("could not verify the restore of cluster %q: %v", clusterName, err)
+		}
+	}()
+}
+
+// verifyRestore restores the latest backup of a cluster into a throwaway clone, runs the checks of the cluster
+// against the clone and records the result in the status of the cluster
+func (c *Controller) verifyRestore(source *cluster.Cluster) error {
+	c.logger.Infof("verifying that cluster %q can be restored from its backups", source.Name)
+	status := source.GetRestoreVerificationStatus()
+	if status == nil {
+		status = &acidv1.RestoreVerificationStatus{}
+	}
+
+	err := c.runRestoreVerification(source, status)
+
+	now := metav1.Now()
+	status.CompletionTime = &now
+	eventType, message := v1.EventTypeNormal, ""
+	if err != nil {
+		status.Phase = acidv1.VerificationPhaseFailed
+		status.Message = err.Error()
+		eventType, message = v1.EventTypeWarning, fmt.Sprintf("Restore verification failed: %v", err)
+	} else {
+		status.Phase = acidv1.VerificationPhaseSucceeded
+		status.Message = ""
+		status.LastSuccessTime = &now
+		message = fmt.Sprintf("Cluster restored to %s in %s, all checks passed", status.TargetTime, status.RecoveryTime)
+	}
+	if ref := source.GetReference(); ref != nil {
+		c.eventRecorder.Event(ref, eventType, "RestoreVerification", message)
+	}
+
+	if err2 := source.SetRestoreVerificationStatus(status); err2 != nil {
+		return err2
+	}
+	return err
+}
+
+func (c *Controller) runRestoreVerification(source *cluster.Cluster, status *acidv1.RestoreVerificationStatus) error {
+	sourcePg, err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(source.Namespace).Get(
+		context.TODO(), source.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get cluster %q: %v", source.Name, err)
+	}
+
+	if status.Phase != acidv1.VerificationPhaseRunning {
+		// the start time is set first, so that a failing verification is only retried after the interval
+		now := metav1.Now()
+		status.Phase = acidv1.VerificationPhaseRunning
+		status.Clone = source.Name + restoreVerificationCloneSuffix
+		status.TargetTime = now.UTC().Format(restoreTimeLayout)
+		status.StartTime = &now
+		status.CompletionTime = nil
+		status.RecoveryTime = ""
+		status.Message = ""
+
+		backups, err := source.ListBasebackups()
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("cluster %q has no base backups", source.Name)
+		}
+		if err := source.SetRestoreVerificationStatus(status); err != nil {
+			return err
+		}
+	}
+
+	pg := restoreVerificationManifest(sourcePg, status.Clone, status.TargetTime)
+	if err := c.createRestoredCluster(pg); err != nil {
+		return err
+	}
+	defer c.deleteRestoreVerificationClone(pg)
+
+	timeout := c.opConfig.RestoreVerificationTimeout
+	if timeout <= 0 {
+		timeout = constants.PostgresRestoreTimeout
+	}
+	clone, err := c.waitForRestoredCluster(pg, timeout)
+	if err != nil {
+		return err
+	}
+	status.RecoveryTime = time.Since(status.StartTime.Time).Round(time.Second).String()
+
+	database, checks := source.RestoreVerificationChecks()
+	return clone.RunRestoreVerificationChecks(database, checks)
+}
+
+// restoreVerificationManifest generates the manifest of the clone a cluster is verified with. The clone runs a
+// single instance and leaves out everything that is not needed to run the checks.
+func restoreVerificationManifest(source *acidv1.Postgresql, name, targetTime string) *acidv1.Postgresql {
+	pg := cloneManifest(name, source, targetTime)
+	if pg.Annotations == nil {
+		pg.Annotations = make(map[string]string)
+	}
+	pg.Annotations[constants.RestoreVerificationAnnotationKey] = source.Name
+
+	disabled := false
+	pg.Spec.NumberOfInstances = 1
+	pg.Spec.EnableMasterLoadBalancer = &disabled
+	pg.Spec.EnableReplicaLoadBalancer = &disabled
+	pg.Spec.EnableConnectionPool = &disabled
+	pg.Spec.EnableLogicalBackup = false
+	pg.Spec.Publications = nil
+	pg.Spec.RestoreVerification = nil
+
+	// the clone restores the subscriptions of the source with its connections and slots. Without workers they
+	// cannot consume the changes of the slots before they are dropped ahead of the checks.
+	if pg.Spec.Parameters == nil {
+		pg.Spec.Parameters = make(map[string]string)
+	}
+	pg.Spec.Parameters[restoreVerificationWorkersParameter] = "0"
+	return pg
+}
+
+// deleteRestoreVerificationClone deletes the clone of a verification, its resources are removed by the
+// delete event of the cluster. The base backups and WAL the clone archived are deleted beforehand, they
+// would otherwise pile up in the WAL bucket with every verification.
+func (c *Controller) deleteRestoreVerificationClone(pg *acidv1.Postgresql) {
+	clusterName := spec.NamespacedName{Namespace: pg.Namespace, Name: pg.Name}
+	c.clustersMu.RLock()
+	clone, ok := c.clusters[clusterName]
+	c.clustersMu.RUnlock()
+	if ok {
+		if err := clone.DeleteBackups(); err != nil {
+			c.logger.Warningf("could not delete the backups of clone %q of the restore verification: %v", pg.Name, err)
+		}
+	}
+
+	err := c.KubeClient.AcidV1ClientSet.AcidV1().Postgresqls(pg.Namespace).Delete(context.TODO(), pg.Name, metav1.DeleteOptions{})
+	if err != nil && !k8sutil.ResourceNotFound(err) {
+		c.logger.Errorf("could not delete clone %q of the restore verification: %v", pg.Name, err)
+		return
+	}
+	c.logger.Infof("deleted clone %q of the restore verification", pg.Name)
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRestoreVerificationManifest(t *testing.T) {
+	enabled := true
+	source := &acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "acid-test-cluster",
+			Namespace:   "default",
+			UID:         types.UID("efd12e58-5786-11e8-b5a7-06148230260c"),
+			Annotations: map[string]string{"acid.zalan.do/controller": "postgresql-test"},
+		},
+		Spec: acidv1.PostgresSpec{
+			TeamID:                   "acid",
+			NumberOfInstances:        3,
+			EnableMasterLoadBalancer: &enabled,
+			EnableConnectionPool:     &enabled,
+			EnableLogicalBackup:      true,
+			Publications:             map[string]acidv1.Publication{"orders": {Database: "shop", AllTables: true}},
+			PostgresqlParam:          acidv1.PostgresqlParam{Parameters: map[string]string{"work_mem": "8MB"}},
+			RestoreVerification:      &acidv1.RestoreVerification{Interval: "24h"},
+		},
+	}
+
+	pg := restoreVerificationManifest(source, "acid-test-cluster-verify", "2020-06-01T10:00:00+00:00")
+
+	if pg.Name != "acid-test-cluster-verify" || pg.Namespace != "default" {
+		t.Errorf("unexpected clone %s/%s", pg.Namespace, pg.Name)
+	}
+	if pg.Spec.Clone.ClusterName != "acid-test-cluster" || pg.Spec.Clone.EndTimestamp != "2020-06-01T10:00:00+00:00" {
+		t.Errorf("expected a clone of the source cluster, got %#v", pg.Spec.Clone)
+	}
+	if pg.Annotations["acid.zalan.do/restore-verification-of"] != "acid-test-cluster" ||
+		pg.Annotations["acid.zalan.do/controller"] != "postgresql-test" {
+		t.Errorf("expected the verification and controller annotations, got %v", pg.Annotations)
+	}
+	if pg.Spec.NumberOfInstances != 1 {
+		t.Errorf("expected a single instance, got %d", pg.Spec.NumberOfInstances)
+	}
+	if *pg.Spec.EnableMasterLoadBalancer || *pg.Spec.EnableConnectionPool || pg.Spec.EnableLogicalBackup {
+		t.Errorf("expected load balancers, connection pool and logical backups to be disabled")
+	}
+	if pg.Spec.Publications != nil || pg.Spec.RestoreVerification != nil {
+		t.Errorf("expected the clone to neither publish nor be verified itself")
+	}
+	expectedParameters := map[string]string{"work_mem": "8MB", "max_logical_replication_workers": "0"}
+	if !reflect.DeepEqual(pg.Spec.Parameters, expectedParameters) {
+		t.Errorf("expected the clone to run without logical replication workers, got parameters %v", pg.Spec.Parameters)
+	}
+	if len(source.Spec.Parameters) != 1 {
+		t.Errorf("expected the parameters of the source cluster to stay unchanged, got %v", source.Spec.Parameters)
+	}
+	if source.Spec.NumberOfInstances != 3 || !*source.Spec.EnableMasterLoadBalancer || source.Spec.RestoreVerification == nil {
+		t.Errorf("expected the source cluster to stay unchanged")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
 )
 
 // EventType contains type of the events for the TPRs and Pods received from Kubernetes
@@ -17,6 +19,7 @@ const (
 	EventDelete EventType = "DELETE"
 	EventSync   EventType = "SYNC"
 	EventRepair EventType = "REPAIR"
+	EventRetry  EventType = "RETRY"
 )
 
 // ClusterEvent carries the payload of the Cluster TPR events.
@@ -28,3 +31,11 @@ type ClusterEvent struct {
 	NewSpec   *acidv1.Postgresql
 	WorkerID  uint32
 }
+
+// clusterName returns the name of the cluster the event belongs to
+func (e ClusterEvent) clusterName() spec.NamespacedName {
+	if e.NewSpec != nil {
+		return util.NameFromMeta(e.NewSpec.ObjectMeta)
+	}
+	return util.NameFromMeta(e.OldSpec.ObjectMeta)
+}
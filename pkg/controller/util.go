@@ -30,6 +30,7 @@ func (c *Controller) makeClusterConfig() cluster.Config {
 		OpConfig:            config.Copy(c.opConfig),
 		InfrastructureRoles: infrastructureRoles,
 		PodServiceAccount:   c.PodServiceAccount,
+		SecretBackend:       c.secretBackend,
 	}
 }
 
@@ -101,6 +102,14 @@ func (c *Controller) createConfigurationCRD(enableValidation *bool) error {
 	return c.createOperatorCRD(acidv1.ConfigurationCRD(enableValidation))
 }
 
+func (c *Controller) createPostgresBackupCRD(enableValidation *bool) error {
+	return c.createOperatorCRD(acidv1.PostgresBackupCRD(enableValidation))
+}
+
+func (c *Controller) createPostgresRestoreCRD(enableValidation *bool) error {
+	return c.createOperatorCRD(acidv1.PostgresRestoreCRD(enableValidation))
+}
+
 func readDecodedRole(s string) (*spec.PgUser, error) {
 	var result spec.PgUser
 	if err := yaml.Unmarshal([]byte(s), &result); err != nil {
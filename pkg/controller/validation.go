@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"fmt"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+	"github.com/zalando/postgres-operator/pkg/cluster"
+	"github.com/zalando/postgres-operator/pkg/util/config"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ValidatePostgresql is called by the admission webhook before a Postgres manifest is stored.
+// Manifests owned by other operator instances are left to their own webhooks.
+func (c *Controller) ValidatePostgresql(oldPg, newPg *acidv1.Postgresql) error {
+	if !c.hasOwnership(newPg) {
+		return nil
+	}
+	return cluster.ValidateManifest(c.opConfig, oldPg, newPg)
+}
+
+// DefaultPostgresql is called by the admission webhook when a Postgres manifest is created. It fills the
+// configured fields with the operator defaults and returns the names of the spec fields it changed.
+func (c *Controller) DefaultPostgresql(pg *acidv1.Postgresql) []string {
+	if !c.opConfig.EnableDefaultingWebhook || !c.hasOwnership(pg) {
+		return nil
+	}
+	return cluster.DefaultManifest(c.opConfig, &pg.Spec, c.opConfig.DefaultedFields)
+}
+
+// ValidateOperatorConfiguration is called by the admission webhook before an operator configuration is stored.
+// It applies the same checks the operator runs when it loads its configuration on startup.
+func (c *Controller) ValidateOperatorConfiguration(cfg *acidv1.OperatorConfiguration) error {
+	opConfig := c.importConfigurationFromCRD(&cfg.Configuration)
+	if err := config.Validate(opConfig); err != nil {
+		return err
+	}
+	if err := cluster.ValidateDefaultedFields(opConfig.DefaultedFields); err != nil {
+		return err
+	}
+
+	for _, quantity := range []struct {
+		name  string
+		value string
+	}{
+		{"default_cpu_request", opConfig.DefaultCPURequest},
+		{"default_memory_request", opConfig.DefaultMemoryRequest},
+		{"default_cpu_limit", opConfig.DefaultCPULimit},
+		{"default_memory_limit", opConfig.DefaultMemoryLimit},
+		{"connection_pool_default_cpu_request", opConfig.ConnectionPool.ConnPoolDefaultCPURequest},
+		{"connection_pool_default_memory_request", opConfig.ConnectionPool.ConnPoolDefaultMemoryRequest},
+		{"connection_pool_default_cpu_limit", opConfig.ConnectionPool.ConnPoolDefaultCPULimit},
+		{"connection_pool_default_memory_limit", opConfig.ConnectionPool.ConnPoolDefaultMemoryLimit},
+	} {
+		if quantity.value == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantity.value); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %v", quantity.value, quantity.name, err)
+		}
+	}
+
+	return nil
+}
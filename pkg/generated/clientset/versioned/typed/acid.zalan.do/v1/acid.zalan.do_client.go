@@ -33,6 +33,8 @@ import (
 type AcidV1Interface interface {
 	RESTClient() rest.Interface
 	OperatorConfigurationsGetter
+	PostgresBackupsGetter
+	PostgresRestoresGetter
 	PostgresqlsGetter
 }
 
@@ -45,6 +47,14 @@ func (c *AcidV1Client) OperatorConfigurations(namespace string) OperatorConfigur
 	return newOperatorConfigurations(c, namespace)
 }
 
+func (c *AcidV1Client) PostgresBackups(namespace string) PostgresBackupInterface {
+	return newPostgresBackups(c, namespace)
+}
+
+func (c *AcidV1Client) PostgresRestores(namespace string) PostgresRestoreInterface {
+	return newPostgresRestores(c, namespace)
+}
+
 func (c *AcidV1Client) Postgresqls(namespace string) PostgresqlInterface {
 	return newPostgresqls(c, namespace)
 }
@@ -38,6 +38,14 @@ func (c *FakeAcidV1) OperatorConfigurations(namespace string) v1.OperatorConfigu
 	return &FakeOperatorConfigurations{c, namespace}
 }
 
+func (c *FakeAcidV1) PostgresBackups(namespace string) v1.PostgresBackupInterface {
+	return &FakePostgresBackups{c, namespace}
+}
+
+func (c *FakeAcidV1) PostgresRestores(namespace string) v1.PostgresRestoreInterface {
+	return &FakePostgresRestores{c, namespace}
+}
+
 func (c *FakeAcidV1) Postgresqls(namespace string) v1.PostgresqlInterface {
 	return &FakePostgresqls{c, namespace}
 }
@@ -26,4 +26,8 @@ package v1
 
 type OperatorConfigurationExpansion interface{}
 
+type PostgresBackupExpansion interface{}
+
+type PostgresRestoreExpansion interface{}
+
 type PostgresqlExpansion interface{}
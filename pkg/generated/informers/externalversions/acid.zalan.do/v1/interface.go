@@ -30,6 +30,10 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// PostgresBackups returns a PostgresBackupInformer.
+	PostgresBackups() PostgresBackupInformer
+	// PostgresRestores returns a PostgresRestoreInformer.
+	PostgresRestores() PostgresRestoreInformer
 	// Postgresqls returns a PostgresqlInformer.
 	Postgresqls() PostgresqlInformer
 }
@@ -45,6 +49,16 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// PostgresBackups returns a PostgresBackupInformer.
+func (v *version) PostgresBackups() PostgresBackupInformer {
+	return &postgresBackupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// PostgresRestores returns a PostgresRestoreInformer.
+func (v *version) PostgresRestores() PostgresRestoreInformer {
+	return &postgresRestoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Postgresqls returns a PostgresqlInformer.
 func (v *version) Postgresqls() PostgresqlInformer {
 	return &postgresqlInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -59,6 +59,10 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=acid.zalan.do, Version=v1
+	case v1.SchemeGroupVersion.WithResource("postgresbackups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Acid().V1().PostgresBackups().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("postgresrestores"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Acid().V1().PostgresRestores().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("postgresqls"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Acid().V1().Postgresqls().Informer()}, nil
 
@@ -24,6 +24,22 @@ SOFTWARE.
 
 package v1
 
+// PostgresBackupListerExpansion allows custom methods to be added to
+// PostgresBackupLister.
+type PostgresBackupListerExpansion interface{}
+
+// PostgresBackupNamespaceListerExpansion allows custom methods to be added to
+// PostgresBackupNamespaceLister.
+type PostgresBackupNamespaceListerExpansion interface{}
+
+// PostgresRestoreListerExpansion allows custom methods to be added to
+// PostgresRestoreLister.
+type PostgresRestoreListerExpansion interface{}
+
+// PostgresRestoreNamespaceListerExpansion allows custom methods to be added to
+// PostgresRestoreNamespaceLister.
+type PostgresRestoreNamespaceListerExpansion interface{}
+
 // PostgresqlListerExpansion allows custom methods to be added to
 // PostgresqlLister.
 type PostgresqlListerExpansion interface{}
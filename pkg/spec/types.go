@@ -31,16 +31,19 @@ const (
 	RoleOriginInfrastructure
 	RoleOriginTeamsAPI
 	RoleOriginSystem
+	RoleOriginBootstrap
 	RoleConnectionPool
 )
 
 type syncUserOperation int
 
-// Possible values for the sync user operation (removal of users is not supported yet)
+// Possible values for the sync user operation
 const (
 	PGSyncUserAdd = iota
 	PGsyncUserAlter
-	PGSyncAlterSet // handle ALTER ROLE SET parameter = value
+	PGSyncAlterSet      // handle ALTER ROLE SET parameter = value
+	PGSyncUserRename    // rename a deprecated role back to its original name
+	PGSyncUserDeprecate // rename a role that is not defined anymore and forbid it to log in
 )
 
 // PgUser contains information about a single user.
@@ -52,6 +55,7 @@ type PgUser struct {
 	MemberOf   []string          `yaml:"inrole"`
 	Parameters map[string]string `yaml:"db_parameters"`
 	AdminRole  string            `yaml:"admin_role"`
+	Namespace  string            `yaml:"-"` // namespace of the secret with the credentials, if not the one of the cluster
 }
 
 // PgUserMap maps user names to the definitions.
@@ -90,12 +94,16 @@ type ControllerStatus struct {
 	LastSyncTime    int64
 	Clusters        int
 	WorkerQueueSize map[int]int
+	Identity        string
+	Leader          string
+	IsLeader        bool
 }
 
-// QueueDump describes cache.FIFO queue
+// QueueDump describes the cluster event queue of a worker
 type QueueDump struct {
-	Keys []string
-	List []interface{}
+	Keys    []string
+	List    []interface{}
+	Retries map[string]int // failed attempts per cluster with pending events
 }
 
 // ControllerConfig describes configuration of the controller
@@ -109,6 +117,11 @@ type ControllerConfig struct {
 	CRDReadyWaitTimeout  time.Duration
 	ConfigMapName        NamespacedName
 	Namespace            string
+
+	EnableLeaderElection        bool
+	LeaderElectionLeaseDuration time.Duration
+	LeaderElectionRenewDeadline time.Duration
+	LeaderElectionRetryPeriod   time.Duration
 }
 
 // cached value for the GetOperatorNamespace
@@ -180,6 +193,8 @@ func (r RoleOrigin) String() string {
 		return "teams API role"
 	case RoleOriginSystem:
 		return "system role"
+	case RoleOriginBootstrap:
+		return "bootstrapped role"
 	case RoleConnectionPool:
 		return "connection pool role"
 	default:
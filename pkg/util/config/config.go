@@ -51,14 +51,25 @@ type Resources struct {
 
 // Auth describes authentication specific configuration parameters
 type Auth struct {
-	SecretNameTemplate            StringTemplate      `name:"secret_name_template" default:"{username}.{cluster}.credentials.{tprkind}.{tprgroup}"`
-	PamRoleName                   string              `name:"pam_role_name" default:"zalandos"`
-	PamConfiguration              string              `name:"pam_configuration" default:"https://info.example.com/oauth2/tokeninfo?access_token= uid realm=/employees"`
-	TeamsAPIUrl                   string              `name:"teams_api_url" default:"https://teams.example.com/api/"`
-	OAuthTokenSecretName          spec.NamespacedName `name:"oauth_token_secret_name" default:"postgresql-operator"`
-	InfrastructureRolesSecretName spec.NamespacedName `name:"infrastructure_roles_secret_name"`
-	SuperUsername                 string              `name:"super_username" default:"postgres"`
-	ReplicationUsername           string              `name:"replication_username" default:"standby"`
+	SecretNameTemplate                StringTemplate      `name:"secret_name_template" default:"{username}.{cluster}.credentials.{tprkind}.{tprgroup}"`
+	PamRoleName                       string              `name:"pam_role_name" default:"zalandos"`
+	PamConfiguration                  string              `name:"pam_configuration" default:"https://info.example.com/oauth2/tokeninfo?access_token= uid realm=/employees"`
+	TeamsAPIUrl                       string              `name:"teams_api_url" default:"https://teams.example.com/api/"`
+	OAuthTokenSecretName              spec.NamespacedName `name:"oauth_token_secret_name" default:"postgresql-operator"`
+	InfrastructureRolesSecretName     spec.NamespacedName `name:"infrastructure_roles_secret_name"`
+	SuperUsername                     string              `name:"super_username" default:"postgres"`
+	ReplicationUsername               string              `name:"replication_username" default:"standby"`
+	EnableRoleDeprecation             bool                `name:"enable_role_deprecation" default:"true"`
+	RoleDeletionSuffix                string              `name:"role_deletion_suffix" default:"_deleted"`
+	EnableRoleDrop                    bool                `name:"enable_role_drop" default:"false"`
+	RoleDropGracePeriod               time.Duration       `name:"role_drop_grace_period" default:"168h"`
+	EnablePasswordRotation            bool                `name:"enable_password_rotation" default:"false"`
+	PasswordRotationInterval          time.Duration       `name:"password_rotation_interval" default:"2160h"`
+	PasswordRotationOverlapPeriod     time.Duration       `name:"password_rotation_overlap_period" default:"168h"`
+	EnableSystemPasswordRotation      bool                `name:"enable_system_password_rotation" default:"false"`
+	PasswordEncryption                string              `name:"password_encryption" default:"md5"`
+	EnablePasswordEncryptionMigration bool                `name:"enable_password_encryption_migration" default:"false"`
+	EnableCrossNamespaceSecrets       bool                `name:"enable_cross_namespace_secrets" default:"false"`
 }
 
 // Scalyr holds the configuration for the Scalyr Agent sidecar for log shipping:
@@ -74,14 +85,23 @@ type Scalyr struct {
 
 // LogicalBackup defines configuration for logical backup
 type LogicalBackup struct {
-	LogicalBackupSchedule          string `name:"logical_backup_schedule" default:"30 00 * * *"`
-	LogicalBackupDockerImage       string `name:"logical_backup_docker_image" default:"registry.opensource.zalan.do/acid/logical-backup"`
-	LogicalBackupS3Bucket          string `name:"logical_backup_s3_bucket" default:""`
-	LogicalBackupS3Region          string `name:"logical_backup_s3_region" default:""`
-	LogicalBackupS3Endpoint        string `name:"logical_backup_s3_endpoint" default:""`
-	LogicalBackupS3AccessKeyID     string `name:"logical_backup_s3_access_key_id" default:""`
-	LogicalBackupS3SecretAccessKey string `name:"logical_backup_s3_secret_access_key" default:""`
-	LogicalBackupS3SSE             string `name:"logical_backup_s3_sse" default:"AES256"`
+	LogicalBackupSchedule               string `name:"logical_backup_schedule" default:"30 00 * * *"`
+	LogicalBackupDockerImage            string `name:"logical_backup_docker_image" default:"registry.opensource.zalan.do/acid/logical-backup"`
+	LogicalBackupProvider               string `name:"logical_backup_provider" default:"s3"`
+	LogicalBackupS3Bucket               string `name:"logical_backup_s3_bucket" default:""`
+	LogicalBackupS3Region               string `name:"logical_backup_s3_region" default:""`
+	LogicalBackupS3Endpoint             string `name:"logical_backup_s3_endpoint" default:""`
+	LogicalBackupS3AccessKeyID          string `name:"logical_backup_s3_access_key_id" default:""`
+	LogicalBackupS3SecretAccessKey      string `name:"logical_backup_s3_secret_access_key" default:""`
+	LogicalBackupS3SSE                  string `name:"logical_backup_s3_sse" default:"AES256"`
+	LogicalBackupGCSBucket              string `name:"logical_backup_gcs_bucket" default:""`
+	LogicalBackupGCSCredentialsSecret   string `name:"logical_backup_gcs_credentials_secret" default:""`
+	LogicalBackupAzureStorageAccount    string `name:"logical_backup_azure_storage_account" default:""`
+	LogicalBackupAzureStorageContainer  string `name:"logical_backup_azure_storage_container" default:""`
+	LogicalBackupAzureCredentialsSecret string `name:"logical_backup_azure_credentials_secret" default:""`
+	LogicalBackupPVCClaimName           string `name:"logical_backup_pvc_claim_name" default:""`
+	LogicalBackupRetentionCount         int32  `name:"logical_backup_retention_count" default:"0"`
+	LogicalBackupRetentionDays          int32  `name:"logical_backup_retention_days" default:"0"`
 }
 
 // Operator options for connection pooler
@@ -98,6 +118,32 @@ type ConnectionPool struct {
 	ConnPoolDefaultMemoryLimit   string `name:"connection_pool_default_memory_limit" default:"100Mi"`
 }
 
+// Webhook describes the configuration of the admission webhook server
+type Webhook struct {
+	EnableAdmissionWebhook  bool     `name:"enable_admission_webhook" default:"false"`
+	WebhookPort             int      `name:"webhook_port" default:"8443"`
+	WebhookTLSCertFile      string   `name:"webhook_tls_cert_file" default:"/etc/webhook/certs/tls.crt"`
+	WebhookTLSKeyFile       string   `name:"webhook_tls_key_file" default:"/etc/webhook/certs/tls.key"`
+	EnableDefaultingWebhook bool     `name:"enable_defaulting_webhook" default:"false"`
+	DefaultedFields         []string `name:"defaulted_fields" default:"resources,docker_image,connection_pool"`
+}
+
+// SecretBackend describes where the credentials of roles are stored
+type SecretBackend struct {
+	SecretBackendType    string              `name:"secret_backend_type" default:"kubernetes"`
+	VaultAddress         string              `name:"vault_address" default:""`
+	VaultKVMount         string              `name:"vault_kv_mount" default:"secret"`
+	VaultPathPrefix      string              `name:"vault_path_prefix" default:"postgres-operator"`
+	VaultTokenSecretName spec.NamespacedName `name:"vault_token_secret_name"`
+}
+
+// RestoreVerification describes the periodic verification that clusters can be restored from their backups
+type RestoreVerification struct {
+	EnableRestoreVerification   bool          `name:"enable_restore_verification" default:"false"`
+	RestoreVerificationInterval time.Duration `name:"restore_verification_interval" default:"168h"`
+	RestoreVerificationTimeout  time.Duration `name:"restore_verification_timeout" default:"6h"`
+}
+
 // Config describes operator config
 type Config struct {
 	CRD
@@ -106,6 +152,9 @@ type Config struct {
 	Scalyr
 	LogicalBackup
 	ConnectionPool
+	Webhook
+	SecretBackend
+	RestoreVerification
 
 	WatchedNamespace      string            `name:"watched_namespace"`    // special values: "*" means 'watch all namespaces', the empty string "" means 'watch a namespace where operator is deployed to'
 	EtcdHost              string            `name:"etcd_host" default:""` // special values: the empty string "" means Patroni will use K8s as a DCS
@@ -135,6 +184,9 @@ type Config struct {
 	CustomPodAnnotations                   map[string]string `name:"custom_pod_annotations"`
 	EnablePodAntiAffinity                  bool              `name:"enable_pod_antiaffinity" default:"false"`
 	PodAntiAffinityTopologyKey             string            `name:"pod_antiaffinity_topology_key" default:"kubernetes.io/hostname"`
+	EnablePausedClusterDeletion            bool              `name:"enable_paused_cluster_deletion" default:"false"`
+	EnableDryRun                           bool              `name:"enable_dry_run" default:"false"`
+	AllowedExtensions                      []string          `name:"allowed_extensions" default:""`
 	// deprecated and kept for backward compatibility
 	EnableLoadBalancer        *bool             `name:"enable_load_balancer"`
 	MasterDNSNameFormat       StringTemplate    `name:"master_dns_name_format" default:"{cluster}.{team}.{hostedzone}"`
@@ -185,7 +237,7 @@ func NewFromMap(m map[string]string) *Config {
 			panic(err)
 		}
 	}
-	if err := validate(&cfg); err != nil {
+	if err := Validate(&cfg); err != nil {
 		panic(err)
 	}
 
@@ -204,7 +256,8 @@ func Copy(c *Config) Config {
 	return cfg
 }
 
-func validate(cfg *Config) (err error) {
+// Validate checks the configuration for inconsistent values
+func Validate(cfg *Config) (err error) {
 	if cfg.MinInstances > 0 && cfg.MaxInstances > 0 && cfg.MinInstances > cfg.MaxInstances {
 		err = fmt.Errorf("minimum number of instances %d is set higher than the maximum number %d",
 			cfg.MinInstances, cfg.MaxInstances)
@@ -217,5 +270,27 @@ func validate(cfg *Config) (err error) {
 		msg := "number of connection pool instances should be higher than %d"
 		err = fmt.Errorf(msg, constants.ConnPoolMinInstances)
 	}
+
+	if cfg.SecretBackendType != constants.SecretBackendKubernetes && cfg.SecretBackendType != constants.SecretBackendVault {
+		err = fmt.Errorf("secret backend type should be %q or %q, got %q", constants.SecretBackendKubernetes,
+			constants.SecretBackendVault, cfg.SecretBackendType)
+	} else if cfg.SecretBackendType == constants.SecretBackendVault && cfg.VaultAddress == "" {
+		err = fmt.Errorf("vault address should be set for the %q secret backend", constants.SecretBackendVault)
+	}
+
+	switch cfg.LogicalBackupProvider {
+	case constants.LogicalBackupProviderS3, constants.LogicalBackupProviderGCS,
+		constants.LogicalBackupProviderAzure, constants.LogicalBackupProviderPVC:
+	default:
+		err = fmt.Errorf("logical backup provider should be one of %q, %q, %q or %q, got %q",
+			constants.LogicalBackupProviderS3, constants.LogicalBackupProviderGCS, constants.LogicalBackupProviderAzure,
+			constants.LogicalBackupProviderPVC, cfg.LogicalBackupProvider)
+	}
+
+	if cfg.PasswordEncryption != constants.PasswordEncryptionMD5 &&
+		cfg.PasswordEncryption != constants.PasswordEncryptionSCRAMSHA256 {
+		err = fmt.Errorf("password encryption should be %q or %q, got %q", constants.PasswordEncryptionMD5,
+			constants.PasswordEncryptionSCRAMSHA256, cfg.PasswordEncryption)
+	}
 	return
 }
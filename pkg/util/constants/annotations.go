@@ -8,4 +8,9 @@ const (
 	KubeIAmAnnotation                  = "iam.amazonaws.com/role"
 	VolumeStorateProvisionerAnnotation = "pv.kubernetes.io/provisioned-by"
 	PostgresqlControllerAnnotationKey  = "acid.zalan.do/controller"
+	PostgresqlPausedAnnotationKey      = "acid.zalan.do/paused"
+	PasswordRotationTimeAnnotationKey  = "acid.zalan.do/password-rotation-time"
+	PasswordRotationUserAnnotationKey  = "acid.zalan.do/password-rotation-user"
+	SecretClusterAnnotationKey         = "acid.zalan.do/cluster"
+	RestoreVerificationAnnotationKey   = "acid.zalan.do/restore-verification-of"
 )
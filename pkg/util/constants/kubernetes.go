@@ -11,4 +11,7 @@ const (
 	QueueResyncPeriodPod  = 5 * time.Minute
 	QueueResyncPeriodTPR  = 5 * time.Minute
 	QueueResyncPeriodNode = 5 * time.Minute
+
+	ClusterEventRetryBaseDelay = 5 * time.Second
+	ClusterEventRetryMaxDelay  = 10 * time.Minute
 )
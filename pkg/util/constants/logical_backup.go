@@ -0,0 +1,17 @@
+package constants
+
+// Logical backup specific constants
+const (
+	LogicalBackupProviderS3    = "s3"
+	LogicalBackupProviderGCS   = "gcs"
+	LogicalBackupProviderAzure = "azure"
+	LogicalBackupProviderPVC   = "pvc"
+
+	LogicalBackupVolumeName              = "logical-backup"
+	LogicalBackupPVCMountPath            = "/backup"
+	LogicalBackupGCSCredentialsMountPath = "/var/secrets/google"
+	LogicalBackupGCSCredentialsKey       = "key.json"
+	LogicalBackupAzureStorageKey         = "account-key"
+	LogicalBackupS3AccessKeyIDKey        = "AWS_ACCESS_KEY_ID"
+	LogicalBackupS3SecretAccessKeyKey    = "AWS_SECRET_ACCESS_KEY"
+)
@@ -11,6 +11,14 @@ const (
 	PostgresConnectRetryTimeout = 2 * time.Minute
 	PostgresConnectTimeout      = 15 * time.Second
 
+	PostgresBackupCheckInterval = 10 * time.Second
+	PostgresBackupTimeout       = 24 * time.Hour
+
+	PostgresRestoreCheckInterval = 10 * time.Second
+	PostgresRestoreTimeout       = 24 * time.Hour
+
+	RestoreVerificationCheckInterval = time.Minute
+
 	ShmVolumeName = "dshm"
 	ShmVolumePath = "/dev/shm"
 )
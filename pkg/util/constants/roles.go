@@ -15,3 +15,23 @@ const (
 	RoleFlagReplication       = "REPLICATION"
 	RoleFlagByPassRLS         = "BYPASSRLS"
 )
+
+// Suffixes of the default roles of prepared databases and schemas, e.g. foo_owner and foo_owner_user
+const (
+	OwnerRoleNameSuffix  = "_owner"
+	ReaderRoleNameSuffix = "_reader"
+	WriterRoleNameSuffix = "_writer"
+	UserRoleNameSuffix   = "_user"
+)
+
+// Password encryption methods, named after the values of the password_encryption parameter of PostgreSQL
+const (
+	PasswordEncryptionMD5         = "md5"
+	PasswordEncryptionSCRAMSHA256 = "scram-sha-256"
+)
+
+// Backends storing the credentials of roles
+const (
+	SecretBackendKubernetes = "kubernetes"
+	SecretBackendVault      = "vault"
+)
@@ -8,6 +8,7 @@ import (
 	b64 "encoding/base64"
 
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	clientbatchv1 "k8s.io/client-go/kubernetes/typed/batch/v1"
 	clientbatchv1beta1 "k8s.io/client-go/kubernetes/typed/batch/v1beta1"
 
 	apiappsv1 "k8s.io/api/apps/v1"
@@ -19,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	appsv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	policyv1beta1 "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 	rbacv1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
@@ -45,12 +47,15 @@ type KubernetesClient struct {
 	corev1.NodesGetter
 	corev1.NamespacesGetter
 	corev1.ServiceAccountsGetter
+	corev1.EventsGetter
 	appsv1.StatefulSetsGetter
 	appsv1.DeploymentsGetter
 	rbacv1.RoleBindingsGetter
 	policyv1beta1.PodDisruptionBudgetsGetter
 	apiextbeta1.CustomResourceDefinitionsGetter
 	clientbatchv1beta1.CronJobsGetter
+	clientbatchv1.JobsGetter
+	coordinationv1.LeasesGetter
 
 	RESTClient      rest.Interface
 	AcidV1ClientSet *acidv1client.Clientset
@@ -136,12 +141,15 @@ func NewFromConfig(cfg *rest.Config) (KubernetesClient, error) {
 	kubeClient.PersistentVolumesGetter = client.CoreV1()
 	kubeClient.NodesGetter = client.CoreV1()
 	kubeClient.NamespacesGetter = client.CoreV1()
+	kubeClient.EventsGetter = client.CoreV1()
 	kubeClient.StatefulSetsGetter = client.AppsV1()
 	kubeClient.DeploymentsGetter = client.AppsV1()
 	kubeClient.PodDisruptionBudgetsGetter = client.PolicyV1beta1()
 	kubeClient.RESTClient = client.CoreV1().RESTClient()
 	kubeClient.RoleBindingsGetter = client.RbacV1()
 	kubeClient.CronJobsGetter = client.BatchV1beta1()
+	kubeClient.JobsGetter = client.BatchV1()
+	kubeClient.LeasesGetter = client.CoordinationV1()
 
 	apiextClient, err := apiextclient.NewForConfig(cfg)
 	if err != nil {
@@ -217,7 +225,28 @@ func SamePDB(cur, new *policybeta1.PodDisruptionBudget) (match bool, reason stri
 }
 
 func getJobImage(cronJob *batchv1beta1.CronJob) string {
-	return cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image
+	return getJobContainer(cronJob).Image
+}
+
+func getJobContainer(cronJob *batchv1beta1.CronJob) *v1.Container {
+	return &cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+}
+
+// getJobVolumeSources maps the volumes of the job to the claims and secrets they refer to, ignoring the
+// defaults K8s fills in
+func getJobVolumeSources(cronJob *batchv1beta1.CronJob) map[string]string {
+	sources := make(map[string]string)
+	for _, volume := range cronJob.Spec.JobTemplate.Spec.Template.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			sources[volume.Name] = "claim " + volume.PersistentVolumeClaim.ClaimName
+		case volume.Secret != nil:
+			sources[volume.Name] = "secret " + volume.Secret.SecretName
+		default:
+			sources[volume.Name] = ""
+		}
+	}
+	return sources
 }
 
 // SameLogicalBackupJob compares Specs of logical backup cron jobs
@@ -235,6 +264,18 @@ func SameLogicalBackupJob(cur, new *batchv1beta1.CronJob) (match bool, reason st
 			newImage, curImage)
 	}
 
+	newEnv := getJobContainer(new).Env
+	curEnv := getJobContainer(cur).Env
+	if !reflect.DeepEqual(newEnv, curEnv) {
+		return false, "new job's environment doesn't match the current one"
+	}
+
+	newVolumes := getJobVolumeSources(new)
+	curVolumes := getJobVolumeSources(cur)
+	if !reflect.DeepEqual(newVolumes, curVolumes) {
+		return false, fmt.Sprintf("new job's volumes %v don't match the current ones %v", newVolumes, curVolumes)
+	}
+
 	return true, ""
 }
 
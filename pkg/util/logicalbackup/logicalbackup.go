@@ -0,0 +1,78 @@
+package logicalbackup
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// scheduled dumps are named after the time the logical backup job started them,
+// dumps of on-demand backups are named after their PostgresBackup
+var scheduledDumpName = regexp.MustCompile(`^[0-9]+\.sql\.gz$`)
+
+// Dump describes a logical backup of a cluster in the backup storage.
+type Dump struct {
+	Name         string    `json:"name"`
+	Location     string    `json:"location"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+	Scheduled    bool      `json:"scheduled"`
+}
+
+// Storage defines the set of methods used to access the logical backups of a cluster in a provider-specific storage.
+type Storage interface {
+	ListDumps(prefix string) ([]Dump, error)
+	DeleteDump(dump Dump) error
+}
+
+// Retention defines how many scheduled dumps are kept and for how long. Zero disables the respective limit.
+type Retention struct {
+	Count int32 `json:"count"`
+	Days  int32 `json:"days"`
+}
+
+// Enabled checks whether any limit is set
+func (r Retention) Enabled() bool {
+	return r.Count > 0 || r.Days > 0
+}
+
+// IsScheduled checks whether a dump was taken by the logical backup job of a cluster
+func IsScheduled(name string) bool {
+	return scheduledDumpName.MatchString(name)
+}
+
+// SortDumps sorts dumps from the latest to the oldest one
+func SortDumps(dumps []Dump) {
+	sort.SliceStable(dumps, func(i, j int) bool {
+		return dumps[i].LastModified.After(dumps[j].LastModified)
+	})
+}
+
+// Expired returns the scheduled dumps that exceed the retention. The latest scheduled dump is always kept, so that a
+// cluster whose logical backup job stopped working does not end up without any dump. Dumps of on-demand backups are
+// never expired, they are removed by hand.
+func Expired(dumps []Dump, retention Retention, now time.Time) []Dump {
+	result := make([]Dump, 0)
+	if !retention.Enabled() {
+		return result
+	}
+
+	scheduled := make([]Dump, 0, len(dumps))
+	for _, dump := range dumps {
+		if dump.Scheduled {
+			scheduled = append(scheduled, dump)
+		}
+	}
+	SortDumps(scheduled)
+
+	cutoff := now.AddDate(0, 0, -int(retention.Days))
+	for i, dump := range scheduled {
+		if i == 0 {
+			continue
+		}
+		if (retention.Count > 0 && i >= int(retention.Count)) || (retention.Days > 0 && dump.LastModified.Before(cutoff)) {
+			result = append(result, dump)
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package logicalbackup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsScheduled(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduled bool
+	}{
+		{"1591005600.sql.gz", true},
+		{"pre-migration.sql.gz", false},
+		{"1591005600.sql", false},
+	}
+
+	for _, tt := range tests {
+		if scheduled := IsScheduled(tt.name); scheduled != tt.scheduled {
+			t.Errorf("expected %t for %q, got %t", tt.scheduled, tt.name, scheduled)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2020, 6, 10, 12, 0, 0, 0, time.UTC)
+	dump := func(name string, daysAgo int) Dump {
+		return Dump{Name: name, LastModified: now.AddDate(0, 0, -daysAgo), Scheduled: IsScheduled(name)}
+	}
+	dumps := []Dump{
+		dump("3.sql.gz", 3),
+		dump("1.sql.gz", 1),
+		dump("pre-migration.sql.gz", 20),
+		dump("10.sql.gz", 10),
+		dump("2.sql.gz", 2),
+	}
+
+	tests := []struct {
+		subTest   string
+		dumps     []Dump
+		retention Retention
+		expected  []string
+	}{
+		{
+			subTest:   "no retention",
+			dumps:     dumps,
+			retention: Retention{},
+			expected:  []string{},
+		},
+		{
+			subTest:   "retention by count",
+			dumps:     dumps,
+			retention: Retention{Count: 2},
+			expected:  []string{"3.sql.gz", "10.sql.gz"},
+		},
+		{
+			subTest:   "retention by age",
+			dumps:     dumps,
+			retention: Retention{Days: 5},
+			expected:  []string{"10.sql.gz"},
+		},
+		{
+			subTest:   "retention by count and age",
+			dumps:     dumps,
+			retention: Retention{Count: 3, Days: 2},
+			expected:  []string{"3.sql.gz", "10.sql.gz"},
+		},
+		{
+			subTest:   "latest dump is kept",
+			dumps:     []Dump{dump("10.sql.gz", 10), dump("20.sql.gz", 20)},
+			retention: Retention{Days: 5},
+			expected:  []string{"20.sql.gz"},
+		},
+	}
+
+	for _, tt := range tests {
+		names := make([]string, 0)
+		for _, dump := range Expired(tt.dumps, tt.retention, now) {
+			names = append(names, dump.Name)
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s: expected expired dumps %v, got %v", tt.subTest, tt.expected, names)
+		}
+	}
+}
+
+func TestS3ObjectKey(t *testing.T) {
+	storage := &S3Storage{Bucket: "backups"}
+	tests := []struct {
+		location string
+		key      string
+		err      bool
+	}{
+		{"s3://backups/spilo/acid-test-cluster/logical_backups/1.sql.gz", "spilo/acid-test-cluster/logical_backups/1.sql.gz", false},
+		{"s3://other/spilo/acid-test-cluster/logical_backups/1.sql.gz", "", true},
+		{"s3://backups/", "", true},
+	}
+
+	for _, tt := range tests {
+		key, err := storage.objectKey(tt.location)
+		if key != tt.key || (err != nil) != tt.err {
+			t.Errorf("expected %q and error %t for %q, got %q and error %v", tt.key, tt.err, tt.location, key, err)
+		}
+	}
+}
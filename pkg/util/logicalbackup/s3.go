@@ -0,0 +1,125 @@
+package logicalbackup
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// region the bucket region is looked up in when none is configured, and the region of S3-compatible endpoints
+const defaultS3Region = "us-east-1"
+
+// S3Storage implements the logical backup storage interface for AWS S3 and S3-compatible endpoints such as MinIO.
+type S3Storage struct {
+	Bucket          string
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	connection *s3.S3
+}
+
+// Connect establishes the S3 session. Without a configured region, the region of the bucket is looked up on AWS.
+func (s *S3Storage) Connect() error {
+	if s.Bucket == "" {
+		return fmt.Errorf("no logical backup bucket is configured")
+	}
+
+	config := &aws.Config{Region: aws.String(defaultS3Region)}
+	if s.Region != "" {
+		config.Region = aws.String(s.Region)
+	}
+	if s.Endpoint != "" {
+		// S3-compatible storage usually does not support bucket names as part of the host name
+		config.Endpoint = aws.String(s.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if s.AccessKeyID != "" {
+		config.Credentials = credentials.NewStaticCredentials(s.AccessKeyID, s.SecretAccessKey, "")
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return fmt.Errorf("could not establish AWS session: %v", err)
+	}
+	if s.Region == "" && s.Endpoint == "" {
+		region, err := s3manager.GetBucketRegion(aws.BackgroundContext(), sess, s.Bucket, defaultS3Region)
+		if err != nil {
+			return fmt.Errorf("could not get region of bucket %q: %v", s.Bucket, err)
+		}
+		sess = sess.Copy(&aws.Config{Region: aws.String(region)})
+	}
+	s.connection = s3.New(sess)
+	return nil
+}
+
+// ListDumps lists the dumps stored under the prefix of a cluster
+func (s *S3Storage) ListDumps(prefix string) ([]Dump, error) {
+	if s.connection == nil {
+		if err := s.Connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]Dump, 0)
+	err := s.connection.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			key := aws.StringValue(object.Key)
+			name := path.Base(key)
+			result = append(result, Dump{
+				Name:         name,
+				Location:     fmt.Sprintf("s3://%s/%s", s.Bucket, key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+				Scheduled:    IsScheduled(name),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list objects in bucket %q with prefix %q: %v", s.Bucket, prefix, err)
+	}
+	SortDumps(result)
+	return result, nil
+}
+
+// DeleteDump deletes a dump returned by ListDumps
+func (s *S3Storage) DeleteDump(dump Dump) error {
+	if s.connection == nil {
+		if err := s.Connect(); err != nil {
+			return err
+		}
+	}
+
+	key, err := s.objectKey(dump.Location)
+	if err != nil {
+		return err
+	}
+	if _, err := s.connection.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("could not delete %q: %v", dump.Location, err)
+	}
+	return nil
+}
+
+// objectKey converts s3://bucket/key to the key of an object in the configured bucket
+func (s *S3Storage) objectKey(location string) (string, error) {
+	bucketURL := fmt.Sprintf("s3://%s/", s.Bucket)
+	key := strings.TrimPrefix(location, bucketURL)
+	if key == location || key == "" {
+		return "", fmt.Errorf("%q is not stored in bucket %q", location, s.Bucket)
+	}
+	return key, nil
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "postgres_operator"
+
+// operations on Postgres clusters that are timed
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationSync   = "sync"
+)
+
+// outcomes of counted actions
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	operations = []string{OperationCreate, OperationUpdate, OperationSync}
+	results    = []string{ResultSuccess, ResultFailure}
+	podRoles   = []string{"master", "replica"}
+)
+
+var (
+	// Registry holds all metrics of the operator, it is exposed by the REST API
+	Registry = prometheus.NewRegistry()
+
+	clusterOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "cluster_operation_duration_seconds",
+			Help:      "Duration of create, update and sync operations on Postgres clusters.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		},
+		[]string{"operation", "namespace", "cluster"})
+
+	clusterOperationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "cluster_operation_errors_total",
+			Help:      "Number of failed create, update and sync operations on Postgres clusters.",
+		},
+		[]string{"operation", "namespace", "cluster"})
+
+	switchovers = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "switchovers_total",
+			Help:      "Number of switchovers initiated by the operator.",
+		},
+		[]string{"namespace", "cluster", "result"})
+
+	podMigrations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "pod_migrations_total",
+			Help:      "Number of Postgres pods migrated away from decommissioned nodes.",
+		},
+		[]string{"namespace", "cluster", "role", "result"})
+
+	volumeResizes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "volume_resizes_total",
+			Help:      "Number of persistent volume resizes of Postgres clusters.",
+		},
+		[]string{"namespace", "cluster", "result"})
+
+	teamsAPIRequestDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "teams_api_request_duration_seconds",
+			Help:      "Latency of requests to the Teams API.",
+			Buckets:   prometheus.DefBuckets,
+		})
+
+	teamsAPIErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "teams_api_errors_total",
+			Help:      "Number of failed requests to the Teams API.",
+		})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		clusterOperationDuration,
+		clusterOperationErrors,
+		switchovers,
+		podMigrations,
+		volumeResizes,
+		teamsAPIRequestDuration,
+		teamsAPIErrors,
+	)
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ObserveClusterOperation records the duration and the outcome of a create, update or sync operation
+func ObserveClusterOperation(operation, namespace, cluster string, duration time.Duration, err error) {
+	clusterOperationDuration.WithLabelValues(operation, namespace, cluster).Observe(duration.Seconds())
+	// create the error series right away, so that its rate is known before the first error
+	errorCounter := clusterOperationErrors.WithLabelValues(operation, namespace, cluster)
+	if err != nil {
+		errorCounter.Inc()
+	}
+}
+
+// CountSwitchover records the outcome of a switchover
+func CountSwitchover(namespace, cluster string, err error) {
+	switchovers.WithLabelValues(namespace, cluster, result(err)).Inc()
+}
+
+// CountPodMigration records the outcome of moving a master or replica pod to another node
+func CountPodMigration(namespace, cluster, role string, err error) {
+	podMigrations.WithLabelValues(namespace, cluster, role, result(err)).Inc()
+}
+
+// CountVolumeResize records the outcome of resizing the volumes of a cluster
+func CountVolumeResize(namespace, cluster string, err error) {
+	volumeResizes.WithLabelValues(namespace, cluster, result(err)).Inc()
+}
+
+// ObserveTeamsAPIRequest records the latency and the outcome of a Teams API request
+func ObserveTeamsAPIRequest(duration time.Duration, err error) {
+	teamsAPIRequestDuration.Observe(duration.Seconds())
+	if err != nil {
+		teamsAPIErrors.Inc()
+	}
+}
+
+// ForgetCluster removes all series of a deleted cluster
+func ForgetCluster(namespace, cluster string) {
+	for _, operation := range operations {
+		clusterOperationDuration.DeleteLabelValues(operation, namespace, cluster)
+		clusterOperationErrors.DeleteLabelValues(operation, namespace, cluster)
+	}
+	for _, res := range results {
+		switchovers.DeleteLabelValues(namespace, cluster, res)
+		volumeResizes.DeleteLabelValues(namespace, cluster, res)
+		for _, role := range podRoles {
+			podMigrations.DeleteLabelValues(namespace, cluster, role, res)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveClusterOperation(t *testing.T) {
+	ObserveClusterOperation(OperationSync, "default", "acid-test", time.Second, nil)
+	if errors := testutil.ToFloat64(clusterOperationErrors.WithLabelValues(OperationSync, "default", "acid-test")); errors != 0 {
+		t.Errorf("Expected no errors after a successful sync, got %v", errors)
+	}
+
+	ObserveClusterOperation(OperationSync, "default", "acid-test", time.Second, fmt.Errorf("could not sync"))
+	if errors := testutil.ToFloat64(clusterOperationErrors.WithLabelValues(OperationSync, "default", "acid-test")); errors != 1 {
+		t.Errorf("Expected one error after a failed sync, got %v", errors)
+	}
+
+	ForgetCluster("default", "acid-test")
+	if clusterOperationErrors.DeleteLabelValues(OperationSync, "default", "acid-test") {
+		t.Errorf("Expected series of deleted cluster to be removed")
+	}
+}
+
+func TestCountSwitchover(t *testing.T) {
+	CountSwitchover("default", "acid-test", nil)
+	CountSwitchover("default", "acid-test", fmt.Errorf("could not switch over"))
+	CountSwitchover("default", "acid-test", nil)
+
+	if successes := testutil.ToFloat64(switchovers.WithLabelValues("default", "acid-test", ResultSuccess)); successes != 2 {
+		t.Errorf("Expected 2 successful switchovers, got %v", successes)
+	}
+	if failures := testutil.ToFloat64(switchovers.WithLabelValues("default", "acid-test", ResultFailure)); failures != 1 {
+		t.Errorf("Expected 1 failed switchover, got %v", failures)
+	}
+	ForgetCluster("default", "acid-test")
+}
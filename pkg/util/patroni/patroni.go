@@ -17,6 +17,8 @@ import (
 const (
 	failoverPath = "/failover"
 	configPath   = "/config"
+	statusPath   = "/patroni"
+	restartPath  = "/restart"
 	apiPort      = 8008
 	timeout      = 30 * time.Second
 )
@@ -25,6 +27,18 @@ const (
 type Interface interface {
 	Switchover(master *v1.Pod, candidate string) error
 	SetPostgresParameters(server *v1.Pod, options map[string]string) error
+	GetPostgresParameters(server *v1.Pod) (map[string]string, error)
+	GetMemberData(server *v1.Pod) (MemberData, error)
+	Restart(server *v1.Pod) error
+	GetSlots(server *v1.Pod) (map[string]map[string]string, error)
+	SetSlots(server *v1.Pod, slots map[string]map[string]string) error
+}
+
+// MemberData describes the state of the Patroni member of a pod
+type MemberData struct {
+	Role           string `json:"role"`
+	State          string `json:"state"`
+	PendingRestart bool   `json:"pending_restart"`
 }
 
 // Patroni API client
@@ -94,6 +108,34 @@ func (p *Patroni) httpPostOrPatch(method string, url string, body *bytes.Buffer)
 	return nil
 }
 
+func (p *Patroni) httpGet(url string) (body []byte, err error) {
+	p.logger.Debugf("making GET http request: %s", url)
+
+	resp, err := p.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("could not make request: %v", err)
+	}
+	defer func() {
+		if err2 := resp.Body.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("could not close request: %v, prior error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("could not close request: %v", err2)
+			}
+		}
+	}()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("patroni returned '%s'", string(body))
+	}
+	return body, nil
+}
+
 // Switchover by calling Patroni REST API
 func (p *Patroni) Switchover(master *v1.Pod, candidate string) error {
 	buf := &bytes.Buffer{}
@@ -108,8 +150,6 @@ func (p *Patroni) Switchover(master *v1.Pod, candidate string) error {
 	return p.httpPostOrPatch(http.MethodPost, apiURLString+failoverPath, buf)
 }
 
-//TODO: add an option call /patroni to check if it is necessary to restart the server
-
 //SetPostgresParameters sets Postgres options via Patroni patch API call.
 func (p *Patroni) SetPostgresParameters(server *v1.Pod, parameters map[string]string) error {
 	buf := &bytes.Buffer{}
@@ -123,3 +163,110 @@ func (p *Patroni) SetPostgresParameters(server *v1.Pod, parameters map[string]st
 	}
 	return p.httpPostOrPatch(http.MethodPatch, apiURLString+configPath, buf)
 }
+
+// GetPostgresParameters returns Postgres options stored in the Patroni dynamic configuration.
+func (p *Patroni) GetPostgresParameters(server *v1.Pod) (map[string]string, error) {
+	apiURLString, err := apiURL(server)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.httpGet(apiURLString + configPath)
+	if err != nil {
+		return nil, err
+	}
+	return postgresParameters(body)
+}
+
+// postgresParameters returns the Postgres options of a Patroni configuration as strings. Numbers keep the
+// form they have in the configuration, so that they compare equal to the strings of the manifest.
+func postgresParameters(body []byte) (map[string]string, error) {
+	var config struct {
+		PostgreSQL struct {
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"postgresql"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("could not decode Patroni configuration: %v", err)
+	}
+
+	parameters := make(map[string]string, len(config.PostgreSQL.Parameters))
+	for name, value := range config.PostgreSQL.Parameters {
+		parameters[name] = fmt.Sprintf("%v", value)
+	}
+	return parameters, nil
+}
+
+// GetMemberData returns the state of the Patroni member running in the pod.
+func (p *Patroni) GetMemberData(server *v1.Pod) (MemberData, error) {
+	var member MemberData
+	apiURLString, err := apiURL(server)
+	if err != nil {
+		return member, err
+	}
+	body, err := p.httpGet(apiURLString + statusPath)
+	if err != nil {
+		return member, err
+	}
+	if err := json.Unmarshal(body, &member); err != nil {
+		return member, fmt.Errorf("could not decode Patroni member data: %v", err)
+	}
+	return member, nil
+}
+
+// Restart restarts Postgres in the pod via the Patroni API.
+func (p *Patroni) Restart(server *v1.Pod) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(map[string]interface{}{}); err != nil {
+		return fmt.Errorf("could not encode json: %v", err)
+	}
+	apiURLString, err := apiURL(server)
+	if err != nil {
+		return err
+	}
+	return p.httpPostOrPatch(http.MethodPost, apiURLString+restartPath, buf)
+}
+
+// GetSlots returns the permanent replication slots stored in the Patroni dynamic configuration.
+func (p *Patroni) GetSlots(server *v1.Pod) (map[string]map[string]string, error) {
+	apiURLString, err := apiURL(server)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.httpGet(apiURLString + configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Slots map[string]map[string]interface{} `json:"slots"`
+	}
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("could not decode Patroni configuration: %v", err)
+	}
+
+	slots := make(map[string]map[string]string, len(config.Slots))
+	for name, slot := range config.Slots {
+		slots[name] = make(map[string]string, len(slot))
+		for key, value := range slot {
+			slots[name][key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return slots, nil
+}
+
+// SetSlots adds or changes permanent replication slots via Patroni patch API call. Slots with a nil
+// configuration are removed from the dynamic configuration.
+func (p *Patroni) SetSlots(server *v1.Pod, slots map[string]map[string]string) error {
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(map[string]interface{}{"slots": slots})
+	if err != nil {
+		return fmt.Errorf("could not encode json: %v", err)
+	}
+	apiURLString, err := apiURL(server)
+	if err != nil {
+		return err
+	}
+	return p.httpPostOrPatch(http.MethodPatch, apiURLString+configPath, buf)
+}
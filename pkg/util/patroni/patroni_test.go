@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"k8s.io/api/core/v1"
+	"reflect"
 	"testing"
 )
 
@@ -72,3 +73,22 @@ func TestApiURL(t *testing.T) {
 		}
 	}
 }
+
+func TestPostgresParameters(t *testing.T) {
+	body := []byte(`{"ttl": 30, "postgresql": {"parameters": {"max_connections": 1000000, "wal_log_hints": true,
+		"wal_level": "logical", "checkpoint_completion_target": 0.9}}}`)
+	expected := map[string]string{
+		"max_connections":              "1000000",
+		"wal_log_hints":                "true",
+		"wal_level":                    "logical",
+		"checkpoint_completion_target": "0.9",
+	}
+
+	parameters, err := postgresParameters(body)
+	if err != nil {
+		t.Fatalf("could not get parameters: %v", err)
+	}
+	if !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("expected parameters %v, got %v", expected, parameters)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/zalando/postgres-operator/pkg/util/metrics"
 )
 
 // InfrastructureAccount defines an account of the team on some infrastructure (i.e AWS, Google) platform.
@@ -73,6 +76,11 @@ func (t *API) TeamInfo(teamID, token string) (tm *Team, err error) {
 		resp *http.Response
 	)
 
+	start := time.Now()
+	defer func() {
+		metrics.ObserveTeamsAPIRequest(time.Since(start), err)
+	}()
+
 	url := fmt.Sprintf("%s/teams/%s", t.url, teamID)
 	t.logger.Debugf("request url: %s", url)
 	req, err = http.NewRequest("GET", url, nil)
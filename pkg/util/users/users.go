@@ -9,11 +9,13 @@ import (
 
 	"github.com/zalando/postgres-operator/pkg/spec"
 	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
 )
 
 const (
 	createUserSQL        = `SET LOCAL synchronous_commit = 'local'; CREATE ROLE "%s" %s %s;`
 	alterUserSQL         = `ALTER ROLE "%s" %s`
+	renameUserSQL        = `ALTER ROLE "%s" RENAME TO "%s"`
 	alterRoleResetAllSQL = `ALTER ROLE "%s" RESET ALL`
 	alterRoleSetSQL      = `ALTER ROLE "%s" SET %s TO %s`
 	grantToUserSQL       = `GRANT %s TO "%s"`
@@ -27,7 +29,16 @@ const (
 // with those defined in the manifest, altering existing users when necessary. It will never strips
 // an existing roles of another role membership, nor it removes the already assigned flag
 // (except for the NOLOGIN). TODO: process other NOflags, i.e. NOSUPERUSER correctly.
+// Database users that are not among the new users are deprecated: they are renamed with the
+// RoleDeletionSuffix and set to NOLOGIN. A deprecated role is renamed back once it is defined again.
+// Deprecation is disabled when the suffix is empty.
+// Passwords are hashed with the PasswordEncryption method, md5 or scram-sha-256, before they are sent to
+// the database. With MigratePasswordEncryption, roles whose password is stored with another method are
+// altered to use the configured one.
 type DefaultUserSyncStrategy struct {
+	RoleDeletionSuffix        string
+	PasswordEncryption        string
+	MigratePasswordEncryption bool
 }
 
 // ProduceSyncRequests figures out the types of changes that need to happen with the given users.
@@ -35,9 +46,16 @@ func (strategy DefaultUserSyncStrategy) ProduceSyncRequests(dbUsers spec.PgUserM
 	newUsers spec.PgUserMap) []spec.PgSyncUserRequest {
 
 	var reqs []spec.PgSyncUserRequest
-	// No existing roles are deleted or stripped of role memebership/flags
+	// No existing roles are stripped of role memebership/flags
 	for name, newUser := range newUsers {
 		dbUser, exists := dbUsers[name]
+		if !exists && strategy.RoleDeletionSuffix != "" {
+			// restore the deprecated role, so that its objects are kept
+			dbUser, exists = dbUsers[name+strategy.RoleDeletionSuffix]
+			if exists {
+				reqs = append(reqs, spec.PgSyncUserRequest{Kind: spec.PGSyncUserRename, User: newUser})
+			}
+		}
 		if !exists {
 			reqs = append(reqs, spec.PgSyncUserRequest{Kind: spec.PGSyncUserAdd, User: newUser})
 			if len(newUser.Parameters) > 0 {
@@ -45,10 +63,10 @@ func (strategy DefaultUserSyncStrategy) ProduceSyncRequests(dbUsers spec.PgUserM
 			}
 		} else {
 			r := spec.PgSyncUserRequest{}
-			newMD5Password := util.PGUserPassword(newUser)
 
-			if dbUser.Password != newMD5Password {
-				r.User.Password = newMD5Password
+			// roles without a password, e.g. users with rotated credentials, keep their current password
+			if newUser.Password != "" && strategy.passwordChanged(newUser, dbUser.Password) {
+				r.User.Password = strategy.encryptPassword(newUser)
 				r.Kind = spec.PGsyncUserAlter
 			}
 			if addNewRoles, equal := util.SubstractStringSlices(newUser.MemberOf, dbUser.MemberOf); !equal {
@@ -69,32 +87,95 @@ func (strategy DefaultUserSyncStrategy) ProduceSyncRequests(dbUsers spec.PgUserM
 		}
 	}
 
+	if strategy.RoleDeletionSuffix == "" {
+		return reqs
+	}
+	for name, dbUser := range dbUsers {
+		if _, exists := newUsers[name]; exists || strings.HasSuffix(name, strategy.RoleDeletionSuffix) {
+			continue
+		}
+		// the name of the deprecated role is taken, keep the role until the other one is dropped
+		if _, exists := dbUsers[name+strategy.RoleDeletionSuffix]; exists {
+			continue
+		}
+		reqs = append(reqs, spec.PgSyncUserRequest{Kind: spec.PGSyncUserDeprecate, User: dbUser})
+	}
+
 	return reqs
 }
 
+// passwordChanged checks the password of the user against the hash stored in the database. SCRAM verifiers
+// are salted differently every time, so they are verified instead of being compared to a new verifier.
+func (strategy DefaultUserSyncStrategy) passwordChanged(user spec.PgUser, dbPassword string) bool {
+	if !util.PGUserPasswordMatches(user, dbPassword) {
+		return true
+	}
+	if !strategy.MigratePasswordEncryption || util.IsPasswordHash(user.Password) {
+		return false
+	}
+	return util.PasswordEncryptionOf(dbPassword) != strategy.passwordEncryption()
+}
+
+func (strategy DefaultUserSyncStrategy) passwordEncryption() string {
+	if strategy.PasswordEncryption == "" {
+		return constants.PasswordEncryptionMD5
+	}
+	return strategy.PasswordEncryption
+}
+
+func (strategy DefaultUserSyncStrategy) encryptPassword(user spec.PgUser) string {
+	return util.PGUserPasswordEncrypted(user, strategy.passwordEncryption())
+}
+
 // ExecuteSyncRequests makes actual database changes from the requests passed in its arguments.
+// Roles might be created before the roles they are members of, so failed requests are retried as long
+// as fewer of them fail than in the previous attempt.
 func (strategy DefaultUserSyncStrategy) ExecuteSyncRequests(reqs []spec.PgSyncUserRequest, db *sql.DB) error {
+	var (
+		retries []spec.PgSyncUserRequest
+		errs    []string
+	)
 	for _, r := range reqs {
+		var err error
 		switch r.Kind {
 		case spec.PGSyncUserAdd:
-			if err := strategy.createPgUser(r.User, db); err != nil {
-				return fmt.Errorf("could not create user %q: %v", r.User.Name, err)
+			if err = strategy.createPgUser(r.User, db); err != nil {
+				err = fmt.Errorf("could not create user %q: %v", r.User.Name, err)
 			}
 		case spec.PGsyncUserAlter:
-			if err := strategy.alterPgUser(r.User, db); err != nil {
-				return fmt.Errorf("could not alter user %q: %v", r.User.Name, err)
+			if err = strategy.alterPgUser(r.User, db); err != nil {
+				err = fmt.Errorf("could not alter user %q: %v", r.User.Name, err)
 			}
 		case spec.PGSyncAlterSet:
-			if err := strategy.alterPgUserSet(r.User, db); err != nil {
-				return fmt.Errorf("could not set custom user %q parameters: %v", r.User.Name, err)
+			if err = strategy.alterPgUserSet(r.User, db); err != nil {
+				err = fmt.Errorf("could not set custom user %q parameters: %v", r.User.Name, err)
+			}
+		case spec.PGSyncUserRename:
+			if err = strategy.renamePgUser(r.User.Name+strategy.RoleDeletionSuffix, r.User.Name, db); err != nil {
+				err = fmt.Errorf("could not restore deprecated user %q: %v", r.User.Name, err)
+			}
+		case spec.PGSyncUserDeprecate:
+			if err = strategy.deprecatePgUser(r.User, db); err != nil {
+				err = fmt.Errorf("could not deprecate user %q: %v", r.User.Name, err)
 			}
 		default:
 			return fmt.Errorf("unrecognized operation: %v", r.Kind)
 		}
+		if err != nil {
+			retries = append(retries, r)
+			errs = append(errs, err.Error())
+		}
+	}
 
+	if len(retries) == 0 {
+		return nil
 	}
-	return nil
+	if len(retries) < len(reqs) {
+		return strategy.ExecuteSyncRequests(retries, db)
+	}
+	return fmt.Errorf("%s", strings.Join(errs, "; "))
 }
+
 func (strategy DefaultUserSyncStrategy) alterPgUserSet(user spec.PgUser, db *sql.DB) (err error) {
 	queries := produceAlterRoleSetStmts(user)
 	query := fmt.Sprintf(doBlockStmt, strings.Join(queries, ";"))
@@ -122,7 +203,7 @@ func (strategy DefaultUserSyncStrategy) createPgUser(user spec.PgUser, db *sql.D
 	if user.Password == "" {
 		userPassword = "PASSWORD NULL"
 	} else {
-		userPassword = fmt.Sprintf(passwordTemplate, util.PGUserPassword(user))
+		userPassword = fmt.Sprintf(passwordTemplate, strategy.encryptPassword(user))
 	}
 	query := fmt.Sprintf(createUserSQL, user.Name, strings.Join(userFlags, " "), userPassword)
 
@@ -137,7 +218,7 @@ func (strategy DefaultUserSyncStrategy) alterPgUser(user spec.PgUser, db *sql.DB
 	var resultStmt []string
 
 	if user.Password != "" || len(user.Flags) > 0 {
-		alterStmt := produceAlterStmt(user)
+		alterStmt := produceAlterStmt(user, strategy.passwordEncryption())
 		resultStmt = append(resultStmt, alterStmt)
 	}
 	if len(user.MemberOf) > 0 {
@@ -156,14 +237,40 @@ func (strategy DefaultUserSyncStrategy) alterPgUser(user spec.PgUser, db *sql.DB
 	return nil
 }
 
-func produceAlterStmt(user spec.PgUser) string {
+func (strategy DefaultUserSyncStrategy) renamePgUser(oldName, newName string, db *sql.DB) error {
+	query := fmt.Sprintf(renameUserSQL, oldName, newName)
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("dB error: %v query %s", err, query)
+	}
+
+	return nil
+}
+
+// deprecatePgUser renames the role with the deletion suffix and forbids it to log in. The objects
+// and privileges of the role are kept, so that it can be restored.
+func (strategy DefaultUserSyncStrategy) deprecatePgUser(user spec.PgUser, db *sql.DB) error {
+	resultStmt := []string{
+		fmt.Sprintf(alterUserSQL, user.Name, constants.RoleFlagNoLogin),
+		fmt.Sprintf(renameUserSQL, user.Name, user.Name+strategy.RoleDeletionSuffix),
+	}
+	query := fmt.Sprintf(doBlockStmt, strings.Join(resultStmt, ";"))
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("dB error: %v query %s", err, query)
+	}
+
+	return nil
+}
+
+func produceAlterStmt(user spec.PgUser, encryption string) string {
 	// ALTER ROLE ... LOGIN ENCRYPTED PASSWORD ..
 	result := make([]string, 0)
 	password := user.Password
 	flags := user.Flags
 
 	if password != "" {
-		result = append(result, fmt.Sprintf(passwordTemplate, util.PGUserPassword(user)))
+		result = append(result, fmt.Sprintf(passwordTemplate, util.PGUserPasswordEncrypted(user, encryption)))
 	}
 	if len(flags) != 0 {
 		result = append(result, strings.Join(flags, " "))
@@ -0,0 +1,160 @@
+package users
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
+)
+
+type syncRequest struct {
+	kind int
+	name string
+}
+
+func syncRequests(reqs []spec.PgSyncUserRequest) []syncRequest {
+	result := make([]syncRequest, 0)
+	for _, r := range reqs {
+		result = append(result, syncRequest{int(r.Kind), r.User.Name})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].name == result[j].name {
+			return result[i].kind < result[j].kind
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+func TestProduceSyncRequests(t *testing.T) {
+	testName := "TestProduceSyncRequests"
+	foo := spec.PgUser{Name: "foo", Password: "secret", Flags: []string{"LOGIN"}}
+	fooInDB := spec.PgUser{Name: "foo", Password: util.PGUserPassword(foo), Flags: []string{"LOGIN"}}
+	fooSCRAM := util.PGUserPasswordEncrypted(foo, constants.PasswordEncryptionSCRAMSHA256)
+	fooInDBWithSCRAM := spec.PgUser{Name: "foo", Password: fooSCRAM, Flags: []string{"LOGIN"}}
+	fooWithNewPassword := spec.PgUser{Name: "foo", Password: "new secret", Flags: []string{"LOGIN"}}
+	fooDeprecated := spec.PgUser{Name: "foo_deleted", Password: "", Flags: []string{}}
+	bar := spec.PgUser{Name: "bar", Password: "md5bar", Flags: []string{"LOGIN"}}
+	barDeprecated := spec.PgUser{Name: "bar_deleted", Flags: []string{}}
+
+	tests := []struct {
+		subTest    string
+		suffix     string
+		encryption string
+		migrate    bool
+		dbUsers    spec.PgUserMap
+		newUsers   spec.PgUserMap
+		expected   []syncRequest
+	}{
+		{
+			subTest:  "create a new role",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{{spec.PGSyncUserAdd, "foo"}},
+		},
+		{
+			subTest:  "role in sync",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{"foo": fooInDB},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{},
+		},
+		{
+			subTest:  "restore a deprecated role with its login and password",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{"foo_deleted": fooDeprecated},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{{spec.PGsyncUserAlter, "foo"}, {spec.PGSyncUserRename, "foo"}},
+		},
+		{
+			subTest:  "deprecate a removed role",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{"foo": fooInDB, "bar": bar},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{{spec.PGSyncUserDeprecate, "bar"}},
+		},
+		{
+			subTest:  "keep deprecated roles",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{"foo": fooInDB, "bar_deleted": barDeprecated},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{},
+		},
+		{
+			subTest:  "do not deprecate a role when its deprecated name is taken",
+			suffix:   "_deleted",
+			dbUsers:  spec.PgUserMap{"bar": bar, "bar_deleted": barDeprecated},
+			newUsers: spec.PgUserMap{},
+			expected: []syncRequest{},
+		},
+		{
+			subTest:  "deprecation disabled without a suffix",
+			suffix:   "",
+			dbUsers:  spec.PgUserMap{"foo_deleted": fooDeprecated, "bar": bar},
+			newUsers: spec.PgUserMap{"foo": foo},
+			expected: []syncRequest{{spec.PGSyncUserAdd, "foo"}},
+		},
+		{
+			subTest:    "role with a SCRAM verifier in sync",
+			encryption: constants.PasswordEncryptionSCRAMSHA256,
+			dbUsers:    spec.PgUserMap{"foo": fooInDBWithSCRAM},
+			newUsers:   spec.PgUserMap{"foo": foo},
+			expected:   []syncRequest{},
+		},
+		{
+			subTest:    "changed password of a role with a SCRAM verifier",
+			encryption: constants.PasswordEncryptionSCRAMSHA256,
+			dbUsers:    spec.PgUserMap{"foo": fooInDBWithSCRAM},
+			newUsers:   spec.PgUserMap{"foo": fooWithNewPassword},
+			expected:   []syncRequest{{spec.PGsyncUserAlter, "foo"}},
+		},
+		{
+			subTest:    "keep md5 passwords without migration",
+			encryption: constants.PasswordEncryptionSCRAMSHA256,
+			dbUsers:    spec.PgUserMap{"foo": fooInDB},
+			newUsers:   spec.PgUserMap{"foo": foo},
+			expected:   []syncRequest{},
+		},
+		{
+			subTest:    "migrate md5 passwords to SCRAM",
+			encryption: constants.PasswordEncryptionSCRAMSHA256,
+			migrate:    true,
+			dbUsers:    spec.PgUserMap{"foo": fooInDB},
+			newUsers:   spec.PgUserMap{"foo": foo},
+			expected:   []syncRequest{{spec.PGsyncUserAlter, "foo"}},
+		},
+		{
+			subTest:    "keep SCRAM verifiers with md5 encryption",
+			encryption: constants.PasswordEncryptionMD5,
+			dbUsers:    spec.PgUserMap{"foo": fooInDBWithSCRAM},
+			newUsers:   spec.PgUserMap{"foo": foo},
+			expected:   []syncRequest{},
+		},
+	}
+
+	for _, tt := range tests {
+		strategy := DefaultUserSyncStrategy{
+			RoleDeletionSuffix:        tt.suffix,
+			PasswordEncryption:        tt.encryption,
+			MigratePasswordEncryption: tt.migrate,
+		}
+		produced := strategy.ProduceSyncRequests(tt.dbUsers, tt.newUsers)
+		reqs := syncRequests(produced)
+		if !reflect.DeepEqual(reqs, tt.expected) {
+			t.Errorf("%s %s: expected requests %v, got %v", testName, tt.subTest, tt.expected, reqs)
+		}
+		for _, r := range produced {
+			if r.Kind != spec.PGsyncUserAlter || r.User.Password == "" {
+				continue
+			}
+			if encryption := util.PasswordEncryptionOf(r.User.Password); encryption != strategy.passwordEncryption() {
+				t.Errorf("%s %s: expected a password hashed with %s, got %q", testName, tt.subTest,
+					strategy.passwordEncryption(), r.User.Password)
+			}
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package util
 
 import (
+	"crypto/hmac"
 	"crypto/md5" // #nosec we need it to for PostgreSQL md5 passwords
 	cryptoRand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"math/rand"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,12 +22,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
 )
 
 const (
-	md5prefix = "md5"
+	md5prefix         = "md5"
+	scramsha256prefix = "SCRAM-SHA-256"
+
+	// parameters PostgreSQL uses for SCRAM verifiers
+	scramIterations = 4096
+	scramSaltLength = 16
 )
 
+var scramVerifierRegexp = regexp.MustCompile(`^SCRAM-SHA-256\$(\d+):([A-Za-z0-9+/=]+)\$([A-Za-z0-9+/=]+):([A-Za-z0-9+/=]+)$`)
+
 var passwordChars = []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 
 func init() {
@@ -63,7 +77,7 @@ func NameFromMeta(meta metav1.ObjectMeta) spec.NamespacedName {
 
 // PGUserPassword is used to generate md5 password hash for a given user. It does nothing for already hashed passwords.
 func PGUserPassword(user spec.PgUser) string {
-	if (len(user.Password) == md5.Size*2+len(md5prefix) && user.Password[:3] == md5prefix) || user.Password == "" {
+	if IsPasswordHash(user.Password) || user.Password == "" {
 		// Avoid processing already encrypted or empty passwords
 		return user.Password
 	}
@@ -71,6 +85,97 @@ func PGUserPassword(user spec.PgUser) string {
 	return md5prefix + hex.EncodeToString(s[:])
 }
 
+// PGUserPasswordEncrypted generates the password hash of a given user with the encryption method, md5 or
+// scram-sha-256. SCRAM verifiers are computed client-side with a random salt, so that the plain password
+// never reaches the server. It does nothing for already hashed passwords.
+func PGUserPasswordEncrypted(user spec.PgUser, encryption string) string {
+	if encryption != constants.PasswordEncryptionSCRAMSHA256 || IsPasswordHash(user.Password) || user.Password == "" {
+		return PGUserPassword(user)
+	}
+	salt := make([]byte, scramSaltLength)
+	if _, err := cryptoRand.Read(salt); err != nil {
+		panic(fmt.Errorf("Unable to generate secure, random salt: %v", err))
+	}
+	return scramVerifier(user.Password, salt, scramIterations)
+}
+
+// IsPasswordHash checks if the password is an md5 hash or a SCRAM-SHA-256 verifier
+func IsPasswordHash(password string) bool {
+	return PasswordEncryptionOf(password) != ""
+}
+
+// PasswordEncryptionOf returns the encryption method of a password hash, or an empty string for passwords in clear text
+func PasswordEncryptionOf(password string) string {
+	if len(password) == md5.Size*2+len(md5prefix) && password[:len(md5prefix)] == md5prefix {
+		return constants.PasswordEncryptionMD5
+	}
+	if scramVerifierRegexp.MatchString(password) {
+		return constants.PasswordEncryptionSCRAMSHA256
+	}
+	return ""
+}
+
+// PGUserPasswordMatches checks if the password of a given user matches the hash stored in the database. SCRAM
+// verifiers are salted, so they are recomputed with the stored salt instead of being compared to a new one.
+func PGUserPasswordMatches(user spec.PgUser, hash string) bool {
+	if IsPasswordHash(user.Password) || user.Password == "" {
+		return user.Password == hash
+	}
+	switch PasswordEncryptionOf(hash) {
+	case constants.PasswordEncryptionMD5:
+		return PGUserPassword(user) == hash
+	case constants.PasswordEncryptionSCRAMSHA256:
+		matches := scramVerifierRegexp.FindStringSubmatch(hash)
+		iterations, err := strconv.Atoi(matches[1])
+		if err != nil || iterations <= 0 {
+			return false
+		}
+		salt, err := base64.StdEncoding.DecodeString(matches[2])
+		if err != nil {
+			return false
+		}
+		expected := scramVerifier(user.Password, salt, iterations)
+		return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1
+	}
+	return false
+}
+
+// scramVerifier computes a SCRAM-SHA-256 verifier in the format PostgreSQL stores in pg_authid, see RFC 5802.
+// PostgreSQL normalizes passwords with SASLprep, which leaves the ASCII passwords generated by the operator unchanged.
+func scramVerifier(password string, salt []byte, iterations int) string {
+	saltedPassword := pbkdf2SHA256([]byte(password), salt, iterations)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	storedKey := sha256.Sum256(clientKey)
+	serverKey := hmacSHA256(saltedPassword, []byte("Server Key"))
+
+	return fmt.Sprintf("%s$%d:%s$%s:%s", scramsha256prefix, iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey[:]),
+		base64.StdEncoding.EncodeToString(serverKey))
+}
+
+// pbkdf2SHA256 derives a key of the size of one SHA-256 block, as SCRAM does not need longer ones
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	block := make([]byte, 4)
+	binary.BigEndian.PutUint32(block, 1)
+
+	u := hmacSHA256(password, append(append([]byte{}, salt...), block...))
+	result := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		u = hmacSHA256(password, u)
+		for j := range result {
+			result[j] ^= u[j]
+		}
+	}
+	return result
+}
+
+func hmacSHA256(key, message []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
 // Diff returns diffs between 2 objects
 func Diff(a, b interface{}) []string {
 	return pretty.Diff(a, b)
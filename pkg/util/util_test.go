@@ -9,6 +9,7 @@ import (
 	"regexp"
 
 	"github.com/zalando/postgres-operator/pkg/spec"
+	"github.com/zalando/postgres-operator/pkg/util/constants"
 )
 
 var pgUsers = []struct {
@@ -114,6 +115,44 @@ func TestPGUserPassword(t *testing.T) {
 	}
 }
 
+func TestSCRAMVerifier(t *testing.T) {
+	expected := "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$bpSY5Ze9NUH+I35LC3gVq+DpBfK46iXBxvhAKqVu9pE=:VpYlBuxyzeCI1KnctrefdljpB1mk3Gp7sBI/t11+NkQ="
+	if verifier := scramVerifier("secret", []byte("0123456789abcdef"), 4096); verifier != expected {
+		t.Errorf("scramVerifier expected: %q, got: %q", expected, verifier)
+	}
+}
+
+func TestPGUserPasswordMatches(t *testing.T) {
+	user := spec.PgUser{Name: "test", Password: "password"}
+	scram := PGUserPasswordEncrypted(user, constants.PasswordEncryptionSCRAMSHA256)
+	if PasswordEncryptionOf(scram) != constants.PasswordEncryptionSCRAMSHA256 {
+		t.Errorf("PGUserPasswordEncrypted expected a SCRAM-SHA-256 verifier, got: %q", scram)
+	}
+	if PGUserPasswordEncrypted(user, constants.PasswordEncryptionSCRAMSHA256) == scram {
+		t.Errorf("PGUserPasswordEncrypted expected a new salt for every verifier")
+	}
+
+	tests := []struct {
+		password string
+		hash     string
+		out      bool
+	}{
+		{"password", "md587f77988ccb5aa917c93201ba314fcd4", true},
+		{"password", scram, true},
+		{"other", "md587f77988ccb5aa917c93201ba314fcd4", false},
+		{"other", scram, false},
+		{"md587f77988ccb5aa917c93201ba314fcd4", "md587f77988ccb5aa917c93201ba314fcd4", true},
+		{scram, scram, true},
+		{"password", "", false},
+	}
+	for _, tt := range tests {
+		user := spec.PgUser{Name: "test", Password: tt.password}
+		if actual := PGUserPasswordMatches(user, tt.hash); actual != tt.out {
+			t.Errorf("PGUserPasswordMatches(%q, %q) expected: %t, got: %t", tt.password, tt.hash, tt.out, actual)
+		}
+	}
+}
+
 func TestPrettyDiff(t *testing.T) {
 	for _, tt := range prettyDiffTest {
 		if actual := PrettyDiff(tt.inA, tt.inB); actual != tt.out {
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+const (
+	shutdownTimeout = time.Second * 10
+	httpReadTimeout = time.Second * 10
+
+	// the API server gives up on webhooks after 30 seconds at most
+	httpWriteTimeout = time.Second * 30
+
+	validatePostgresqlPath            = "/validate/postgresql"
+	validateOperatorConfigurationPath = "/validate/operatorconfiguration"
+	mutatePostgresqlPath              = "/mutate/postgresql"
+)
+
+// admissionController describes the validation and defaulting methods of a controller
+type admissionController interface {
+	ValidatePostgresql(oldPg, newPg *acidv1.Postgresql) error
+	ValidateOperatorConfiguration(cfg *acidv1.OperatorConfiguration) error
+	DefaultPostgresql(pg *acidv1.Postgresql) []string
+}
+
+// patchOperation is a single operation of a JSON patch (RFC 6902)
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Server describes the admission webhook server
+type Server struct {
+	logger     *logrus.Entry
+	http       http.Server
+	controller admissionController
+	certFile   string
+	keyFile    string
+}
+
+// New creates a new admission webhook server
+func New(controller admissionController, port int, certFile, keyFile string, logger *logrus.Logger) *Server {
+	s := &Server{
+		logger:     logger.WithField("pkg", "webhook"),
+		controller: controller,
+		certFile:   certFile,
+		keyFile:    keyFile,
+	}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(validatePostgresqlPath, s.validatePostgresql)
+	mux.HandleFunc(validateOperatorConfigurationPath, s.validateOperatorConfiguration)
+	mux.HandleFunc(mutatePostgresqlPath, s.mutatePostgresql)
+
+	s.http = http.Server{
+		Addr:         fmt.Sprintf(":%d", port),
+		Handler:      mux,
+		ReadTimeout:  httpReadTimeout,
+		WriteTimeout: httpWriteTimeout,
+	}
+
+	return s
+}
+
+// Run starts the HTTPS server
+func (s *Server) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	go func() {
+		if err := s.http.ListenAndServeTLS(s.certFile, s.keyFile); err != http.ErrServerClosed {
+			s.logger.Fatalf("could not start admission webhook server: %v", err)
+		}
+	}()
+	s.logger.Infof("listening on %s", s.http.Addr)
+
+	<-stopCh
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.http.Shutdown(ctx)
+	if err == nil {
+		s.logger.Infoln("admission webhook server shut down")
+		return
+	}
+	if err == context.DeadlineExceeded {
+		s.logger.Warningf("shutdown timeout exceeded, closing admission webhook server")
+		if err = s.http.Close(); err != nil {
+			s.logger.Errorf("could not close admission webhook server: %v", err)
+		}
+		return
+	}
+	s.logger.Errorf("could not shut down admission webhook server: %v", err)
+}
+
+func (s *Server) validatePostgresql(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func(request *admissionv1beta1.AdmissionRequest) ([]patchOperation, error) {
+		var oldPg *acidv1.Postgresql
+		newPg := &acidv1.Postgresql{}
+
+		// decoding does not fail on invalid cluster names, those are recorded in the Error field instead
+		if err := json.Unmarshal(request.Object.Raw, newPg); err != nil {
+			return nil, fmt.Errorf("could not decode postgresql manifest: %v", err)
+		}
+		if len(request.OldObject.Raw) > 0 {
+			oldPg = &acidv1.Postgresql{}
+			if err := json.Unmarshal(request.OldObject.Raw, oldPg); err != nil {
+				return nil, fmt.Errorf("could not decode previous postgresql manifest: %v", err)
+			}
+		}
+
+		return nil, s.controller.ValidatePostgresql(oldPg, newPg)
+	})
+}
+
+func (s *Server) validateOperatorConfiguration(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func(request *admissionv1beta1.AdmissionRequest) ([]patchOperation, error) {
+		cfg := &acidv1.OperatorConfiguration{}
+		if err := json.Unmarshal(request.Object.Raw, cfg); err != nil {
+			return nil, fmt.Errorf("could not decode operator configuration: %v", err)
+		}
+
+		return nil, s.controller.ValidateOperatorConfiguration(cfg)
+	})
+}
+
+// mutatePostgresql fills the operator defaults into new Postgres manifests. Updates are left
+// untouched, so changed defaults do not overwrite values stored on creation.
+func (s *Server) mutatePostgresql(w http.ResponseWriter, req *http.Request) {
+	s.serve(w, req, func(request *admissionv1beta1.AdmissionRequest) ([]patchOperation, error) {
+		if request.Operation != admissionv1beta1.Create {
+			return nil, nil
+		}
+
+		pg := &acidv1.Postgresql{}
+		if err := json.Unmarshal(request.Object.Raw, pg); err != nil {
+			return nil, fmt.Errorf("could not decode postgresql manifest: %v", err)
+		}
+		// invalid manifests are rejected by the validating webhook or marked as invalid by the operator
+		if pg.Error != "" {
+			return nil, nil
+		}
+
+		changed := s.controller.DefaultPostgresql(pg)
+		if len(changed) == 0 {
+			return nil, nil
+		}
+
+		// take the values from the serialized spec to get exactly the JSON of the manifest fields
+		specJSON, err := json.Marshal(pg.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("could not encode defaulted postgresql spec: %v", err)
+		}
+		spec := make(map[string]interface{})
+		if err := json.Unmarshal(specJSON, &spec); err != nil {
+			return nil, fmt.Errorf("could not decode defaulted postgresql spec: %v", err)
+		}
+
+		patch := make([]patchOperation, 0, len(changed))
+		for _, field := range changed {
+			// "add" replaces the value if the field already exists
+			patch = append(patch, patchOperation{Op: "add", Path: "/spec/" + field, Value: spec[field]})
+		}
+		return patch, nil
+	})
+}
+
+// serve decodes the admission review, runs the admission function and writes back the verdict
+// together with the patch to apply, if any. Deletions are always admitted.
+func (s *Server) serve(w http.ResponseWriter, req *http.Request,
+	admit func(*admissionv1beta1.AdmissionRequest) ([]patchOperation, error)) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		s.logger.Errorf("could not read admission review: %v", err)
+		http.Error(w, "could not read request body", http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		s.logger.Errorf("could not decode admission review: %v", err)
+		http.Error(w, "could not decode admission review", http.StatusBadRequest)
+		return
+	}
+
+	request := review.Request
+	response := &admissionv1beta1.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+	if request.Operation != admissionv1beta1.Delete {
+		patch, err := admit(request)
+		if err == nil && len(patch) > 0 {
+			response.Patch, err = json.Marshal(patch)
+			patchType := admissionv1beta1.PatchTypeJSONPatch
+			response.PatchType = &patchType
+		}
+		if err != nil {
+			s.logger.Infof("rejected %s of %s %q in namespace %q: %v",
+				request.Operation, request.Kind.Kind, request.Name, request.Namespace, err)
+			response.Allowed = false
+			response.Patch = nil
+			response.PatchType = nil
+			response.Result = &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+				Reason:  metav1.StatusReasonInvalid,
+				Code:    http.StatusUnprocessableEntity,
+			}
+		}
+	}
+
+	review.Response = response
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		s.logger.Errorf("could not encode admission review response: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	acidv1 "github.com/zalando/postgres-operator/pkg/apis/acid.zalan.do/v1"
+)
+
+type mockController struct{}
+
+func (c *mockController) DefaultPostgresql(pg *acidv1.Postgresql) []string {
+	if pg.Spec.DockerImage != "" {
+		return nil
+	}
+	pg.Spec.DockerImage = "spilo:latest"
+	return []string{"dockerImage"}
+}
+
+func (c *mockController) ValidatePostgresql(oldPg, newPg *acidv1.Postgresql) error {
+	if oldPg != nil && oldPg.Spec.TeamID != newPg.Spec.TeamID {
+		return fmt.Errorf("teamId cannot be changed")
+	}
+	return nil
+}
+
+func (c *mockController) ValidateOperatorConfiguration(cfg *acidv1.OperatorConfiguration) error {
+	if cfg.Configuration.Workers == 0 {
+		return fmt.Errorf("number of workers should be higher than 0")
+	}
+	return nil
+}
+
+func newPostgresql(teamID string) []byte {
+	pg, _ := json.Marshal(acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: teamID + "-test"},
+		Spec:       acidv1.PostgresSpec{TeamID: teamID},
+	})
+	return pg
+}
+
+func TestAdmissionReview(t *testing.T) {
+	testName := "TestAdmissionReview"
+	server := New(&mockController{}, 8443, "", "", logrus.New())
+
+	// marshalling acidv1.OperatorConfiguration would emit the empty namespaced
+	// names, which cannot be decoded again outside of the operator pod
+	validConfig := []byte(`{"kind":"OperatorConfiguration","configuration":{"workers":4}}`)
+	invalidConfig := []byte(`{"kind":"OperatorConfiguration","configuration":{}}`)
+
+	tests := []struct {
+		subTest   string
+		path      string
+		operation admissionv1beta1.Operation
+		object    []byte
+		oldObject []byte
+		allowed   bool
+	}{
+		{
+			subTest:   "create postgresql",
+			path:      validatePostgresqlPath,
+			operation: admissionv1beta1.Create,
+			object:    newPostgresql("acid"),
+			allowed:   true,
+		},
+		{
+			subTest:   "update postgresql with changed team",
+			path:      validatePostgresqlPath,
+			operation: admissionv1beta1.Update,
+			object:    newPostgresql("foo"),
+			oldObject: newPostgresql("acid"),
+			allowed:   false,
+		},
+		{
+			subTest:   "delete is always allowed",
+			path:      validatePostgresqlPath,
+			operation: admissionv1beta1.Delete,
+			object:    newPostgresql("foo"),
+			oldObject: newPostgresql("acid"),
+			allowed:   true,
+		},
+		{
+			subTest:   "valid operator configuration",
+			path:      validateOperatorConfigurationPath,
+			operation: admissionv1beta1.Update,
+			object:    validConfig,
+			allowed:   true,
+		},
+		{
+			subTest:   "invalid operator configuration",
+			path:      validateOperatorConfigurationPath,
+			operation: admissionv1beta1.Create,
+			object:    invalidConfig,
+			allowed:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		review := admissionv1beta1.AdmissionReview{
+			Request: &admissionv1beta1.AdmissionRequest{
+				UID:       types.UID("test-uid"),
+				Operation: tt.operation,
+				Object:    runtime.RawExtension{Raw: tt.object},
+				OldObject: runtime.RawExtension{Raw: tt.oldObject},
+			},
+		}
+		body, _ := json.Marshal(review)
+
+		req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		server.http.Handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s: Expected status code 200, got %d", testName, tt.subTest, rec.Code)
+			continue
+		}
+		result := admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Errorf("%s %s: Could not decode response: %v", testName, tt.subTest, err)
+			continue
+		}
+		if result.Response == nil || result.Response.UID != "test-uid" {
+			t.Errorf("%s %s: Expected response for request test-uid, got %#v", testName, tt.subTest, result.Response)
+			continue
+		}
+		if result.Response.Allowed != tt.allowed {
+			t.Errorf("%s %s: Expected allowed to be %t, got %t", testName, tt.subTest, tt.allowed, result.Response.Allowed)
+		}
+		if !tt.allowed && (result.Response.Result == nil || result.Response.Result.Message == "") {
+			t.Errorf("%s %s: Expected a reason for the rejection", testName, tt.subTest)
+		}
+	}
+}
+
+func TestInvalidAdmissionReview(t *testing.T) {
+	server := New(&mockController{}, 8443, "", "", logrus.New())
+
+	req := httptest.NewRequest(http.MethodPost, validatePostgresqlPath, bytes.NewReader([]byte("{}")))
+	rec := httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code 400 for a review without request, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, validatePostgresqlPath, nil)
+	rec = httptest.NewRecorder()
+	server.http.Handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code 405 for GET requests, got %d", rec.Code)
+	}
+}
+
+func TestMutatePostgresql(t *testing.T) {
+	testName := "TestMutatePostgresql"
+	server := New(&mockController{}, 8443, "", "", logrus.New())
+
+	withImage, _ := json.Marshal(acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test"},
+		Spec:       acidv1.PostgresSpec{TeamID: "acid", DockerImage: "custom"},
+	})
+	// the cluster name does not start with the team, so decoding records an error
+	invalidName, _ := json.Marshal(acidv1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       acidv1.PostgresSpec{TeamID: "acid"},
+	})
+
+	tests := []struct {
+		subTest   string
+		operation admissionv1beta1.Operation
+		object    []byte
+		patch     string
+	}{
+		{
+			subTest:   "default docker image on create",
+			operation: admissionv1beta1.Create,
+			object:    newPostgresql("acid"),
+			patch:     `[{"op":"add","path":"/spec/dockerImage","value":"spilo:latest"}]`,
+		},
+		{
+			subTest:   "keep docker image set by the user",
+			operation: admissionv1beta1.Create,
+			object:    withImage,
+		},
+		{
+			subTest:   "invalid manifests are not defaulted",
+			operation: admissionv1beta1.Create,
+			object:    invalidName,
+		},
+		{
+			subTest:   "updates are not defaulted",
+			operation: admissionv1beta1.Update,
+			object:    newPostgresql("acid"),
+		},
+	}
+
+	for _, tt := range tests {
+		review := admissionv1beta1.AdmissionReview{
+			Request: &admissionv1beta1.AdmissionRequest{
+				UID:       types.UID("test-uid"),
+				Operation: tt.operation,
+				Object:    runtime.RawExtension{Raw: tt.object},
+			},
+		}
+		body, _ := json.Marshal(review)
+
+		req := httptest.NewRequest(http.MethodPost, mutatePostgresqlPath, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		server.http.Handler.ServeHTTP(rec, req)
+
+		result := admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || result.Response == nil {
+			t.Errorf("%s %s: Could not decode response: %v", testName, tt.subTest, err)
+			continue
+		}
+		if !result.Response.Allowed {
+			t.Errorf("%s %s: Expected request to be allowed", testName, tt.subTest)
+		}
+		if string(result.Response.Patch) != tt.patch {
+			t.Errorf("%s %s: Expected patch %s, have %s instead", testName, tt.subTest, tt.patch, result.Response.Patch)
+		}
+		if (tt.patch != "") != (result.Response.PatchType != nil) {
+			t.Errorf("%s %s: Expected patch type to be set only with a patch", testName, tt.subTest)
+		}
+	}
+}